@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"bgc-app/internal/business/market"
+	"bgc-app/internal/business/trade"
 )
 
 type MarketHandler struct {
@@ -36,9 +38,14 @@ func (h *MarketHandler) GetMarketSize(c *gin.Context) {
 		YearTo:     yearTo,
 		NCMChapter: ncmChapter,
 		Scenario:   scenario,
+		Flow:       trade.Flow(c.Query("flow")),
 	}
 
 	result, err := h.service.CalculateMarketSize(req)
+	if errors.Is(err, trade.ErrInvalidFlow) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"bgc-app/internal/business/destination"
+	"bgc-app/internal/business/trade"
 
 	"github.com/gin-gonic/gin"
 )
@@ -71,6 +73,15 @@ func (h *SimulatorHandler) SimulateDestinations(c *gin.Context) {
 
 // handleError trata erros de forma consistente
 func (h *SimulatorHandler) handleError(c *gin.Context, err error) {
+	// trade.ParseFlow embrulha ErrInvalidFlow com os fluxos aceitos
+	if errors.Is(err, trade.ErrInvalidFlow) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
 	switch err {
 	case destination.ErrInvalidNCM, destination.ErrInvalidVolume, destination.ErrInvalidMaxResults:
 		c.JSON(http.StatusBadRequest, ErrorResponse{
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"bgc-app/internal/gatewayclient"
 )
 
 type routeMetrics struct {
@@ -44,6 +46,7 @@ func RequestID() gin.HandlerFunc {
 			rid = newReqID()
 		}
 		c.Set("req_id", rid)
+		c.Request = c.Request.WithContext(gatewayclient.WithRequestID(c.Request.Context(), rid))
 		c.Writer.Header().Set("X-Request-Id", rid)
 		c.Next()
 	}
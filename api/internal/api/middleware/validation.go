@@ -31,6 +31,7 @@ func (vm *ValidationMiddleware) ValidateMarketSizeRequest() gin.HandlerFunc {
 			"year_to":     c.Query("year_to"),
 			"ncm_chapter": c.Query("ncm_chapter"),
 			"scenario":    c.Query("scenario"),
+			"flow":        c.Query("flow"),
 		}
 
 		// Remove empty optional parameters
@@ -40,6 +41,9 @@ func (vm *ValidationMiddleware) ValidateMarketSizeRequest() gin.HandlerFunc {
 		if params["scenario"] == "" {
 			delete(params, "scenario")
 		}
+		if params["flow"] == "" {
+			delete(params, "flow")
+		}
 
 		// Convert year_from and year_to to integers if present
 		if yearFrom := c.Query("year_from"); yearFrom != "" {
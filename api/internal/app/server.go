@@ -18,6 +18,7 @@ import (
 	"bgc-app/internal/business/market"
 	"bgc-app/internal/business/route"
 	"bgc-app/internal/config"
+	"bgc-app/internal/gatewayclient"
 	"bgc-app/internal/observability/metrics"
 	"bgc-app/internal/repository/postgres"
 )
@@ -40,7 +41,13 @@ func NewServer(cfg *config.AppConfig, db *sql.DB) *Server {
 	marketService := market.NewService(marketRepo, cfg)
 	routeService := route.NewService(routeRepo, weights, tariffs)
 	healthService := health.NewService(cfg, weights, tariffs)
-	destinationService := destination.NewService(destinationRepo)
+	var destinationOpts []destination.Option
+	if cfg.GatewayURL != "" {
+		gateway := gatewayclient.New(cfg.GatewayURL, gatewayclient.WithAPIKey(cfg.GatewayAPIKey))
+		destinationOpts = append(destinationOpts, destination.WithGateway(gateway))
+		log.Printf("Integration gateway enabled for live data: %s", cfg.GatewayURL)
+	}
+	destinationService := destination.NewService(destinationRepo, destinationOpts...)
 
 	marketHandler := handlers.NewMarketHandler(marketService)
 	routeHandler := handlers.NewRouteHandler(routeService)
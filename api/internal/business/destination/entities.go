@@ -1,6 +1,10 @@
 package destination
 
-import "time"
+import (
+	"time"
+
+	"bgc-app/internal/business/trade"
+)
 
 // DestinationRecommendation representa uma recomendação de destino de exportação
 type DestinationRecommendation struct {
@@ -20,6 +24,27 @@ type DestinationRecommendation struct {
 	Region               string  `json:"region"`                  // Região geográfica
 	FlagEmoji            string  `json:"flag_emoji,omitempty"`    // Emoji da bandeira
 	RecommendationReason string  `json:"recommendation_reason"`   // Explicação do score
+	LiveData             *LiveMarketData `json:"live_data,omitempty"` // Dados ao vivo do ComexStat (se disponíveis)
+	TradeBalance         *TradeBalance   `json:"trade_balance,omitempty"` // Exportações - importações com o país no NCM
+}
+
+// TradeBalance saldo comercial do Brasil com um país em um NCM (últimos 12 meses)
+type TradeBalance struct {
+	NCM         string  `json:"ncm"`
+	CountryCode string  `json:"country_code"`
+	ExportsUSD  float64 `json:"exports_usd"` // Soma de vl_fob em stg.exportacao
+	ImportsUSD  float64 `json:"imports_usd"` // Soma de vl_fob em stg.importacao
+	BalanceUSD  float64 `json:"balance_usd"` // ExportsUSD - ImportsUSD (negativo = déficit)
+}
+
+// LiveMarketData dados do fluxo (exportação ou importação) do último mês publicados no ComexStat (via integration gateway)
+type LiveMarketData struct {
+	Year          int     `json:"year"`
+	Month         int     `json:"month"`
+	ValueUSD      float64 `json:"value_usd"`        // Soma de VL_FOB do NCM para o país
+	WeightKg      float64 `json:"weight_kg"`        // Soma de KG_LIQUIDO
+	PricePerKgUSD float64 `json:"price_per_kg_usd"` // ValueUSD / WeightKg
+	Source        string  `json:"source"`
 }
 
 // SimulatorRequest representa a requisição ao simulador
@@ -29,6 +54,7 @@ type SimulatorRequest struct {
 	Countries   []string `json:"countries,omitempty"`                  // Lista de países específicos (opcional)
 	MaxResults  int      `json:"max_results,omitempty"`                // Número máximo de resultados (default: 10)
 	IncludeAll  bool     `json:"include_all,omitempty"`                // Incluir todos os países disponíveis
+	Flow        trade.Flow `json:"flow,omitempty"`                     // export (default) ou import (países de origem)
 }
 
 // SimulatorResponse representa a resposta do simulador
@@ -40,12 +66,14 @@ type SimulatorResponse struct {
 // SimulatorMetadata metadados sobre a análise
 type SimulatorMetadata struct {
 	NCM              string    `json:"ncm"`                // NCM analisado
+	Flow             trade.Flow `json:"flow"`              // Fluxo analisado (export, import)
 	ProductName      string    `json:"product_name"`       // Nome do produto (se disponível)
 	AnalysisDate     time.Time `json:"analysis_date"`      // Data da análise
 	TotalDestinations int      `json:"total_destinations"` // Total de destinos analisados
 	CacheHit         bool      `json:"cache_hit"`          // Foi cache hit?
 	CacheLevel       string    `json:"cache_level,omitempty"` // Nível do cache (l1, l2, l3)
 	ProcessingTimeMs int64     `json:"processing_time_ms"` // Tempo de processamento
+	LiveDataStatus   string    `json:"live_data_status,omitempty"` // ok, unavailable (vazio = gateway não configurado)
 }
 
 // CountryMetadata representa os metadados de um país
@@ -128,6 +156,13 @@ func (r *SimulatorRequest) ValidateSimulatorRequest() error {
 		r.MaxResults = 10
 	}
 
+	// Fluxo: exportação por padrão
+	flow, err := trade.ParseFlow(string(r.Flow), trade.SimulatorFlows)
+	if err != nil {
+		return err
+	}
+	r.Flow = flow
+
 	return nil
 }
 
@@ -1,7 +1,10 @@
 package destination
 
 import (
+	"errors"
 	"testing"
+
+	"bgc-app/internal/business/trade"
 )
 
 // TestValidateSimulatorRequest testa a validação de requisições
@@ -104,12 +107,28 @@ func TestValidateSimulatorRequest(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "valid import flow",
+			req: SimulatorRequest{
+				NCM:  "12345678",
+				Flow: trade.FlowImport,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "invalid flow",
+			req: SimulatorRequest{
+				NCM:  "12345678",
+				Flow: "transit",
+			},
+			wantErr: trade.ErrInvalidFlow,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			err := tt.req.ValidateSimulatorRequest()
-			if err != tt.wantErr {
+			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("ValidateSimulatorRequest() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
@@ -124,6 +143,17 @@ func TestValidateSimulatorRequest(t *testing.T) {
 	}
 }
 
+// TestValidateSimulatorRequest_DefaultFlow testa que o fluxo padrão é exportação
+func TestValidateSimulatorRequest_DefaultFlow(t *testing.T) {
+	req := SimulatorRequest{NCM: "12345678"}
+	if err := req.ValidateSimulatorRequest(); err != nil {
+		t.Fatalf("ValidateSimulatorRequest() error = %v", err)
+	}
+	if req.Flow != trade.FlowExport {
+		t.Errorf("Flow = %q, expected %q", req.Flow, trade.FlowExport)
+	}
+}
+
 // TestCalculateScore testa o cálculo de score
 func TestCalculateScore(t *testing.T) {
 	tests := []struct {
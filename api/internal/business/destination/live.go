@@ -0,0 +1,147 @@
+package destination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"bgc-app/internal/business/trade"
+)
+
+// Connector/endpoints do integration gateway usados para dados ao vivo
+const (
+	liveDataConnector      = "comexstat"
+	liveDataEndpoint       = "exportacao_mes"
+	liveDataImportEndpoint = "importacao_mes"
+)
+
+// Status do enriquecimento com dados ao vivo
+const (
+	LiveDataOK          = "ok"
+	LiveDataUnavailable = "unavailable"
+)
+
+// enrichWithLiveData adiciona às recomendações as exportações (ou importações) do último mês publicado no ComexStat
+func (s *Service) enrichWithLiveData(ctx context.Context, ncm string, flow trade.Flow, recommendations []DestinationRecommendation) string {
+	if s.gateway == nil || len(recommendations) == 0 {
+		return ""
+	}
+
+	endpoint := liveDataEndpoint
+	if flow == trade.FlowImport {
+		endpoint = liveDataImportEndpoint
+	}
+
+	// O connector devolve co_pais com o código numérico da ComexStat (ex.: 249 = US)
+	countries, err := s.repo.GetComexStatCountries(ctx)
+	if err == nil && len(countries) == 0 {
+		err = errors.New("stg.comexstat_paises is empty (run bgc-ingest sync-comexstat)")
+	}
+	if err != nil {
+		log.Printf("destination: live data skipped: comexstat country codes: %v", err)
+		return LiveDataUnavailable
+	}
+
+	year, month := liveDataPeriod(s.now())
+	result, err := s.gateway.Execute(ctx, liveDataConnector, endpoint, map[string]interface{}{
+		"ano": year,
+		"mes": month,
+	})
+	if err != nil {
+		log.Printf("destination: live data skipped: %v", fmt.Errorf("%w: %v", ErrExternalAPIFailed, err))
+		return LiveDataUnavailable
+	}
+
+	live := aggregateLiveRows(result.Data["rows"], ncm, year, month, countries)
+	for i := range recommendations {
+		if data, ok := live[recommendations[i].CountryCode]; ok {
+			recommendations[i].LiveData = data
+		}
+	}
+
+	return LiveDataOK
+}
+
+// liveDataPeriod mês anterior ao atual (ComexStat publica com defasagem de um mês)
+func liveDataPeriod(now time.Time) (year, month int) {
+	previous := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	return previous.Year(), int(previous.Month())
+}
+
+// aggregateLiveRows soma as linhas do NCM por país ISO (campos mapeados pelo connector comexstat)
+func aggregateLiveRows(rows interface{}, ncm string, year, month int, countries map[int]string) map[string]*LiveMarketData {
+	items, _ := rows.([]interface{})
+	result := make(map[string]*LiveMarketData)
+
+	for _, item := range items {
+		row, ok := item.(map[string]interface{})
+		if !ok || fmt.Sprint(row["co_ncm"]) != ncm {
+			continue
+		}
+
+		country := liveCountryCode(row["co_pais"], countries)
+		if country == "" {
+			continue
+		}
+
+		data, exists := result[country]
+		if !exists {
+			data = &LiveMarketData{Year: year, Month: month, Source: liveDataConnector}
+			result[country] = data
+		}
+		data.ValueUSD += toFloat(row["vl_fob"])
+		data.WeightKg += toFloat(row["kg_liquido"])
+	}
+
+	for _, data := range result {
+		if data.WeightKg > 0 {
+			data.PricePerKgUSD = data.ValueUSD / data.WeightKg
+		}
+	}
+
+	return result
+}
+
+// liveCountryCode converte o co_pais da linha (código numérico da ComexStat, texto ou número)
+// para ISO alpha-2; códigos que já são alpha-2 passam direto, desconhecidos retornam vazio
+func liveCountryCode(value interface{}, countries map[int]string) string {
+	var code string
+	switch v := value.(type) {
+	case string:
+		code = strings.TrimSpace(v)
+	case float64:
+		code = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+
+	if n, err := strconv.Atoi(code); err == nil {
+		return countries[n]
+	}
+	if len(code) == 2 {
+		return strings.ToUpper(code)
+	}
+	return ""
+}
+
+// toFloat converte números JSON (float64) ou strings numéricas
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case string:
+		var f float64
+		fmt.Sscan(v, &f)
+		return f
+	default:
+		return 0
+	}
+}
@@ -2,16 +2,22 @@ package destination
 
 import (
 	"context"
+	"log"
 	"sort"
 	"time"
+
+	"bgc-app/internal/business/trade"
+	"bgc-app/internal/gatewayclient"
 )
 
 // Repository define a interface para acesso a dados
 type Repository interface {
 	GetCountryMetadata(ctx context.Context, countryCode string) (*CountryMetadata, error)
 	GetAllCountries(ctx context.Context) ([]CountryMetadata, error)
-	GetMarketDataByNCM(ctx context.Context, ncm string, year, month int) ([]MarketData, error)
-	GetMarketDataByNCMAndCountry(ctx context.Context, ncm, countryCode string, year, month int) (*MarketData, error)
+	GetMarketDataByNCM(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error)
+	GetMarketDataByNCMAndCountry(ctx context.Context, ncm, countryCode string, flow trade.Flow, year, month int) (*MarketData, error)
+	GetTradeBalanceByNCM(ctx context.Context, ncm string, year, month int) ([]TradeBalance, error)
+	GetComexStatCountries(ctx context.Context) (map[int]string, error)
 }
 
 // ServiceInterface define a interface do serviço de destinos
@@ -23,14 +29,31 @@ type ServiceInterface interface {
 type Service struct {
 	repo    Repository
 	weights ScoringWeights
+	gateway gatewayclient.API
+	now     func() time.Time
+}
+
+// Option configura o Service
+type Option func(*Service)
+
+// WithGateway habilita o enriquecimento com dados ao vivo do ComexStat via integration gateway
+func WithGateway(gateway gatewayclient.API) Option {
+	return func(s *Service) {
+		s.gateway = gateway
+	}
 }
 
 // NewService cria uma nova instância do Service
-func NewService(repo Repository) *Service {
-	return &Service{
+func NewService(repo Repository, opts ...Option) *Service {
+	s := &Service{
 		repo:    repo,
 		weights: DefaultScoringWeights(),
+		now:     time.Now,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // RecommendDestinations gera recomendações de destinos de exportação
@@ -42,9 +65,9 @@ func (s *Service) RecommendDestinations(ctx context.Context, req SimulatorReques
 		return nil, err
 	}
 
-	// Busca dados de mercado do NCM (últimos 3 meses)
+	// Busca dados de mercado do NCM no fluxo pedido (últimos 12 meses)
 	now := time.Now()
-	marketData, err := s.repo.GetMarketDataByNCM(ctx, req.NCM, now.Year(), int(now.Month()))
+	marketData, err := s.repo.GetMarketDataByNCM(ctx, req.NCM, req.Flow, now.Year(), int(now.Month()))
 	if err != nil {
 		return nil, err
 	}
@@ -150,20 +173,51 @@ func (s *Service) RecommendDestinations(ctx context.Context, req SimulatorReques
 		recommendations = recommendations[:req.MaxResults]
 	}
 
+	// Saldo comercial por país (falha não impede a resposta)
+	s.enrichWithTradeBalance(ctx, req.NCM, now, recommendations)
+
+	// Enriquece com dados ao vivo (falha do gateway não impede a resposta)
+	liveDataStatus := s.enrichWithLiveData(ctx, req.NCM, req.Flow, recommendations)
+
 	processingTime := time.Since(startTime).Milliseconds()
 
 	return &SimulatorResponse{
 		Destinations: recommendations,
 		Metadata: SimulatorMetadata{
 			NCM:              req.NCM,
+			Flow:             req.Flow,
 			ProductName:      "", // TODO: Buscar nome do produto
 			AnalysisDate:     time.Now(),
 			TotalDestinations: len(recommendations),
 			ProcessingTimeMs: processingTime,
+			LiveDataStatus:   liveDataStatus,
 		},
 	}, nil
 }
 
+// enrichWithTradeBalance adiciona exportações, importações e saldo do NCM com cada país
+func (s *Service) enrichWithTradeBalance(ctx context.Context, ncm string, now time.Time, recommendations []DestinationRecommendation) {
+	if len(recommendations) == 0 {
+		return
+	}
+
+	balances, err := s.repo.GetTradeBalanceByNCM(ctx, ncm, now.Year(), int(now.Month()))
+	if err != nil {
+		log.Printf("destination: trade balance skipped: %v", err)
+		return
+	}
+
+	byCountry := make(map[string]TradeBalance, len(balances))
+	for _, b := range balances {
+		byCountry[b.CountryCode] = b
+	}
+	for i := range recommendations {
+		if b, ok := byCountry[recommendations[i].CountryCode]; ok {
+			recommendations[i].TradeBalance = &b
+		}
+	}
+}
+
 // estimateMargin estima margem baseada no preço
 func (s *Service) estimateMargin(pricePerKg float64) float64 {
 	// Lógica simplificada: margens maiores para preços mais altos
@@ -5,14 +5,19 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"bgc-app/internal/business/trade"
+	"bgc-app/internal/gatewayclient"
 )
 
 // MockRepository implementa a interface Repository para testes
 type MockRepository struct {
 	GetCountryMetadataFunc           func(ctx context.Context, countryCode string) (*CountryMetadata, error)
 	GetAllCountriesFunc              func(ctx context.Context) ([]CountryMetadata, error)
-	GetMarketDataByNCMFunc           func(ctx context.Context, ncm string, year, month int) ([]MarketData, error)
-	GetMarketDataByNCMAndCountryFunc func(ctx context.Context, ncm, countryCode string, year, month int) (*MarketData, error)
+	GetMarketDataByNCMFunc           func(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error)
+	GetMarketDataByNCMAndCountryFunc func(ctx context.Context, ncm, countryCode string, flow trade.Flow, year, month int) (*MarketData, error)
+	GetTradeBalanceByNCMFunc         func(ctx context.Context, ncm string, year, month int) ([]TradeBalance, error)
+	GetComexStatCountriesFunc        func(ctx context.Context) (map[int]string, error)
 }
 
 func (m *MockRepository) GetCountryMetadata(ctx context.Context, countryCode string) (*CountryMetadata, error) {
@@ -29,20 +34,37 @@ func (m *MockRepository) GetAllCountries(ctx context.Context) ([]CountryMetadata
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockRepository) GetMarketDataByNCM(ctx context.Context, ncm string, year, month int) ([]MarketData, error) {
+func (m *MockRepository) GetMarketDataByNCM(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error) {
 	if m.GetMarketDataByNCMFunc != nil {
-		return m.GetMarketDataByNCMFunc(ctx, ncm, year, month)
+		return m.GetMarketDataByNCMFunc(ctx, ncm, flow, year, month)
 	}
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockRepository) GetMarketDataByNCMAndCountry(ctx context.Context, ncm, countryCode string, year, month int) (*MarketData, error) {
+func (m *MockRepository) GetMarketDataByNCMAndCountry(ctx context.Context, ncm, countryCode string, flow trade.Flow, year, month int) (*MarketData, error) {
 	if m.GetMarketDataByNCMAndCountryFunc != nil {
-		return m.GetMarketDataByNCMAndCountryFunc(ctx, ncm, countryCode, year, month)
+		return m.GetMarketDataByNCMAndCountryFunc(ctx, ncm, countryCode, flow, year, month)
 	}
 	return nil, errors.New("not implemented")
 }
 
+func (m *MockRepository) GetTradeBalanceByNCM(ctx context.Context, ncm string, year, month int) ([]TradeBalance, error) {
+	if m.GetTradeBalanceByNCMFunc != nil {
+		return m.GetTradeBalanceByNCMFunc(ctx, ncm, year, month)
+	}
+	return nil, nil // saldo é opcional na recomendação
+}
+
+func (m *MockRepository) GetComexStatCountries(ctx context.Context) (map[int]string, error) {
+	if m.GetComexStatCountriesFunc != nil {
+		return m.GetComexStatCountriesFunc(ctx)
+	}
+	return comexStatCountries, nil
+}
+
+// comexStatCountries trecho da tabela PAIS.csv da ComexStat (CO_PAIS -> ISO alpha-2)
+var comexStatCountries = map[int]string{160: "CN", 249: "US", 63: "AR"}
+
 // TestNewService testa a criação de um novo service
 func TestNewService(t *testing.T) {
 	mockRepo := &MockRepository{}
@@ -66,7 +88,7 @@ func TestNewService(t *testing.T) {
 // TestRecommendDestinations_Success testa o fluxo completo de sucesso
 func TestRecommendDestinations_Success(t *testing.T) {
 	mockRepo := &MockRepository{
-		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, year, month int) ([]MarketData, error) {
+		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error) {
 			return []MarketData{
 				{
 					NCM:              "12345678",
@@ -209,7 +231,7 @@ func TestRecommendDestinations_InvalidRequest(t *testing.T) {
 // TestRecommendDestinations_NoDataAvailable testa quando não há dados
 func TestRecommendDestinations_NoDataAvailable(t *testing.T) {
 	mockRepo := &MockRepository{
-		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, year, month int) ([]MarketData, error) {
+		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error) {
 			return []MarketData{}, nil // Sem dados
 		},
 	}
@@ -232,7 +254,7 @@ func TestRecommendDestinations_RepositoryError(t *testing.T) {
 	expectedErr := errors.New("database connection failed")
 
 	mockRepo := &MockRepository{
-		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, year, month int) ([]MarketData, error) {
+		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error) {
 			return nil, expectedErr
 		},
 	}
@@ -253,7 +275,7 @@ func TestRecommendDestinations_RepositoryError(t *testing.T) {
 // TestRecommendDestinations_CountryFilter testa filtro por países
 func TestRecommendDestinations_CountryFilter(t *testing.T) {
 	mockRepo := &MockRepository{
-		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, year, month int) ([]MarketData, error) {
+		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error) {
 			return []MarketData{
 				{CountryCode: "US", TotalValueUSD: 1000000, AvgPricePerKgUSD: 100, GrowthRatePct: 10},
 				{CountryCode: "CN", TotalValueUSD: 800000, AvgPricePerKgUSD: 100, GrowthRatePct: 15},
@@ -318,7 +340,7 @@ func TestRecommendDestinations_MaxResultsLimit(t *testing.T) {
 	}
 
 	mockRepo := &MockRepository{
-		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, year, month int) ([]MarketData, error) {
+		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error) {
 			return marketData, nil
 		},
 		GetAllCountriesFunc: func(ctx context.Context) ([]CountryMetadata, error) {
@@ -517,7 +539,7 @@ func TestRecommendDestinations_Context(t *testing.T) {
 	cancel() // Cancela imediatamente
 
 	mockRepo := &MockRepository{
-		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, year, month int) ([]MarketData, error) {
+		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error) {
 			// Verificar se contexto foi cancelado
 			select {
 			case <-ctx.Done():
@@ -544,7 +566,7 @@ func TestRecommendDestinations_Context(t *testing.T) {
 // TestRecommendDestinations_ProcessingTime testa que processing time é calculado
 func TestRecommendDestinations_ProcessingTime(t *testing.T) {
 	mockRepo := &MockRepository{
-		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, year, month int) ([]MarketData, error) {
+		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error) {
 			// Simular delay
 			time.Sleep(10 * time.Millisecond)
 			return []MarketData{
@@ -583,3 +605,216 @@ func derefFloat64(ptr *float64, defaultVal float64) float64 {
 	}
 	return *ptr
 }
+
+// liveDataRepo repositório com dois países para testes de dados ao vivo
+func liveDataRepo() *MockRepository {
+	return &MockRepository{
+		GetMarketDataByNCMFunc: func(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error) {
+			return []MarketData{
+				{NCM: ncm, CountryCode: "CN", TotalValueUSD: 5000000, AvgPricePerKgUSD: 10, GrowthRatePct: 5},
+				{NCM: ncm, CountryCode: "US", TotalValueUSD: 3000000, AvgPricePerKgUSD: 12, GrowthRatePct: 3},
+			}, nil
+		},
+		GetAllCountriesFunc: func(ctx context.Context) ([]CountryMetadata, error) {
+			return []CountryMetadata{
+				{Code: "CN", NamePt: "China", Region: "Asia", DistanceBrazilKm: 17000},
+				{Code: "US", NamePt: "Estados Unidos", Region: "Americas", DistanceBrazilKm: 7000},
+			}, nil
+		},
+	}
+}
+
+// TestRecommendDestinations_LiveData testa o enriquecimento com dados do ComexStat via gateway
+func TestRecommendDestinations_LiveData(t *testing.T) {
+	fake := gatewayclient.NewFake().SetResult("comexstat", "exportacao_mes", map[string]interface{}{
+		"rows": []interface{}{
+			// co_pais como o connector comexstat devolve: código numérico da ComexStat
+			map[string]interface{}{"co_pais": "160", "co_ncm": "12019000", "vl_fob": 1000.0, "kg_liquido": 400.0},
+			map[string]interface{}{"co_pais": 160.0, "co_ncm": "12019000", "vl_fob": 500.0, "kg_liquido": 100.0},
+			map[string]interface{}{"co_pais": "249", "co_ncm": "09011110", "vl_fob": 900.0, "kg_liquido": 90.0},
+			map[string]interface{}{"co_pais": "999", "co_ncm": "12019000", "vl_fob": 700.0, "kg_liquido": 70.0},
+		},
+	})
+
+	service := NewService(liveDataRepo(), WithGateway(fake))
+	service.now = func() time.Time { return time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC) }
+
+	ctx := gatewayclient.WithRequestID(context.Background(), "req-42")
+	resp, err := service.RecommendDestinations(ctx, SimulatorRequest{NCM: "12019000"})
+	if err != nil {
+		t.Fatalf("RecommendDestinations() error = %v", err)
+	}
+
+	if resp.Metadata.LiveDataStatus != LiveDataOK {
+		t.Errorf("LiveDataStatus = %q, expected %q", resp.Metadata.LiveDataStatus, LiveDataOK)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 1 || calls[0].Params["ano"] != 2024 || calls[0].Params["mes"] != 12 || calls[0].RequestID != "req-42" {
+		t.Errorf("gateway calls = %+v, expected comexstat 2024/12 with request ID", calls)
+	}
+
+	for _, rec := range resp.Destinations {
+		switch rec.CountryCode {
+		case "CN":
+			if rec.LiveData == nil || rec.LiveData.ValueUSD != 1500 || rec.LiveData.WeightKg != 500 || rec.LiveData.PricePerKgUSD != 3 {
+				t.Errorf("CN live data = %+v", rec.LiveData)
+			}
+		case "US":
+			if rec.LiveData != nil {
+				t.Errorf("US should have no live data for NCM 12019000, got %+v", rec.LiveData)
+			}
+		}
+	}
+}
+
+// TestRecommendDestinations_LiveDataWithoutCountryCodes testa que sem a tabela de países o gateway nem é chamado
+func TestRecommendDestinations_LiveDataWithoutCountryCodes(t *testing.T) {
+	fake := gatewayclient.NewFake().SetResult("comexstat", "exportacao_mes", map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{"co_pais": "160", "co_ncm": "12019000", "vl_fob": 1000.0, "kg_liquido": 400.0},
+		},
+	})
+
+	for name, countries := range map[string]func(ctx context.Context) (map[int]string, error){
+		"query error": func(ctx context.Context) (map[int]string, error) { return nil, errors.New("relation does not exist") },
+		"empty table": func(ctx context.Context) (map[int]string, error) { return map[int]string{}, nil },
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := liveDataRepo()
+			repo.GetComexStatCountriesFunc = countries
+
+			resp, err := NewService(repo, WithGateway(fake)).RecommendDestinations(context.Background(), SimulatorRequest{NCM: "12019000"})
+			if err != nil {
+				t.Fatalf("RecommendDestinations() error = %v", err)
+			}
+			if resp.Metadata.LiveDataStatus != LiveDataUnavailable {
+				t.Errorf("LiveDataStatus = %q, expected %q", resp.Metadata.LiveDataStatus, LiveDataUnavailable)
+			}
+			if len(fake.Calls()) != 0 {
+				t.Errorf("gateway calls = %+v, expected none", fake.Calls())
+			}
+		})
+	}
+}
+
+// TestLiveCountryCode testa a conversão do co_pais do connector para ISO alpha-2
+func TestLiveCountryCode(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{"249", "US"},
+		{"0249", "US"},
+		{" 160 ", "CN"},
+		{249.0, "US"},
+		{"cn", "CN"},
+		{"999", ""},
+		{"", ""},
+		{nil, ""},
+		{"USA", ""},
+	}
+
+	for _, tt := range tests {
+		if got := liveCountryCode(tt.value, comexStatCountries); got != tt.expected {
+			t.Errorf("liveCountryCode(%#v) = %q, expected %q", tt.value, got, tt.expected)
+		}
+	}
+}
+
+// TestRecommendDestinations_LiveDataUnavailable testa que falhas do gateway não quebram a recomendação
+func TestRecommendDestinations_LiveDataUnavailable(t *testing.T) {
+	fake := gatewayclient.NewFake().SetError("comexstat", "exportacao_mes",
+		&gatewayclient.Error{StatusCode: 429, Err: gatewayclient.ErrQuotaExceeded})
+
+	service := NewService(liveDataRepo(), WithGateway(fake))
+
+	resp, err := service.RecommendDestinations(context.Background(), SimulatorRequest{NCM: "12019000"})
+	if err != nil {
+		t.Fatalf("RecommendDestinations() error = %v", err)
+	}
+
+	if resp.Metadata.LiveDataStatus != LiveDataUnavailable {
+		t.Errorf("LiveDataStatus = %q, expected %q", resp.Metadata.LiveDataStatus, LiveDataUnavailable)
+	}
+	if len(resp.Destinations) != 2 {
+		t.Errorf("expected 2 destinations, got %d", len(resp.Destinations))
+	}
+}
+
+// TestRecommendDestinations_ImportFlow testa que o fluxo chega ao repository e ao gateway
+func TestRecommendDestinations_ImportFlow(t *testing.T) {
+	var gotFlow trade.Flow
+	repo := liveDataRepo()
+	repo.GetMarketDataByNCMFunc = func(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]MarketData, error) {
+		gotFlow = flow
+		return []MarketData{{NCM: ncm, CountryCode: "CN", TotalValueUSD: 2000000, AvgPricePerKgUSD: 8}}, nil
+	}
+	fake := gatewayclient.NewFake().SetResult("comexstat", "importacao_mes", map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{"co_pais": "160", "co_ncm": "85171300", "vl_fob": 800.0, "kg_liquido": 10.0},
+		},
+	})
+
+	service := NewService(repo, WithGateway(fake))
+	resp, err := service.RecommendDestinations(context.Background(), SimulatorRequest{NCM: "85171300", Flow: trade.FlowImport})
+	if err != nil {
+		t.Fatalf("RecommendDestinations() error = %v", err)
+	}
+
+	if gotFlow != trade.FlowImport || resp.Metadata.Flow != trade.FlowImport {
+		t.Errorf("flow = %q (repository), %q (metadata), expected %q", gotFlow, resp.Metadata.Flow, trade.FlowImport)
+	}
+	calls := fake.Calls()
+	if len(calls) != 1 || calls[0].Endpoint != "importacao_mes" {
+		t.Errorf("gateway calls = %+v, expected comexstat/importacao_mes", calls)
+	}
+	if len(resp.Destinations) != 1 || resp.Destinations[0].LiveData == nil || resp.Destinations[0].LiveData.ValueUSD != 800 {
+		t.Errorf("destinations = %+v, expected CN with live import data", resp.Destinations)
+	}
+}
+
+// TestRecommendDestinations_TradeBalance testa o saldo comercial por país
+func TestRecommendDestinations_TradeBalance(t *testing.T) {
+	repo := liveDataRepo()
+	repo.GetTradeBalanceByNCMFunc = func(ctx context.Context, ncm string, year, month int) ([]TradeBalance, error) {
+		return []TradeBalance{
+			{NCM: ncm, CountryCode: "CN", ExportsUSD: 5000000, ImportsUSD: 1000000, BalanceUSD: 4000000},
+		}, nil
+	}
+
+	service := NewService(repo)
+	resp, err := service.RecommendDestinations(context.Background(), SimulatorRequest{NCM: "12019000"})
+	if err != nil {
+		t.Fatalf("RecommendDestinations() error = %v", err)
+	}
+
+	for _, rec := range resp.Destinations {
+		switch rec.CountryCode {
+		case "CN":
+			if rec.TradeBalance == nil || rec.TradeBalance.BalanceUSD != 4000000 || rec.TradeBalance.ImportsUSD != 1000000 {
+				t.Errorf("CN trade balance = %+v", rec.TradeBalance)
+			}
+		case "US":
+			if rec.TradeBalance != nil {
+				t.Errorf("US should have no trade balance, got %+v", rec.TradeBalance)
+			}
+		}
+	}
+}
+
+// TestRecommendDestinations_TradeBalanceUnavailable testa que falha no saldo não quebra a recomendação
+func TestRecommendDestinations_TradeBalanceUnavailable(t *testing.T) {
+	repo := liveDataRepo()
+	repo.GetTradeBalanceByNCMFunc = func(ctx context.Context, ncm string, year, month int) ([]TradeBalance, error) {
+		return nil, errors.New("relation \"stg.importacao\" does not exist")
+	}
+
+	resp, err := NewService(repo).RecommendDestinations(context.Background(), SimulatorRequest{NCM: "12019000"})
+	if err != nil {
+		t.Fatalf("RecommendDestinations() error = %v", err)
+	}
+	if len(resp.Destinations) != 2 || resp.Destinations[0].TradeBalance != nil {
+		t.Errorf("expected 2 destinations without trade balance, got %+v", resp.Destinations)
+	}
+}
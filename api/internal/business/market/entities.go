@@ -1,5 +1,7 @@
 package market
 
+import "bgc-app/internal/business/trade"
+
 type MarketItem struct {
 	Ano        int     `json:"ano"`
 	NCMChapter string  `json:"ncm_chapter"`
@@ -12,10 +14,12 @@ type MarketSizeRequest struct {
 	YearTo     int
 	NCMChapter string
 	Scenario   string
+	Flow       trade.Flow // trade.MarketFlows; vazio = total (exportação + importação)
 }
 
 type MarketSizeResponse struct {
 	Metric   string       `json:"metric"`
 	Scenario string       `json:"scenario"`
+	Flow     trade.Flow   `json:"flow"`
 	Items    []MarketItem `json:"items"`
 }
@@ -1,5 +1,7 @@
 package market
 
+import "bgc-app/internal/business/trade"
+
 type Repository interface {
-	GetMarketDataByYearRange(yearFrom, yearTo int, chapters []string, ncmChapter string) ([]MarketItem, error)
+	GetMarketDataByYearRange(yearFrom, yearTo int, chapters []string, ncmChapter string, flow trade.Flow) ([]MarketItem, error)
 }
@@ -3,6 +3,7 @@ package market
 import (
 	"fmt"
 
+	"bgc-app/internal/business/trade"
 	"bgc-app/internal/config"
 )
 
@@ -23,6 +24,11 @@ func NewService(repo Repository, cfg *config.AppConfig) Service {
 }
 
 func (s *service) CalculateMarketSize(req MarketSizeRequest) (*MarketSizeResponse, error) {
+	flow, err := trade.ParseFlow(string(req.Flow), trade.MarketFlows)
+	if err != nil {
+		return nil, err
+	}
+
 	chapters := []string{}
 	if req.Metric == "SAM" || req.Metric == "SOM" {
 		if len(s.config.ScopeChapters) == 0 {
@@ -31,7 +37,7 @@ func (s *service) CalculateMarketSize(req MarketSizeRequest) (*MarketSizeRespons
 		chapters = s.config.ScopeChapters
 	}
 
-	items, err := s.repo.GetMarketDataByYearRange(req.YearFrom, req.YearTo, chapters, req.NCMChapter)
+	items, err := s.repo.GetMarketDataByYearRange(req.YearFrom, req.YearTo, chapters, req.NCMChapter, flow)
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +65,7 @@ func (s *service) CalculateMarketSize(req MarketSizeRequest) (*MarketSizeRespons
 	return &MarketSizeResponse{
 		Metric:   req.Metric,
 		Scenario: req.Scenario,
+		Flow:     flow,
 		Items:    processedItems,
 	}, nil
 }
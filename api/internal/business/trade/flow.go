@@ -0,0 +1,43 @@
+package trade
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Flow fluxo de comércio analisado (mesmos valores de comexstat_cache.type)
+type Flow string
+
+const (
+	FlowExport Flow = "export" // stg.exportacao: países de destino
+	FlowImport Flow = "import" // stg.importacao: países de origem
+	FlowTotal  Flow = "total"  // exportação + importação (só market size)
+)
+
+// Fluxos aceitos por endpoint; o primeiro é o default quando flow não é informado.
+// Market size soma os dois fluxos por padrão (TAM, como antes de existir flow);
+// o simulador ranqueia países de um fluxo só e por isso parte de exportação.
+var (
+	MarketFlows    = []Flow{FlowTotal, FlowExport, FlowImport}
+	SimulatorFlows = []Flow{FlowExport, FlowImport}
+)
+
+// ErrInvalidFlow flow fora dos aceitos pelo endpoint
+var ErrInvalidFlow = errors.New("flow inválido")
+
+// ParseFlow normaliza flow e valida contra accepted; vazio resolve para accepted[0]
+func ParseFlow(s string, accepted []Flow) (Flow, error) {
+	flow := Flow(strings.ToLower(strings.TrimSpace(s)))
+	if flow == "" {
+		return accepted[0], nil
+	}
+	names := make([]string, 0, len(accepted))
+	for _, f := range accepted {
+		if f == flow {
+			return f, nil
+		}
+		names = append(names, string(f))
+	}
+	return "", fmt.Errorf("%w: use %s", ErrInvalidFlow, strings.Join(names, "|"))
+}
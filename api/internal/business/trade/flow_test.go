@@ -0,0 +1,40 @@
+package trade
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestParseFlow testa o default e a validação de cada endpoint
+func TestParseFlow(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		accepted []Flow
+		want     Flow
+		wantErr  bool
+	}{
+		{name: "market default", in: "", accepted: MarketFlows, want: FlowTotal},
+		{name: "market import", in: "import", accepted: MarketFlows, want: FlowImport},
+		{name: "market total", in: " TOTAL ", accepted: MarketFlows, want: FlowTotal},
+		{name: "simulator default", in: "", accepted: SimulatorFlows, want: FlowExport},
+		{name: "simulator import", in: "Import", accepted: SimulatorFlows, want: FlowImport},
+		{name: "simulator total", in: "total", accepted: SimulatorFlows, wantErr: true},
+		{name: "unknown", in: "transit", accepted: MarketFlows, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFlow(tt.in, tt.accepted)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidFlow) {
+					t.Errorf("ParseFlow(%q) error = %v, expected ErrInvalidFlow", tt.in, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseFlow(%q) = (%q, %v), expected %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
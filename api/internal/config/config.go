@@ -21,6 +21,8 @@ type AppConfig struct {
 	SOMAggressive      float64
 	PartnerWeightsFile string
 	TariffScenariosFile string
+	GatewayURL          string // Integration Gateway (vazio = sem dados ao vivo)
+	GatewayAPIKey       string
 }
 
 type PartnerWeights map[string]map[string]float64
@@ -51,6 +53,8 @@ func LoadConfig() *AppConfig {
 		SOMAggressive:       0.03,
 		PartnerWeightsFile:  getenv("PARTNER_WEIGHTS_FILE", "./config/partners_stub.yaml"),
 		TariffScenariosFile: getenv("TARIFF_SCENARIOS_FILE", "./config/tariff_scenarios.yaml"),
+		GatewayURL:          getenv("INTEGRATION_GATEWAY_URL", ""),
+		GatewayAPIKey:       getenv("INTEGRATION_GATEWAY_API_KEY", ""),
 	}
 
 	if v := getenv("SCOPE_CHAPTERS", ""); v != "" {
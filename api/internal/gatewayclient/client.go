@@ -0,0 +1,212 @@
+// Package gatewayclient cliente tipado do Integration Gateway (services/integration-gateway)
+package gatewayclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Headers enviados ao gateway
+const (
+	APIKeyHeader    = "X-API-Key"
+	RequestIDHeader = "X-Request-Id"
+)
+
+// API operações do gateway (implementada por Client e Fake)
+type API interface {
+	ListConnectors(ctx context.Context) ([]Connector, error)
+	GetConnector(ctx context.Context, id string) (*ConnectorDetails, error)
+	Execute(ctx context.Context, connectorID, endpoint string, params map[string]interface{}) (*ExecuteResult, error)
+}
+
+// Connector item da listagem de conectores
+type Connector struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	Provider  string   `json:"provider"`
+	Endpoints []string `json:"endpoints"`
+}
+
+// ConnectorDetails detalhes de um connector (endpoints visíveis ao caller)
+type ConnectorDetails struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Provider    string `json:"provider"`
+	Integration struct {
+		Type      string                  `json:"type"`
+		Endpoints map[string]EndpointInfo `json:"endpoints"`
+	} `json:"integration"`
+}
+
+// EndpointInfo endpoint de um connector
+type EndpointInfo struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// ExecuteResult resultado da execução de um endpoint
+type ExecuteResult struct {
+	Data       map[string]interface{} `json:"data"`
+	StatusCode int                    `json:"status_code"` // status HTTP do upstream
+	Duration   string                 `json:"duration"`
+}
+
+// Client cliente HTTP do gateway
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// Option configura o Client
+type Option func(*Client)
+
+// WithAPIKey credencial do caller (ver config/callers.yaml no gateway)
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithHTTPClient usa um http.Client próprio
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout timeout total por chamada (default 10s)
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// New cria um cliente para o gateway em baseURL (ex: http://integration-gateway:8081)
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ListConnectors lista os conectores permitidos ao caller
+func (c *Client) ListConnectors(ctx context.Context) ([]Connector, error) {
+	var connectors []Connector
+	if err := c.do(ctx, http.MethodGet, "/v1/connectors", nil, &connectors); err != nil {
+		return nil, err
+	}
+	return connectors, nil
+}
+
+// GetConnector obtém os detalhes de um connector
+func (c *Client) GetConnector(ctx context.Context, id string) (*ConnectorDetails, error) {
+	var details ConnectorDetails
+	if err := c.do(ctx, http.MethodGet, "/v1/connectors/"+url.PathEscape(id), nil, &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// Execute executa um endpoint de um connector com os parâmetros informados
+func (c *Client) Execute(ctx context.Context, connectorID, endpoint string, params map[string]interface{}) (*ExecuteResult, error) {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	var result ExecuteResult
+	path := "/v1/connectors/" + url.PathEscape(connectorID) + "/" + url.PathEscape(endpoint)
+	if err := c.do(ctx, http.MethodPost, path, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// do executa a chamada propagando request ID e trace context
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("gateway: failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("gateway: failed to build request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(APIKeyHeader, c.apiKey)
+	}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &Error{Op: method + " " + path, Err: ErrUnavailable, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &Error{Op: method + " " + path, StatusCode: resp.StatusCode, Err: ErrUnavailable, Message: err.Error()}
+	}
+
+	if resp.StatusCode >= 300 {
+		return newError(method+" "+path, resp, data)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("gateway: invalid response from %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// retryAfter interpreta o header Retry-After (segundos)
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+type requestIDKey struct{}
+
+// WithRequestID associa o request ID ao context (propagado como X-Request-Id)
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext obtém o request ID do context
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
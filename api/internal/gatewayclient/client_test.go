@@ -0,0 +1,212 @@
+package gatewayclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestClient_Execute(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var received *http.Request
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"data": {"total_records": 2}, "status_code": 200, "duration": "12ms"}`))
+	}))
+	defer server.Close()
+
+	client := New(server.URL+"/", WithAPIKey("bgc-api-key"))
+
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanCtx)
+	ctx = WithRequestID(ctx, "req-123")
+
+	result, err := client.Execute(ctx, "comexstat", "exportacao_mes", map[string]interface{}{"ano": 2025, "mes": 9})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if received.URL.Path != "/v1/connectors/comexstat/exportacao_mes" {
+		t.Errorf("path = %s", received.URL.Path)
+	}
+	if received.Header.Get(APIKeyHeader) != "bgc-api-key" {
+		t.Errorf("api key header = %q", received.Header.Get(APIKeyHeader))
+	}
+	if received.Header.Get(RequestIDHeader) != "req-123" {
+		t.Errorf("request id header = %q", received.Header.Get(RequestIDHeader))
+	}
+	if received.Header.Get("traceparent") == "" {
+		t.Error("traceparent header should be propagated")
+	}
+	if body["ano"] != float64(2025) {
+		t.Errorf("body = %v", body)
+	}
+	if result.Data["total_records"] != float64(2) || result.StatusCode != 200 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestClient_ListAndGetConnectors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/connectors":
+			w.Write([]byte(`[{"id": "comexstat", "name": "ComexStat", "version": "1.0.0", "endpoints": ["exportacao_mes"]}]`))
+		case "/v1/connectors/comexstat":
+			w.Write([]byte(`{"id": "comexstat", "integration": {"type": "rest_api", "endpoints": {"exportacao_mes": {"method": "POST", "path": "/api/exp/{ano}/{mes}"}}}}`))
+		default:
+			w.WriteHeader(404)
+			w.Write([]byte(`{"error": "connector not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := New(server.URL)
+
+	connectors, err := client.ListConnectors(context.Background())
+	if err != nil || len(connectors) != 1 || connectors[0].Endpoints[0] != "exportacao_mes" {
+		t.Fatalf("ListConnectors() = %+v, %v", connectors, err)
+	}
+
+	details, err := client.GetConnector(context.Background(), "comexstat")
+	if err != nil || details.Integration.Endpoints["exportacao_mes"].Path != "/api/exp/{ano}/{mes}" {
+		t.Fatalf("GetConnector() = %+v, %v", details, err)
+	}
+
+	_, err = client.GetConnector(context.Background(), "unknown")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetConnector(unknown) error = %v, expected ErrNotFound", err)
+	}
+}
+
+func TestClient_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		header   map[string]string
+		expected error
+		check    func(t *testing.T, gwErr *Error)
+	}{
+		{"unauthorized", 401, `{"error": "invalid API key", "reason": "invalid_credentials"}`, nil, ErrUnauthorized, func(t *testing.T, e *Error) {
+			if e.Reason != "invalid_credentials" {
+				t.Errorf("reason = %q", e.Reason)
+			}
+		}},
+		{"forbidden", 403, `{"error": "caller bgc-api may not use connector viacep", "reason": "connector_not_allowed"}`, nil, ErrForbidden, nil},
+		{"quota", 429, `{"error": "quota exceeded", "reason": "daily_quota_exceeded"}`, map[string]string{"Retry-After": "30"}, ErrQuotaExceeded, func(t *testing.T, e *Error) {
+			if e.RetryAfter != 30*time.Second {
+				t.Errorf("retry after = %v", e.RetryAfter)
+			}
+		}},
+		{"contract violation", 502, `{"error": "response violates contract", "code": "contract_violation", "violations": [{"type": "schema", "location": "/data", "message": "missing"}]}`, nil, ErrInvalidResponse, func(t *testing.T, e *Error) {
+			if len(e.Violations) != 1 || e.Violations[0].Location != "/data" {
+				t.Errorf("violations = %+v", e.Violations)
+			}
+		}},
+		{"proxy bad gateway", 502, `<html><body>502 Bad Gateway</body></html>`, nil, ErrUnavailable, func(t *testing.T, e *Error) {
+			if e.Code != "" {
+				t.Errorf("code = %q", e.Code)
+			}
+		}},
+		{"bad request", 400, `{"error": "invalid request body"}`, nil, ErrInvalidRequest, nil},
+		{"upstream not found", 404, `{"error": "request failed with status 404", "code": "upstream_not_found", "connector": "receita-federal", "endpoint": "consulta_cnpj", "upstream_status": 404}`, nil, ErrUpstreamNotFound, func(t *testing.T, e *Error) {
+			if e.Code != CodeUpstreamNotFound || e.Connector != "receita-federal" || e.Endpoint != "consulta_cnpj" || e.Upstream != 404 {
+				t.Errorf("error = %+v", e)
+			}
+		}},
+		{"upstream rate limited", 429, `{"error": "server error: 429", "code": "upstream_rate_limited", "upstream_status": 429}`, map[string]string{"Retry-After": "12"}, ErrRateLimited, func(t *testing.T, e *Error) {
+			if e.RetryAfter != 12*time.Second {
+				t.Errorf("retry after = %v", e.RetryAfter)
+			}
+		}},
+		{"circuit open", 503, `{"error": "circuit breaker open", "code": "circuit_open"}`, map[string]string{"Retry-After": "60"}, ErrUnavailable, nil},
+		{"upstream timeout", 504, `{"error": "context deadline exceeded", "code": "upstream_timeout"}`, nil, ErrTimeout, nil},
+		{"upstream error", 502, `{"error": "request failed with status 500", "code": "upstream_error", "upstream_status": 500}`, nil, ErrUpstreamFailed, nil},
+		{"execution failed", 500, `not json`, nil, ErrUpstreamFailed, func(t *testing.T, e *Error) {
+			if e.Message != "Internal Server Error" {
+				t.Errorf("message = %q", e.Message)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := New(server.URL).Execute(context.Background(), "comexstat", "exportacao_mes", nil)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("error = %v, expected %v", err, tt.expected)
+			}
+
+			var gwErr *Error
+			if !errors.As(err, &gwErr) || gwErr.StatusCode != tt.status {
+				t.Fatalf("error should be *Error with status %d, got %v", tt.status, err)
+			}
+			if tt.check != nil {
+				tt.check(t, gwErr)
+			}
+		})
+	}
+}
+
+func TestClient_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	_, err := New(server.URL).ListConnectors(context.Background())
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("error = %v, expected ErrUnavailable", err)
+	}
+}
+
+func TestFake(t *testing.T) {
+	fake := NewFake().
+		AddConnector(Connector{ID: "comexstat", Endpoints: []string{"exportacao_mes"}}).
+		SetResult("comexstat", "exportacao_mes", map[string]interface{}{"total_records": 1}).
+		SetError("comexstat", "importacao_mes", &Error{StatusCode: 429, Err: ErrQuotaExceeded})
+
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	result, err := fake.Execute(ctx, "comexstat", "exportacao_mes", map[string]interface{}{"ano": 2025})
+	if err != nil || result.Data["total_records"] != 1 {
+		t.Fatalf("Execute() = %+v, %v", result, err)
+	}
+
+	if _, err := fake.Execute(ctx, "comexstat", "importacao_mes", nil); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Execute(importacao_mes) error = %v", err)
+	}
+	if _, err := fake.Execute(ctx, "viacep", "consulta_cep", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Execute(viacep) error = %v", err)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 3 || calls[0].RequestID != "req-1" || calls[0].Params["ano"] != 2025 {
+		t.Errorf("calls = %+v", calls)
+	}
+
+	if _, err := fake.GetConnector(ctx, "comexstat"); err != nil {
+		t.Errorf("GetConnector() error = %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package gatewayclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Erros do gateway (use errors.Is)
+var (
+	ErrUnauthorized    = errors.New("gateway: caller not authenticated")
+	ErrForbidden       = errors.New("gateway: caller not allowed")
+	ErrNotFound        = errors.New("gateway: connector or endpoint not found")
+	ErrInvalidRequest  = errors.New("gateway: invalid request")
+	ErrQuotaExceeded   = errors.New("gateway: caller quota exceeded")
+	ErrInvalidResponse = errors.New("gateway: upstream response violates connector contract")
+	ErrUpstreamFailed  = errors.New("gateway: connector execution failed")
+	ErrUnavailable     = errors.New("gateway: unavailable")
+
+	// Erros do upstream do connector (code no corpo de erro do gateway)
+	ErrUpstreamNotFound = errors.New("gateway: upstream resource not found")
+	ErrRateLimited      = errors.New("gateway: connector rate limited")
+	ErrTimeout          = errors.New("gateway: upstream timeout")
+)
+
+// Códigos de erro do gateway (ver internal/framework/errors.go no gateway)
+const (
+	CodeConnectorNotFound   = "connector_not_found"
+	CodeEndpointNotFound    = "endpoint_not_found"
+	CodeInvalidParams       = "invalid_params"
+	CodeRateLimited         = "rate_limited"
+	CodeCircuitOpen         = "circuit_open"
+	CodeUpstreamNotFound    = "upstream_not_found"
+	CodeUpstreamRejected    = "upstream_rejected"
+	CodeUpstreamRateLimited = "upstream_rate_limited"
+	CodeUpstreamUnavailable = "upstream_unavailable"
+	CodeUpstreamTimeout     = "upstream_timeout"
+	CodeContractViolation   = "contract_violation"
+)
+
+// Violation violação de contrato reportada pelo gateway (502)
+type Violation struct {
+	Type     string `json:"type"`
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+// Error erro de uma chamada ao gateway
+type Error struct {
+	Op         string        // ex: POST /v1/connectors/comexstat/exportacao_mes
+	StatusCode int           // status HTTP do gateway (0 se não houve resposta)
+	Reason     string        // motivo informado pelo gateway (ex: endpoint_not_allowed)
+	Code       string        // código do erro (ex: upstream_not_found); igual a Reason nas negações de acesso
+	Message    string        // mensagem do gateway
+	Connector  string        // connector da chamada (erros de execução)
+	Endpoint   string        // endpoint da chamada (erros de execução)
+	Upstream   int           // status HTTP do upstream (0 se não houve resposta)
+	RetryAfter time.Duration // para ErrQuotaExceeded, ErrRateLimited e ErrUnavailable
+	Violations []Violation   // para ErrInvalidResponse
+	Err        error         // erro sentinela
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s: %s: %s", e.Err, e.Op, e.Message)
+	}
+	return fmt.Sprintf("%s: %s returned %d: %s", e.Err, e.Op, e.StatusCode, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// errorPayload corpo de erro do gateway
+type errorPayload struct {
+	Error          string      `json:"error"`
+	Code           string      `json:"code"`
+	Reason         string      `json:"reason"`
+	Connector      string      `json:"connector"`
+	Endpoint       string      `json:"endpoint"`
+	UpstreamStatus int         `json:"upstream_status"`
+	Violations     []Violation `json:"violations"`
+}
+
+// newError converte a resposta de erro do gateway em *Error
+func newError(op string, resp *http.Response, body []byte) *Error {
+	var payload errorPayload
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Error == "" {
+		payload.Error = http.StatusText(resp.StatusCode)
+	}
+
+	return &Error{
+		Op:         op,
+		StatusCode: resp.StatusCode,
+		Reason:     payload.Reason,
+		Code:       payload.Code,
+		Message:    payload.Error,
+		Connector:  payload.Connector,
+		Endpoint:   payload.Endpoint,
+		Upstream:   payload.UpstreamStatus,
+		RetryAfter: retryAfter(resp),
+		Violations: payload.Violations,
+		Err:        sentinelFor(resp.StatusCode, payload.Code),
+	}
+}
+
+// sentinelFor mapeia o code (ou, sem code, o status HTTP) do gateway para erro sentinela
+func sentinelFor(status int, code string) error {
+	switch code {
+	case CodeConnectorNotFound, CodeEndpointNotFound:
+		return ErrNotFound
+	case CodeInvalidParams, CodeUpstreamRejected:
+		return ErrInvalidRequest
+	case CodeUpstreamNotFound:
+		return ErrUpstreamNotFound
+	case CodeRateLimited, CodeUpstreamRateLimited:
+		return ErrRateLimited
+	case CodeCircuitOpen, CodeUpstreamUnavailable:
+		return ErrUnavailable
+	case CodeUpstreamTimeout:
+		return ErrTimeout
+	case CodeContractViolation:
+		return ErrInvalidResponse
+	}
+
+	switch {
+	case status == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case status == http.StatusForbidden:
+		return ErrForbidden
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusTooManyRequests:
+		return ErrQuotaExceeded
+	case status == http.StatusBadGateway && code == "":
+		// 502 sem code vem do ingress/proxy (gateway fora do ar), não do gateway
+		return ErrUnavailable
+	case status == http.StatusGatewayTimeout:
+		return ErrTimeout
+	case status == http.StatusServiceUnavailable:
+		return ErrUnavailable
+	case status >= 400 && status < 500:
+		return ErrInvalidRequest
+	default:
+		return ErrUpstreamFailed
+	}
+}
@@ -0,0 +1,129 @@
+package gatewayclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Call chamada registrada pelo Fake
+type Call struct {
+	Connector string
+	Endpoint  string
+	Params    map[string]interface{}
+	RequestID string
+}
+
+// Fake implementação em memória de API para testes unitários
+type Fake struct {
+	mu         sync.Mutex
+	details    map[string]*ConnectorDetails
+	connectors []Connector
+	results    map[string]*ExecuteResult
+	errors     map[string]error
+	calls      []Call
+}
+
+var _ API = (*Fake)(nil)
+
+// NewFake cria um fake sem conectores
+func NewFake() *Fake {
+	return &Fake{
+		details: make(map[string]*ConnectorDetails),
+		results: make(map[string]*ExecuteResult),
+		errors:  make(map[string]error),
+	}
+}
+
+// AddConnector registra um connector na listagem e nos detalhes
+func (f *Fake) AddConnector(connector Connector) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.connectors = append(f.connectors, connector)
+
+	details := &ConnectorDetails{ID: connector.ID, Name: connector.Name, Version: connector.Version, Provider: connector.Provider}
+	details.Integration.Endpoints = make(map[string]EndpointInfo, len(connector.Endpoints))
+	for _, endpoint := range connector.Endpoints {
+		details.Integration.Endpoints[endpoint] = EndpointInfo{Method: "POST"}
+	}
+	f.details[connector.ID] = details
+	return f
+}
+
+// SetResult define os dados retornados por connector/endpoint
+func (f *Fake) SetResult(connectorID, endpoint string, data map[string]interface{}) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.results[connectorID+"/"+endpoint] = &ExecuteResult{Data: data, StatusCode: 200, Duration: "1ms"}
+	return f
+}
+
+// SetError define o erro retornado por connector/endpoint (ex: &Error{Err: ErrQuotaExceeded})
+func (f *Fake) SetError(connectorID, endpoint string, err error) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errors[connectorID+"/"+endpoint] = err
+	return f
+}
+
+// Calls chamadas de Execute recebidas
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Call(nil), f.calls...)
+}
+
+// ListConnectors implementa API
+func (f *Fake) ListConnectors(ctx context.Context) ([]Connector, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Connector(nil), f.connectors...), nil
+}
+
+// GetConnector implementa API
+func (f *Fake) GetConnector(ctx context.Context, id string) (*ConnectorDetails, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	details, ok := f.details[id]
+	if !ok {
+		return nil, &Error{Op: "GET /v1/connectors/" + id, StatusCode: 404, Message: "connector not found", Err: ErrNotFound}
+	}
+	return details, nil
+}
+
+// Execute implementa API
+func (f *Fake) Execute(ctx context.Context, connectorID, endpoint string, params map[string]interface{}) (*ExecuteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{
+		Connector: connectorID,
+		Endpoint:  endpoint,
+		Params:    params,
+		RequestID: RequestIDFromContext(ctx),
+	})
+
+	key := connectorID + "/" + endpoint
+	if err, ok := f.errors[key]; ok {
+		return nil, err
+	}
+	if result, ok := f.results[key]; ok {
+		return result, nil
+	}
+	return nil, &Error{
+		Op:         "POST /v1/connectors/" + key,
+		StatusCode: 404,
+		Message:    fmt.Sprintf("no fake result for %s", key),
+		Err:        ErrNotFound,
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"bgc-app/internal/business/destination"
+	"bgc-app/internal/business/trade"
 
 	"github.com/lib/pq"
 )
@@ -129,13 +130,33 @@ func (r *DestinationRepository) GetAllCountries(ctx context.Context) ([]destinat
 	return countries, nil
 }
 
-// GetMarketDataByNCM busca dados de mercado por NCM
-// Retorna dados agregados dos últimos 12 meses por país
-func (r *DestinationRepository) GetMarketDataByNCM(ctx context.Context, ncm string, year, month int) ([]destination.MarketData, error) {
+// flowTables tabela de staging (schema ComexStat) de cada fluxo
+var flowTables = map[trade.Flow]string{
+	trade.FlowExport: "stg.exportacao",
+	trade.FlowImport: "stg.importacao",
+}
+
+// flowTable resolve a tabela do fluxo (já validado por trade.ParseFlow no service)
+func flowTable(flow trade.Flow) (string, error) {
+	table, ok := flowTables[flow]
+	if !ok {
+		return "", fmt.Errorf("no staging table for flow %q", flow)
+	}
+	return table, nil
+}
+
+// GetMarketDataByNCM busca dados de mercado por NCM no fluxo informado
+// Retorna dados agregados dos últimos 12 meses por país (destino na exportação, origem na importação)
+func (r *DestinationRepository) GetMarketDataByNCM(ctx context.Context, ncm string, flow trade.Flow, year, month int) ([]destination.MarketData, error) {
+	table, err := flowTable(flow)
+	if err != nil {
+		return nil, err
+	}
+
 	// Busca dados dos últimos 12 meses
 	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -12, 0)
 
-	query := `
+	query := fmt.Sprintf(`
 		WITH market_data AS (
 			SELECT
 				$1 as ncm,
@@ -145,7 +166,7 @@ func (r *DestinationRepository) GetMarketDataByNCM(ctx context.Context, ncm stri
 				SUM(vl_fob) as total_value_usd,
 				SUM(kg_liquido) as total_weight_kg,
 				COUNT(*) as transaction_count
-			FROM stg.exportacao
+			FROM %s
 			WHERE
 				SUBSTRING(co_ncm, 1, 8) = $1
 				AND co_ano >= $2
@@ -198,7 +219,7 @@ func (r *DestinationRepository) GetMarketDataByNCM(ctx context.Context, ncm stri
 		WHERE a.total_value_usd > 0
 		ORDER BY a.total_value_usd DESC
 		LIMIT 100
-	`
+	`, table)
 
 	rows, err := r.db.QueryContext(ctx, query, ncm, startDate.Year(), int(startDate.Month()))
 	if err != nil {
@@ -240,9 +261,14 @@ func (r *DestinationRepository) GetMarketDataByNCM(ctx context.Context, ncm stri
 	return marketData, nil
 }
 
-// GetMarketDataByNCMAndCountry busca dados de mercado por NCM e país específico
-func (r *DestinationRepository) GetMarketDataByNCMAndCountry(ctx context.Context, ncm, countryCode string, year, month int) (*destination.MarketData, error) {
-	query := `
+// GetMarketDataByNCMAndCountry busca dados de mercado por NCM e país específico no fluxo informado
+func (r *DestinationRepository) GetMarketDataByNCMAndCountry(ctx context.Context, ncm, countryCode string, flow trade.Flow, year, month int) (*destination.MarketData, error) {
+	table, err := flowTable(flow)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
 		SELECT
 			$1 as ncm,
 			co_pais as country_code,
@@ -257,18 +283,18 @@ func (r *DestinationRepository) GetMarketDataByNCMAndCountry(ctx context.Context
 			END as avg_price_per_kg_usd,
 			COUNT(*) as transaction_count,
 			0 as growth_rate_pct
-		FROM stg.exportacao
+		FROM %s
 		WHERE
 			SUBSTRING(co_ncm, 1, 8) = $1
 			AND co_pais = $4
 			AND co_ano = $2
 			AND co_mes = $3
 		GROUP BY co_pais
-	`
+	`, table)
 
 	var data destination.MarketData
 
-	err := r.db.QueryRowContext(ctx, query, ncm, year, month, countryCode).Scan(
+	err = r.db.QueryRowContext(ctx, query, ncm, year, month, countryCode).Scan(
 		&data.NCM,
 		&data.CountryCode,
 		&data.Year,
@@ -290,6 +316,102 @@ func (r *DestinationRepository) GetMarketDataByNCMAndCountry(ctx context.Context
 	return &data, nil
 }
 
+// GetComexStatCountries códigos de país da ComexStat (CO_PAIS) -> ISO alpha-2
+// (stg.comexstat_paises, gravada pelo bgc-ingest a partir do PAIS.csv)
+func (r *DestinationRepository) GetComexStatCountries(ctx context.Context) (map[int]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT co_pais, iso_a2 FROM stg.comexstat_paises`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comexstat countries: %w", err)
+	}
+	defer rows.Close()
+
+	countries := make(map[int]string)
+
+	for rows.Next() {
+		var code int
+		var iso string
+
+		if err := rows.Scan(&code, &iso); err != nil {
+			return nil, fmt.Errorf("failed to scan comexstat country: %w", err)
+		}
+
+		countries[code] = iso
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comexstat countries: %w", err)
+	}
+
+	return countries, nil
+}
+
+// GetTradeBalanceByNCM busca exportações, importações e saldo do NCM por país
+// (mesma janela de 12 meses de GetMarketDataByNCM)
+func (r *DestinationRepository) GetTradeBalanceByNCM(ctx context.Context, ncm string, year, month int) ([]destination.TradeBalance, error) {
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -12, 0)
+
+	query := `
+		WITH exports AS (
+			SELECT co_pais, SUM(vl_fob) as value_usd
+			FROM stg.exportacao
+			WHERE
+				SUBSTRING(co_ncm, 1, 8) = $1
+				AND co_ano >= $2
+				AND (co_ano > $2 OR co_mes >= $3)
+			GROUP BY co_pais
+		),
+		imports AS (
+			SELECT co_pais, SUM(vl_fob) as value_usd
+			FROM stg.importacao
+			WHERE
+				SUBSTRING(co_ncm, 1, 8) = $1
+				AND co_ano >= $2
+				AND (co_ano > $2 OR co_mes >= $3)
+			GROUP BY co_pais
+		)
+		SELECT
+			$1 as ncm,
+			COALESCE(e.co_pais, i.co_pais) as country_code,
+			COALESCE(e.value_usd, 0) as exports_usd,
+			COALESCE(i.value_usd, 0) as imports_usd,
+			COALESCE(e.value_usd, 0) - COALESCE(i.value_usd, 0) as balance_usd
+		FROM exports e
+		FULL OUTER JOIN imports i ON i.co_pais = e.co_pais
+		ORDER BY balance_usd DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, ncm, startDate.Year(), int(startDate.Month()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trade balance: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []destination.TradeBalance
+
+	for rows.Next() {
+		var b destination.TradeBalance
+
+		err := rows.Scan(
+			&b.NCM,
+			&b.CountryCode,
+			&b.ExportsUSD,
+			&b.ImportsUSD,
+			&b.BalanceUSD,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trade balance: %w", err)
+		}
+
+		balances = append(balances, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trade balance: %w", err)
+	}
+
+	return balances, nil
+}
+
 // SaveRecommendation salva uma recomendação para analytics
 func (r *DestinationRepository) SaveRecommendation(ctx context.Context, req destination.SimulatorRequest, resp destination.SimulatorResponse, userID, sessionID string, ipAddress string, cacheHit bool, cacheLevel string) error {
 	query := `
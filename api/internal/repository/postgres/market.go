@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"bgc-app/internal/business/market"
+	"bgc-app/internal/business/trade"
 	"bgc-app/internal/observability/metrics"
 	"bgc-app/internal/observability/tracing"
 	"go.opentelemetry.io/otel/attribute"
@@ -22,7 +23,14 @@ func NewMarketRepository(db *sql.DB) market.Repository {
 	return &MarketRepository{db: db}
 }
 
-func (r *MarketRepository) GetMarketDataByYearRange(yearFrom, yearTo int, chapters []string, ncmChapter string) ([]market.MarketItem, error) {
+// flowColumns coluna de v_tam_by_year_chapter de cada fluxo
+var flowColumns = map[trade.Flow]string{
+	trade.FlowTotal:  "tam_total_usd",
+	trade.FlowExport: "exp_valor_usd",
+	trade.FlowImport: "imp_valor_usd",
+}
+
+func (r *MarketRepository) GetMarketDataByYearRange(yearFrom, yearTo int, chapters []string, ncmChapter string, flow trade.Flow) ([]market.MarketItem, error) {
 	ctx, span := tracing.StartSpan(context.Background(), "db.GetMarketDataByYearRange")
 	defer span.End()
 
@@ -30,11 +38,17 @@ func (r *MarketRepository) GetMarketDataByYearRange(yearFrom, yearTo int, chapte
 		attribute.Int("year.from", yearFrom),
 		attribute.Int("year.to", yearTo),
 		attribute.Int("chapters.count", len(chapters)),
+		attribute.String("flow", string(flow)),
 	)
+	column, ok := flowColumns[flow]
+	if !ok {
+		return nil, fmt.Errorf("no v_tam_by_year_chapter column for flow %q", flow)
+	}
+
 	var sb strings.Builder
 	args := []any{yearFrom, yearTo}
 
-	sb.WriteString(`SELECT ano, ncm_chapter, tam_total_usd
+	sb.WriteString(`SELECT ano, ncm_chapter, ` + column + `
 	                FROM v_tam_by_year_chapter
 	               WHERE ano BETWEEN $1 AND $2`)
 	argPos := 3
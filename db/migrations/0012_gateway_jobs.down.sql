@@ -0,0 +1,3 @@
+-- Down 0012: drop gateway job history
+
+DROP TABLE IF EXISTS public.gateway_job_runs;
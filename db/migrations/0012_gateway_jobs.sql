@@ -0,0 +1,28 @@
+-- Migration 0012: Scheduled connector jobs (integration-gateway)
+-- Run history for gateway jobs
+
+-- ============================================================================
+-- RUN HISTORY
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS public.gateway_job_runs (
+  id BIGSERIAL PRIMARY KEY,
+  job_id TEXT NOT NULL,                    -- connector.job (ex: comexstat.exportacao_mensal)
+  scheduled_for TIMESTAMPTZ NOT NULL,      -- Schedule tick being executed
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at TIMESTAMPTZ,
+  status TEXT NOT NULL,                    -- running | success | failed
+  attempts INTEGER NOT NULL DEFAULT 0,
+  params JSONB,                            -- Rendered endpoint params
+  rows_upserted BIGINT NOT NULL DEFAULT 0,
+  error TEXT,
+  replica TEXT,                            -- Hostname of the replica that ran the job
+
+  -- One run per job and schedule tick across replicas
+  CONSTRAINT uq_gateway_job_runs_tick UNIQUE (job_id, scheduled_for),
+  CONSTRAINT valid_job_status CHECK (status IN ('running', 'success', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_gateway_job_runs_job_started
+  ON public.gateway_job_runs(job_id, started_at DESC);
+
+COMMENT ON TABLE public.gateway_job_runs IS 'Execution history of integration-gateway scheduled jobs';
@@ -0,0 +1,3 @@
+-- Down 0013: drop bgc-ingest load checkpoints
+
+DROP TABLE IF EXISTS stg.ingest_checkpoint;
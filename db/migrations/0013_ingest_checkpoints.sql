@@ -0,0 +1,17 @@
+-- Migration 0013: bgc-ingest load checkpoints
+-- Progress of batched COPY loads (load-csv, load-xlsx) so interrupted loads can resume
+
+CREATE TABLE IF NOT EXISTS stg.ingest_checkpoint (
+  source TEXT NOT NULL,                    -- Absolute file path (plus #sheet for XLSX)
+  target_table TEXT NOT NULL,              -- Quoted target table (ex: "stg"."exportacao")
+  fingerprint TEXT NOT NULL,               -- File size:mtime; a new file restarts the load
+  rows_done BIGINT NOT NULL DEFAULT 0,     -- Rows committed so far (skipped on resume)
+  status TEXT NOT NULL,                    -- running | done
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (source, target_table),
+  CONSTRAINT valid_checkpoint_status CHECK (status IN ('running', 'done'))
+);
+
+COMMENT ON TABLE stg.ingest_checkpoint IS 'Resumable load checkpoints written by bgc-ingest (one row per source file and target table)';
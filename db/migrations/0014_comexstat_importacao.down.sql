@@ -0,0 +1,11 @@
+-- Down 0014: drop stg.importacao and restore the legacy table renamed by the up migration
+
+DROP TABLE IF EXISTS stg.importacao;
+
+DO $$
+BEGIN
+  IF to_regclass('stg.importacao_legacy') IS NOT NULL THEN
+    ALTER TABLE stg.importacao_legacy RENAME TO importacao;
+    ALTER INDEX IF EXISTS stg.idx_importacao_legacy_ncm RENAME TO idx_importacao_ncm;
+  END IF;
+END $$;
@@ -0,0 +1,63 @@
+-- Migration 0014: ComexStat Schema for Importacao
+-- stg.importacao with the same schema as stg.exportacao (0011/0012), loaded by bgc-ingest load-comexstat
+-- co_pais is the country of origin (ISO 3166-1 alpha-2)
+
+-- Keep the legacy table (ano, setor, pais, ncm, valor, qtde) instead of dropping it
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_schema = 'stg' AND table_name = 'importacao' AND column_name = 'setor'
+  ) THEN
+    ALTER TABLE stg.importacao RENAME TO importacao_legacy;
+    ALTER INDEX IF EXISTS stg.idx_importacao_ncm RENAME TO idx_importacao_legacy_ncm;
+  END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS stg.importacao (
+  id BIGSERIAL PRIMARY KEY,
+
+  -- Time dimensions
+  co_ano INTEGER NOT NULL,                 -- Year (2020-2030)
+  co_mes INTEGER NOT NULL,                 -- Month (1-12)
+
+  -- Geographic dimensions
+  co_pais TEXT NOT NULL,                   -- Origin country code (ISO 3166-1 alpha-2)
+  sg_uf_ncm TEXT,                          -- Destination state code (SP, RJ, MG, etc)
+
+  -- Product dimensions
+  co_ncm TEXT NOT NULL,                    -- NCM code (8 digits)
+  co_sh4 TEXT,                             -- SH4 code (4 digits)
+  co_sh2 TEXT,                             -- SH2 code (2 digits)
+
+  -- Trade metrics
+  vl_fob NUMERIC(18,2) NOT NULL,           -- FOB value in USD
+  kg_liquido NUMERIC(18,2) NOT NULL,       -- Net weight in kg
+
+  -- Additional metrics
+  qt_estat INTEGER,                        -- Statistical quantity
+  vl_frete NUMERIC(18,2),                  -- Freight value
+  vl_seguro NUMERIC(18,2),                 -- Insurance value
+
+  -- Metadata
+  created_at TIMESTAMPTZ DEFAULT now(),
+
+  CONSTRAINT valid_imp_year CHECK (co_ano >= 2020 AND co_ano <= 2030),
+  CONSTRAINT valid_imp_month CHECK (co_mes >= 1 AND co_mes <= 12),
+  CONSTRAINT valid_imp_ncm_length CHECK (LENGTH(co_ncm) = 8),
+  CONSTRAINT valid_imp_fob CHECK (vl_fob >= 0),
+  CONSTRAINT valid_imp_weight CHECK (kg_liquido >= 0)
+);
+
+-- Indexes for performance (same as stg.exportacao)
+CREATE INDEX IF NOT EXISTS idx_importacao_ncm ON stg.importacao(co_ncm);
+CREATE INDEX IF NOT EXISTS idx_importacao_country ON stg.importacao(co_pais);
+CREATE INDEX IF NOT EXISTS idx_importacao_date ON stg.importacao(co_ano, co_mes);
+CREATE INDEX IF NOT EXISTS idx_importacao_ncm_country ON stg.importacao(co_ncm, co_pais);
+CREATE INDEX IF NOT EXISTS idx_importacao_ncm_date ON stg.importacao(co_ncm, co_ano, co_mes);
+
+-- Upsert key (same as uq_exportacao_periodo_pais_ncm_uf)
+CREATE UNIQUE INDEX IF NOT EXISTS uq_importacao_periodo_pais_ncm_uf
+  ON stg.importacao(co_ano, co_mes, co_pais, co_ncm, sg_uf_ncm) NULLS NOT DISTINCT;
+
+COMMENT ON TABLE stg.importacao IS 'Brazilian import data from ComexStat (staging layer)';
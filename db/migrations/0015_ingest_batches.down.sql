@@ -0,0 +1,20 @@
+-- Down 0015: drop load batches and row provenance columns
+-- Loaded rows are kept; only the batch history and provenance are lost.
+
+DROP INDEX IF EXISTS stg.idx_exportacao_ingest_batch;
+DROP INDEX IF EXISTS stg.idx_importacao_ingest_batch;
+
+ALTER TABLE stg.exportacao
+  DROP COLUMN IF EXISTS ingest_source,
+  DROP COLUMN IF EXISTS ingest_batch,
+  DROP COLUMN IF EXISTS ingest_at;
+
+ALTER TABLE stg.importacao
+  DROP COLUMN IF EXISTS ingest_source,
+  DROP COLUMN IF EXISTS ingest_batch,
+  DROP COLUMN IF EXISTS ingest_at;
+
+ALTER TABLE stg.ingest_checkpoint
+  DROP COLUMN IF EXISTS batch_id;
+
+DROP TABLE IF EXISTS stg.ingest_batches;
@@ -0,0 +1,54 @@
+-- Migration 0015: bgc-ingest load batches (provenance, dedupe and rollback)
+-- Every load-csv / load-xlsx / load-comexstat run is recorded as a batch; loaded rows
+-- carry the batch id so `bgc-ingest batches rollback <id>` can delete exactly them.
+
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+CREATE TABLE IF NOT EXISTS stg.ingest_batches (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  kind TEXT NOT NULL,                      -- csv | xlsx | comexstat
+  source TEXT NOT NULL,                    -- Absolute file path (plus #sheet for XLSX)
+  file_hash TEXT NOT NULL,                 -- sha256 of the file (plus #sheet for XLSX)
+  target_table TEXT NOT NULL,              -- schema.table
+  status TEXT NOT NULL,                    -- running | success | failed | rolled_back
+  rows_loaded BIGINT NOT NULL DEFAULT 0,   -- Source rows committed (including resumed runs)
+  rows_rejected BIGINT NOT NULL DEFAULT 0, -- Rows written to the rejects file
+  error TEXT,                              -- Last error (status failed)
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at TIMESTAMPTZ,
+  rolled_back_at TIMESTAMPTZ,
+
+  CONSTRAINT valid_batch_status CHECK (status IN ('running', 'success', 'failed', 'rolled_back'))
+);
+
+-- Dedupe lookup: same file already loaded into the same table
+CREATE INDEX IF NOT EXISTS idx_ingest_batches_hash ON stg.ingest_batches(file_hash, target_table);
+CREATE INDEX IF NOT EXISTS idx_ingest_batches_started ON stg.ingest_batches(started_at DESC);
+
+-- Checkpoints belong to a batch (resume continues the same batch)
+ALTER TABLE stg.ingest_checkpoint
+  ADD COLUMN IF NOT EXISTS batch_id UUID;
+
+-- Row provenance (0003 columns were dropped when 0011 recreated stg.exportacao)
+ALTER TABLE stg.exportacao
+  ADD COLUMN IF NOT EXISTS ingest_source TEXT,
+  ADD COLUMN IF NOT EXISTS ingest_batch UUID,
+  ADD COLUMN IF NOT EXISTS ingest_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE stg.importacao
+  ADD COLUMN IF NOT EXISTS ingest_source TEXT,
+  ADD COLUMN IF NOT EXISTS ingest_batch UUID,
+  ADD COLUMN IF NOT EXISTS ingest_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Rollback deletes by batch
+CREATE INDEX IF NOT EXISTS idx_exportacao_ingest_batch
+  ON stg.exportacao(ingest_batch)
+  WHERE ingest_batch IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_importacao_ingest_batch
+  ON stg.importacao(ingest_batch)
+  WHERE ingest_batch IS NOT NULL;
+
+COMMENT ON TABLE stg.ingest_batches IS 'Load batches written by bgc-ingest (file hash, counts, status; rows keep ingest_batch)';
+COMMENT ON COLUMN stg.exportacao.ingest_batch IS 'stg.ingest_batches.id of the load that last wrote the row';
+COMMENT ON COLUMN stg.importacao.ingest_batch IS 'stg.ingest_batches.id of the load that last wrote the row';
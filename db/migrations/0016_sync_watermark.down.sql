@@ -0,0 +1,3 @@
+-- Down 0016: drop sync-comexstat watermark (the next sync refetches every month)
+
+DROP TABLE IF EXISTS stg.sync_watermark;
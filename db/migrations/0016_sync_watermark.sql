@@ -0,0 +1,26 @@
+-- Migration 0016: sync-comexstat watermark
+-- One row per connector, flow and month fetched from the integration gateway, so
+-- incremental runs only fetch new months and re-fetch recent ones for MDIC revisions.
+
+CREATE TABLE IF NOT EXISTS stg.sync_watermark (
+  source TEXT NOT NULL,                    -- Gateway connector (comexstat)
+  flow TEXT NOT NULL,                      -- exp | imp
+  co_ano INTEGER NOT NULL,
+  co_mes INTEGER NOT NULL,
+  rows_fetched BIGINT NOT NULL DEFAULT 0,  -- Rows returned by the connector
+  total_records BIGINT NOT NULL DEFAULT 0, -- Total reported by the upstream
+  content_hash TEXT NOT NULL,              -- sha256 of the rows; a new hash means the month was revised
+  batch_id UUID,                           -- stg.ingest_batches.id of the load that wrote the month
+  fetched_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  changed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (source, flow, co_ano, co_mes),
+  CONSTRAINT valid_sync_flow CHECK (flow IN ('exp', 'imp')),
+  CONSTRAINT valid_sync_month CHECK (co_mes >= 1 AND co_mes <= 12)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_watermark_batch
+  ON stg.sync_watermark(batch_id)
+  WHERE batch_id IS NOT NULL;
+
+COMMENT ON TABLE stg.sync_watermark IS 'Months synced by bgc-ingest sync-comexstat (content hash per flow and month)';
@@ -0,0 +1,3 @@
+-- Down 0017: drop the unique index (refresh-views falls back to a blocking REFRESH)
+
+DROP INDEX IF EXISTS public.uq_v_tam_by_year_chapter;
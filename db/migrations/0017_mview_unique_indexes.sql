@@ -0,0 +1,11 @@
+-- Migration 0017: unique indexes for REFRESH MATERIALIZED VIEW CONCURRENTLY
+-- `bgc-ingest refresh-views` only refreshes a view concurrently (without blocking readers)
+-- when it has a plain unique index. v_tam_by_year_chapter (db/init) groups by (ano, ncm_chapter).
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_matviews WHERE schemaname = 'public' AND matviewname = 'v_tam_by_year_chapter') THEN
+    CREATE UNIQUE INDEX IF NOT EXISTS uq_v_tam_by_year_chapter
+      ON public.v_tam_by_year_chapter(ano, ncm_chapter);
+  END IF;
+END $$;
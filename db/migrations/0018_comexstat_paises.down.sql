@@ -0,0 +1,3 @@
+-- Down 0018: drop ComexStat country codes (the next load-comexstat / sync-comexstat recreates the rows)
+
+DROP TABLE IF EXISTS stg.comexstat_paises;
@@ -0,0 +1,14 @@
+-- Migration 0018: ComexStat country codes
+-- CO_PAIS (numeric ComexStat/Siscomex code) -> ISO 3166-1 alpha-2, written by bgc-ingest
+-- from the official PAIS.csv on every load-comexstat / sync-comexstat run. The API reads it
+-- to match rows fetched live from the gateway (numeric co_pais) to recommendations (ISO).
+
+CREATE TABLE IF NOT EXISTS stg.comexstat_paises (
+  co_pais INTEGER PRIMARY KEY,             -- ComexStat country code (e.g. 249 = United States)
+  iso_a2 TEXT NOT NULL,                    -- ISO 3166-1 alpha-2 (ZZ = not identified)
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT valid_paises_iso CHECK (iso_a2 ~ '^[A-Z]{2}$')
+);
+
+COMMENT ON TABLE stg.comexstat_paises IS 'ComexStat CO_PAIS to ISO alpha-2 (from PAIS.csv, loaded by bgc-ingest)';
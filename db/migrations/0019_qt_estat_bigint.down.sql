@@ -0,0 +1,4 @@
+-- Down 0019: qt_estat back to INTEGER (fails if any row holds a value above 2147483647)
+
+ALTER TABLE stg.importacao ALTER COLUMN qt_estat TYPE INTEGER;
+ALTER TABLE stg.exportacao ALTER COLUMN qt_estat TYPE INTEGER;
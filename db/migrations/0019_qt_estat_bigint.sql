@@ -0,0 +1,6 @@
+-- Migration 0019: qt_estat as BIGINT
+-- QT_ESTAT of bulk NCMs (iron ore, soy) is reported in kg: a single EXP/IMP row can
+-- exceed INTEGER, and the per-key SUM of the bgc-ingest upsert certainly does.
+
+ALTER TABLE stg.exportacao ALTER COLUMN qt_estat TYPE BIGINT;
+ALTER TABLE stg.importacao ALTER COLUMN qt_estat TYPE BIGINT;
@@ -0,0 +1,4 @@
+-- Down 0020: drop the stg.exportacao upsert key
+-- Duplicates merged by the up migration stay merged (their metrics were summed)
+
+DROP INDEX IF EXISTS stg.uq_exportacao_periodo_pais_ncm_uf;
@@ -0,0 +1,43 @@
+-- Migration 0020: upsert key for stg.exportacao (DESTRUCTIVE)
+-- Merges duplicate rows of each (year, month, country, NCM, UF) key before creating the
+-- unique index used by bgc-ingest load-comexstat / sync-comexstat (ON CONFLICT). Older
+-- duplicates are deleted and cannot be restored by the down migration: back up
+-- stg.exportacao first and apply with `bgc-ingest migrate up --allow-destructive`.
+-- Runs after 0019 so the summed qt_estat fits in BIGINT.
+-- migrate:destructive
+
+-- Merge duplicates before creating the unique index: metrics are summed into the
+-- newest row of each key (same aggregation as bgc-ingest load-comexstat), then the
+-- older rows are removed
+WITH dup AS (
+  SELECT max(id) AS keep_id,
+         sum(vl_fob) AS vl_fob,
+         sum(kg_liquido) AS kg_liquido,
+         sum(qt_estat) AS qt_estat,
+         sum(vl_frete) AS vl_frete,
+         sum(vl_seguro) AS vl_seguro
+  FROM stg.exportacao
+  GROUP BY co_ano, co_mes, co_pais, co_ncm, sg_uf_ncm   -- GROUP BY treats NULL UFs as equal
+  HAVING count(*) > 1
+)
+UPDATE stg.exportacao e
+SET vl_fob = dup.vl_fob,
+    kg_liquido = dup.kg_liquido,
+    qt_estat = dup.qt_estat,
+    vl_frete = dup.vl_frete,
+    vl_seguro = dup.vl_seguro
+FROM dup
+WHERE e.id = dup.keep_id;
+
+DELETE FROM stg.exportacao a
+USING stg.exportacao b
+WHERE a.id < b.id
+  AND a.co_ano = b.co_ano
+  AND a.co_mes = b.co_mes
+  AND a.co_pais = b.co_pais
+  AND a.co_ncm = b.co_ncm
+  AND a.sg_uf_ncm IS NOT DISTINCT FROM b.sg_uf_ncm;
+
+-- NULLS NOT DISTINCT: rows without UF (national totals) also conflict
+CREATE UNIQUE INDEX IF NOT EXISTS uq_exportacao_periodo_pais_ncm_uf
+  ON stg.exportacao(co_ano, co_mes, co_pais, co_ncm, sg_uf_ncm) NULLS NOT DISTINCT;
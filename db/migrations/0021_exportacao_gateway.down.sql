@@ -0,0 +1,4 @@
+-- Down 0021: drop the gateway job landing table
+
+DROP VIEW IF EXISTS stg.v_exportacao_gateway;
+DROP TABLE IF EXISTS stg.exportacao_gateway;
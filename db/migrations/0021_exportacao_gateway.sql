@@ -0,0 +1,34 @@
+-- Migration 0021: landing table for the integration-gateway comexstat job
+-- comexstat.exportacao_mensal (config/connectors/comexstat.yaml) fetches every page of the
+-- previous month and replaces that month here, with metrics summed per key. co_pais keeps
+-- the numeric ComexStat code as returned by the API; v_exportacao_gateway converts it to
+-- ISO through stg.comexstat_paises (0018). stg.exportacao stays owned by bgc-ingest.
+
+CREATE TABLE IF NOT EXISTS stg.exportacao_gateway (
+  co_ano INTEGER NOT NULL,
+  co_mes INTEGER NOT NULL,
+  co_pais TEXT NOT NULL,                   -- ComexStat country code (numeric, as fetched)
+  sg_uf_ncm TEXT,
+  co_ncm TEXT NOT NULL,
+  vl_fob NUMERIC(18,2) NOT NULL,
+  kg_liquido NUMERIC(18,2) NOT NULL,
+  qt_estat BIGINT,
+  vl_frete NUMERIC(18,2),
+  vl_seguro NUMERIC(18,2),
+  fetched_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Job upsert key (NULLS NOT DISTINCT: rows without UF also conflict)
+CREATE UNIQUE INDEX IF NOT EXISTS uq_exportacao_gateway_periodo_pais_ncm_uf
+  ON stg.exportacao_gateway(co_ano, co_mes, co_pais, co_ncm, sg_uf_ncm) NULLS NOT DISTINCT;
+
+CREATE OR REPLACE VIEW stg.v_exportacao_gateway AS
+SELECT g.co_ano, g.co_mes,
+       COALESCE(p.iso_a2, 'ZZ') AS co_pais,
+       g.sg_uf_ncm, g.co_ncm, g.vl_fob, g.kg_liquido, g.qt_estat, g.vl_frete, g.vl_seguro,
+       g.fetched_at
+FROM stg.exportacao_gateway g
+LEFT JOIN stg.comexstat_paises p ON p.co_pais::text = g.co_pais;
+
+COMMENT ON TABLE stg.exportacao_gateway IS 'Exports landed by the integration-gateway comexstat job (numeric co_pais)';
+COMMENT ON VIEW stg.v_exportacao_gateway IS 'stg.exportacao_gateway with co_pais converted to ISO alpha-2';
@@ -0,0 +1,324 @@
+// batches.go — proveniência das cargas (stg.ingest_batches, migration 0015)
+//
+// Cada carga vira um lote com hash do arquivo, origem, destino, contagens, status e horários.
+// As linhas carregadas recebem ingest_batch (id do lote) e ingest_source, então:
+//   - recarregar o mesmo arquivo (mesmo hash) no mesmo destino não duplica dados (use --force);
+//   - `bgc-ingest batches rollback <id>` remove exatamente as linhas do lote (csv, xlsx).
+//
+// Cargas da ComexStat (load-comexstat, sync-comexstat) não são desfeitas: substituem o
+// ano/mês inteiro e somam as linhas na chave única, então a linha passa a ser do último
+// lote mesmo contendo valores de lotes anteriores. Para corrigir, recarregue o período.
+//
+// Comandos:
+//   bgc-ingest batches list [--limit 20] [--status success]
+//   bgc-ingest batches rollback <id>
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Tipos de lote (stg.ingest_batches.kind) sem rollback: merge + substituição do período.
+var mergedKinds = map[string]bool{"comexstat": true, "gateway": true}
+
+// checkRollback recusa desfazer lotes cujas linhas não são só do lote.
+func checkRollback(id, kind string) error {
+	if mergedKinds[kind] {
+		return fmt.Errorf("lote %s (%s) não pode ser desfeito: a carga substitui o período e soma linhas de lotes anteriores; recarregue o período", id, kind)
+	}
+	return nil
+}
+
+// Status de um lote
+const (
+	batchRunning    = "running"
+	batchSuccess    = "success"
+	batchFailed     = "failed"
+	batchRolledBack = "rolled_back"
+)
+
+// sourceFile arquivo de origem de uma carga.
+type sourceFile struct {
+	path        string // caminho absoluto
+	fingerprint string // tamanho + mtime (checkpoint)
+	size        int64
+	hash        string // sha256 do conteúdo (deduplicação de lotes)
+}
+
+// inspectFile identifica o arquivo: fingerprint p/ checkpoint e sha256 p/ deduplicação.
+// Se o arquivo for substituído, o checkpoint antigo deixa de valer.
+func inspectFile(path string) (*sourceFile, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(abs)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("hash de %s: %w", abs, err)
+	}
+	return &sourceFile{
+		path:        abs,
+		fingerprint: fmt.Sprintf("%d:%d", st.Size(), st.ModTime().UnixNano()),
+		size:        st.Size(),
+		hash:        hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// tableName nome schema.tabela (sem aspas) gravado em stg.ingest_batches.
+func tableName(table pgx.Identifier) string {
+	return strings.Join(table, ".")
+}
+
+// findLoadedBatch lote concluído com o mesmo hash e destino ("" = não carregado).
+func findLoadedBatch(ctx context.Context, pool *pgxpool.Pool, hash, table string) (string, error) {
+	var id string
+	err := pool.QueryRow(ctx, `
+		SELECT id::text FROM stg.ingest_batches
+		WHERE file_hash = $1 AND target_table = $2 AND status = $3
+		ORDER BY started_at DESC
+		LIMIT 1`, hash, table, batchSuccess,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return id, err
+}
+
+// startBatch cria o lote da carga ou, na retomada, reabre o lote do checkpoint.
+func startBatch(ctx context.Context, pool *pgxpool.Pool, job copyJob, resumeID string) (string, error) {
+	if resumeID != "" {
+		tag, err := pool.Exec(ctx, `
+			UPDATE stg.ingest_batches
+			SET status = $2, error = NULL, finished_at = NULL
+			WHERE id = $1::uuid AND status IN ($2, $3)`, resumeID, batchRunning, batchFailed)
+		if err != nil {
+			return "", err
+		}
+		if tag.RowsAffected() == 1 {
+			return resumeID, nil
+		}
+	}
+
+	var id string
+	err := pool.QueryRow(ctx, `
+		INSERT INTO stg.ingest_batches (kind, source, file_hash, target_table, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id::text`,
+		job.kind, job.source, job.hash, tableName(job.table), batchRunning,
+	).Scan(&id)
+	return id, err
+}
+
+// finishBatch grava o resultado do lote (status success ou failed).
+// rows_loaded é o total do lote (inclui execuções anteriores retomadas).
+func finishBatch(ctx context.Context, pool *pgxpool.Pool, id string, rowsLoaded, rejected int64, runErr error) error {
+	status, msg := batchSuccess, ""
+	if runErr != nil {
+		status, msg = batchFailed, runErr.Error()
+	}
+	_, err := pool.Exec(ctx, `
+		UPDATE stg.ingest_batches
+		SET status = $2, rows_loaded = $3, rows_rejected = rows_rejected + $4,
+		    error = NULLIF($5, ''), finished_at = now()
+		WHERE id = $1::uuid`, id, status, rowsLoaded, rejected, msg)
+	return err
+}
+
+// withProvenance acrescenta ingest_batch/ingest_source às linhas, se o destino tiver as colunas.
+func withProvenance(ctx context.Context, pool *pgxpool.Pool, job copyJob, batchID string) (copyJob, error) {
+	var n int
+	err := pool.QueryRow(ctx, `
+		SELECT count(*) FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2 AND column_name IN ('ingest_batch', 'ingest_source')`,
+		schemaOf(job.table), job.table[len(job.table)-1],
+	).Scan(&n)
+	if err != nil || n < 2 {
+		return job, err
+	}
+
+	source := job.kind + ":" + filepath.Base(job.source)
+	next := job.next
+	job.columns = append(append([]string(nil), job.columns...), "ingest_batch", "ingest_source")
+	job.next = func() ([]any, error) {
+		row, err := next()
+		if err != nil {
+			return nil, err
+		}
+		return append(row, batchID, source), nil
+	}
+	return job, nil
+}
+
+// schemaOf schema da tabela (public se não informado).
+func schemaOf(table pgx.Identifier) string {
+	if len(table) > 1 {
+		return table[0]
+	}
+	return "public"
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Comando: batches
+////////////////////////////////////////////////////////////////////////////////
+
+// cmdBatches roteia `batches list` e `batches rollback <id>`.
+func cmdBatches(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: bgc-ingest batches <list|rollback <id>>")
+	}
+	switch args[0] {
+	case "list":
+		return cmdBatchesList(args[1:])
+	case "rollback":
+		if len(args) != 2 {
+			return errors.New("usage: bgc-ingest batches rollback <id>")
+		}
+		return cmdBatchesRollback(args[1])
+	default:
+		return fmt.Errorf("batches: subcomando desconhecido %q", args[0])
+	}
+}
+
+// batchRow lote como impresso por `batches list`.
+type batchRow struct {
+	ID           string     `json:"id"`
+	Kind         string     `json:"kind"`
+	Source       string     `json:"source"`
+	FileHash     string     `json:"file_hash"`
+	TargetTable  string     `json:"target_table"`
+	Status       string     `json:"status"`
+	RowsLoaded   int64      `json:"rows_loaded"`
+	RowsRejected int64      `json:"rows_rejected"`
+	Error        *string    `json:"error,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	RolledBackAt *time.Time `json:"rolled_back_at,omitempty"`
+}
+
+// cmdBatchesList imprime os lotes mais recentes (um JSON por linha).
+func cmdBatchesList(args []string) error {
+	fs := flag.NewFlagSet("batches list", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "quantidade de lotes")
+	status := fs.String("status", "", "filtra por status (running, success, failed, rolled_back)")
+	fs.SetOutput(new(nopWriter))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	pool, err := connect()
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer pool.Close()
+
+	rows, err := pool.Query(context.Background(), `
+		SELECT id::text, kind, source, file_hash, target_table, status, rows_loaded, rows_rejected,
+		       error, started_at, finished_at, rolled_back_at
+		FROM stg.ingest_batches
+		WHERE $1 = '' OR status = $1
+		ORDER BY started_at DESC
+		LIMIT $2`, *status, *limit)
+	if err != nil {
+		return fmt.Errorf("listar lotes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b batchRow
+		if err := rows.Scan(&b.ID, &b.Kind, &b.Source, &b.FileHash, &b.TargetTable, &b.Status,
+			&b.RowsLoaded, &b.RowsRejected, &b.Error, &b.StartedAt, &b.FinishedAt, &b.RolledBackAt); err != nil {
+			return fmt.Errorf("ler lote: %w", err)
+		}
+		out, _ := json.Marshal(b)
+		fmt.Println(string(out))
+	}
+	return rows.Err()
+}
+
+// cmdBatchesRollback apaga as linhas do lote e o marca como rolled_back (em uma transação).
+// O checkpoint do arquivo (e o watermark do sync) também é removido, para que ele possa ser carregado de novo.
+func cmdBatchesRollback(id string) error {
+	pool, err := connect()
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var kind, target, status string
+	err = tx.QueryRow(ctx, `
+		SELECT kind, target_table, status FROM stg.ingest_batches
+		WHERE id = $1::uuid
+		FOR UPDATE`, id,
+	).Scan(&kind, &target, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("lote %s não encontrado", id)
+	}
+	if err != nil {
+		return fmt.Errorf("ler lote: %w", err)
+	}
+	switch status {
+	case batchRolledBack:
+		return fmt.Errorf("lote %s já foi desfeito", id)
+	case batchRunning:
+		return fmt.Errorf("lote %s ainda em execução", id)
+	}
+	if err := checkRollback(id, kind); err != nil {
+		return err
+	}
+
+	table, err := parseTable(target)
+	if err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE ingest_batch = $1::uuid`, table.Sanitize()), id)
+	if err != nil {
+		return fmt.Errorf("apagar linhas do lote: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE stg.ingest_batches SET status = $2, rolled_back_at = now()
+		WHERE id = $1::uuid`, id, batchRolledBack); err != nil {
+		return fmt.Errorf("atualizar lote: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM stg.ingest_checkpoint WHERE batch_id = $1::uuid`, id); err != nil {
+		return fmt.Errorf("apagar checkpoint: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	out, _ := json.Marshal(map[string]any{"rolled_back": id, "table": target, "rows_deleted": tag.RowsAffected()})
+	fmt.Println(string(out))
+	return nil
+}
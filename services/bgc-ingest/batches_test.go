@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestInspectFile(t *testing.T) {
+	path := writeFile(t, "EXP_2024.csv", "abc")
+
+	src, err := inspectFile(path)
+	if err != nil {
+		t.Fatalf("inspectFile: %v", err)
+	}
+	if !filepath.IsAbs(src.path) || src.size != 3 {
+		t.Errorf("path = %q, size = %d", src.path, src.size)
+	}
+	// sha256("abc")
+	if want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; src.hash != want {
+		t.Errorf("hash = %s, want %s", src.hash, want)
+	}
+
+	// Mesmo conteúdo, mtime diferente: mesmo hash (dedupe), outro fingerprint (checkpoint)
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	touched, err := inspectFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if touched.hash != src.hash || touched.fingerprint == src.fingerprint {
+		t.Errorf("touched: hash %s/%s fingerprint %s/%s", touched.hash, src.hash, touched.fingerprint, src.fingerprint)
+	}
+
+	if _, err := inspectFile(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("arquivo inexistente: esperava erro")
+	}
+}
+
+func TestTableNameAndSchema(t *testing.T) {
+	tests := []struct {
+		table      pgx.Identifier
+		wantName   string
+		wantSchema string
+	}{
+		{table: pgx.Identifier{"stg", "exportacao"}, wantName: "stg.exportacao", wantSchema: "stg"},
+		{table: pgx.Identifier{"exportacao"}, wantName: "exportacao", wantSchema: "public"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantName, func(t *testing.T) {
+			if got := tableName(tt.table); got != tt.wantName {
+				t.Errorf("tableName = %q, want %q", got, tt.wantName)
+			}
+			if got := schemaOf(tt.table); got != tt.wantSchema {
+				t.Errorf("schemaOf = %q, want %q", got, tt.wantSchema)
+			}
+		})
+	}
+}
+
+func TestCmdBatchesUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "no subcommand", wantErr: "usage"},
+		{name: "rollback without id", args: []string{"rollback"}, wantErr: "usage"},
+		{name: "rollback extra args", args: []string{"rollback", "a", "b"}, wantErr: "usage"},
+		{name: "unknown", args: []string{"purge"}, wantErr: "desconhecido"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cmdBatches(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("cmdBatches(%v) = %v, want error containing %q", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckRollback(t *testing.T) {
+	tests := []struct {
+		kind    string
+		wantErr bool
+	}{
+		{kind: "csv"},
+		{kind: "xlsx"},
+		{kind: "comexstat", wantErr: true},
+		{kind: "gateway", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			err := checkRollback("b1", tt.kind)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkRollback(%s) = %v, wantErr %v", tt.kind, err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "recarregue o período") {
+				t.Errorf("erro sem orientação: %v", err)
+			}
+		})
+	}
+}
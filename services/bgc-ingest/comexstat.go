@@ -0,0 +1,339 @@
+// comexstat.go — comando load-comexstat (arquivos oficiais EXP_YYYY.csv / IMP_YYYY.csv)
+//
+// Os arquivos da ComexStat (https://www.gov.br/mdic/pt-br/assuntos/comercio-exterior/estatisticas/base-de-dados-bruta)
+// são separados por ';', com aspas e cabeçalho:
+//   EXP: CO_ANO;CO_MES;CO_NCM;CO_UNID;CO_PAIS;SG_UF_NCM;CO_VIA;CO_URF;QT_ESTAT;KG_LIQUIDO;VL_FOB
+//   IMP: ... ;VL_FOB;VL_FRETE;VL_SEGURO
+//
+// Cada linha é detalhada por via/URF/unidade; stg.exportacao (e stg.importacao) guarda uma
+// linha por (ano, mês, país, NCM, UF). Por isso os lotes são agregados e somados no destino
+// (mergeSpec em loader.go). Para o resultado não dobrar ao recarregar, a carga do zero
+// apaga antes o ano do arquivo (EXP_2024.csv -> co_ano = 2024; ver --year).
+// O download do MDIC (EXP_2024.zip) pode ser carregado direto: o CSV é lido de dentro
+// do zip (--member para escolher o membro; ver sources.go).
+// Linhas somadas ficam com o ingest_batch do último lote que as gravou.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// comexOpts opções de load-comexstat.
+type comexOpts struct {
+	path      string // arquivo EXP_YYYY.csv / IMP_YYYY.csv
+	flow      string // exp | imp
+	countries string // tabela PAIS.csv da ComexStat
+	year      int    // ano substituído na carga (0 = só soma, não apaga nada)
+	table     string // tabela de destino (default pelo fluxo)
+	member    string // membro do .zip (vazio = todos)
+	copy      copyOpts
+}
+
+// comexTables tabela de destino por fluxo.
+var comexTables = map[string]string{
+	"exp": "stg.exportacao",
+	"imp": "stg.importacao",
+}
+
+// comexConflict chave de upsert de stg.exportacao / stg.importacao (migrations 0020 e 0014).
+var comexConflict = []string{"co_ano", "co_mes", "co_pais", "co_ncm", "sg_uf_ncm"}
+
+// comexFileName reconhece EXP_2024.csv, IMP_2023.csv, EXP_2024_MUN.csv...
+var comexFileName = regexp.MustCompile(`^(EXP|IMP)_(\d{4})`)
+
+// parseComexOpts lê as flags; fluxo e ano saem do nome do arquivo se não informados.
+func parseComexOpts(args []string) (*comexOpts, error) {
+	fs := flag.NewFlagSet("load-comexstat", flag.ContinueOnError)
+	path := fs.String("path", "", "arquivo oficial da ComexStat (ex.: /data/EXP_2024.csv ou EXP_2024.zip)")
+	flow := fs.String("flow", "", "exp ou imp (default: prefixo do arquivo)")
+	countries := fs.String("countries", "", "tabela PAIS.csv da ComexStat (default: PAIS.csv ao lado do arquivo)")
+	year := fs.Int("year", -1, "ano substituído pela carga (default: ano do nome do arquivo; 0 = só soma)")
+	table := fs.String("table", "", "tabela de destino (default: stg.exportacao ou stg.importacao)")
+	member := fs.String("member", "", "membro do .zip (nome ou glob; default: todos)")
+	copyOptions := copyFlags(fs)
+	fs.SetOutput(new(nopWriter))
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *path == "" {
+		return nil, errors.New("obrigatório: --path")
+	}
+
+	opts := &comexOpts{path: *path, flow: strings.ToLower(*flow), countries: *countries, year: *year, table: *table, member: *member}
+
+	match := comexFileName.FindStringSubmatch(strings.ToUpper(filepath.Base(*path)))
+	if opts.flow == "" {
+		if match == nil {
+			return nil, errors.New("--flow obrigatório (arquivo fora do padrão EXP_YYYY/IMP_YYYY)")
+		}
+		opts.flow = strings.ToLower(match[1])
+	}
+	if _, ok := comexTables[opts.flow]; !ok {
+		return nil, fmt.Errorf("--flow inválido: %q (use exp ou imp)", opts.flow)
+	}
+	if opts.table == "" {
+		opts.table = comexTables[opts.flow]
+	}
+	if opts.year < 0 {
+		if match == nil {
+			return nil, errors.New("--year obrigatório (arquivo fora do padrão EXP_YYYY/IMP_YYYY; use 0 para só somar)")
+		}
+		opts.year, _ = strconv.Atoi(match[2])
+	}
+	if opts.countries == "" {
+		opts.countries = filepath.Join(filepath.Dir(*path), "PAIS.csv")
+	}
+
+	copyOpt, err := copyOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts.copy = copyOpt
+	return opts, nil
+}
+
+// comexColumns colunas do arquivo -> colunas de destino (obrigatória = precisa estar no cabeçalho).
+var comexColumns = []struct {
+	file     string
+	required bool
+}{
+	{"CO_ANO", true},
+	{"CO_MES", true},
+	{"CO_NCM", true},
+	{"CO_PAIS", true},
+	{"SG_UF_NCM", false},
+	{"QT_ESTAT", false},
+	{"KG_LIQUIDO", true},
+	{"VL_FOB", true},
+	{"VL_FRETE", false},
+	{"VL_SEGURO", false},
+}
+
+// cmdLoadComexStat carrega um arquivo EXP/IMP oficial em stg.exportacao / stg.importacao.
+func cmdLoadComexStat(args []string) error {
+	opts, err := parseComexOpts(args)
+	if err != nil {
+		return err
+	}
+	table, err := parseTable(opts.table)
+	if err != nil {
+		return err
+	}
+	countries, err := loadCountryMap(opts.countries)
+	if err != nil {
+		return err
+	}
+
+	src, err := inspectFile(opts.path)
+	if err != nil {
+		return fmt.Errorf("abrir arquivo: %w", err)
+	}
+	source, hash := src.path, src.hash
+	if opts.member != "" {
+		source, hash = source+"#"+opts.member, hash+"#"+opts.member
+	}
+
+	// CSV puro, .csv.gz ou membros do .zip do MDIC (ver sources.go)
+	records, err := openRecords(opts.path, inputOpts{member: opts.member, sep: ';', header: true})
+	if err != nil {
+		return fmt.Errorf("abrir arquivo: %w", err)
+	}
+	defer records.close()
+
+	// Cabeçalho: posição de cada coluna conhecida
+	idx := map[string]int{}
+	for i, h := range records.header() {
+		idx[normalizeHeader(h)] = i
+	}
+	for _, c := range comexColumns {
+		if _, ok := idx[c.file]; !ok && c.required {
+			return fmt.Errorf("coluna %s ausente no cabeçalho", c.file)
+		}
+	}
+
+	// Colunas de destino: fixas + métricas opcionais presentes no arquivo
+	columns := []string{"co_ano", "co_mes", "co_pais", "sg_uf_ncm", "co_ncm", "co_sh4", "co_sh2", "vl_fob", "kg_liquido"}
+	sums := []string{"vl_fob", "kg_liquido"}
+	var optional []string
+	for _, name := range []string{"QT_ESTAT", "VL_FRETE", "VL_SEGURO"} {
+		if _, ok := idx[name]; ok {
+			optional = append(optional, name)
+			columns = append(columns, strings.ToLower(name))
+			sums = append(sums, strings.ToLower(name))
+		}
+	}
+
+	unmapped := map[string]int64{} // CO_PAIS sem ISO (carregados como ZZ)
+	validator := newRowValidator(columns)
+
+	next := func() ([]any, error) {
+		for {
+			rec, err := records.read()
+			if err != nil {
+				return nil, err
+			}
+			if len(rec) == 0 || (len(rec) == 1 && strings.TrimSpace(rec[0]) == "") {
+				continue
+			}
+
+			get := func(col string) string {
+				if i, ok := idx[col]; ok && i < len(rec) {
+					return strings.TrimSpace(rec[i])
+				}
+				return ""
+			}
+
+			// Erro de conversão/validação = linha rejeitada (ver validate.go)
+			row, err := comexRow(get, countries, unmapped, opts.year, optional)
+			if err == nil {
+				err = validator.check(row)
+			}
+			if err != nil {
+				return nil, rejectRow(records.line(), rec, err)
+			}
+			return row, nil
+		}
+	}
+
+	// Carga do zero: o arquivo substitui o ano inteiro no destino
+	var prepare func(ctx context.Context, tx pgx.Tx) error
+	if opts.year > 0 {
+		prepare = func(ctx context.Context, tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE co_ano = $1`, table.Sanitize()), opts.year)
+			return err
+		}
+	}
+
+	pool, err := connect()
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer pool.Close()
+	if err := saveCountryMap(context.Background(), pool, countries); err != nil {
+		return err
+	}
+
+	res, err := runCopy(context.Background(), pool, copyJob{
+		kind:        "comexstat",
+		source:      source,
+		fingerprint: src.fingerprint,
+		hash:        hash,
+		table:       table,
+		columns:     columns,
+		next:        next,
+		done:        records.done,
+		opts:        opts.copy,
+		merge:       &mergeSpec{conflict: comexConflict, sum: sums},
+		prepare:     prepare,
+	})
+	if err != nil {
+		return err
+	}
+
+	extra := map[string]any{"table": opts.table, "source": "comexstat", "flow": opts.flow}
+	if opts.year > 0 {
+		extra["year"] = opts.year
+	}
+	if len(unmapped) > 0 {
+		extra["unmapped_countries"] = unmapped
+	}
+	printCopyResult(res, extra)
+	return nil
+}
+
+// comexRow converte uma linha do arquivo nas colunas de destino.
+// CO_PAIS sem ISO vira ZZ (contado em unmapped); demais erros são *fieldError.
+func comexRow(get func(string) string, countries countryMap, unmapped map[string]int64, year int, optional []string) ([]any, error) {
+	ano, err := strconv.Atoi(get("CO_ANO"))
+	if err != nil {
+		return nil, &fieldError{"CO_ANO", ruleParse, fmt.Sprintf("inteiro inválido %q", get("CO_ANO"))}
+	}
+	if year > 0 && ano != year {
+		return nil, &fieldError{"CO_ANO", ruleYearFile, fmt.Sprintf("ano %d diferente do ano da carga %d (ver --year)", ano, year)}
+	}
+	mes, err := strconv.Atoi(get("CO_MES"))
+	if err != nil {
+		return nil, &fieldError{"CO_MES", ruleParse, fmt.Sprintf("inteiro inválido %q", get("CO_MES"))}
+	}
+
+	ncm := get("CO_NCM")
+	if ncm != "" && len(ncm) < 8 {
+		ncm = strings.Repeat("0", 8-len(ncm)) + ncm // NCM perdeu zeros à esquerda (ex.: planilha)
+	}
+	var sh4, sh2 any
+	if len(ncm) >= 4 {
+		sh4, sh2 = ncm[:4], ncm[:2]
+	}
+
+	pais, ok := countries.lookup(get("CO_PAIS"))
+	if !ok {
+		unmapped[get("CO_PAIS")]++
+	}
+
+	var uf any // UF vazia -> NULL (total nacional)
+	if v := get("SG_UF_NCM"); v != "" {
+		uf = v
+	}
+
+	fob, err := parseComexNumber(get("VL_FOB"))
+	if err != nil {
+		return nil, &fieldError{"VL_FOB", ruleParse, err.Error()}
+	}
+	kg, err := parseComexNumber(get("KG_LIQUIDO"))
+	if err != nil {
+		return nil, &fieldError{"KG_LIQUIDO", ruleParse, err.Error()}
+	}
+
+	row := []any{ano, mes, pais, uf, ncm, sh4, sh2, fob, kg}
+	for _, name := range optional {
+		if name == "QT_ESTAT" {
+			n, err := parseComexInt(get(name))
+			if err != nil {
+				return nil, &fieldError{name, ruleParse, err.Error()}
+			}
+			row = append(row, n)
+			continue
+		}
+		v, err := parseComexNumber(get(name))
+		if err != nil {
+			return nil, &fieldError{name, ruleParse, err.Error()}
+		}
+		row = append(row, v)
+	}
+	return row, nil
+}
+
+// parseComexInt lê QT_ESTAT (BIGINT, migration 0019): inteiros sem passar por float64,
+// que perderia precisão acima de 2^53; valores com decimais são truncados.
+func parseComexInt(s string) (int64, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	v, err := parseComexNumber(s)
+	if err != nil {
+		return 0, err
+	}
+	return int64(v), nil
+}
+
+// parseComexNumber lê um valor numérico do arquivo (vazio = 0; aceita vírgula decimal).
+func parseComexNumber(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+	if err != nil {
+		return 0, fmt.Errorf("valor inválido %q", s)
+	}
+	return v, nil
+}
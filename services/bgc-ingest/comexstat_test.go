@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFile grava um arquivo temporário do teste e devolve o caminho.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCountryMapLookup(t *testing.T) {
+	m := countryMap{160: "CN", 249: "US", 999: unknownCountry}
+
+	tests := []struct {
+		code   string
+		want   string
+		wantOK bool
+	}{
+		{code: "160", want: "CN", wantOK: true},
+		{code: " 249 ", want: "US", wantOK: true},
+		{code: "999", want: unknownCountry, wantOK: true},
+		{code: "cn", want: "CN", wantOK: true}, // já é ISO alpha-2 (gateway)
+		{code: "AR", want: "AR", wantOK: true},
+		{code: "123", want: unknownCountry, wantOK: false},
+		{code: "XX", want: unknownCountry, wantOK: false},
+		{code: "", want: unknownCountry, wantOK: false},
+		{code: "China", want: unknownCountry, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, ok := m.lookup(tt.code)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("lookup(%q) = (%q, %v), want (%q, %v)", tt.code, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLoadCountryMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    countryMap
+		wantErr bool
+	}{
+		{
+			name: "official layout",
+			content: "\ufeff\"CO_PAIS\";\"CO_PAIS_ISON3\";\"CO_PAIS_ISOA3\";\"NO_PAIS\"\n" +
+				"\"160\";\"156\";\"CHN\";\"China\"\n" +
+				"\"249\";\"840\";\"USA\";\"Estados Unidos\"\n" +
+				"\"999\";\"898\";\"ZZZ\";\"Não Declarados\"\n" +
+				"\"abc\";\"0\";\"ARG\";\"inválido\"\n" +
+				"\"63\"\n",
+			want: countryMap{160: "CN", 249: "US", 999: unknownCountry},
+		},
+		{
+			name:    "missing columns",
+			content: "CO_PAIS;NO_PAIS\n160;China\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			content: "CO_PAIS;CO_PAIS_ISOA3\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadCountryMap(writeFile(t, "PAIS.csv", tt.content))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("esperava erro, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadCountryMap: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := loadCountryMap(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("arquivo inexistente: esperava erro")
+	}
+}
+
+func TestParseComexNumber(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "1500", want: 1500},
+		{in: "1500.25", want: 1500.25},
+		{in: "1500,25", want: 1500.25},
+		{in: "-3", want: -3},
+		{in: "1.500,25", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseComexNumber(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseComexNumber(%q) = %v, esperava erro", tt.in, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseComexNumber(%q) = (%v, %v), want %v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestComexRow(t *testing.T) {
+	countries := countryMap{160: "CN"}
+	base := map[string]string{
+		"CO_ANO": "2024", "CO_MES": "3", "CO_NCM": "2011000", "CO_PAIS": "160",
+		"SG_UF_NCM": "SP", "KG_LIQUIDO": "10,5", "VL_FOB": "1000", "QT_ESTAT": "7", "VL_FRETE": "",
+	}
+	record := func(changes map[string]string) func(string) string {
+		rec := map[string]string{}
+		for k, v := range base {
+			rec[k] = v
+		}
+		for k, v := range changes {
+			rec[k] = v
+		}
+		return func(col string) string { return rec[col] }
+	}
+
+	tests := []struct {
+		name         string
+		changes      map[string]string
+		year         int
+		optional     []string
+		want         []any
+		wantRule     string
+		wantUnmapped map[string]int64
+	}{
+		{
+			name:     "pads ncm and parses optional metrics",
+			year:     2024,
+			optional: []string{"QT_ESTAT", "VL_FRETE"},
+			want:     []any{2024, 3, "CN", "SP", "02011000", "0201", "02", 1000.0, 10.5, int64(7), 0.0},
+		},
+		{
+			name:    "empty uf is national total",
+			changes: map[string]string{"SG_UF_NCM": ""},
+			want:    []any{2024, 3, "CN", nil, "02011000", "0201", "02", 1000.0, 10.5},
+		},
+		{
+			name:         "unmapped country becomes ZZ",
+			changes:      map[string]string{"CO_PAIS": "777"},
+			want:         []any{2024, 3, unknownCountry, "SP", "02011000", "0201", "02", 1000.0, 10.5},
+			wantUnmapped: map[string]int64{"777": 1},
+		},
+		{
+			name:     "qt_estat above int32",
+			changes:  map[string]string{"QT_ESTAT": "9007199254740993"}, // 2^53 + 1: float64 perderia o 1
+			optional: []string{"QT_ESTAT"},
+			want:     []any{2024, 3, "CN", "SP", "02011000", "0201", "02", 1000.0, 10.5, int64(9007199254740993)},
+		},
+		{
+			name:     "qt_estat with decimals",
+			changes:  map[string]string{"QT_ESTAT": "3000000000,7"},
+			optional: []string{"QT_ESTAT"},
+			want:     []any{2024, 3, "CN", "SP", "02011000", "0201", "02", 1000.0, 10.5, int64(3000000000)},
+		},
+		{name: "year from another file", year: 2023, wantRule: ruleYearFile},
+		{name: "invalid year", changes: map[string]string{"CO_ANO": "20x4"}, wantRule: ruleParse},
+		{name: "invalid month", changes: map[string]string{"CO_MES": ""}, wantRule: ruleParse},
+		{name: "invalid fob", changes: map[string]string{"VL_FOB": "n/a"}, wantRule: ruleParse},
+		{name: "invalid optional", changes: map[string]string{"QT_ESTAT": "x"}, optional: []string{"QT_ESTAT"}, wantRule: ruleParse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unmapped := map[string]int64{}
+			got, err := comexRow(record(tt.changes), countries, unmapped, tt.year, tt.optional)
+			if tt.wantRule != "" {
+				var fe *fieldError
+				if !errors.As(err, &fe) || fe.rule != tt.wantRule {
+					t.Fatalf("err = %v, want rule %q", err, tt.wantRule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("comexRow: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v\nwant %#v", got, tt.want)
+			}
+			if tt.wantUnmapped == nil {
+				tt.wantUnmapped = map[string]int64{}
+			}
+			if !reflect.DeepEqual(unmapped, tt.wantUnmapped) {
+				t.Errorf("unmapped = %v, want %v", unmapped, tt.wantUnmapped)
+			}
+		})
+	}
+}
+
+func TestParseComexOpts(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantFlow  string
+		wantYear  int
+		wantTable string
+		wantErr   bool
+	}{
+		{
+			name:     "from file name",
+			args:     []string{"--path=/data/EXP_2024.csv"},
+			wantFlow: "exp", wantYear: 2024, wantTable: "stg.exportacao",
+		},
+		{
+			name:     "zip import",
+			args:     []string{"--path=/data/imp_2023.zip"},
+			wantFlow: "imp", wantYear: 2023, wantTable: "stg.importacao",
+		},
+		{
+			name:     "explicit flags",
+			args:     []string{"--path=/data/dump.csv", "--flow=IMP", "--year=0", "--table=stg.teste"},
+			wantFlow: "imp", wantYear: 0, wantTable: "stg.teste",
+		},
+		{name: "missing path", wantErr: true},
+		{name: "unknown flow", args: []string{"--path=/data/dump.csv", "--flow=x", "--year=0"}, wantErr: true},
+		{name: "no flow in name", args: []string{"--path=/data/dump.csv", "--year=0"}, wantErr: true},
+		{name: "no year in name", args: []string{"--path=/data/dump.csv", "--flow=exp"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseComexOpts(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("esperava erro, got %+v", opts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseComexOpts: %v", err)
+			}
+			if opts.flow != tt.wantFlow || opts.year != tt.wantYear || opts.table != tt.wantTable {
+				t.Errorf("got flow=%q year=%d table=%q, want flow=%q year=%d table=%q",
+					opts.flow, opts.year, opts.table, tt.wantFlow, tt.wantYear, tt.wantTable)
+			}
+			if want := filepath.Join(filepath.Dir(opts.path), "PAIS.csv"); opts.countries != want {
+				t.Errorf("countries = %q, want %q", opts.countries, want)
+			}
+		})
+	}
+}
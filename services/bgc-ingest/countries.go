@@ -0,0 +1,174 @@
+// countries.go — códigos de país da ComexStat -> ISO 3166-1 alpha-2
+//
+// Os arquivos EXP/IMP trazem CO_PAIS numérico (tabela própria da Siscomex, ex.: 160 = China).
+// A tabela oficial PAIS.csv (https://balanca.economia.gov.br/balanca/bd/tabelas/PAIS.csv)
+// liga CO_PAIS ao ISO alpha-3 (CO_PAIS_ISOA3); daqui convertemos para alpha-2, que é o
+// formato de stg.exportacao.co_pais (migration 0011). A tabela convertida também vai para
+// stg.comexstat_paises (migration 0018), lida pela API.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// unknownCountry código ISO reservado para país não identificado (ex.: 999 "Não declarados").
+const unknownCountry = "ZZ"
+
+// countryMap CO_PAIS (numérico) -> ISO alpha-2.
+type countryMap map[int]string
+
+// lookup converte o CO_PAIS do arquivo; ok=false se o código não está na tabela.
+// Códigos que já são ISO alpha-2 (ex.: vindos do gateway) passam direto.
+func (m countryMap) lookup(code string) (string, bool) {
+	if iso := strings.ToUpper(strings.TrimSpace(code)); len(iso) == 2 && knownCountries[iso] {
+		return iso, true
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(code))
+	if err != nil {
+		return unknownCountry, false
+	}
+	iso, ok := m[n]
+	if !ok {
+		return unknownCountry, false
+	}
+	return iso, true
+}
+
+// loadCountryMap lê a tabela PAIS.csv da ComexStat (separador ';', cabeçalho obrigatório).
+// Países sem ISO alpha-3 conhecido (zonas, "a designar") mapeiam para ZZ.
+func loadCountryMap(path string) (countryMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("abrir tabela de países: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(skipBOM(bufio.NewReader(f)))
+	r.Comma = ';'
+	r.FieldsPerRecord = -1
+
+	hdr, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("ler cabeçalho da tabela de países: %w", err)
+	}
+	idxCode, idxA3 := -1, -1
+	for i, h := range hdr {
+		switch normalizeHeader(h) {
+		case "CO_PAIS":
+			idxCode = i
+		case "CO_PAIS_ISOA3":
+			idxA3 = i
+		}
+	}
+	if idxCode < 0 || idxA3 < 0 {
+		return nil, errors.New("tabela de países sem colunas CO_PAIS e CO_PAIS_ISOA3")
+	}
+
+	m := countryMap{}
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ler tabela de países: %w", err)
+		}
+		if idxCode >= len(rec) || idxA3 >= len(rec) {
+			continue
+		}
+		code, err := strconv.Atoi(strings.TrimSpace(rec[idxCode]))
+		if err != nil {
+			continue
+		}
+		iso, ok := iso3to2[strings.ToUpper(strings.TrimSpace(rec[idxA3]))]
+		if !ok {
+			iso = unknownCountry
+		}
+		m[code] = iso
+	}
+	if len(m) == 0 {
+		return nil, errors.New("tabela de países vazia")
+	}
+	return m, nil
+}
+
+// saveCountryMap grava a tabela em stg.comexstat_paises (migration 0018), usada pela API
+// para converter o CO_PAIS numérico dos dados ao vivo do gateway.
+func saveCountryMap(ctx context.Context, pool *pgxpool.Pool, m countryMap) error {
+	codes := make([]int32, 0, len(m))
+	isos := make([]string, 0, len(m))
+	for code, iso := range m {
+		codes = append(codes, int32(code))
+		isos = append(isos, iso)
+	}
+	_, err := pool.Exec(ctx, `
+		INSERT INTO stg.comexstat_paises (co_pais, iso_a2)
+		SELECT * FROM unnest($1::int[], $2::text[])
+		ON CONFLICT (co_pais) DO UPDATE SET iso_a2 = EXCLUDED.iso_a2, updated_at = now()
+		WHERE stg.comexstat_paises.iso_a2 <> EXCLUDED.iso_a2`, codes, isos)
+	if err != nil {
+		return fmt.Errorf("gravar tabela de países: %w", err)
+	}
+	return nil
+}
+
+// skipBOM descarta o BOM UTF-8 do início (o csv.Reader recusa BOM antes de campo com aspas).
+func skipBOM(r *bufio.Reader) *bufio.Reader {
+	if b, err := r.Peek(3); err == nil && string(b) == "\ufeff" {
+		_, _ = r.Discard(3)
+	}
+	return r
+}
+
+// normalizeHeader remove BOM, aspas e espaços de um nome de coluna (arquivos da ComexStat).
+func normalizeHeader(h string) string {
+	h = strings.TrimPrefix(h, "\ufeff")
+	return strings.ToUpper(strings.Trim(strings.TrimSpace(h), `"`))
+}
+
+// iso3to2 ISO 3166-1 alpha-3 -> alpha-2.
+var iso3to2 = map[string]string{
+	"ABW": "AW", "AFG": "AF", "AGO": "AO", "AIA": "AI", "ALA": "AX", "ALB": "AL", "AND": "AD", "ARE": "AE",
+	"ARG": "AR", "ARM": "AM", "ASM": "AS", "ATA": "AQ", "ATF": "TF", "ATG": "AG", "AUS": "AU", "AUT": "AT",
+	"AZE": "AZ", "BDI": "BI", "BEL": "BE", "BEN": "BJ", "BES": "BQ", "BFA": "BF", "BGD": "BD", "BGR": "BG",
+	"BHR": "BH", "BHS": "BS", "BIH": "BA", "BLM": "BL", "BLR": "BY", "BLZ": "BZ", "BMU": "BM", "BOL": "BO",
+	"BRA": "BR", "BRB": "BB", "BRN": "BN", "BTN": "BT", "BVT": "BV", "BWA": "BW", "CAF": "CF", "CAN": "CA",
+	"CCK": "CC", "CHE": "CH", "CHL": "CL", "CHN": "CN", "CIV": "CI", "CMR": "CM", "COD": "CD", "COG": "CG",
+	"COK": "CK", "COL": "CO", "COM": "KM", "CPV": "CV", "CRI": "CR", "CUB": "CU", "CUW": "CW", "CXR": "CX",
+	"CYM": "KY", "CYP": "CY", "CZE": "CZ", "DEU": "DE", "DJI": "DJ", "DMA": "DM", "DNK": "DK", "DOM": "DO",
+	"DZA": "DZ", "ECU": "EC", "EGY": "EG", "ERI": "ER", "ESH": "EH", "ESP": "ES", "EST": "EE", "ETH": "ET",
+	"FIN": "FI", "FJI": "FJ", "FLK": "FK", "FRA": "FR", "FRO": "FO", "FSM": "FM", "GAB": "GA", "GBR": "GB",
+	"GEO": "GE", "GGY": "GG", "GHA": "GH", "GIB": "GI", "GIN": "GN", "GLP": "GP", "GMB": "GM", "GNB": "GW",
+	"GNQ": "GQ", "GRC": "GR", "GRD": "GD", "GRL": "GL", "GTM": "GT", "GUF": "GF", "GUM": "GU", "GUY": "GY",
+	"HKG": "HK", "HMD": "HM", "HND": "HN", "HRV": "HR", "HTI": "HT", "HUN": "HU", "IDN": "ID", "IMN": "IM",
+	"IND": "IN", "IOT": "IO", "IRL": "IE", "IRN": "IR", "IRQ": "IQ", "ISL": "IS", "ISR": "IL", "ITA": "IT",
+	"JAM": "JM", "JEY": "JE", "JOR": "JO", "JPN": "JP", "KAZ": "KZ", "KEN": "KE", "KGZ": "KG", "KHM": "KH",
+	"KIR": "KI", "KNA": "KN", "KOR": "KR", "KWT": "KW", "LAO": "LA", "LBN": "LB", "LBR": "LR", "LBY": "LY",
+	"LCA": "LC", "LIE": "LI", "LKA": "LK", "LSO": "LS", "LTU": "LT", "LUX": "LU", "LVA": "LV", "MAC": "MO",
+	"MAF": "MF", "MAR": "MA", "MCO": "MC", "MDA": "MD", "MDG": "MG", "MDV": "MV", "MEX": "MX", "MHL": "MH",
+	"MKD": "MK", "MLI": "ML", "MLT": "MT", "MMR": "MM", "MNE": "ME", "MNG": "MN", "MNP": "MP", "MOZ": "MZ",
+	"MRT": "MR", "MSR": "MS", "MTQ": "MQ", "MUS": "MU", "MWI": "MW", "MYS": "MY", "MYT": "YT", "NAM": "NA",
+	"NCL": "NC", "NER": "NE", "NFK": "NF", "NGA": "NG", "NIC": "NI", "NIU": "NU", "NLD": "NL", "NOR": "NO",
+	"NPL": "NP", "NRU": "NR", "NZL": "NZ", "OMN": "OM", "PAK": "PK", "PAN": "PA", "PCN": "PN", "PER": "PE",
+	"PHL": "PH", "PLW": "PW", "PNG": "PG", "POL": "PL", "PRI": "PR", "PRK": "KP", "PRT": "PT", "PRY": "PY",
+	"PSE": "PS", "PYF": "PF", "QAT": "QA", "REU": "RE", "ROU": "RO", "RUS": "RU", "RWA": "RW", "SAU": "SA",
+	"SDN": "SD", "SEN": "SN", "SGP": "SG", "SGS": "GS", "SHN": "SH", "SJM": "SJ", "SLB": "SB", "SLE": "SL",
+	"SLV": "SV", "SMR": "SM", "SOM": "SO", "SPM": "PM", "SRB": "RS", "SSD": "SS", "STP": "ST", "SUR": "SR",
+	"SVK": "SK", "SVN": "SI", "SWE": "SE", "SWZ": "SZ", "SXM": "SX", "SYC": "SC", "SYR": "SY", "TCA": "TC",
+	"TCD": "TD", "TGO": "TG", "THA": "TH", "TJK": "TJ", "TKL": "TK", "TKM": "TM", "TLS": "TL", "TON": "TO",
+	"TTO": "TT", "TUN": "TN", "TUR": "TR", "TUV": "TV", "TWN": "TW", "TZA": "TZ", "UGA": "UG", "UKR": "UA",
+	"UMI": "UM", "URY": "UY", "USA": "US", "UZB": "UZ", "VAT": "VA", "VCT": "VC", "VEN": "VE", "VGB": "VG",
+	"VIR": "VI", "VNM": "VN", "VUT": "VU", "WLF": "WF", "WSM": "WS", "YEM": "YE", "ZAF": "ZA", "ZMB": "ZM",
+	"ZWE": "ZW",
+}
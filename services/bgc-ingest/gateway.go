@@ -0,0 +1,133 @@
+// gateway.go — cliente mínimo do Integration Gateway (services/integration-gateway)
+//
+// Usado por sync-comexstat. Configuração por ambiente, como no bgc-api:
+//   INTEGRATION_GATEWAY_URL      (ex.: http://integration-gateway:8081)
+//   INTEGRATION_GATEWAY_API_KEY  (caller bgc-ingest em config/callers.yaml)
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gatewayClient executa endpoints de connectors via POST /v1/connectors/{id}/{endpoint}.
+type gatewayClient struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// newGatewayClient lê INTEGRATION_GATEWAY_URL / INTEGRATION_GATEWAY_API_KEY.
+func newGatewayClient(timeout time.Duration) (*gatewayClient, error) {
+	baseURL := strings.TrimRight(os.Getenv("INTEGRATION_GATEWAY_URL"), "/")
+	if baseURL == "" {
+		return nil, errors.New("INTEGRATION_GATEWAY_URL não definido")
+	}
+	return &gatewayClient{
+		baseURL: baseURL,
+		apiKey:  os.Getenv("INTEGRATION_GATEWAY_API_KEY"),
+		http:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// gatewayError resposta de erro do gateway ({"error", "code", "upstream_status"}).
+type gatewayError struct {
+	status     int    // status HTTP do gateway (0 = sem resposta)
+	code       string // ex.: rate_limited, upstream_not_found
+	message    string
+	retryAfter time.Duration // header Retry-After (429/503)
+}
+
+func (e *gatewayError) Error() string {
+	if e.status == 0 {
+		return "gateway: " + e.message
+	}
+	if e.code == "" {
+		return fmt.Sprintf("gateway: status %d: %s", e.status, e.message)
+	}
+	return fmt.Sprintf("gateway: status %d (%s): %s", e.status, e.code, e.message)
+}
+
+// retryable rate limit, circuito aberto ou gateway fora do ar: vale tentar de novo.
+func (e *gatewayError) retryable() bool {
+	switch e.status {
+	case 0, http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// notFound o upstream não tem o recurso (ex.: mês ainda não publicado pelo MDIC).
+func (e *gatewayError) notFound() bool {
+	return e.code == "upstream_not_found"
+}
+
+// execute chama o endpoint e devolve o `data` mapeado pelo connector.
+// Números chegam como json.Number (sem perda de precisão em vl_fob).
+// refresh=true ignora o cache do gateway (?refresh=true) e regrava o resultado.
+func (c *gatewayClient) execute(ctx context.Context, connector, endpoint string, params map[string]any, refresh bool) (map[string]any, error) {
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("gateway: params: %w", err)
+	}
+	path := "/v1/connectors/" + url.PathEscape(connector) + "/" + url.PathEscape(endpoint)
+	query := ""
+	if refresh {
+		query = "?refresh=true"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path+query, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("gateway: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, &gatewayError{message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &gatewayError{status: resp.StatusCode, message: err.Error()}
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+			Code  string `json:"code"`
+		}
+		if json.Unmarshal(body, &e) != nil || e.Error == "" {
+			e.Error = http.StatusText(resp.StatusCode)
+		}
+		ge := &gatewayError{status: resp.StatusCode, code: e.Code, message: e.Error}
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			ge.retryAfter = time.Duration(secs) * time.Second
+		}
+		return nil, ge
+	}
+
+	var out struct {
+		Data map[string]any `json:"data"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("gateway: resposta inválida de %s: %w", path, err)
+	}
+	return out.Data, nil
+}
@@ -0,0 +1,560 @@
+// loader.go — carga em lote via COPY (usada por load-csv e load-xlsx)
+//
+// Cada carga é registrada como um lote em stg.ingest_batches (ver batches.go).
+// A carga é dividida em lotes de --batch-size linhas. Cada lote roda em uma
+// transação própria: COPY das linhas + atualização do checkpoint em
+// stg.ingest_checkpoint (migration 0013). Se o processo cair, a próxima execução
+// com o mesmo arquivo pula as linhas já confirmadas e continua de onde parou.
+//
+// Memória: as linhas são lidas do arquivo sob demanda (pgx.CopyFromFunc), então
+// nunca há mais de uma linha em memória, independente do tamanho do lote.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Opções de carga (flags comuns a load-csv e load-xlsx)
+////////////////////////////////////////////////////////////////////////////////
+
+// copyOpts controla lotes, checkpoints e progresso da carga.
+type copyOpts struct {
+	batchSize int           // linhas por lote (1 lote = 1 transação = 1 checkpoint)
+	progress  time.Duration // intervalo entre linhas de progresso (0 = desliga)
+	resume    bool          // retoma do checkpoint se o arquivo não mudou
+	maxErrors int           // linhas rejeitadas toleradas antes de abortar (-1 = sem limite)
+	rejects   string        // arquivo CSV de rejeitos (vazio = <tmp>/<arquivo>.rejects.csv)
+	force     bool          // recarrega arquivo já carregado (mesmo hash e destino)
+}
+
+// copyFlags registra as flags de carga no FlagSet do comando.
+// Devolve uma função que monta/valida copyOpts após o fs.Parse.
+func copyFlags(fs *flag.FlagSet) func() (copyOpts, error) {
+	batchSize := fs.Int("batch-size", 50000, "linhas por lote/commit (checkpoint)")
+	progress := fs.Duration("progress", 10*time.Second, "intervalo do log de progresso (0 desliga)")
+	resume := fs.Bool("resume", true, "retoma carga interrompida a partir do último checkpoint")
+	maxErrors := fs.Int("max-errors", 100, "linhas rejeitadas toleradas antes de abortar (-1 = sem limite)")
+	rejects := fs.String("rejects", "", "arquivo CSV de linhas rejeitadas (default: <tmp>/<arquivo>.rejects.csv)")
+	force := fs.Bool("force", false, "recarrega mesmo se o arquivo já foi carregado nessa tabela")
+
+	return func() (copyOpts, error) {
+		if *batchSize <= 0 {
+			return copyOpts{}, errors.New("--batch-size deve ser > 0")
+		}
+		if *progress < 0 {
+			return copyOpts{}, errors.New("--progress deve ser >= 0")
+		}
+		return copyOpts{
+			batchSize: *batchSize,
+			progress:  *progress,
+			resume:    *resume,
+			maxErrors: *maxErrors,
+			rejects:   *rejects,
+			force:     *force,
+		}, nil
+	}
+}
+
+// parseTable converte "schema.tabela" em identificador pgx (com quoting seguro).
+func parseTable(table string) (pgx.Identifier, error) {
+	parts := strings.Split(table, ".")
+	for _, p := range parts {
+		if strings.TrimSpace(p) == "" {
+			return nil, fmt.Errorf("tabela inválida: %q", table)
+		}
+	}
+	if len(parts) > 2 {
+		return nil, fmt.Errorf("tabela inválida: %q (use schema.tabela)", table)
+	}
+	return pgx.Identifier(parts), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Job de carga
+////////////////////////////////////////////////////////////////////////////////
+
+// rowReader devolve a próxima linha já convertida para as colunas de destino.
+// Retorna io.EOF ao final do arquivo e *rowError para linha inválida (rejeitada).
+type rowReader func() ([]any, error)
+
+// copyJob descreve uma carga: origem, destino e como ler as linhas.
+type copyJob struct {
+	kind        string         // comando de origem (csv, xlsx, comexstat)
+	source      string         // identificador da origem no checkpoint (caminho absoluto, sheet...)
+	fingerprint string         // muda se o arquivo mudar (tamanho + mtime)
+	hash        string         // sha256 do conteúdo (deduplicação de lotes; + sheet no XLSX)
+	table       pgx.Identifier // tabela de destino
+	columns     []string       // colunas de destino, na ordem devolvida por next
+	next        rowReader      // leitor de linhas
+	done        func() float64 // fração da origem já lida (0..1); nil = desconhecida
+	opts        copyOpts
+
+	// merge (opcional): COPY em tabela temporária + upsert agregado na tabela de destino
+	merge *mergeSpec
+	// prepare (opcional): roda na transação do 1º lote quando a carga começa do zero
+	prepare func(ctx context.Context, tx pgx.Tx) error
+}
+
+// mergeSpec agrega as linhas de cada lote antes de gravar (GROUP BY das demais colunas)
+// e soma as métricas quando a chave já existe (ON CONFLICT ... DO UPDATE).
+type mergeSpec struct {
+	conflict []string // colunas do índice único da tabela de destino
+	sum      []string // métricas somadas (no lote e com a linha existente)
+}
+
+// copyResult resumo de uma carga.
+type copyResult struct {
+	Loaded   int64 // linhas carregadas nesta execução
+	Skipped  int64 // linhas puladas por já estarem no checkpoint
+	Batches  int   // lotes confirmados nesta execução
+	Finished bool  // false = arquivo já tinha sido carregado por completo (nada feito)
+	Elapsed  time.Duration
+	BatchID  string // lote em stg.ingest_batches (o existente, se já carregado)
+
+	Rejected    int64            // linhas rejeitadas nesta execução
+	RejectRules map[string]int64 // rejeições por regra
+	RejectsFile string           // arquivo de rejeitos (vazio = nenhuma rejeição)
+}
+
+// runCopy executa o job em lotes com checkpoint, progresso e registro do lote.
+func runCopy(ctx context.Context, pool *pgxpool.Pool, job copyJob) (*copyResult, error) {
+	start := time.Now()
+	res := &copyResult{}
+	target := job.table.Sanitize()
+
+	// 0) Mesmo arquivo já carregado nesse destino: nada a fazer (salvo --force)
+	if !job.opts.force {
+		id, err := findLoadedBatch(ctx, pool, job.hash, tableName(job.table))
+		if err != nil {
+			return nil, fmt.Errorf("consultar lotes: %w", err)
+		}
+		if id != "" {
+			res.BatchID = id
+			res.Elapsed = time.Since(start)
+			return res, nil
+		}
+	}
+
+	// 1) Checkpoint: decide se começa do zero ou retoma (e de qual lote)
+	var done int64
+	var resumeID string
+	if job.opts.resume && !job.opts.force {
+		cp, err := loadCheckpoint(ctx, pool, job.source, target)
+		if err != nil {
+			return nil, fmt.Errorf("ler checkpoint: %w", err)
+		}
+		if cp != nil && cp.fingerprint == job.fingerprint && cp.status == checkpointRunning {
+			done = cp.rowsDone
+			resumeID = cp.batchID
+		}
+	}
+
+	batchID, err := startBatch(ctx, pool, job, resumeID)
+	if err != nil {
+		return nil, fmt.Errorf("registrar lote: %w", err)
+	}
+	res.BatchID = batchID
+	if batchID != resumeID {
+		done = 0 // lote novo (ex.: checkpoint sem lote): carrega do início
+	}
+
+	// Linhas levam ingest_batch/ingest_source (se o destino tiver as colunas)
+	job, err = withProvenance(ctx, pool, job, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("colunas de proveniência: %w", err)
+	}
+
+	err = copyRows(ctx, pool, job, batchID, done, res)
+	if ferr := finishBatch(ctx, pool, batchID, res.Skipped+res.Loaded, res.Rejected, err); ferr != nil && err == nil {
+		err = fmt.Errorf("finalizar lote: %w", ferr)
+	}
+	res.Elapsed = time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// copyRows lê a origem e grava em lotes a partir da linha done, preenchendo res.
+func copyRows(ctx context.Context, pool *pgxpool.Pool, job copyJob, batchID string, done int64, res *copyResult) error {
+	target := job.table.Sanitize()
+	if err := saveCheckpoint(ctx, pool, job.source, target, job.fingerprint, batchID, done, checkpointRunning); err != nil {
+		return fmt.Errorf("gravar checkpoint: %w", err)
+	}
+
+	// Rejeitos: na retomada o arquivo da execução anterior é mantido (append)
+	rejects := newRejectWriter(rejectsPath(job), done > 0)
+	defer func() {
+		_ = rejects.close()
+		res.Rejected = rejects.total
+		res.RejectRules = rejects.summary()
+		if rejects.total > 0 {
+			res.RejectsFile = rejects.path
+		}
+	}()
+
+	// 2) Pula as linhas já confirmadas em execuções anteriores
+	// (linhas rejeitadas não entram em rows_done, então não contam aqui)
+	for res.Skipped < done {
+		if _, err := job.next(); err != nil {
+			var rowErr *rowError
+			if errors.As(err, &rowErr) {
+				continue
+			}
+			if err == io.EOF {
+				return fmt.Errorf("checkpoint aponta %d linhas, mas o arquivo tem %d", done, res.Skipped)
+			}
+			return fmt.Errorf("pular linha %d: %w", res.Skipped+1, err)
+		}
+		res.Skipped++
+	}
+
+	// 3) Lotes: COPY de até batchSize linhas + checkpoint na mesma transação
+	prog := newProgress(job.opts.progress, job.done)
+	fresh := done == 0
+	eof := false
+	for !eof {
+		var inBatch int64
+		var readErr error
+		src := pgx.CopyFromFunc(func() ([]any, error) {
+			if inBatch >= int64(job.opts.batchSize) {
+				return nil, nil
+			}
+			row, err := job.next()
+			// Linha inválida: rejeitos + próxima linha (até --max-errors)
+			for err != nil {
+				var rowErr *rowError
+				if !errors.As(err, &rowErr) {
+					break
+				}
+				if readErr = reject(rejects, rowErr, job.opts.maxErrors); readErr != nil {
+					return nil, readErr
+				}
+				row, err = job.next()
+			}
+			if err == io.EOF {
+				eof = true
+				return nil, nil
+			}
+			if err != nil {
+				readErr = fmt.Errorf("linha %d: %w", done+inBatch+1, err)
+				return nil, readErr
+			}
+			inBatch++
+			prog.tick(res.Loaded + inBatch)
+			return row, nil
+		})
+
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("begin: %w", err)
+		}
+		if fresh && job.prepare != nil {
+			if err := job.prepare(ctx, tx); err != nil {
+				_ = tx.Rollback(ctx)
+				return fmt.Errorf("preparar carga: %w", err)
+			}
+		}
+		n, err := copyBatch(ctx, tx, job, src)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			if readErr != nil {
+				return readErr
+			}
+			return fmt.Errorf("copy lote %d (a partir da linha %d): %w", res.Batches+1, done+1, err)
+		}
+
+		status := checkpointRunning
+		if eof {
+			status = checkpointDone
+		}
+		if err := saveCheckpoint(ctx, tx, job.source, target, job.fingerprint, batchID, done+n, status); err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("gravar checkpoint: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("commit lote %d: %w", res.Batches+1, err)
+		}
+
+		fresh = false
+		done += n
+		res.Loaded += n
+		res.Batches++
+	}
+
+	if err := rejects.close(); err != nil {
+		return fmt.Errorf("gravar arquivo de rejeitos: %w", err)
+	}
+	res.Finished = true
+	prog.final(res.Loaded)
+	return nil
+}
+
+// reject grava a linha no arquivo de rejeitos e aborta se passou de maxErrors.
+func reject(rejects *rejectWriter, rowErr *rowError, maxErrors int) error {
+	if err := rejects.write(rowErr); err != nil {
+		return err
+	}
+	if maxErrors >= 0 && rejects.total > int64(maxErrors) {
+		return fmt.Errorf("%d linhas rejeitadas (limite --max-errors=%d); última: %w; rejeitos em %s",
+			rejects.total, maxErrors, rowErr, rejects.path)
+	}
+	return nil
+}
+
+// rejectsPath arquivo de rejeitos do job (--rejects ou <tmp>/<arquivo>.rejects.csv).
+func rejectsPath(job copyJob) string {
+	if job.opts.rejects != "" {
+		return job.opts.rejects
+	}
+	name := strings.NewReplacer("#", "_", " ", "_").Replace(filepath.Base(job.source))
+	return filepath.Join(os.TempDir(), name+".rejects.csv")
+}
+
+// copyBatch grava um lote: COPY direto na tabela ou, com merge, via tabela temporária.
+// Retorna o número de linhas da origem copiadas (base do checkpoint).
+func copyBatch(ctx context.Context, tx pgx.Tx, job copyJob, src pgx.CopyFromSource) (int64, error) {
+	if job.merge == nil {
+		return tx.CopyFrom(ctx, job.table, job.columns, src)
+	}
+
+	quoted := make([]string, len(job.columns))
+	for i, c := range job.columns {
+		quoted[i] = pgx.Identifier{c}.Sanitize()
+	}
+	colList := strings.Join(quoted, ", ")
+
+	// Tabela temporária com as colunas do destino (descartada no commit)
+	tmp := pgx.Identifier{"ingest_copy"}
+	if _, err := tx.Exec(ctx, fmt.Sprintf(
+		`CREATE TEMP TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA`,
+		tmp.Sanitize(), colList, job.table.Sanitize())); err != nil {
+		return 0, fmt.Errorf("criar tabela temporária: %w", err)
+	}
+	n, err := tx.CopyFrom(ctx, tmp, job.columns, src)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, mergeSQL(job, tmp)); err != nil {
+		return 0, fmt.Errorf("merge lote: %w", err)
+	}
+	return n, nil
+}
+
+// mergeSQL monta o INSERT ... SELECT ... GROUP BY ... ON CONFLICT do lote.
+func mergeSQL(job copyJob, tmp pgx.Identifier) string {
+	isSum := map[string]bool{}
+	for _, c := range job.merge.sum {
+		isSum[c] = true
+	}
+	isConflict := map[string]bool{}
+	for _, c := range job.merge.conflict {
+		isConflict[c] = true
+	}
+
+	target := job.table.Sanitize()
+	var cols, selects, groups, sets []string
+	for _, c := range job.columns {
+		q := pgx.Identifier{c}.Sanitize()
+		cols = append(cols, q)
+		switch {
+		case isSum[c]:
+			selects = append(selects, fmt.Sprintf("SUM(%s)", q))
+			sets = append(sets, fmt.Sprintf("%s = COALESCE(t.%s, 0) + COALESCE(EXCLUDED.%s, 0)", q, q, q))
+		default:
+			selects = append(selects, q)
+			groups = append(groups, q)
+			if !isConflict[c] {
+				sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", q, q))
+			}
+		}
+	}
+	conflict := make([]string, len(job.merge.conflict))
+	for i, c := range job.merge.conflict {
+		conflict[i] = pgx.Identifier{c}.Sanitize()
+	}
+
+	return fmt.Sprintf(`INSERT INTO %s AS t (%s) SELECT %s FROM %s GROUP BY %s ON CONFLICT (%s) DO UPDATE SET %s`,
+		target, strings.Join(cols, ", "), strings.Join(selects, ", "), tmp.Sanitize(),
+		strings.Join(groups, ", "), strings.Join(conflict, ", "), strings.Join(sets, ", "))
+}
+
+// printCopyResult imprime o resumo da carga em JSON (stdout), com campos extras do comando.
+func printCopyResult(res *copyResult, extra map[string]any) {
+	out := map[string]any{
+		"loaded":  res.Loaded,
+		"skipped": res.Skipped,
+		"batches": res.Batches,
+		"elapsed": res.Elapsed.Round(time.Millisecond).String(),
+	}
+	if secs := res.Elapsed.Seconds(); secs > 0 {
+		out["rows_per_sec"] = float64(int64(float64(res.Loaded)/secs*10)) / 10
+	}
+	if res.Rejected > 0 {
+		out["rejected"] = res.Rejected
+		out["rejected_by_rule"] = res.RejectRules
+		out["rejects_file"] = res.RejectsFile
+	}
+	if res.BatchID != "" {
+		out["batch_id"] = res.BatchID
+	}
+	// lote concluído com o mesmo arquivo: nada a fazer (use --force)
+	if !res.Finished {
+		out["already_loaded"] = true
+	}
+	for k, v := range extra {
+		out[k] = v
+	}
+	b, _ := json.Marshal(out)
+	fmt.Println(string(b))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Checkpoints (stg.ingest_checkpoint)
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	checkpointRunning = "running"
+	checkpointDone    = "done"
+)
+
+type checkpoint struct {
+	fingerprint string
+	batchID     string // lote em stg.ingest_batches ("" = checkpoint anterior aos lotes)
+	rowsDone    int64
+	status      string
+}
+
+// execer é satisfeito por *pgxpool.Pool e pgx.Tx.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// loadCheckpoint lê o checkpoint de (source, target); nil se não existir.
+func loadCheckpoint(ctx context.Context, pool *pgxpool.Pool, source, target string) (*checkpoint, error) {
+	var cp checkpoint
+	var batchID *string
+	err := pool.QueryRow(ctx, `
+		SELECT fingerprint, batch_id::text, rows_done, status
+		FROM stg.ingest_checkpoint
+		WHERE source = $1 AND target_table = $2`, source, target,
+	).Scan(&cp.fingerprint, &batchID, &cp.rowsDone, &cp.status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if batchID != nil {
+		cp.batchID = *batchID
+	}
+	return &cp, nil
+}
+
+// saveCheckpoint grava o progresso de (source, target).
+// Se o lote mudou, started_at é reiniciado (nova carga do arquivo).
+func saveCheckpoint(ctx context.Context, db execer, source, target, fingerprint, batchID string, rowsDone int64, status string) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO stg.ingest_checkpoint (source, target_table, fingerprint, batch_id, rows_done, status)
+		VALUES ($1, $2, $3, $4::uuid, $5, $6)
+		ON CONFLICT (source, target_table) DO UPDATE SET
+			started_at  = CASE WHEN stg.ingest_checkpoint.batch_id IS NOT DISTINCT FROM EXCLUDED.batch_id
+			                   THEN stg.ingest_checkpoint.started_at ELSE now() END,
+			fingerprint = EXCLUDED.fingerprint,
+			batch_id    = EXCLUDED.batch_id,
+			rows_done   = EXCLUDED.rows_done,
+			status      = EXCLUDED.status,
+			updated_at  = now()`,
+		source, target, fingerprint, batchID, rowsDone, status)
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Progresso (JSON no stderr; stdout fica só com o resumo final)
+////////////////////////////////////////////////////////////////////////////////
+
+type progress struct {
+	every time.Duration
+	done  func() float64
+	start time.Time
+	last  time.Time
+}
+
+func newProgress(every time.Duration, done func() float64) *progress {
+	now := time.Now()
+	return &progress{every: every, done: done, start: now, last: now}
+}
+
+// tick emite uma linha de progresso se o intervalo passou.
+// Checa o relógio a cada 1024 linhas para não pesar no loop.
+func (p *progress) tick(rows int64) {
+	if p.every <= 0 || rows%1024 != 0 {
+		return
+	}
+	if time.Since(p.last) < p.every {
+		return
+	}
+	p.last = time.Now()
+	p.emit(rows)
+}
+
+// final emite a última linha de progresso (se habilitado).
+func (p *progress) final(rows int64) {
+	if p.every > 0 {
+		p.emit(rows)
+	}
+}
+
+func (p *progress) emit(rows int64) {
+	elapsed := time.Since(p.start)
+	line := map[string]any{
+		"progress":     true,
+		"rows":         rows,
+		"elapsed":      elapsed.Round(time.Second).String(),
+		"rows_per_sec": 0.0,
+	}
+	if secs := elapsed.Seconds(); secs > 0 {
+		line["rows_per_sec"] = float64(int64(float64(rows)/secs*10)) / 10
+	}
+	// pct/eta só quando a origem sabe quanto já foi lido
+	if p.done != nil {
+		if frac := p.done(); frac > 0 {
+			if frac > 1 {
+				frac = 1
+			}
+			line["pct"] = float64(int64(frac*1000)) / 10
+			eta := time.Duration(float64(elapsed) * (1 - frac) / frac)
+			line["eta"] = eta.Round(time.Second).String()
+		}
+	}
+	b, _ := json.Marshal(line)
+	fmt.Fprintln(os.Stderr, string(b))
+}
+
+// countingReader conta bytes lidos (progresso de CSV pelo tamanho do arquivo).
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
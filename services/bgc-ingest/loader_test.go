@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestParseTable(t *testing.T) {
+	tests := []struct {
+		table   string
+		want    pgx.Identifier
+		wantErr bool
+	}{
+		{table: "stg.exportacao", want: pgx.Identifier{"stg", "exportacao"}},
+		{table: "exportacao", want: pgx.Identifier{"exportacao"}},
+		{table: "", wantErr: true},
+		{table: "stg.", wantErr: true},
+		{table: ".exportacao", wantErr: true},
+		{table: "stg. ", wantErr: true},
+		{table: "db.stg.exportacao", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			got, err := parseTable(tt.table)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseTable(%q) = %v, esperava erro", tt.table, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTable(%q): %v", tt.table, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTable(%q) = %v, want %v", tt.table, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    copyOpts
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: copyOpts{batchSize: 50000, progress: 10 * time.Second, resume: true, maxErrors: 100},
+		},
+		{
+			name: "custom",
+			args: []string{"--batch-size=10", "--progress=0", "--resume=false", "--max-errors=-1", "--rejects=/tmp/r.csv", "--force"},
+			want: copyOpts{batchSize: 10, maxErrors: -1, rejects: "/tmp/r.csv", force: true},
+		},
+		{name: "zero batch", args: []string{"--batch-size=0"}, wantErr: true},
+		{name: "negative progress", args: []string{"--progress=-1s"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			build := copyFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got, err := build()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("esperava erro, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("copyFlags: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRejectsPath(t *testing.T) {
+	tests := []struct {
+		name string
+		job  copyJob
+		want string
+	}{
+		{
+			name: "flag",
+			job:  copyJob{source: "/data/a.csv", opts: copyOpts{rejects: "/out/r.csv"}},
+			want: "/out/r.csv",
+		},
+		{
+			name: "default",
+			job:  copyJob{source: "/data/a.csv"},
+			want: filepath.Join(os.TempDir(), "a.csv.rejects.csv"),
+		},
+		{
+			name: "sheet",
+			job:  copyJob{source: "/data/ncm 2024.xlsx#Plan1"},
+			want: filepath.Join(os.TempDir(), "ncm_2024.xlsx_Plan1.rejects.csv"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rejectsPath(tt.job); got != tt.want {
+				t.Errorf("rejectsPath = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSQL(t *testing.T) {
+	job := copyJob{
+		table:   pgx.Identifier{"stg", "exportacao"},
+		columns: []string{"ano", "pais", "ncm", "valor", "fonte"},
+		merge: &mergeSpec{
+			conflict: []string{"ano", "pais", "ncm"},
+			sum:      []string{"valor"},
+		},
+	}
+
+	got := mergeSQL(job, pgx.Identifier{"ingest_copy"})
+	want := `INSERT INTO "stg"."exportacao" AS t ("ano", "pais", "ncm", "valor", "fonte") ` +
+		`SELECT "ano", "pais", "ncm", SUM("valor"), "fonte" FROM "ingest_copy" ` +
+		`GROUP BY "ano", "pais", "ncm", "fonte" ` +
+		`ON CONFLICT ("ano", "pais", "ncm") DO UPDATE SET ` +
+		`"valor" = COALESCE(t."valor", 0) + COALESCE(EXCLUDED."valor", 0), "fonte" = EXCLUDED."fonte"`
+	if got != want {
+		t.Errorf("mergeSQL:\n got %s\nwant %s", got, want)
+	}
+}
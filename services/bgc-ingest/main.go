@@ -3,6 +3,27 @@
 //   1) `health`         -> testa conexão no Postgres com SELECT 1
 //   2) `insert-sample`  -> insere uma linha de exemplo em stg.exportacao
 //   3) `load-csv`       -> carrega um CSV para stg.exportacao (opções --path/--sep/--dec/--header)
+//                          também .csv.gz, .zip (--member) e Parquet (--format; ver sources.go)
+//   4) `load-xlsx`      -> carrega uma planilha XLSX (opções --path/--sheet/--dec/--header)
+//   5) `load-comexstat` -> carrega arquivo oficial EXP_YYYY.csv/IMP_YYYY.csv (ver comexstat.go)
+//   6) `batches`        -> lista/desfaz lotes de carga (`batches list`, `batches rollback <id>`)
+//   7) `sync-comexstat` -> busca meses no connector comexstat do Integration Gateway (ver sync.go)
+//   8) `refresh-views`  -> atualiza as materialized views (CONCURRENTLY quando possível; ver views.go)
+//   9) `quality-check`  -> órfãos, negativos, meses faltando e duplicados; relatório JSON (ver quality.go)
+//  10) `migrate`        -> aplica/reverte db/migrations com histórico e checksums (ver migrate.go)
+//
+// As cargas usam COPY em lotes (--batch-size), com checkpoint por lote em
+// stg.ingest_checkpoint (--resume) e progresso periódico no stderr (--progress).
+// Detalhes em loader.go.
+//
+// Cada carga vira um lote em stg.ingest_batches (hash, origem, contagens, status).
+// Um arquivo já carregado na mesma tabela é pulado, salvo com --force (ver batches.go).
+//
+// Linhas inválidas (parse, NCM, ano/mês, país, valores negativos) vão para um CSV de
+// rejeitos (--rejects) e a carga aborta após --max-errors rejeições (ver validate.go).
+//
+// load-csv e load-xlsx aceitam --mapping mapping.yaml para declarar as colunas de origem
+// de cada coluna de destino (tipos, separadores, datas, constantes, lookups; ver mapping.go).
 //
 // Observação: usamos pgx/pool (driver nativo) e variáveis de ambiente
 // (PGHOST, PGPORT, PGUSER, PGPASSWORD, PGDATABASE, PGSSLMODE) para configurar a conexão.
@@ -10,9 +31,7 @@
 package main
 
 import (
-	"bufio"          // buffer de leitura de arquivo (eficiente p/ CSVs grandes)
 	"context"        // contextos com timeout/cancel para operações no banco
-	"encoding/csv"   // parser de CSV
 	"errors"         // tratamento de erros
 	"flag"           // parsing de flags do comando load-csv
 	"fmt"            // impressão/format
@@ -131,7 +150,13 @@ type csvOpts struct {
 	sep    rune   // separador de campos (',' ou ';', etc.)
 	dec    string // separador decimal ('.' ou ',')
 	hasHdr bool   // primeira linha é cabeçalho?
-	table  string // tabela de destino (mantido p/ futuro; hoje usamos stg.exportacao)
+	table  string // tabela de destino (schema.tabela)
+	copy   copyOpts // lotes, checkpoint e progresso (ver loader.go)
+	format string // auto | csv | gzip | zip | parquet (ver sources.go)
+	member string // membro do .zip (vazio = todos)
+
+	mappingPath string       // --mapping (vazio = colunas legadas)
+	mapping     *mappingFile // mapeamento declarativo (ver mapping.go)
 }
 
 // nopWriter "silencia" a saída padrão do flag.FlagSet (evita poluir logs)
@@ -144,11 +169,15 @@ func parseCSVOpts(args []string) (*csvOpts, error) {
 	fs := flag.NewFlagSet("load-csv", flag.ContinueOnError)
 
 	// Define flags com defaults:
-	path := fs.String("path", "", "caminho do CSV dentro do pod (ex.: /data/sample.csv)")
+	path := fs.String("path", "", "caminho do CSV dentro do pod (ex.: /data/sample.csv, .csv.gz, .zip ou .parquet)")
 	sepStr := fs.String("sep", ",", "separador de campos (ex.: ',' ou ';')")
 	dec := fs.String("dec", ".", "separador decimal ('.' ou ',')")
 	hasHdr := fs.Bool("header", true, "primeira linha é cabeçalho?")
 	table := fs.String("table", "stg.exportacao", "tabela de destino")
+	mappingPath := fs.String("mapping", "", "arquivo YAML de mapeamento de colunas (ver mapping.go)")
+	format := fs.String("format", formatAuto, "formato: auto (pelo conteúdo), csv, gzip, zip ou parquet")
+	member := fs.String("member", "", "membro do .zip a carregar (nome ou glob; default: todos)")
+	copyOptions := copyFlags(fs) // --batch-size, --progress, --resume
 
 	// Evita que o FlagSet escreva help no stdout (deixa erros limpos nos logs)
 	fs.SetOutput(new(nopWriter))
@@ -163,42 +192,88 @@ func parseCSVOpts(args []string) (*csvOpts, error) {
 		return nil, errors.New("obrigatório: --path")
 	}
 
+	// Mapeamento: sep/header/table do arquivo valem quando a flag não foi passada
+	var mapping *mappingFile
+	if *mappingPath != "" {
+		m, err := loadMapping(*mappingPath)
+		if err != nil {
+			return nil, err
+		}
+		explicit := explicitFlags(fs)
+		if m.Sep != "" && !explicit["sep"] {
+			*sepStr = m.Sep
+		}
+		if m.Header != nil && !explicit["header"] {
+			*hasHdr = *m.Header
+		}
+		if m.Table != "" && !explicit["table"] {
+			*table = m.Table
+		}
+		if m.Decimal == "" {
+			m.Decimal = *dec
+		}
+		mapping = m
+	}
+
 	// Converte o separador de string para rune (precisa 1 caractere)
 	runes := []rune(*sepStr)
 	if len(runes) != 1 {
 		return nil, errors.New("--sep deve ter 1 caractere")
 	}
 
+	copyOpt, err := copyOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	return &csvOpts{
 		path:   *path,
 		sep:    runes[0],
 		dec:    *dec,
 		hasHdr: *hasHdr,
 		table:  *table,
+		copy:   copyOpt,
+		format: strings.ToLower(*format),
+		member: *member,
+
+		mappingPath: *mappingPath,
+		mapping:     mapping,
 	}, nil
 }
 
-// cmdLoadCSV abre o arquivo CSV, interpreta colunas e insere na stg.exportacao.
+// legacyColumns colunas de destino de load-csv/load-xlsx (na ordem dos readers).
+var legacyColumns = []string{"ano", "setor", "pais", "ncm", "valor", "qtde"}
+
+// cmdLoadCSV abre o arquivo CSV, interpreta colunas e carrega na tabela via COPY em lotes.
 func cmdLoadCSV(args []string) error {
 	// Lê e valida opções
 	opts, err := parseCSVOpts(args)
 	if err != nil {
 		return err
 	}
+	table, err := parseTable(opts.table)
+	if err != nil {
+		return err
+	}
 
-	// Abre o arquivo para leitura
-	f, err := os.Open(opts.path)
+	// Identidade do arquivo: checkpoint (caminho + tamanho/mtime) e lote (sha256)
+	src, err := inspectFile(opts.path)
 	if err != nil {
 		return fmt.Errorf("abrir csv: %w", err)
 	}
-	defer f.Close()
+	source, hash := src.path, src.hash
+	if opts.member != "" {
+		// um membro do .zip não retoma nem deduplica outro
+		source, hash = source+"#"+opts.member, hash+"#"+opts.member
+	}
 
-	// csv.Reader com separador configurável; FieldsPerRecord=-1 permite linhas com
-	// contagem de campos variável (útil para CSVs “imperfeitos”)
-	r := csv.NewReader(bufio.NewReader(f))
-	r.Comma = opts.sep
-	r.FieldsPerRecord = -1
-	r.ReuseRecord = true // reaproveita slice internamente (menos GC)
+	// Registros do arquivo: CSV puro, .csv.gz, membros de .zip ou Parquet (ver sources.go).
+	// O cabeçalho (se houver) já vem lido; no Parquet são os nomes das colunas.
+	records, err := openRecords(opts.path, inputOpts{format: opts.format, member: opts.member, sep: opts.sep, header: opts.hasHdr})
+	if err != nil {
+		return fmt.Errorf("abrir csv: %w", err)
+	}
+	defer records.close()
 
 	// Índices das colunas; tentaremos mapear automaticamente via cabeçalho
 	var idxAno, idxSetor, idxPais, idxNcm, idxValor, idxQtde int
@@ -221,14 +296,9 @@ func cmdLoadCSV(args []string) error {
 		idxQtde = find("qtde")
 	}
 
-	// Se o CSV tem cabeçalho, lê a 1ª linha e tenta mapear colunas por nome.
-	if opts.hasHdr {
-		hdr, err := r.Read()
-		if err != nil {
-			return fmt.Errorf("ler cabeçalho: %w", err)
-		}
-		autoIndex(hdr)
-	}
+	// Se o arquivo tem cabeçalho, tenta mapear colunas por nome.
+	hdr := records.header()
+	autoIndex(hdr)
 
 	// Se alguma coluna não foi encontrada por nome, assume ordem padrão (0..5).
 	defaultIfNeg := func(i, def int) int {
@@ -244,23 +314,6 @@ func cmdLoadCSV(args []string) error {
 	idxValor = defaultIfNeg(idxValor, 4)
 	idxQtde = defaultIfNeg(idxQtde, 5)
 
-	// Abre pool e inicia transação (melhor para múltiplos inserts)
-	pool, err := connect()
-	if err != nil {
-		return fmt.Errorf("connect: %w", err)
-	}
-	defer pool.Close()
-
-	tx, err := pool.Begin(context.Background())
-	if err != nil {
-		return fmt.Errorf("begin: %w", err)
-	}
-	// Garante rollback se der erro ou se sair antes do commit
-	defer func() { _ = tx.Rollback(context.Background()) }()
-
-	// Statement simples; depois podemos evoluir para COPY/COPY FROM p/ performance
-	stmt := `INSERT INTO stg.exportacao (ano, setor, pais, ncm, valor, qtde) VALUES ($1,$2,$3,$4,$5,$6)`
-
 	// decToDot converte decimal com vírgula para ponto, se necessário
 	decToDot := func(s string) string {
 		if opts.dec == "," {
@@ -269,46 +322,97 @@ func cmdLoadCSV(args []string) error {
 		return s
 	}
 
-	count := 0 // contador de linhas carregadas
-	for {
-		// Lê próxima linha do CSV
-		rec, err := r.Read()
-
-		// io.EOF -> acabou o arquivo (sai do loop)
-		if err == io.EOF {
-			break
-		}
-		// Se houve erro e NÃO é diferença de quantidade de campos, aborta
-		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
-			return fmt.Errorf("csv read: %w", err)
+	// convert transforma o registro nas colunas de destino:
+	// com --mapping pelo mapeamento declarativo; sem, pelas colunas legadas.
+	columns := legacyColumns
+	convert := func(rec []string) ([]any, error) {
+		// Linha curta: rejeita (antes causava index out of range)
+		for _, idx := range []int{idxAno, idxSetor, idxPais, idxNcm, idxValor, idxQtde} {
+			if idx >= len(rec) {
+				return nil, &fieldError{rule: ruleShortRecord, msg: fmt.Sprintf("linha com %d colunas", len(rec))}
+			}
 		}
-		// Linhas vazias: segue para a próxima
-		if rec == nil || len(rec) == 0 {
-			continue
+		ano, err := strconv.Atoi(strings.TrimSpace(rec[idxAno]))
+		if err != nil {
+			return nil, &fieldError{"ano", ruleParse, fmt.Sprintf("inteiro inválido %q", rec[idxAno])}
 		}
-
-		// Parse e normalização dos campos:
-		ano, _ := strconv.Atoi(strings.TrimSpace(rec[idxAno]))
 		setor := strings.TrimSpace(rec[idxSetor])
 		pais := strings.TrimSpace(rec[idxPais])
 		ncm := strings.TrimSpace(rec[idxNcm])
-		valor, _ := strconv.ParseFloat(decToDot(strings.TrimSpace(rec[idxValor])), 64)
-		qtde, _ := strconv.ParseFloat(decToDot(strings.TrimSpace(rec[idxQtde])), 64)
+		valor, err := strconv.ParseFloat(decToDot(strings.TrimSpace(rec[idxValor])), 64)
+		if err != nil {
+			return nil, &fieldError{"valor", ruleParse, fmt.Sprintf("número inválido %q", rec[idxValor])}
+		}
+		qtde, err := strconv.ParseFloat(decToDot(strings.TrimSpace(rec[idxQtde])), 64)
+		if err != nil {
+			return nil, &fieldError{"qtde", ruleParse, fmt.Sprintf("número inválido %q", rec[idxQtde])}
+		}
+		return []any{ano, setor, pais, ncm, valor, qtde}, nil
+	}
+	if opts.mapping != nil {
+		mapper, err := opts.mapping.compile(hdr)
+		if err != nil {
+			return fmt.Errorf("mapping: %w", err)
+		}
+		columns = opts.mapping.targets()
+		convert = mapper.row
+	}
+	validator := newRowValidator(columns)
+
+	// next lê a próxima linha do arquivo já convertida para as colunas de destino.
+	// Lê sob demanda: o COPY puxa uma linha por vez (memória constante).
+	next := func() ([]any, error) {
+		for {
+			// Lê próximo registro (io.EOF -> acabou o arquivo)
+			rec, err := records.read()
+			if err != nil {
+				return nil, err
+			}
+			// Linhas vazias: segue para a próxima
+			if len(rec) == 0 {
+				continue
+			}
 
-		// Executa INSERT na transação
-		if _, err := tx.Exec(context.Background(), stmt, ano, setor, pais, ncm, valor, qtde); err != nil {
-			return fmt.Errorf("insert linha %d: %w", count+1, err)
+			// Parse, normalização e validação dos campos (erro = linha rejeitada)
+			row, err := convert(rec)
+			if err == nil {
+				err = validator.check(row)
+			}
+			if err != nil {
+				return nil, rejectRow(records.line(), rec, err)
+			}
+			return row, nil
 		}
-		count++
 	}
 
-	// Fecha a transação gravando as mudanças
-	if err := tx.Commit(context.Background()); err != nil {
-		return fmt.Errorf("commit: %w", err)
+	pool, err := connect()
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer pool.Close()
+
+	// COPY em lotes de --batch-size linhas; cada lote confirma um checkpoint
+	res, err := runCopy(context.Background(), pool, copyJob{
+		kind:        "csv",
+		source:      source,
+		fingerprint: src.fingerprint,
+		hash:        hash,
+		table:       table,
+		columns:     columns,
+		next:        next,
+		done:        records.done, // fração do arquivo já lida, para pct/ETA do progresso
+		opts:        opts.copy,
+	})
+	if err != nil {
+		return err
 	}
 
-	// Log amigável em JSON com o total carregado
-	fmt.Printf(`{"loaded": %d, "table":"%s"}`+"\n", count, opts.table)
+	// Log amigável em JSON com o resumo da carga
+	extra := map[string]any{"table": opts.table, "source": "csv"}
+	if opts.mappingPath != "" {
+		extra["mapping"] = opts.mappingPath
+	}
+	printCopyResult(res, extra)
 	return nil
 }
 // ===== XLSX loader =====
@@ -318,7 +422,11 @@ type xlsxOpts struct {
     sheet  string // nome da planilha (ex.: "Sheet1"); se vazio, usa a primeira
     dec    string // separador decimal: "." ou ","
     hasHdr bool   // primeira linha é cabeçalho?
-    table  string // tabela destino (mantém para futura flexibilidade)
+    table  string // tabela destino (schema.tabela)
+    copy   copyOpts // lotes, checkpoint e progresso (ver loader.go)
+
+    mappingPath string       // --mapping (vazio = colunas legadas)
+    mapping     *mappingFile // mapeamento declarativo (ver mapping.go)
 }
 
 func parseXLSXOpts(args []string) (*xlsxOpts, error) {
@@ -328,6 +436,8 @@ func parseXLSXOpts(args []string) (*xlsxOpts, error) {
     dec := fs.String("dec", ".", "separador decimal ('.' ou ',')")
     hasHdr := fs.Bool("header", true, "primeira linha é cabeçalho?")
     table := fs.String("table", "stg.exportacao", "tabela de destino")
+    mappingPath := fs.String("mapping", "", "arquivo YAML de mapeamento de colunas (ver mapping.go)")
+    copyOptions := copyFlags(fs) // --batch-size, --progress, --resume
     fs.SetOutput(new(nopWriter))
     if err := fs.Parse(args); err != nil {
         return nil, err
@@ -335,18 +445,43 @@ func parseXLSXOpts(args []string) (*xlsxOpts, error) {
     if *path == "" {
         return nil, errors.New("obrigatório: --path")
     }
+    // mapeamento: sheet/header/table do arquivo valem quando a flag não foi passada
+    var mapping *mappingFile
+    if *mappingPath != "" {
+        m, err := loadMapping(*mappingPath)
+        if err != nil { return nil, err }
+        explicit := explicitFlags(fs)
+        if m.Sheet != "" && !explicit["sheet"] { *sheet = m.Sheet }
+        if m.Header != nil && !explicit["header"] { *hasHdr = *m.Header }
+        if m.Table != "" && !explicit["table"] { *table = m.Table }
+        if m.Decimal == "" { m.Decimal = *dec }
+        mapping = m
+    }
+    copyOpt, err := copyOptions()
+    if err != nil {
+        return nil, err
+    }
     return &xlsxOpts{
         path:   *path,
         sheet:  *sheet,
         dec:    *dec,
         hasHdr: *hasHdr,
         table:  *table,
+        copy:   copyOpt,
+
+        mappingPath: *mappingPath,
+        mapping:     mapping,
     }, nil
 }
 
 func cmdLoadXLSX(args []string) error {
     opts, err := parseXLSXOpts(args)
     if err != nil { return err }
+    table, err := parseTable(opts.table)
+    if err != nil { return err }
+
+    src, err := inspectFile(opts.path)
+    if err != nil { return fmt.Errorf("abrir xlsx: %w", err) }
 
     f, err := excelize.OpenFile(opts.path)
     if err != nil { return fmt.Errorf("abrir xlsx: %w", err) }
@@ -365,6 +500,15 @@ func cmdLoadXLSX(args []string) error {
     if err != nil { return fmt.Errorf("abrir linhas da sheet %q: %w", sheet, err) }
     defer rows.Close()
 
+    // total de linhas pela dimensão da sheet (ex.: "A1:F1000") -> pct/ETA do progresso
+    totalRows := 0
+    if dim, err := f.GetSheetDimension(sheet); err == nil {
+        if i := strings.LastIndex(dim, ":"); i >= 0 {
+            _, totalRows, _ = excelize.CellNameToCoordinates(dim[i+1:])
+        }
+    }
+    readRows := 0
+
     // índices das colunas (tentamos mapear por cabeçalho)
     var idxAno, idxSetor, idxPais, idxNcm, idxValor, idxQtde int
     autoIndex := func(header []string) {
@@ -383,11 +527,14 @@ func cmdLoadXLSX(args []string) error {
     }
 
     // lê cabeçalho, se houver
+    var hdr []string
     if opts.hasHdr && rows.Next() {
-        hdr, err := rows.Columns()
+        readRows++
+        hdr, err = rows.Columns()
         if err != nil { return fmt.Errorf("ler cabeçalho: %w", err) }
-        autoIndex(hdr)
+        if hdr == nil { hdr = []string{} } // cabeçalho vazio != sem cabeçalho
     }
+    autoIndex(hdr)
 
     defaultIfNeg := func(i, def int) int {
         if i < 0 { return def }
@@ -405,51 +552,83 @@ func cmdLoadXLSX(args []string) error {
         return s
     }
 
+    // convert transforma a linha nas colunas de destino (--mapping ou colunas legadas)
+    columns := legacyColumns
+    convert := func(rec []string) ([]any, error) {
+            get := func(idx int) string {
+                if idx >= 0 && idx < len(rec) { return strings.TrimSpace(rec[idx]) }
+                return ""
+            }
+            anoStr   := get(idxAno)
+            setor    := get(idxSetor)
+            pais     := get(idxPais)
+            ncm      := get(idxNcm)
+            valorStr := get(idxValor)
+            qtdeStr  := get(idxQtde)
+
+            ano, err := strconv.Atoi(anoStr)
+            if err != nil { return nil, &fieldError{"ano", ruleParse, fmt.Sprintf("inteiro inválido %q", anoStr)} }
+            valor, err := strconv.ParseFloat(decToDot(valorStr), 64)
+            if err != nil { return nil, &fieldError{"valor", ruleParse, fmt.Sprintf("número inválido %q", valorStr)} }
+            qtde, err := strconv.ParseFloat(decToDot(qtdeStr), 64)
+            if err != nil { return nil, &fieldError{"qtde", ruleParse, fmt.Sprintf("número inválido %q", qtdeStr)} }
+            return []any{ano, setor, pais, ncm, valor, qtde}, nil
+    }
+    if opts.mapping != nil {
+        mapper, err := opts.mapping.compile(hdr)
+        if err != nil { return fmt.Errorf("mapping: %w", err) }
+        columns = opts.mapping.targets()
+        convert = mapper.row
+    }
+    validator := newRowValidator(columns)
+
+    // next lê a próxima linha da sheet já convertida (sob demanda)
+    next := func() ([]any, error) {
+        for rows.Next() {
+            readRows++
+            rec, err := rows.Columns()
+            if err != nil { return nil, fmt.Errorf("ler linha: %w", err) }
+            // pular linhas totalmente vazias
+            empty := true
+            for _, c := range rec { if strings.TrimSpace(c) != "" { empty = false; break } }
+            if empty { continue }
+
+            // erro de conversão/validação = linha rejeitada
+            row, err := convert(rec)
+            if err == nil { err = validator.check(row) }
+            if err != nil { return nil, rejectRow(readRows, rec, err) }
+            return row, nil
+        }
+        if err := rows.Error(); err != nil { return nil, fmt.Errorf("iterar linhas: %w", err) }
+        return nil, io.EOF
+    }
+
+    done := func() float64 {
+        if totalRows == 0 { return 0 }
+        return float64(readRows) / float64(totalRows)
+    }
+
     pool, err := connect()
     if err != nil { return fmt.Errorf("connect: %w", err) }
     defer pool.Close()
 
-    tx, err := pool.Begin(context.Background())
-    if err != nil { return fmt.Errorf("begin: %w", err) }
-    defer func() { _ = tx.Rollback(context.Background()) }()
-
-    stmt := `INSERT INTO stg.exportacao (ano, setor, pais, ncm, valor, qtde) VALUES ($1,$2,$3,$4,$5,$6)`
-
-    count := 0
-    for rows.Next() {
-        rec, err := rows.Columns()
-        if err != nil { return fmt.Errorf("ler linha: %w", err) }
-        // pular linhas totalmente vazias
-        empty := true
-        for _, c := range rec { if strings.TrimSpace(c) != "" { empty = false; break } }
-        if empty { continue }
-
-        get := func(idx int) string {
-            if idx >= 0 && idx < len(rec) { return strings.TrimSpace(rec[idx]) }
-            return ""
-        }
-        anoStr   := get(idxAno)
-        setor    := get(idxSetor)
-        pais     := get(idxPais)
-        ncm      := get(idxNcm)
-        valorStr := get(idxValor)
-        qtdeStr  := get(idxQtde)
-
-        ano, _ := strconv.Atoi(anoStr)
-        valor, _ := strconv.ParseFloat(decToDot(valorStr), 64)
-        qtde, _ := strconv.ParseFloat(decToDot(qtdeStr), 64)
-
-        if _, err := tx.Exec(context.Background(), stmt, ano, setor, pais, ncm, valor, qtde); err != nil {
-            return fmt.Errorf("insert linha %d: %w", count+1, err)
-        }
-        count++
-    }
-    if err := rows.Error(); err != nil { return fmt.Errorf("iterar linhas: %w", err) }
+    // checkpoint e lote por arquivo + sheet (uma sheet não retoma nem deduplica outra)
+    res, err := runCopy(context.Background(), pool, copyJob{
+        kind:        "xlsx",
+        source:      src.path + "#" + sheet,
+        fingerprint: src.fingerprint,
+        hash:        src.hash + "#" + sheet,
+        table:       table,
+        columns:     columns,
+        next:        next,
+        done:        done,
+        opts:        opts.copy,
+    })
+    if err != nil { return err }
 
-    if err := tx.Commit(context.Background()); err != nil {
-        return fmt.Errorf("commit: %w", err)
-    }
-    fmt.Printf(`{"loaded": %d, "table":"%s","source":"xlsx","sheet":"%s"}`+"\n", count, opts.table, sheet)
+    extra := map[string]any{"table": opts.table, "source": "xlsx", "sheet": sheet}
+    if opts.mappingPath != "" { extra["mapping"] = opts.mappingPath }
+    printCopyResult(res, extra)
     return nil
 }
 
@@ -460,7 +639,7 @@ func cmdLoadXLSX(args []string) error {
 func main() {
 	// Verifica se pelo menos 1 argumento foi passado (o nome do comando)
 	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "usage: bgc-ingest <health|insert-sample|load-csv|load-xlsx>")
+		fmt.Fprintln(os.Stderr, "usage: bgc-ingest <health|insert-sample|load-csv|load-xlsx|load-comexstat|sync-comexstat|batches|refresh-views|quality-check|migrate>")
 		os.Exit(2) // código 2 -> uso incorreto
 	}
 
@@ -476,6 +655,18 @@ func main() {
 		err = cmdLoadCSV(os.Args[2:])
 	case "load-xlsx":
     	err = cmdLoadXLSX(os.Args[2:])
+	case "load-comexstat":
+		err = cmdLoadComexStat(os.Args[2:])
+	case "sync-comexstat":
+		err = cmdSyncComexStat(os.Args[2:])
+	case "batches":
+		err = cmdBatches(os.Args[2:])
+	case "refresh-views":
+		err = cmdRefreshViews(os.Args[2:])
+	case "quality-check":
+		err = cmdQualityCheck(os.Args[2:])
+	case "migrate":
+		err = cmdMigrate(os.Args[2:])
 	default:
 		fmt.Fprintln(os.Stderr, "unknown command:", os.Args[1])
 		os.Exit(2)
@@ -0,0 +1,338 @@
+// mapping.go — mapeamento declarativo de colunas (--mapping mapping.yaml) para load-csv/load-xlsx
+//
+// Exemplo (planilha de parceiro -> stg.exportacao):
+//
+//	table: stg.exportacao
+//	header: true
+//	decimal: ","          # default de todas as colunas
+//	thousands: "."
+//	columns:
+//	  - target: co_ano
+//	    source: Data Embarque
+//	    type: date
+//	    format: DD/MM/YYYY
+//	    part: year
+//	  - target: co_mes
+//	    source: Data Embarque
+//	    type: date
+//	    format: DD/MM/YYYY
+//	    part: month
+//	  - target: co_pais
+//	    source: País
+//	    lookup: { China: CN, "Estados Unidos": US }
+//	  - target: co_ncm
+//	    index: 3           # posição (0 = 1ª coluna), p/ arquivos sem cabeçalho
+//	    required: true     # vazio rejeita a linha (ver validate.go)
+//	  - target: vl_fob
+//	    source: Valor US$
+//	    type: float
+//	  - target: kg_liquido
+//	    value: 0           # constante
+//
+// Tipos: string (default), int, float, date. Colunas vazias viram NULL, salvo `default`.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mappingFile formato do arquivo --mapping.
+type mappingFile struct {
+	Table      string          `yaml:"table"`       // tabela de destino (schema.tabela)
+	Header     *bool           `yaml:"header"`      // primeira linha é cabeçalho? (default: flag --header)
+	Sep        string          `yaml:"sep"`         // separador do CSV (default: flag --sep)
+	Sheet      string          `yaml:"sheet"`       // planilha do XLSX (default: flag --sheet)
+	Decimal    string          `yaml:"decimal"`     // separador decimal padrão das colunas
+	Thousands  string          `yaml:"thousands"`   // separador de milhar padrão das colunas
+	DateFormat string          `yaml:"date_format"` // formato de data padrão (ex.: DD/MM/YYYY)
+	Columns    []columnMapping `yaml:"columns"`     // colunas de destino, na ordem do COPY
+}
+
+// columnMapping origem e conversão de uma coluna de destino.
+type columnMapping struct {
+	Target    string            `yaml:"target"`    // coluna de destino
+	Source    string            `yaml:"source"`    // nome da coluna no cabeçalho (case-insensitive)
+	Index     *int              `yaml:"index"`     // ou posição da coluna (0 = primeira)
+	Value     interface{}       `yaml:"value"`     // ou valor constante
+	Type      string            `yaml:"type"`      // string | int | float | date
+	Decimal   string            `yaml:"decimal"`   // sobrepõe o default do arquivo
+	Thousands string            `yaml:"thousands"` // sobrepõe o default do arquivo
+	Format    string            `yaml:"format"`    // formato de data (DD/MM/YYYY, YYYY-MM-DD, layout Go...)
+	Part      string            `yaml:"part"`      // date -> year | month | day (vira int)
+	Lookup    map[string]string `yaml:"lookup"`    // de/para aplicado antes da conversão
+	Default   *string           `yaml:"default"`   // valor se vazio (ou sem lookup)
+	Required  bool              `yaml:"required"`  // vazio (sem default) rejeita a linha
+}
+
+// loadMapping lê e valida o arquivo de mapeamento.
+func loadMapping(path string) (*mappingFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("abrir mapping: %w", err)
+	}
+	var m mappingFile
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("mapping %s: %w", path, err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("mapping %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// validate checa o mapeamento sem olhar o arquivo de dados.
+func (m *mappingFile) validate() error {
+	if len(m.Columns) == 0 {
+		return errors.New("columns vazio")
+	}
+	if m.Sep != "" && len([]rune(m.Sep)) != 1 {
+		return errors.New("sep deve ter 1 caractere")
+	}
+	seen := map[string]bool{}
+	for i, c := range m.Columns {
+		if c.Target == "" {
+			return fmt.Errorf("columns[%d]: target obrigatório", i)
+		}
+		if seen[c.Target] {
+			return fmt.Errorf("coluna %s: target repetido", c.Target)
+		}
+		seen[c.Target] = true
+
+		origins := 0
+		if c.Source != "" {
+			origins++
+		}
+		if c.Index != nil {
+			origins++
+			if *c.Index < 0 {
+				return fmt.Errorf("coluna %s: index deve ser >= 0", c.Target)
+			}
+		}
+		if c.Value != nil {
+			origins++
+		}
+		if origins != 1 {
+			return fmt.Errorf("coluna %s: informe exatamente um de source, index ou value", c.Target)
+		}
+
+		switch c.Type {
+		case "", "string", "int", "float":
+			if c.Part != "" {
+				return fmt.Errorf("coluna %s: part só vale para type date", c.Target)
+			}
+		case "date":
+			if c.Format == "" && m.DateFormat == "" {
+				return fmt.Errorf("coluna %s: format (ou date_format) obrigatório para date", c.Target)
+			}
+			switch c.Part {
+			case "", "year", "month", "day":
+			default:
+				return fmt.Errorf("coluna %s: part inválido %q (year, month ou day)", c.Target, c.Part)
+			}
+		default:
+			return fmt.Errorf("coluna %s: type inválido %q", c.Target, c.Type)
+		}
+	}
+	return nil
+}
+
+// targets colunas de destino, na ordem do mapeamento.
+func (m *mappingFile) targets() []string {
+	out := make([]string, len(m.Columns))
+	for i, c := range m.Columns {
+		out[i] = c.Target
+	}
+	return out
+}
+
+// columnConverter converte o registro bruto no valor de uma coluna de destino.
+type columnConverter struct {
+	target    string
+	index     int    // posição no registro (-1 = constante)
+	constant  string // valor de `value`
+	typ       string
+	decimal   string
+	thousands string
+	layout    string // layout Go da data
+	part      string
+	lookup    map[string]string
+	def       *string
+	required  bool
+}
+
+// recordMapper aplica o mapeamento a cada registro (linha) da origem.
+type recordMapper struct {
+	columns []columnConverter
+}
+
+// compile resolve nomes de coluna no cabeçalho (nil = arquivo sem cabeçalho).
+func (m *mappingFile) compile(header []string) (*recordMapper, error) {
+	find := func(name string) int {
+		for i, h := range header {
+			if strings.EqualFold(normalizeHeader(h), strings.TrimSpace(name)) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	rm := &recordMapper{}
+	for _, c := range m.Columns {
+		conv := columnConverter{
+			target:    c.Target,
+			index:     -1,
+			typ:       c.Type,
+			decimal:   firstNonEmpty(c.Decimal, m.Decimal, "."),
+			thousands: firstNonEmpty(c.Thousands, m.Thousands),
+			part:      c.Part,
+			lookup:    c.Lookup,
+			def:       c.Default,
+			required:  c.Required,
+		}
+		if conv.typ == "" {
+			conv.typ = "string"
+		}
+		if conv.typ == "date" {
+			conv.layout = dateLayout(firstNonEmpty(c.Format, m.DateFormat))
+		}
+
+		switch {
+		case c.Value != nil:
+			conv.constant = fmt.Sprint(c.Value)
+		case c.Index != nil:
+			conv.index = *c.Index
+		default:
+			if header == nil {
+				return nil, fmt.Errorf("coluna %s: source %q exige cabeçalho (use index)", c.Target, c.Source)
+			}
+			conv.index = find(c.Source)
+			if conv.index < 0 {
+				return nil, fmt.Errorf("coluna %s: source %q não está no cabeçalho", c.Target, c.Source)
+			}
+		}
+		rm.columns = append(rm.columns, conv)
+	}
+	return rm, nil
+}
+
+// row converte um registro bruto nos valores das colunas de destino.
+func (rm *recordMapper) row(rec []string) ([]any, error) {
+	out := make([]any, len(rm.columns))
+	for i, c := range rm.columns {
+		raw := c.constant
+		if c.index >= 0 {
+			raw = ""
+			if c.index < len(rec) {
+				raw = strings.TrimSpace(rec[c.index])
+			}
+		}
+		v, err := c.convert(raw)
+		if err != nil {
+			err.column = c.target
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// convert aplica lookup, default e tipo a um valor bruto.
+// Erros viram *fieldError (linha rejeitada, ver validate.go).
+func (c *columnConverter) convert(raw string) (any, *fieldError) {
+	if c.lookup != nil && raw != "" {
+		mapped, ok := c.lookup[raw]
+		switch {
+		case ok:
+			raw = mapped
+		case c.def != nil:
+			raw = *c.def
+		default:
+			return nil, &fieldError{rule: ruleLookup, msg: fmt.Sprintf("valor %q sem lookup", raw)}
+		}
+	}
+	if raw == "" {
+		if c.def == nil {
+			if c.required {
+				return nil, &fieldError{rule: ruleRequired, msg: "obrigatória"}
+			}
+			return nil, nil
+		}
+		raw = *c.def
+	}
+
+	switch c.typ {
+	case "int":
+		n, err := strconv.ParseInt(c.number(raw), 10, 64)
+		if err != nil {
+			return nil, &fieldError{rule: ruleParse, msg: fmt.Sprintf("inteiro inválido %q", raw)}
+		}
+		return n, nil
+	case "float":
+		f, err := strconv.ParseFloat(c.number(raw), 64)
+		if err != nil {
+			return nil, &fieldError{rule: ruleParse, msg: fmt.Sprintf("número inválido %q", raw)}
+		}
+		return f, nil
+	case "date":
+		t, err := time.Parse(c.layout, raw)
+		if err != nil {
+			return nil, &fieldError{rule: ruleParse, msg: fmt.Sprintf("data inválida %q (formato %s)", raw, c.layout)}
+		}
+		switch c.part {
+		case "year":
+			return t.Year(), nil
+		case "month":
+			return int(t.Month()), nil
+		case "day":
+			return t.Day(), nil
+		}
+		return t, nil
+	default:
+		return raw, nil
+	}
+}
+
+// number normaliza separadores de milhar/decimal para o formato do strconv.
+func (c *columnConverter) number(raw string) string {
+	if c.thousands != "" {
+		raw = strings.ReplaceAll(raw, c.thousands, "")
+	}
+	if c.decimal != "." {
+		raw = strings.ReplaceAll(raw, c.decimal, ".")
+	}
+	return raw
+}
+
+// dateLayout converte DD/MM/YYYY (e afins) em layout Go; layouts Go passam direto.
+func dateLayout(format string) string {
+	return strings.NewReplacer(
+		"YYYY", "2006", "YY", "06",
+		"MM", "01", "DD", "02",
+		"hh", "15", "mm", "04", "ss", "05",
+	).Replace(format)
+}
+
+// explicitFlags flags passadas na linha de comando (as demais ficam com o valor do mapping).
+func explicitFlags(fs *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
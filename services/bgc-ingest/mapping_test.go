@@ -0,0 +1,182 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColumnConverterConvert(t *testing.T) {
+	def := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		conv     columnConverter
+		raw      string
+		want     any
+		wantRule string
+	}{
+		{name: "string", conv: columnConverter{typ: "string"}, raw: "SP", want: "SP"},
+		{name: "empty is null", conv: columnConverter{typ: "float"}, raw: "", want: nil},
+		{name: "empty uses default", conv: columnConverter{typ: "int", decimal: ".", def: def("0")}, raw: "", want: int64(0)},
+		{name: "empty required", conv: columnConverter{typ: "string", required: true}, raw: "", wantRule: ruleRequired},
+
+		{name: "int", conv: columnConverter{typ: "int", decimal: "."}, raw: "2024", want: int64(2024)},
+		{name: "int thousands", conv: columnConverter{typ: "int", decimal: ",", thousands: "."}, raw: "1.234.567", want: int64(1234567)},
+		{name: "int with decimals", conv: columnConverter{typ: "int", decimal: "."}, raw: "10.5", wantRule: ruleParse},
+
+		{name: "float dot", conv: columnConverter{typ: "float", decimal: "."}, raw: "1234.56", want: 1234.56},
+		{name: "float comma", conv: columnConverter{typ: "float", decimal: ","}, raw: "1234,56", want: 1234.56},
+		{name: "float br thousands", conv: columnConverter{typ: "float", decimal: ",", thousands: "."}, raw: "1.234,56", want: 1234.56},
+		{name: "float us thousands", conv: columnConverter{typ: "float", decimal: ".", thousands: ","}, raw: "1,234.56", want: 1234.56},
+		{name: "float invalid", conv: columnConverter{typ: "float", decimal: "."}, raw: "US$ 10", wantRule: ruleParse},
+
+		{name: "date", conv: columnConverter{typ: "date", layout: "02/01/2006"}, raw: "15/03/2024", want: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{name: "date year", conv: columnConverter{typ: "date", layout: "02/01/2006", part: "year"}, raw: "15/03/2024", want: 2024},
+		{name: "date month", conv: columnConverter{typ: "date", layout: "02/01/2006", part: "month"}, raw: "15/03/2024", want: 3},
+		{name: "date day", conv: columnConverter{typ: "date", layout: "02/01/2006", part: "day"}, raw: "15/03/2024", want: 15},
+		{name: "date invalid", conv: columnConverter{typ: "date", layout: "02/01/2006"}, raw: "2024-03-15", wantRule: ruleParse},
+
+		{name: "lookup", conv: columnConverter{typ: "string", lookup: map[string]string{"China": "CN"}}, raw: "China", want: "CN"},
+		{name: "lookup default", conv: columnConverter{typ: "string", lookup: map[string]string{"China": "CN"}, def: def("ZZ")}, raw: "Marte", want: "ZZ"},
+		{name: "lookup missing", conv: columnConverter{typ: "string", lookup: map[string]string{"China": "CN"}}, raw: "Marte", wantRule: ruleLookup},
+		{name: "lookup then type", conv: columnConverter{typ: "int", decimal: ".", lookup: map[string]string{"jan": "1"}}, raw: "jan", want: int64(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ferr := tt.conv.convert(tt.raw)
+			if tt.wantRule != "" {
+				if ferr == nil || ferr.rule != tt.wantRule {
+					t.Fatalf("convert(%q) = (%v, %v), want rule %q", tt.raw, got, ferr, tt.wantRule)
+				}
+				return
+			}
+			if ferr != nil {
+				t.Fatalf("convert(%q): %v", tt.raw, ferr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convert(%q) = %#v, want %#v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateLayout(t *testing.T) {
+	tests := map[string]string{
+		"DD/MM/YYYY":          "02/01/2006",
+		"YYYY-MM-DD":          "2006-01-02",
+		"DD/MM/YY":            "02/01/06",
+		"YYYY-MM-DD hh:mm:ss": "2006-01-02 15:04:05",
+		"2006-01-02":          "2006-01-02", // layout Go passa direto
+	}
+	for format, want := range tests {
+		if got := dateLayout(format); got != want {
+			t.Errorf("dateLayout(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestMappingValidate(t *testing.T) {
+	idx := func(i int) *int { return &i }
+
+	tests := []struct {
+		name    string
+		m       mappingFile
+		wantErr string
+	}{
+		{
+			name: "valid",
+			m: mappingFile{DateFormat: "DD/MM/YYYY", Columns: []columnMapping{
+				{Target: "co_ano", Source: "Data", Type: "date", Part: "year"},
+				{Target: "co_ncm", Index: idx(3)},
+				{Target: "kg_liquido", Value: 0},
+			}},
+		},
+		{name: "no columns", m: mappingFile{}, wantErr: "columns vazio"},
+		{name: "long sep", m: mappingFile{Sep: ";;", Columns: []columnMapping{{Target: "a", Source: "A"}}}, wantErr: "sep"},
+		{name: "no target", m: mappingFile{Columns: []columnMapping{{Source: "A"}}}, wantErr: "target obrigatório"},
+		{
+			name:    "repeated target",
+			m:       mappingFile{Columns: []columnMapping{{Target: "a", Source: "A"}, {Target: "a", Source: "B"}}},
+			wantErr: "target repetido",
+		},
+		{name: "no origin", m: mappingFile{Columns: []columnMapping{{Target: "a"}}}, wantErr: "exatamente um"},
+		{name: "two origins", m: mappingFile{Columns: []columnMapping{{Target: "a", Source: "A", Index: idx(0)}}}, wantErr: "exatamente um"},
+		{name: "negative index", m: mappingFile{Columns: []columnMapping{{Target: "a", Index: idx(-1)}}}, wantErr: "index"},
+		{name: "invalid type", m: mappingFile{Columns: []columnMapping{{Target: "a", Source: "A", Type: "bool"}}}, wantErr: "type inválido"},
+		{name: "part without date", m: mappingFile{Columns: []columnMapping{{Target: "a", Source: "A", Part: "year"}}}, wantErr: "part só vale"},
+		{name: "date without format", m: mappingFile{Columns: []columnMapping{{Target: "a", Source: "A", Type: "date"}}}, wantErr: "format"},
+		{
+			name:    "invalid part",
+			m:       mappingFile{Columns: []columnMapping{{Target: "a", Source: "A", Type: "date", Format: "YYYY", Part: "week"}}},
+			wantErr: "part inválido",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.m.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecordMapperRow(t *testing.T) {
+	m, err := loadMapping(writeFile(t, "mapping.yaml", `
+table: stg.exportacao
+decimal: ","
+thousands: "."
+date_format: DD/MM/YYYY
+columns:
+  - target: co_ano
+    source: Data Embarque
+    type: date
+    part: year
+  - target: co_pais
+    source: país
+    lookup: { China: CN }
+  - target: vl_fob
+    source: Valor
+    type: float
+  - target: fonte
+    value: parceiro
+`))
+	if err != nil {
+		t.Fatalf("loadMapping: %v", err)
+	}
+
+	rm, err := m.compile([]string{"\ufeffData Embarque", "País", "Valor"})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	got, err := rm.row([]string{"15/03/2024", " China ", "1.234,50"})
+	if err != nil {
+		t.Fatalf("row: %v", err)
+	}
+	want := []any{2024, "CN", 1234.5, "parceiro"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("row = %#v, want %#v", got, want)
+	}
+
+	// Erro de conversão leva o nome da coluna de destino
+	_, err = rm.row([]string{"15/03/2024", "Marte", "1"})
+	fe, ok := err.(*fieldError)
+	if !ok || fe.column != "co_pais" || fe.rule != ruleLookup {
+		t.Errorf("row com país sem lookup: err = %v", err)
+	}
+
+	if _, err := m.compile([]string{"Data Embarque", "Valor"}); err == nil {
+		t.Error("compile sem a coluna País: esperava erro")
+	}
+	if _, err := m.compile(nil); err == nil {
+		t.Error("compile sem cabeçalho com source: esperava erro")
+	}
+}
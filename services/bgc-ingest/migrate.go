@@ -0,0 +1,646 @@
+// migrate.go — aplicação versionada das migrations de db/migrations
+//
+// Substitui os jobs bgc-migrate-NNNN (psql por arquivo). Cada migration aplicada fica
+// em public.schema_migrations (versão, nome, sha256 do arquivo), então:
+//   - só as pendentes rodam, em ordem de versão (lacunas como 0004 -> 0010 são normais);
+//   - cada migration roda em uma transação própria, com o registro na mesma transação;
+//   - um arquivo alterado depois de aplicado (checksum diferente) bloqueia up/down;
+//   - um pg_advisory_lock impede dois migrate ao mesmo tempo.
+//
+// Arquivos: NNNN_nome.sql (up) e, opcional, NNNN_nome.down.sql (down).
+//
+// Comandos (--dir, default $MIGRATIONS_DIR ou db/migrations):
+//   bgc-ingest migrate status
+//   bgc-ingest migrate up [--to 17] [--dry-run] [--allow-destructive]
+//   bgc-ingest migrate down [--steps 1 | --to 15] [--dry-run]
+//   bgc-ingest migrate baseline --version 16 [--dry-run]
+//
+// Bancos criados pelos jobs antigos já têm as migrations aplicadas: rode `baseline`
+// uma vez (marca até a versão informada como aplicada, sem executar). `up` recusa
+// um banco com stg.exportacao e sem histórico, e recusa migrations destrutivas sem
+// --allow-destructive: DROP TABLE/SCHEMA/COLUMN, TRUNCATE (ex.: 0011), DELETE/UPDATE sem
+// WHERE ou o marcador `-- migrate:destructive` (ex.: 0020, que funde e apaga duplicatas).
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrateLockKey chave do pg_advisory_lock do migrate.
+const migrateLockKey int64 = 0x6267632d6d6967 // "bgc-mig"
+
+// Status de uma migration em `migrate status`
+const (
+	migApplied    = "applied"
+	migPending    = "pending"
+	migModified   = "modified"     // aplicada, mas o arquivo mudou depois
+	migMissing    = "missing"      // aplicada, mas o arquivo não existe mais
+	migOutOfOrder = "out_of_order" // pendente com versão menor que a última aplicada
+)
+
+// migration arquivo(s) de uma versão.
+type migration struct {
+	version  int
+	name     string
+	upPath   string
+	downPath string // vazio = sem down
+	checksum string // sha256 do arquivo up
+}
+
+// appliedMigration linha de public.schema_migrations.
+type appliedMigration struct {
+	version   int
+	name      string
+	checksum  string
+	appliedAt time.Time
+	baseline  bool
+}
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+?)(\.down)?\.sql$`)
+
+// destructiveSQL comandos que apagam dados (fora de comentários).
+var destructiveSQL = regexp.MustCompile(`(?i)\b(DROP\s+(TABLE|SCHEMA|COLUMN)|TRUNCATE)\b`)
+
+// Comandos que apagam/reescrevem a tabela inteira quando não têm WHERE.
+var (
+	deleteSQL = regexp.MustCompile(`(?i)\bDELETE\s+FROM\b`)
+	updateSQL = regexp.MustCompile(`(?i)\bUPDATE\s+(ONLY\s+)?[\w."]+(\s+(AS\s+)?\w+)?\s+SET\b`)
+	whereSQL  = regexp.MustCompile(`(?i)\bWHERE\b`)
+)
+
+// destructiveMarker declara no arquivo uma migration destrutiva que a análise não detecta
+// (ex.: DELETE com WHERE que funde linhas).
+var destructiveMarker = regexp.MustCompile(`(?m)^\s*--\s*migrate:destructive\b`)
+
+// loadMigrations lê o diretório e ordena por versão.
+func loadMigrations(dir string) ([]*migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrations: %w", err)
+	}
+	byVersion := map[int]*migration{}
+	for _, e := range entries {
+		m := migrationFile.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[1])
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &migration{version: version}
+			byVersion[version] = mig
+		}
+		path := filepath.Join(dir, e.Name())
+		if m[3] != "" {
+			if mig.downPath != "" {
+				return nil, fmt.Errorf("migrations: dois arquivos down para a versão %d", version)
+			}
+			mig.downPath = path
+			continue
+		}
+		if mig.upPath != "" {
+			return nil, fmt.Errorf("migrations: versão %d duplicada (%s, %s)", version, filepath.Base(mig.upPath), e.Name())
+		}
+		mig.upPath, mig.name = path, m[2]
+		if mig.checksum, err = fileChecksum(path); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]*migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.upPath == "" {
+			return nil, fmt.Errorf("migrations: versão %d só tem o arquivo down", mig.version)
+		}
+		out = append(out, mig)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].version < out[j].version })
+	return out, nil
+}
+
+func fileChecksum(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// readSQL conteúdo do arquivo sem o BOM (alguns arquivos foram salvos no Windows).
+func readSQL(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))), nil
+}
+
+// isDestructive procura DROP/TRUNCATE e DELETE/UPDATE sem WHERE fora de comentários e
+// literais, ou o marcador -- migrate:destructive.
+func isDestructive(sql string) bool {
+	if destructiveMarker.MatchString(sql) {
+		return true
+	}
+	code := stripSQLComments(sql)
+	if destructiveSQL.MatchString(code) {
+		return true
+	}
+	for _, stmt := range strings.Split(code, ";") {
+		for _, re := range []*regexp.Regexp{deleteSQL, updateSQL} {
+			if loc := re.FindStringIndex(stmt); loc != nil && !whereSQL.MatchString(stmt[loc[1]:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stripSQLComments remove comentários de linha (--) e de bloco (/* */, aninháveis no
+// Postgres) e esvazia literais entre aspas simples, mantendo as quebras de linha.
+func stripSQLComments(sql string) string {
+	var b strings.Builder
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			if i < len(sql) {
+				b.WriteByte('\n')
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			depth := 0
+			for ; i < len(sql); i++ {
+				if strings.HasPrefix(sql[i:], "/*") {
+					depth++
+					i++
+				} else if strings.HasPrefix(sql[i:], "*/") {
+					depth--
+					i++
+					if depth == 0 {
+						break
+					}
+				} else if sql[i] == '\n' {
+					b.WriteByte('\n')
+				}
+			}
+			b.WriteByte(' ')
+		case sql[i] == '\'':
+			// '' dentro do literal é aspas escapada: fecha e reabre, o conteúdo some igual
+			for i++; i < len(sql) && sql[i] != '\''; i++ {
+				if sql[i] == '\n' {
+					b.WriteByte('\n')
+				}
+			}
+			b.WriteString("''")
+		default:
+			b.WriteByte(sql[i])
+		}
+	}
+	return b.String()
+}
+
+// ensureMigrationsTable cria public.schema_migrations (primeira execução).
+func ensureMigrationsTable(ctx context.Context, conn *pgxpool.Conn) error {
+	_, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS public.schema_migrations (
+		  version INTEGER PRIMARY KEY,
+		  name TEXT NOT NULL,
+		  checksum TEXT NOT NULL,
+		  baseline BOOLEAN NOT NULL DEFAULT false,
+		  duration_ms BIGINT NOT NULL DEFAULT 0,
+		  applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return fmt.Errorf("criar schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// loadApplied migrations registradas (vazio se a tabela ainda não existe).
+func loadApplied(ctx context.Context, db interface {
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+	QueryRow(context.Context, string, ...any) pgx.Row
+}) (map[int]appliedMigration, error) {
+	var exists bool
+	if err := db.QueryRow(ctx, `SELECT to_regclass('public.schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	applied := map[int]appliedMigration{}
+	if !exists {
+		return applied, nil
+	}
+	rows, err := db.Query(ctx, `SELECT version, name, checksum, applied_at, baseline FROM public.schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("ler schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.version, &a.name, &a.checksum, &a.appliedAt, &a.baseline); err != nil {
+			return nil, err
+		}
+		applied[a.version] = a
+	}
+	return applied, rows.Err()
+}
+
+// migrationStatus situação de cada versão (arquivos + registradas sem arquivo).
+type migrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	HasDown   bool       `json:"has_down"`
+	Baseline  bool       `json:"baseline,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+func statusOf(migs []*migration, applied map[int]appliedMigration) []migrationStatus {
+	lastApplied := 0
+	for v := range applied {
+		lastApplied = max(lastApplied, v)
+	}
+	var out []migrationStatus
+	seen := map[int]bool{}
+	for _, m := range migs {
+		seen[m.version] = true
+		st := migrationStatus{Version: m.version, Name: m.name, HasDown: m.downPath != ""}
+		a, ok := applied[m.version]
+		switch {
+		case !ok && m.version < lastApplied:
+			st.Status = migOutOfOrder
+		case !ok:
+			st.Status = migPending
+		case a.checksum != m.checksum:
+			st.Status = migModified
+		default:
+			st.Status = migApplied
+		}
+		if ok {
+			st.AppliedAt, st.Baseline = &a.appliedAt, a.baseline
+		}
+		out = append(out, st)
+	}
+	for v, a := range applied {
+		if !seen[v] {
+			out = append(out, migrationStatus{Version: v, Name: a.name, Status: migMissing, AppliedAt: &a.appliedAt, Baseline: a.baseline})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out
+}
+
+// checkMigrations recusa up/down com arquivos alterados ou pendentes fora de ordem.
+func checkMigrations(statuses []migrationStatus) error {
+	var modified, outOfOrder []string
+	for _, st := range statuses {
+		switch st.Status {
+		case migModified:
+			modified = append(modified, fmt.Sprintf("%04d_%s", st.Version, st.Name))
+		case migOutOfOrder:
+			outOfOrder = append(outOfOrder, fmt.Sprintf("%04d_%s", st.Version, st.Name))
+		}
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("migrations alteradas depois de aplicadas: %s (crie uma nova migration em vez de editar)", strings.Join(modified, ", "))
+	}
+	if len(outOfOrder) > 0 {
+		return fmt.Errorf("migrations pendentes anteriores à última aplicada: %s (renumere acima da última versão)", strings.Join(outOfOrder, ", "))
+	}
+	return nil
+}
+
+// lockMigrations segura o advisory lock na conexão (liberado no unlock).
+func lockMigrations(ctx context.Context, conn *pgxpool.Conn) (func(), error) {
+	var ok bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, migrateLockKey).Scan(&ok); err != nil {
+		return nil, fmt.Errorf("advisory lock: %w", err)
+	}
+	if !ok {
+		return nil, errors.New("outro migrate está em execução (advisory lock ocupado)")
+	}
+	return func() {
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrateLockKey)
+	}, nil
+}
+
+// runMigration executa o SQL e atualiza schema_migrations na mesma transação.
+func runMigration(ctx context.Context, conn *pgxpool.Conn, m *migration, down bool) (time.Duration, error) {
+	path := m.upPath
+	if down {
+		path = m.downPath
+	}
+	sql, err := readSQL(path)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Sem argumentos o pgx usa o protocolo simples: o arquivo pode ter vários comandos
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return 0, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	elapsed := time.Since(start)
+	if down {
+		_, err = tx.Exec(ctx, `DELETE FROM public.schema_migrations WHERE version = $1`, m.version)
+	} else {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO public.schema_migrations (version, name, checksum, duration_ms)
+			VALUES ($1, $2, $3, $4)`, m.version, m.name, m.checksum, elapsed.Milliseconds())
+	}
+	if err != nil {
+		return 0, fmt.Errorf("registrar migration %d: %w", m.version, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return elapsed, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Comando: migrate
+////////////////////////////////////////////////////////////////////////////////
+
+// migrateOpts flags comuns dos subcomandos.
+type migrateOpts struct {
+	dir              string
+	to               int
+	steps            int
+	version          int
+	dryRun           bool
+	allowDestructive bool
+}
+
+// cmdMigrate roteia `migrate status|up|down|baseline`.
+func cmdMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: bgc-ingest migrate <status|up|down|baseline> [--dir db/migrations]")
+	}
+	sub := args[0]
+	defaultDir := os.Getenv("MIGRATIONS_DIR")
+	if defaultDir == "" {
+		defaultDir = "db/migrations"
+	}
+
+	fs := flag.NewFlagSet("migrate "+sub, flag.ContinueOnError)
+	var opts migrateOpts
+	fs.StringVar(&opts.dir, "dir", defaultDir, "diretório das migrations")
+	switch sub {
+	case "status":
+	case "up":
+		fs.IntVar(&opts.to, "to", 0, "aplica até esta versão (default: todas)")
+		fs.BoolVar(&opts.dryRun, "dry-run", false, "só lista o que seria aplicado")
+		fs.BoolVar(&opts.allowDestructive, "allow-destructive", false, "permite migrations destrutivas (DROP/TRUNCATE, DELETE/UPDATE sem WHERE, -- migrate:destructive)")
+	case "down":
+		fs.IntVar(&opts.steps, "steps", 1, "quantidade de migrations revertidas")
+		fs.IntVar(&opts.to, "to", -1, "reverte até ficar nesta versão (tem precedência sobre --steps)")
+		fs.BoolVar(&opts.dryRun, "dry-run", false, "só lista o que seria revertido")
+	case "baseline":
+		fs.IntVar(&opts.version, "version", 0, "marca como aplicadas as migrations até esta versão (sem executar)")
+		fs.BoolVar(&opts.dryRun, "dry-run", false, "só lista o que seria marcado")
+	default:
+		return fmt.Errorf("migrate: subcomando desconhecido %q", sub)
+	}
+	fs.SetOutput(new(nopWriter))
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	migs, err := loadMigrations(opts.dir)
+	if err != nil {
+		return err
+	}
+
+	pool, err := connect()
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	if sub == "status" {
+		applied, err := loadApplied(ctx, pool)
+		if err != nil {
+			return err
+		}
+		return printMigrationStatus(migs, applied)
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire: %w", err)
+	}
+	defer conn.Release()
+	unlock, err := lockMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if !opts.dryRun {
+		if err := ensureMigrationsTable(ctx, conn); err != nil {
+			return err
+		}
+	}
+	applied, err := loadApplied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "up":
+		return migrateUp(ctx, conn, migs, applied, opts)
+	case "down":
+		return migrateDown(ctx, conn, migs, applied, opts)
+	default:
+		return migrateBaseline(ctx, conn, migs, applied, opts)
+	}
+}
+
+// printMigrationStatus um JSON por versão e um resumo.
+func printMigrationStatus(migs []*migration, applied map[int]appliedMigration) error {
+	statuses := statusOf(migs, applied)
+	counts := map[string]int{}
+	for _, st := range statuses {
+		printJSON(st)
+		counts[st.Status]++
+	}
+	summary := map[string]any{"summary": true}
+	for status, n := range counts {
+		summary[status] = n
+	}
+	printJSON(summary)
+	return nil
+}
+
+func migrateUp(ctx context.Context, conn *pgxpool.Conn, migs []*migration, applied map[int]appliedMigration, opts migrateOpts) error {
+	statuses := statusOf(migs, applied)
+	if err := checkMigrations(statuses); err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		// Banco montado pelos jobs antigos: rodar tudo de novo executaria 0011 (DROP TABLE)
+		var legacy bool
+		if err := conn.QueryRow(ctx, `SELECT to_regclass('stg.exportacao') IS NOT NULL`).Scan(&legacy); err != nil {
+			return err
+		}
+		if legacy {
+			return errors.New("banco já tem stg.exportacao e nenhuma migration registrada: rode `migrate baseline --version N` com a última versão já aplicada")
+		}
+	}
+
+	var pending []*migration
+	for _, m := range migs {
+		if _, ok := applied[m.version]; !ok && (opts.to == 0 || m.version <= opts.to) {
+			pending = append(pending, m)
+		}
+	}
+
+	// Destrutivas são verificadas antes de aplicar qualquer uma
+	destructive := map[int]bool{}
+	var blocked []string
+	for _, m := range pending {
+		sql, err := readSQL(m.upPath)
+		if err != nil {
+			return err
+		}
+		if isDestructive(sql) {
+			destructive[m.version] = true
+			blocked = append(blocked, fmt.Sprintf("%04d_%s", m.version, m.name))
+		}
+	}
+	if len(blocked) > 0 && !opts.allowDestructive && !opts.dryRun {
+		return fmt.Errorf("migrations destrutivas pendentes: %s (revise e use --allow-destructive)", strings.Join(blocked, ", "))
+	}
+
+	start := time.Now()
+	for _, m := range pending {
+		out := map[string]any{"version": m.version, "name": m.name, "destructive": destructive[m.version]}
+		if opts.dryRun {
+			out["action"] = "would_apply"
+			printJSON(out)
+			continue
+		}
+		elapsed, err := runMigration(ctx, conn, m, false)
+		if err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", m.version, m.name, err)
+		}
+		out["action"], out["duration_ms"] = "applied", elapsed.Milliseconds()
+		printJSON(out)
+	}
+	printJSON(map[string]any{
+		"summary":     true,
+		"pending":     len(pending),
+		"dry_run":     opts.dryRun,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+	return nil
+}
+
+func migrateDown(ctx context.Context, conn *pgxpool.Conn, migs []*migration, applied map[int]appliedMigration, opts migrateOpts) error {
+	if err := checkMigrations(statusOf(migs, applied)); err != nil {
+		return err
+	}
+	byVersion := map[int]*migration{}
+	for _, m := range migs {
+		byVersion[m.version] = m
+	}
+	versions := make([]int, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	// Da mais recente para a mais antiga
+	var targets []*migration
+	for _, v := range versions {
+		if opts.to >= 0 {
+			if v <= opts.to {
+				break
+			}
+		} else if len(targets) >= opts.steps {
+			break
+		}
+		m := byVersion[v]
+		switch {
+		case m == nil:
+			return fmt.Errorf("migration %d aplicada, mas o arquivo não existe em %s", v, opts.dir)
+		case m.downPath == "":
+			return fmt.Errorf("migration %04d_%s não tem arquivo .down.sql", v, m.name)
+		}
+		targets = append(targets, m)
+	}
+
+	start := time.Now()
+	for _, m := range targets {
+		out := map[string]any{"version": m.version, "name": m.name}
+		if opts.dryRun {
+			out["action"] = "would_revert"
+			printJSON(out)
+			continue
+		}
+		elapsed, err := runMigration(ctx, conn, m, true)
+		if err != nil {
+			return fmt.Errorf("down %04d_%s: %w", m.version, m.name, err)
+		}
+		out["action"], out["duration_ms"] = "reverted", elapsed.Milliseconds()
+		printJSON(out)
+	}
+	printJSON(map[string]any{
+		"summary":     true,
+		"reverted":    len(targets),
+		"dry_run":     opts.dryRun,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+	return nil
+}
+
+func migrateBaseline(ctx context.Context, conn *pgxpool.Conn, migs []*migration, applied map[int]appliedMigration, opts migrateOpts) error {
+	if opts.version <= 0 {
+		return errors.New("obrigatório: --version (última migration já aplicada no banco)")
+	}
+	marked := 0
+	for _, m := range migs {
+		if m.version > opts.version {
+			break
+		}
+		if _, ok := applied[m.version]; ok {
+			continue
+		}
+		out := map[string]any{"version": m.version, "name": m.name, "action": "baseline"}
+		if opts.dryRun {
+			out["action"] = "would_baseline"
+		} else if _, err := conn.Exec(ctx, `
+			INSERT INTO public.schema_migrations (version, name, checksum, baseline)
+			VALUES ($1, $2, $3, true)`, m.version, m.name, m.checksum); err != nil {
+			return fmt.Errorf("baseline %d: %w", m.version, err)
+		}
+		printJSON(out)
+		marked++
+	}
+	printJSON(map[string]any{"summary": true, "baseline": marked, "dry_run": opts.dryRun})
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// migrationsDir grava os arquivos (nome -> conteúdo) em um diretório temporário.
+func migrationsDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadMigrations(t *testing.T) {
+	dir := migrationsDir(t, map[string]string{
+		"0010_simulator.sql":     "CREATE TABLE a (id int);",
+		"0002_indexes.sql":       "CREATE INDEX i ON a(id);",
+		"0002_indexes.down.sql":  "DROP INDEX i;",
+		"0011_comexstat.sql":     "DROP TABLE a;",
+		"README.md":              "ignorado",
+		"0003_notes.sql.example": "ignorado",
+	})
+
+	migs, err := loadMigrations(dir)
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	var got []string
+	for _, m := range migs {
+		got = append(got, m.name)
+		if len(m.checksum) != 64 {
+			t.Errorf("%s: checksum %q", m.name, m.checksum)
+		}
+	}
+	if want := []string{"indexes", "simulator", "comexstat"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("migrations = %v, want %v", got, want)
+	}
+	if migs[0].version != 2 || filepath.Base(migs[0].downPath) != "0002_indexes.down.sql" || migs[1].downPath != "" {
+		t.Errorf("versão/down: %+v %+v", migs[0], migs[1])
+	}
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{name: "duplicate version", files: map[string]string{"0001_a.sql": "", "0001_b.sql": ""}, wantErr: "duplicada"},
+		{name: "down only", files: map[string]string{"0001_a.down.sql": ""}, wantErr: "só tem o arquivo down"},
+		{name: "two downs", files: map[string]string{"0001_a.sql": "", "0001_a.down.sql": "", "0001_b.down.sql": ""}, wantErr: "dois arquivos down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadMigrations(migrationsDir(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := loadMigrations(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("diretório inexistente: esperava erro")
+	}
+}
+
+// As migrations do repositório carregam e, a partir de 0012 (primeira com o migrate), todas têm down.
+func TestRepositoryMigrations(t *testing.T) {
+	migs, err := loadMigrations("../../db/migrations")
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	for _, m := range migs {
+		if m.version >= 12 && m.downPath == "" {
+			t.Errorf("%04d_%s sem arquivo .down.sql", m.version, m.name)
+		}
+	}
+
+	// Só 0011 (recria stg.exportacao) e 0020 (funde duplicatas) exigem --allow-destructive
+	var destructive []int
+	for _, m := range migs {
+		sql, err := readSQL(m.upPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isDestructive(sql) {
+			destructive = append(destructive, m.version)
+		}
+	}
+	if fmt.Sprint(destructive) != "[11 20]" {
+		t.Errorf("migrations destrutivas = %v, want [11 20]", destructive)
+	}
+}
+
+func TestIsDestructive(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want bool
+	}{
+		{name: "create", sql: "CREATE TABLE stg.a (id int);", want: false},
+		{name: "drop table", sql: "DROP TABLE IF EXISTS stg.exportacao;", want: true},
+		{name: "lower case", sql: "drop   schema old cascade;", want: true},
+		{name: "drop column", sql: "ALTER TABLE a\n  DROP COLUMN b;", want: true},
+		{name: "truncate", sql: "TRUNCATE stg.a;", want: true},
+		{name: "drop index", sql: "DROP INDEX IF EXISTS i;", want: false},
+		{name: "drop view", sql: "DROP VIEW v;", want: false},
+		{name: "line comment", sql: "-- DROP TABLE a (feito em 0011)\nCREATE TABLE b (id int);", want: false},
+		{name: "trailing comment", sql: "CREATE TABLE b (id int); -- não TRUNCATE", want: false},
+		{name: "identifier", sql: "CREATE TABLE drop_tables_log (id int);", want: false},
+		{name: "delete without where", sql: "DELETE FROM stg.exportacao;", want: true},
+		{name: "delete with where", sql: "DELETE FROM stg.exportacao\nWHERE co_ano < 2020;", want: false},
+		{name: "delete where in next statement", sql: "DELETE FROM a; SELECT 1 FROM b WHERE x;", want: true},
+		{name: "cte where does not count", sql: "WITH d AS (SELECT id FROM a WHERE x) DELETE FROM a;", want: true},
+		{name: "update without where", sql: "UPDATE stg.exportacao SET vl_fob = 0;", want: true},
+		{name: "update with alias and where", sql: "UPDATE stg.exportacao e SET vl_fob = 0 FROM d WHERE e.id = d.id;", want: false},
+		{name: "on delete cascade", sql: "CREATE TABLE a (b int REFERENCES b ON DELETE CASCADE ON UPDATE CASCADE);", want: false},
+		{name: "block comment hides drop", sql: "/* DROP TABLE a */ CREATE TABLE b (id int);", want: false},
+		{name: "nested block comment", sql: "/* a /* DROP TABLE x */ TRUNCATE y */ CREATE TABLE b (id int);", want: false},
+		{name: "block comment fakes where", sql: "DELETE FROM a /* WHERE id = 1 */;", want: true},
+		{name: "block comment as whitespace", sql: "DROP/**/TABLE a;", want: true},
+		{name: "line comment inside block", sql: "/* -- */ TRUNCATE a;", want: true},
+		{name: "string literal", sql: "COMMENT ON TABLE a IS 'DROP TABLE; DELETE FROM a';", want: false},
+		{name: "where in string literal", sql: "DELETE FROM a RETURNING 'WHERE';", want: true},
+		{name: "marker", sql: "-- migrate:destructive\nDELETE FROM a USING b WHERE a.id < b.id;", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDestructive(tt.sql); got != tt.want {
+				t.Errorf("isDestructive(%q) = %v, want %v", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSQL(t *testing.T) {
+	path := writeFile(t, "0001_a.sql", "\ufeffCREATE TABLE a (id int);")
+	got, err := readSQL(path)
+	if err != nil || got != "CREATE TABLE a (id int);" {
+		t.Errorf("readSQL = (%q, %v)", got, err)
+	}
+}
+
+func TestStatusOf(t *testing.T) {
+	at := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	migs := []*migration{
+		{version: 1, name: "init", checksum: "a"},
+		{version: 2, name: "indexes", checksum: "b", downPath: "0002_indexes.down.sql"},
+		{version: 3, name: "late", checksum: "c"},
+		{version: 4, name: "edited", checksum: "d2"},
+		{version: 6, name: "next", checksum: "f"},
+	}
+	applied := map[int]appliedMigration{
+		1: {version: 1, name: "init", checksum: "a", appliedAt: at, baseline: true},
+		2: {version: 2, name: "indexes", checksum: "b", appliedAt: at},
+		4: {version: 4, name: "edited", checksum: "d1", appliedAt: at},
+		5: {version: 5, name: "removed", checksum: "e", appliedAt: at},
+	}
+
+	got := statusOf(migs, applied)
+	want := []migrationStatus{
+		{Version: 1, Name: "init", Status: migApplied, Baseline: true, AppliedAt: &at},
+		{Version: 2, Name: "indexes", Status: migApplied, HasDown: true, AppliedAt: &at},
+		{Version: 3, Name: "late", Status: migOutOfOrder},
+		{Version: 4, Name: "edited", Status: migModified, AppliedAt: &at},
+		{Version: 5, Name: "removed", Status: migMissing, AppliedAt: &at},
+		{Version: 6, Name: "next", Status: migPending},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("statusOf:\n got %+v\nwant %+v", got, want)
+	}
+
+	// Banco vazio: tudo pendente
+	for _, st := range statusOf(migs, map[int]appliedMigration{}) {
+		if st.Status != migPending {
+			t.Errorf("banco vazio: %d = %s", st.Version, st.Status)
+		}
+	}
+}
+
+func TestCheckMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []migrationStatus
+		wantErr  string
+	}{
+		{
+			name: "clean",
+			statuses: []migrationStatus{
+				{Version: 1, Name: "init", Status: migApplied},
+				{Version: 2, Name: "gone", Status: migMissing},
+				{Version: 3, Name: "next", Status: migPending},
+			},
+		},
+		{
+			name: "modified",
+			statuses: []migrationStatus{
+				{Version: 1, Name: "init", Status: migModified},
+				{Version: 3, Name: "late", Status: migOutOfOrder},
+			},
+			wantErr: "alteradas depois de aplicadas: 0001_init",
+		},
+		{
+			name: "out of order",
+			statuses: []migrationStatus{
+				{Version: 3, Name: "late", Status: migOutOfOrder},
+				{Version: 4, Name: "later", Status: migOutOfOrder},
+			},
+			wantErr: "anteriores à última aplicada: 0003_late, 0004_later",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMigrations(tt.statuses)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkMigrations: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCmdMigrateUsage(t *testing.T) {
+	if err := cmdMigrate(nil); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Errorf("sem subcomando: err = %v", err)
+	}
+	if err := cmdMigrate([]string{"redo"}); err == nil || !strings.Contains(err.Error(), "desconhecido") {
+		t.Errorf("subcomando desconhecido: err = %v", err)
+	}
+}
@@ -0,0 +1,967 @@
+// parquet.go — leitor Parquet mínimo (exports do data lake)
+//
+// Suporta o que os exports tabulares usam: schema plano (colunas REQUIRED/OPTIONAL,
+// sem aninhamento), páginas de dados v1 e v2, encodings PLAIN e dicionário
+// (PLAIN_DICTIONARY/RLE_DICTIONARY) e compressão SNAPPY, GZIP e ZSTD.
+// Outros casos (colunas aninhadas/repetidas, encodings DELTA_*, LZ4/BROTLI) falham
+// com erro explícito.
+//
+// Os valores viram texto para o mesmo pipeline do CSV: inteiros e floats com ponto
+// decimal, DECIMAL com a escala aplicada, DATE como YYYY-MM-DD e TIMESTAMP em
+// RFC 3339 (UTC); NULL vira vazio.
+//
+// Memória: uma página por coluna; as páginas de um row group são lidas à medida
+// que as linhas são consumidas. Tamanhos e contagens do footer e dos page headers
+// são validados contra o arquivo antes de qualquer alocação.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Tipos físicos do Parquet
+const (
+	pqBoolean = iota
+	pqInt32
+	pqInt64
+	pqInt96
+	pqFloat
+	pqDouble
+	pqByteArray
+	pqFixedLenByteArray
+)
+
+// pqColumn coluna do schema (plano) e como formatar seus valores.
+type pqColumn struct {
+	name     string
+	physical int
+	length   int  // FIXED_LEN_BYTE_ARRAY
+	optional bool // pode ser NULL (definition level 1)
+	kind     string
+	scale    int    // DECIMAL
+	unit     string // TIMESTAMP: millis | micros | nanos
+}
+
+// Tipos lógicos tratados na formatação
+const (
+	pqKindDecimal   = "decimal"
+	pqKindDate      = "date"
+	pqKindTimestamp = "timestamp"
+	pqKindUnsigned  = "unsigned"
+)
+
+// Limites de sanidade para tamanhos lidos do arquivo: valores acima disso
+// indicam arquivo corrompido e falham antes de qualquer alocação.
+const (
+	pqMaxPageSize   = 256 << 20 // página descomprimida
+	pqMaxPageHeader = 16 << 20  // page header (inclui estatísticas)
+	pqMaxPageValues = 1 << 24   // valores numa página
+	pqMaxScale      = 1000      // escala DECIMAL (limite do NUMERIC do Postgres)
+)
+
+type parquetSource struct {
+	f       *os.File
+	cols    []pqColumn
+	groups  []tstruct // row groups do footer
+	numRows int64
+	dataEnd int64 // início do footer: column chunks terminam antes disso
+
+	group    int        // próximo row group
+	chunks   []*pqChunk // cursores das colunas do row group atual
+	rows     int64      // linhas do row group atual
+	pos      int64      // próxima linha no row group
+	rowsRead int64      // linhas lidas no arquivo
+	rec      []string
+}
+
+// pqChunk cursor de um column chunk: lê e decodifica uma página por vez.
+type pqChunk struct {
+	f     *os.File
+	col   pqColumn
+	codec int64
+	off   int64 // próxima página no arquivo
+	end   int64 // fim do column chunk
+	left  int64 // valores ainda não decodificados
+	dict  []any
+	page  []any // valores da página atual (NULL = nil)
+	next  int   // próximo valor da página
+}
+
+// openParquet lê o footer (FileMetaData) e o schema.
+func openParquet(path string) (*parquetSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newParquetSource(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("parquet: %w", err)
+	}
+	return s, nil
+}
+
+func newParquetSource(f *os.File) (*parquetSource, error) {
+	meta, dataEnd, err := readParquetFooter(f)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := parquetColumns(meta.list(2))
+	if err != nil {
+		return nil, err
+	}
+	s := &parquetSource{f: f, cols: cols, numRows: meta.i64(3), dataEnd: dataEnd}
+	if s.numRows < 0 {
+		return nil, fmt.Errorf("num_rows %d inválido", s.numRows)
+	}
+	for i, g := range meta.list(4) {
+		rg, ok := g.(tstruct)
+		if !ok {
+			return nil, fmt.Errorf("row group %d inválido", i)
+		}
+		s.groups = append(s.groups, rg)
+	}
+	return s, nil
+}
+
+// readParquetFooter: arquivo = "PAR1" ... FileMetaData | len(4 bytes LE) | "PAR1".
+// Devolve também o offset onde o footer começa.
+func readParquetFooter(f *os.File) (tstruct, int64, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if st.Size() < 12 {
+		return nil, 0, errors.New("arquivo curto demais")
+	}
+	tail := make([]byte, 8)
+	if _, err := f.ReadAt(tail, st.Size()-8); err != nil {
+		return nil, 0, err
+	}
+	if string(tail[4:]) != "PAR1" {
+		return nil, 0, errors.New("assinatura PAR1 ausente no fim do arquivo")
+	}
+	n := int64(binary.LittleEndian.Uint32(tail[:4]))
+	if n <= 0 || n > st.Size()-12 {
+		return nil, 0, errors.New("tamanho do footer inválido")
+	}
+	buf := make([]byte, n)
+	if _, err := f.ReadAt(buf, st.Size()-8-n); err != nil {
+		return nil, 0, err
+	}
+	meta, err := (&thriftReader{b: buf}).readStruct()
+	return meta, st.Size() - 8 - n, err
+}
+
+// parquetColumns converte os SchemaElement (raiz + colunas) em colunas planas.
+func parquetColumns(schema []any) ([]pqColumn, error) {
+	if len(schema) < 2 {
+		return nil, errors.New("schema vazio")
+	}
+	var cols []pqColumn
+	for _, e := range schema[1:] {
+		el, ok := e.(tstruct)
+		if !ok {
+			return nil, errors.New("schema inválido")
+		}
+		if el.i64(5) > 0 {
+			return nil, fmt.Errorf("coluna %s: schema aninhado não suportado", el.str(4))
+		}
+		col := pqColumn{name: el.str(4), physical: int(el.i64(1)), length: int(el.i64(2))}
+		switch el.i64(3) {
+		case 1:
+			col.optional = true
+		case 2:
+			return nil, fmt.Errorf("coluna %s: colunas repetidas não suportadas", col.name)
+		}
+
+		// Tipo lógico (LogicalType) ou, em arquivos antigos, ConvertedType
+		if lt := el.sub(10); lt != nil {
+			switch {
+			case lt.has(5):
+				col.kind, col.scale = pqKindDecimal, int(lt.sub(5).i64(1))
+			case lt.has(6):
+				col.kind = pqKindDate
+			case lt.has(8):
+				col.kind = pqKindTimestamp
+				unit := lt.sub(8).sub(2)
+				switch {
+				case unit.has(1):
+					col.unit = "millis"
+				case unit.has(2):
+					col.unit = "micros"
+				default:
+					col.unit = "nanos"
+				}
+			case lt.has(10):
+				if !lt.sub(10).b(2) {
+					col.kind = pqKindUnsigned
+				}
+			}
+		} else if el.has(6) {
+			switch el.i64(6) {
+			case 5:
+				col.kind, col.scale = pqKindDecimal, int(el.i64(7))
+			case 6:
+				col.kind = pqKindDate
+			case 9:
+				col.kind, col.unit = pqKindTimestamp, "millis"
+			case 10:
+				col.kind, col.unit = pqKindTimestamp, "micros"
+			case 11, 12, 13, 14:
+				col.kind = pqKindUnsigned
+			}
+		}
+		if col.physical < pqBoolean || col.physical > pqFixedLenByteArray {
+			return nil, fmt.Errorf("coluna %s: tipo físico %d desconhecido", col.name, col.physical)
+		}
+		if col.physical == pqFixedLenByteArray && (col.length <= 0 || col.length > pqMaxPageSize) {
+			return nil, fmt.Errorf("coluna %s: type_length %d inválido", col.name, col.length)
+		}
+		if col.scale < 0 || col.scale > pqMaxScale {
+			return nil, fmt.Errorf("coluna %s: escala %d inválida", col.name, col.scale)
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+func (s *parquetSource) header() []string {
+	h := make([]string, len(s.cols))
+	for i, c := range s.cols {
+		h[i] = c.name
+	}
+	return h
+}
+
+func (s *parquetSource) line() int { return int(s.rowsRead) }
+
+func (s *parquetSource) done() float64 { return fraction(s.rowsRead, s.numRows) }
+
+func (s *parquetSource) close() error { return s.f.Close() }
+
+func (s *parquetSource) read() ([]string, error) {
+	for s.pos >= s.rows {
+		if s.group >= len(s.groups) {
+			return nil, io.EOF
+		}
+		if err := s.openGroup(s.groups[s.group]); err != nil {
+			return nil, fmt.Errorf("parquet: row group %d: %w", s.group, err)
+		}
+		s.group++
+	}
+	if s.rec == nil {
+		s.rec = make([]string, len(s.cols))
+	}
+	for i, c := range s.chunks {
+		v, err := c.value()
+		if err != nil {
+			return nil, fmt.Errorf("parquet: row group %d: coluna %s: %w", s.group-1, c.col.name, err)
+		}
+		s.rec[i] = c.col.format(v)
+	}
+	s.pos++
+	s.rowsRead++
+	return s.rec, nil
+}
+
+// openGroup valida o row group e abre um cursor por coluna; as páginas só são
+// lidas à medida que as linhas são consumidas.
+func (s *parquetSource) openGroup(g tstruct) error {
+	chunks := g.list(1)
+	if len(chunks) != len(s.cols) {
+		return fmt.Errorf("%d colunas no row group, %d no schema", len(chunks), len(s.cols))
+	}
+	rows := g.i64(3)
+	if rows < 0 || rows > s.numRows-s.rowsRead {
+		return fmt.Errorf("num_rows %d inválido (%d linhas restantes no arquivo)", rows, s.numRows-s.rowsRead)
+	}
+	s.chunks = make([]*pqChunk, len(s.cols))
+	for i, c := range chunks {
+		cc, _ := c.(tstruct)
+		ch, err := s.openChunk(s.cols[i], cc.sub(3), rows)
+		if err != nil {
+			return fmt.Errorf("coluna %s: %w", s.cols[i].name, err)
+		}
+		s.chunks[i] = ch
+	}
+	s.rows, s.pos = rows, 0
+	return nil
+}
+
+// openChunk valida offset, tamanho e contagem do column chunk contra o arquivo.
+func (s *parquetSource) openChunk(col pqColumn, meta tstruct, rows int64) (*pqChunk, error) {
+	if meta == nil {
+		return nil, errors.New("column chunk sem metadados")
+	}
+	if n := meta.i64(5); n != rows {
+		return nil, fmt.Errorf("%d valores para %d linhas", n, rows)
+	}
+	offset := meta.i64(9)
+	if d := meta.i64(11); meta.has(11) && d > 0 && d < offset {
+		offset = d
+	}
+	size := meta.i64(7)
+	if offset < 4 || size < 0 || size > s.dataEnd-offset {
+		return nil, fmt.Errorf("column chunk fora do arquivo (offset %d, %d bytes)", offset, size)
+	}
+	return &pqChunk{f: s.f, col: col, codec: meta.i64(4), off: offset, end: offset + size, left: rows}, nil
+}
+
+// value devolve o próximo valor da coluna, lendo a página seguinte quando a
+// atual se esgota.
+func (c *pqChunk) value() (any, error) {
+	for c.next >= len(c.page) {
+		if err := c.readPage(); err != nil {
+			return nil, err
+		}
+	}
+	v := c.page[c.next]
+	c.next++
+	return v, nil
+}
+
+// readPage lê e decodifica a próxima página do column chunk. A página de
+// dicionário só preenche c.dict; outros tipos (INDEX_PAGE) são ignorados.
+func (c *pqChunk) readPage() error {
+	if c.left <= 0 {
+		return errors.New("mais linhas que valores no column chunk")
+	}
+	if c.off >= c.end {
+		return fmt.Errorf("column chunk terminou com %d valores faltando", c.left)
+	}
+	ph, hlen, err := c.readHeader()
+	if err != nil {
+		return fmt.Errorf("page header: %w", err)
+	}
+	size, usize := ph.i64(3), ph.i64(2)
+	if size < 0 || size > c.end-c.off-hlen {
+		return fmt.Errorf("página de %d bytes além do column chunk", size)
+	}
+	if usize < 0 || usize > pqMaxPageSize {
+		return fmt.Errorf("tamanho descomprimido %d inválido", usize)
+	}
+	page := make([]byte, size)
+	if _, err := c.f.ReadAt(page, c.off+hlen); err != nil {
+		return err
+	}
+	c.off += hlen + size
+	c.page, c.next = nil, 0
+
+	switch ph.i64(1) {
+	case 2: // DICTIONARY_PAGE
+		data, err := decompress(c.codec, page, int(usize))
+		if err != nil {
+			return err
+		}
+		n := ph.sub(7).i64(1)
+		if n < 0 || n > pqMaxPageValues {
+			return fmt.Errorf("dicionário com %d valores inválido", n)
+		}
+		if c.dict, _, err = decodePlain(c.col, data, int(n)); err != nil {
+			return fmt.Errorf("dicionário: %w", err)
+		}
+	case 0: // DATA_PAGE
+		data, err := decompress(c.codec, page, int(usize))
+		if err != nil {
+			return err
+		}
+		h := ph.sub(5)
+		n, err := c.pageValues(h.i64(1))
+		if err != nil {
+			return err
+		}
+		var defs []int32
+		if c.col.optional {
+			if len(data) < 4 {
+				return errors.New("página sem definition levels")
+			}
+			l := int(binary.LittleEndian.Uint32(data[:4]))
+			if l > len(data)-4 {
+				return errors.New("definition levels além da página")
+			}
+			if defs, err = decodeRLE(data[4:4+l], 1, n); err != nil {
+				return err
+			}
+			data = data[4+l:]
+		}
+		if c.page, err = appendPage(nil, c.col, h.i64(2), data, defs, n, c.dict); err != nil {
+			return err
+		}
+		c.left -= int64(n)
+	case 3: // DATA_PAGE_V2 (levels fora da compressão)
+		h := ph.sub(8)
+		n, err := c.pageValues(h.i64(1))
+		if err != nil {
+			return err
+		}
+		defLen, repLen := h.i64(5), h.i64(6)
+		if repLen != 0 {
+			return errors.New("repetition levels não suportados")
+		}
+		if defLen < 0 || defLen > size || defLen > usize {
+			return errors.New("definition levels além da página")
+		}
+		var defs []int32
+		if c.col.optional {
+			if defs, err = decodeRLE(page[:defLen], 1, n); err != nil {
+				return err
+			}
+		}
+		data := page[defLen:]
+		if !h.has(7) || h.b(7) {
+			if data, err = decompress(c.codec, data, int(usize-defLen)); err != nil {
+				return err
+			}
+		}
+		if c.page, err = appendPage(nil, c.col, h.i64(4), data, defs, n, c.dict); err != nil {
+			return err
+		}
+		c.left -= int64(n)
+	}
+	return nil
+}
+
+// pageValues valida o num_values de uma página de dados.
+func (c *pqChunk) pageValues(n int64) (int, error) {
+	if n < 0 || n > c.left || n > pqMaxPageValues {
+		return 0, fmt.Errorf("página com %d valores inválida (%d restantes no column chunk)", n, c.left)
+	}
+	return int(n), nil
+}
+
+// readHeader decodifica o page header em c.off. O tamanho do header só é
+// conhecido depois de decodificado, então a janela lida cresce até caber.
+func (c *pqChunk) readHeader() (tstruct, int64, error) {
+	limit := min(c.end-c.off, pqMaxPageHeader)
+	for n := min(1<<10, limit); ; n = min(n*4, limit) {
+		buf := make([]byte, n)
+		if _, err := c.f.ReadAt(buf, c.off); err != nil {
+			return nil, 0, err
+		}
+		tr := &thriftReader{b: buf}
+		ph, err := tr.readStruct()
+		if err == nil {
+			return ph, int64(tr.pos), nil
+		}
+		if !errors.Is(err, io.ErrUnexpectedEOF) || n == limit {
+			return nil, 0, err
+		}
+	}
+}
+
+// appendPage decodifica os valores de uma página e intercala os NULLs (def level 0).
+func appendPage(out []any, col pqColumn, encoding int64, data []byte, defs []int32, n int, dict []any) ([]any, error) {
+	present := n
+	if defs != nil {
+		present = 0
+		for _, d := range defs {
+			if d == 1 {
+				present++
+			}
+		}
+	}
+
+	var vals []any
+	switch encoding {
+	case 0: // PLAIN
+		var err error
+		if vals, _, err = decodePlain(col, data, present); err != nil {
+			return nil, err
+		}
+	case 2, 8: // PLAIN_DICTIONARY, RLE_DICTIONARY
+		if dict == nil {
+			return nil, errors.New("página com dicionário sem DICTIONARY_PAGE")
+		}
+		if len(data) == 0 {
+			if present > 0 {
+				return nil, errors.New("página de dicionário vazia")
+			}
+			break
+		}
+		idx, err := decodeRLE(data[1:], int(data[0]), present)
+		if err != nil {
+			return nil, err
+		}
+		vals = make([]any, len(idx))
+		for i, k := range idx {
+			if int(k) >= len(dict) {
+				return nil, fmt.Errorf("índice %d fora do dicionário", k)
+			}
+			vals[i] = dict[k]
+		}
+	default:
+		return nil, fmt.Errorf("encoding %d não suportado", encoding)
+	}
+	if len(vals) < present {
+		return nil, fmt.Errorf("página com %d valores, esperados %d", len(vals), present)
+	}
+
+	if defs == nil {
+		return append(out, vals...), nil
+	}
+	j := 0
+	for _, d := range defs {
+		if d == 1 {
+			out = append(out, vals[j])
+			j++
+		} else {
+			out = append(out, nil)
+		}
+	}
+	return out, nil
+}
+
+// decompress descomprime uma página (codec do column chunk). size é o tamanho
+// descomprimido do page header: a saída nunca passa dele.
+func decompress(codec int64, data []byte, size int) ([]byte, error) {
+	var out []byte
+	switch codec {
+	case 0: // UNCOMPRESSED
+		return data, nil
+	case 1: // SNAPPY (s2 decodifica snappy)
+		n, err := s2.DecodedLen(data)
+		if err != nil {
+			return nil, err
+		}
+		if n > size {
+			return nil, fmt.Errorf("página descomprime para %d bytes, header diz %d", n, size)
+		}
+		return s2.Decode(make([]byte, 0, size), data)
+	case 2: // GZIP
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		if out, err = io.ReadAll(io.LimitReader(zr, int64(size)+1)); err != nil {
+			return nil, err
+		}
+	case 6: // ZSTD
+		zr, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(size)+1))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		if out, err = zr.DecodeAll(data, make([]byte, 0, size)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("compressão %d não suportada (use SNAPPY, GZIP, ZSTD ou nenhuma)", codec)
+	}
+	if len(out) > size {
+		return nil, fmt.Errorf("página descomprime para mais que os %d bytes do header", size)
+	}
+	return out, nil
+}
+
+// decodePlain lê n valores PLAIN do tipo físico da coluna.
+func decodePlain(col pqColumn, data []byte, n int) ([]any, int, error) {
+	out := make([]any, 0, min(n, len(data)))
+	pos := 0
+	need := func(k int) error {
+		if pos+k > len(data) {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		switch col.physical {
+		case pqBoolean: // 1 bit por valor
+			if i/8 >= len(data) {
+				return nil, 0, io.ErrUnexpectedEOF
+			}
+			out = append(out, data[i/8]>>(i%8)&1 == 1)
+			pos = i/8 + 1
+		case pqInt32:
+			if err := need(4); err != nil {
+				return nil, 0, err
+			}
+			out = append(out, int32(binary.LittleEndian.Uint32(data[pos:])))
+			pos += 4
+		case pqInt64:
+			if err := need(8); err != nil {
+				return nil, 0, err
+			}
+			out = append(out, int64(binary.LittleEndian.Uint64(data[pos:])))
+			pos += 8
+		case pqInt96:
+			if err := need(12); err != nil {
+				return nil, 0, err
+			}
+			out = append(out, int96Time(data[pos:pos+12]))
+			pos += 12
+		case pqFloat:
+			if err := need(4); err != nil {
+				return nil, 0, err
+			}
+			out = append(out, math.Float32frombits(binary.LittleEndian.Uint32(data[pos:])))
+			pos += 4
+		case pqDouble:
+			if err := need(8); err != nil {
+				return nil, 0, err
+			}
+			out = append(out, math.Float64frombits(binary.LittleEndian.Uint64(data[pos:])))
+			pos += 8
+		case pqByteArray:
+			if err := need(4); err != nil {
+				return nil, 0, err
+			}
+			l := int(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+			if err := need(l); err != nil {
+				return nil, 0, err
+			}
+			out = append(out, data[pos:pos+l])
+			pos += l
+		case pqFixedLenByteArray:
+			if err := need(col.length); err != nil {
+				return nil, 0, err
+			}
+			out = append(out, data[pos:pos+col.length])
+			pos += col.length
+		default:
+			return nil, 0, fmt.Errorf("tipo físico %d desconhecido", col.physical)
+		}
+	}
+	return out, pos, nil
+}
+
+// decodeRLE lê n valores do encoding híbrido RLE/bit-packed.
+func decodeRLE(data []byte, width, n int) ([]int32, error) {
+	if width < 0 || width > 32 {
+		return nil, fmt.Errorf("RLE: largura %d inválida", width)
+	}
+	out := make([]int32, 0, n)
+	pos := 0
+	for len(out) < n {
+		if pos >= len(data) {
+			return nil, errors.New("RLE: dados insuficientes")
+		}
+		header, k := binary.Uvarint(data[pos:])
+		if k <= 0 {
+			return nil, errors.New("RLE: cabeçalho inválido")
+		}
+		pos += k
+		if header&1 == 1 { // bit-packed: grupos de 8 valores
+			groups := header >> 1
+			if width > 0 && groups > uint64(len(data)-pos)/uint64(width) {
+				return nil, errors.New("RLE: bit-packed além dos dados")
+			}
+			count := int(min(groups, uint64(n))) * 8
+			nbytes := int(groups) * width
+			for i := 0; i < count && len(out) < n; i++ {
+				var v int32
+				for b := 0; b < width; b++ {
+					bit := i*width + b
+					if data[pos+bit/8]>>(bit%8)&1 == 1 {
+						v |= 1 << b
+					}
+				}
+				out = append(out, v)
+			}
+			pos += nbytes
+		} else { // run: valor repetido
+			count := int(min(header>>1, uint64(n)))
+			nbytes := (width + 7) / 8
+			if pos+nbytes > len(data) {
+				return nil, errors.New("RLE: run além dos dados")
+			}
+			var v int32
+			for b := 0; b < nbytes; b++ {
+				v |= int32(data[pos+b]) << (8 * b)
+			}
+			pos += nbytes
+			for i := 0; i < count && len(out) < n; i++ {
+				out = append(out, v)
+			}
+		}
+	}
+	return out, nil
+}
+
+// int96Time timestamp legado (Impala/Spark): nanos do dia + dia juliano.
+func int96Time(b []byte) time.Time {
+	nanos := int64(binary.LittleEndian.Uint64(b[:8]))
+	julian := int64(binary.LittleEndian.Uint32(b[8:]))
+	const unixEpochJulian = 2440588
+	return time.Unix((julian-unixEpochJulian)*86400, nanos).UTC()
+}
+
+// format converte um valor decodificado no texto do registro.
+func (c pqColumn) format(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case bool:
+		return strconv.FormatBool(x)
+	case int32:
+		switch c.kind {
+		case pqKindDate:
+			return time.Unix(int64(x)*86400, 0).UTC().Format("2006-01-02")
+		case pqKindDecimal:
+			return formatDecimal(big.NewInt(int64(x)), c.scale)
+		case pqKindUnsigned:
+			return strconv.FormatUint(uint64(uint32(x)), 10)
+		}
+		return strconv.FormatInt(int64(x), 10)
+	case int64:
+		switch c.kind {
+		case pqKindTimestamp:
+			var t time.Time
+			switch c.unit {
+			case "millis":
+				t = time.UnixMilli(x)
+			case "micros":
+				t = time.UnixMicro(x)
+			default:
+				t = time.Unix(0, x)
+			}
+			return t.UTC().Format(time.RFC3339Nano)
+		case pqKindDecimal:
+			return formatDecimal(big.NewInt(x), c.scale)
+		case pqKindUnsigned:
+			return strconv.FormatUint(uint64(x), 10)
+		}
+		return strconv.FormatInt(x, 10)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case []byte:
+		if c.kind == pqKindDecimal {
+			// big-endian em complemento de dois
+			n := new(big.Int).SetBytes(x)
+			if len(x) > 0 && x[0]&0x80 != 0 {
+				n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(x))*8))
+			}
+			return formatDecimal(n, c.scale)
+		}
+		return string(x)
+	}
+	return fmt.Sprint(v)
+}
+
+// formatDecimal aplica a escala ao inteiro não escalado (12345, 2 -> 123.45).
+func formatDecimal(n *big.Int, scale int) string {
+	if scale <= 0 {
+		return n.String()
+	}
+	neg := n.Sign() < 0
+	digits := new(big.Int).Abs(n).String()
+	for len(digits) <= scale {
+		digits = "0" + digits
+	}
+	s := digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Thrift compact protocol (footer e page headers)
+////////////////////////////////////////////////////////////////////////////////
+
+// tstruct struct Thrift decodificada: id do campo -> valor
+// (bool, int64, float64, []byte, []any ou tstruct).
+type tstruct map[int16]any
+
+func (t tstruct) has(id int16) bool {
+	_, ok := t[id]
+	return ok
+}
+
+func (t tstruct) i64(id int16) int64 {
+	v, _ := t[id].(int64)
+	return v
+}
+
+func (t tstruct) b(id int16) bool {
+	v, _ := t[id].(bool)
+	return v
+}
+
+func (t tstruct) str(id int16) string {
+	v, _ := t[id].([]byte)
+	return string(v)
+}
+
+func (t tstruct) list(id int16) []any {
+	v, _ := t[id].([]any)
+	return v
+}
+
+func (t tstruct) sub(id int16) tstruct {
+	v, _ := t[id].(tstruct)
+	return v
+}
+
+type thriftReader struct {
+	b     []byte
+	pos   int
+	depth int // structs/listas abertos
+}
+
+// thriftMaxDepth limita o aninhamento (um arquivo malformado não estoura a pilha).
+const thriftMaxDepth = 64
+
+func (r *thriftReader) byte() (byte, error) {
+	if r.pos >= len(r.b) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	c := r.b[r.pos]
+	r.pos++
+	return c, nil
+}
+
+func (r *thriftReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	if n == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if n < 0 {
+		return 0, errors.New("thrift: varint inválido")
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) zigzag() (int64, error) {
+	v, err := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+func (r *thriftReader) readStruct() (tstruct, error) {
+	t := tstruct{}
+	var last int16
+	for {
+		h, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if h == 0 { // STOP
+			return t, nil
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			v, err := r.zigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		last = id
+
+		typ := h & 0x0f
+		switch typ {
+		case 1, 2: // bool no próprio cabeçalho
+			t[id] = typ == 1
+			continue
+		}
+		v, err := r.readValue(typ)
+		if err != nil {
+			return nil, err
+		}
+		t[id] = v
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) (any, error) {
+	if r.depth >= thriftMaxDepth {
+		return nil, errors.New("thrift: aninhamento excessivo")
+	}
+	r.depth++
+	defer func() { r.depth-- }()
+	switch typ {
+	case 1, 2: // bool em lista: 1 byte
+		c, err := r.byte()
+		return c == 1, err
+	case 3: // i8
+		c, err := r.byte()
+		return int64(int8(c)), err
+	case 4, 5, 6: // i16, i32, i64
+		return r.zigzag()
+	case 7: // double
+		if r.pos+8 > len(r.b) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.b[r.pos:]))
+		r.pos += 8
+		return v, nil
+	case 8: // binary/string
+		n, err := r.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(r.b)-r.pos) < n {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := r.b[r.pos : r.pos+int(n)]
+		r.pos += int(n)
+		return v, nil
+	case 9, 10: // list, set
+		h, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(h >> 4)
+		if size == 15 {
+			if size, err = r.uvarint(); err != nil {
+				return nil, err
+			}
+		}
+		if size > uint64(len(r.b)-r.pos) { // cada elemento ocupa ao menos 1 byte
+			return nil, io.ErrUnexpectedEOF
+		}
+		out := make([]any, 0, size)
+		for i := uint64(0); i < size; i++ {
+			v, err := r.readValue(h & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case 11: // map (não usado pelo Parquet: lido e descartado)
+		size, err := r.uvarint()
+		if err != nil || size == 0 {
+			return nil, err
+		}
+		if size > uint64(len(r.b)-r.pos) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		kv, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < size; i++ {
+			if _, err := r.readValue(kv >> 4); err != nil {
+				return nil, err
+			}
+			if _, err := r.readValue(kv & 0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case 12: // struct
+		return r.readStruct()
+	}
+	return nil, fmt.Errorf("thrift: tipo %d desconhecido", typ)
+}
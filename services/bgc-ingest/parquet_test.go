@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testdata/exportacao.parquet: 2 row groups, 7 colunas cobrindo SNAPPY e GZIP, páginas
+// v1 e v2, dicionário, NULLs, DECIMAL (INT64 e FIXED_LEN_BYTE_ARRAY), DATE e TIMESTAMP.
+// Gerado por testdata/exportacao_parquet.py.
+func TestParquetSource(t *testing.T) {
+	src, err := openRecords("testdata/exportacao.parquet", inputOpts{})
+	if err != nil {
+		t.Fatalf("openRecords: %v", err)
+	}
+	defer src.close()
+
+	wantHeader := []string{"co_ano", "co_pais", "vl_fob", "data", "kg_liquido", "embarque", "vl_frete"}
+	if got := src.header(); !reflect.DeepEqual(got, wantHeader) {
+		t.Fatalf("header = %v, want %v", got, wantHeader)
+	}
+
+	want := [][]string{
+		{"2024", "CN", "1500.25", "2024-03-15", "10.5", "2024-03-15T12:30:00Z", "-1.50"},
+		{"2024", "US", "0.05", "2024-03-16", "", "2024-03-16T00:00:00Z", "123.45"},
+		{"2024", "", "-0.01", "2024-03-17", "0", "2024-03-17T23:59:59Z", "0.00"},
+		{"2023", "CN", "0.00", "2023-12-31", "1000000", "2023-12-31T08:00:00Z", "0.07"},
+		{"2023", "AR", "0.99", "2023-01-01", "2.25", "2023-01-01T00:00:01Z", "-0.01"},
+	}
+	var got [][]string
+	for {
+		rec, err := src.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		got = append(got, append([]string(nil), rec...))
+		if src.line() != len(got) {
+			t.Errorf("line = %d, want %d", src.line(), len(got))
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("registros:\n got %q\nwant %q", got, want)
+	}
+	if src.done() != 1 {
+		t.Errorf("done = %v, want 1", src.done())
+	}
+}
+
+func TestOpenParquetInvalid(t *testing.T) {
+	tests := map[string]string{
+		"short":        "PAR1",
+		"no signature": "PAR1xxxxxxxxxxxxxxxx",
+		"bad footer":   "PAR1\x00\x00\xff\xff\xff\x7fPAR1",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := openParquet(writeFile(t, "x.parquet", content)); err == nil {
+				t.Error("esperava erro")
+			}
+		})
+	}
+}
+
+// Arquivo corrompido (byte trocado ou trecho de dados faltando) deve falhar com
+// erro, nunca com panic ou alocação guiada por tamanho lixo.
+func TestParquetMalformed(t *testing.T) {
+	orig, err := os.ReadFile("testdata/exportacao.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "x.parquet")
+	readAll := func(content []byte) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		src, err := openParquet(path)
+		if err != nil {
+			return err
+		}
+		defer src.close()
+		for {
+			if _, err := src.read(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+
+	failed := 0
+	for i := range orig {
+		for _, b := range []byte{0x00, 0x7f, 0xff, orig[i] ^ 0x80} {
+			content := bytes.Clone(orig)
+			content[i] = b
+			err := readAll(content)
+			if err != nil && strings.HasPrefix(err.Error(), "panic") {
+				t.Fatalf("byte %d = %#x: %v", i, b, err)
+			}
+			if err != nil {
+				failed++
+			}
+		}
+	}
+	if failed == 0 {
+		t.Fatal("nenhuma corrupção detectada")
+	}
+
+	// Dados truncados com o footer intacto: column chunks apontam além do arquivo
+	n := int(binary.LittleEndian.Uint32(orig[len(orig)-8:]))
+	footer := orig[len(orig)-8-n:]
+	for _, cut := range []int{4, 64, len(orig) - 8 - n - 1} {
+		content := append(bytes.Clone(orig[:cut]), footer...)
+		if err := readAll(content); err == nil || strings.HasPrefix(err.Error(), "panic") {
+			t.Errorf("dados cortados em %d bytes: err = %v, esperava erro", cut, err)
+		}
+	}
+}
+
+func TestDecodeRLE(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		width   int
+		n       int
+		want    []int32
+		wantErr bool
+	}{
+		{name: "run", data: []byte{5 << 1, 1}, width: 1, n: 5, want: []int32{1, 1, 1, 1, 1}},
+		{name: "bit-packed", data: []byte{1<<1 | 1, 0b00001101}, width: 1, n: 4, want: []int32{1, 0, 1, 1}},
+		{name: "bit-packed width 2", data: []byte{1<<1 | 1, 0b11100100, 0}, width: 2, n: 4, want: []int32{0, 1, 2, 3}},
+		{name: "run then bit-packed", data: []byte{2 << 1, 3, 1<<1 | 1, 0b00000001, 0}, width: 2, n: 3, want: []int32{3, 3, 1}},
+		{name: "run width 9", data: []byte{2 << 1, 0x2c, 0x01}, width: 9, n: 2, want: []int32{300, 300}},
+		{name: "short run", data: []byte{4 << 1}, width: 1, n: 4, wantErr: true},
+		{name: "missing values", data: []byte{2 << 1, 1}, width: 1, n: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeRLE(tt.data, tt.width, tt.n)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("esperava erro, got %v", got)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeRLE = (%v, %v), want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePlain(t *testing.T) {
+	tests := []struct {
+		name    string
+		col     pqColumn
+		data    []byte
+		n       int
+		want    []any
+		wantErr bool
+	}{
+		{name: "boolean", col: pqColumn{physical: pqBoolean}, data: []byte{0b101}, n: 3, want: []any{true, false, true}},
+		{name: "int32", col: pqColumn{physical: pqInt32}, data: []byte{0xff, 0xff, 0xff, 0xff, 2, 0, 0, 0}, n: 2, want: []any{int32(-1), int32(2)}},
+		{name: "byte array", col: pqColumn{physical: pqByteArray}, data: []byte{2, 0, 0, 0, 'C', 'N', 0, 0, 0, 0}, n: 2, want: []any{[]byte("CN"), []byte{}}},
+		{name: "fixed", col: pqColumn{physical: pqFixedLenByteArray, length: 2}, data: []byte{1, 2, 3, 4}, n: 2, want: []any{[]byte{1, 2}, []byte{3, 4}}},
+		{name: "truncated int64", col: pqColumn{physical: pqInt64}, data: []byte{1, 2, 3}, n: 1, wantErr: true},
+		{name: "truncated byte array", col: pqColumn{physical: pqByteArray}, data: []byte{9, 0, 0, 0, 'x'}, n: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := decodePlain(tt.col, tt.data, tt.n)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("esperava erro, got %v", got)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodePlain = (%v, %v), want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		n     int64
+		scale int
+		want  string
+	}{
+		{n: 12345, scale: 2, want: "123.45"},
+		{n: 5, scale: 2, want: "0.05"},
+		{n: 0, scale: 2, want: "0.00"},
+		{n: -1, scale: 3, want: "-0.001"},
+		{n: -12345, scale: 2, want: "-123.45"},
+		{n: 42, scale: 0, want: "42"},
+	}
+	for _, tt := range tests {
+		if got := formatDecimal(big.NewInt(tt.n), tt.scale); got != tt.want {
+			t.Errorf("formatDecimal(%d, %d) = %q, want %q", tt.n, tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestPqColumnFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		col  pqColumn
+		v    any
+		want string
+	}{
+		{name: "null", v: nil, want: ""},
+		{name: "unsigned int32", col: pqColumn{kind: pqKindUnsigned}, v: int32(-1), want: "4294967295"},
+		{name: "timestamp millis", col: pqColumn{kind: pqKindTimestamp, unit: "millis"}, v: int64(1710505800123), want: "2024-03-15T12:30:00.123Z"},
+		{name: "timestamp nanos", col: pqColumn{kind: pqKindTimestamp, unit: "nanos"}, v: int64(1), want: "1970-01-01T00:00:00.000000001Z"},
+		{name: "decimal int32", col: pqColumn{kind: pqKindDecimal, scale: 1}, v: int32(-15), want: "-1.5"},
+		{name: "decimal bytes", col: pqColumn{kind: pqKindDecimal, scale: 2}, v: []byte{0xff, 0x6a}, want: "-1.50"},
+		{name: "float32", v: float32(0.1), want: "0.1"},
+		{name: "int96", v: int96Time([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0x8c, 0x3d, 0x25, 0}), want: "1970-01-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.col.format(tt.v); got != tt.want {
+				t.Errorf("format(%v) = %q, want %q", tt.v, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+// quality.go — verificações de qualidade dos dados carregados
+//
+// Comando:
+//   bgc-ingest quality-check [--tables stg.exportacao,stg.importacao] [--sample 10]
+//                            [--max-orphans 0] [--max-negative 0] [--max-duplicates 0] [--max-gaps -1]
+//
+// Verificações (por tabela, exceto a view legada):
+//   - orphans_ncm:   NCMs de v_quality_orphans_ncm (trade_ncm_year) e, por tabela,
+//                    co_ncm fora de ncm_lookup (se a tabela de NCMs estiver populada);
+//   - negative_values: linhas com vl_fob, kg_liquido, qt_estat, vl_frete ou vl_seguro < 0;
+//   - month_gaps:    NCMs com meses faltando entre o primeiro e o último mês carregado;
+//   - duplicates:    linhas extras com a mesma chave (ano, mês, país, NCM, UF).
+//
+// Imprime um relatório JSON (contagem, limite e amostra por verificação). Se alguma
+// contagem passar do limite (--max-*; -1 = só reporta), o comando termina com erro.
+// NCMs sem exportação em todos os meses são comuns, por isso month_gaps só reporta
+// por padrão.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Status de uma verificação
+const (
+	checkOK      = "ok"
+	checkFailed  = "failed"
+	checkSkipped = "skipped"
+)
+
+// checkResult resultado de uma verificação no relatório.
+type checkResult struct {
+	Check     string           `json:"check"`
+	Table     string           `json:"table,omitempty"`
+	Status    string           `json:"status"`
+	Count     int64            `json:"count"`
+	Threshold int64            `json:"threshold"`
+	Detail    string           `json:"detail,omitempty"`
+	Sample    []map[string]any `json:"sample,omitempty"`
+}
+
+// judge compara a contagem com o limite (-1 = sem limite).
+func (c *checkResult) judge() {
+	c.Status = checkOK
+	if c.Threshold >= 0 && c.Count > c.Threshold {
+		c.Status = checkFailed
+	}
+}
+
+// qualityOpts limites e amostragem do quality-check.
+type qualityOpts struct {
+	tables        []string
+	sample        int
+	maxOrphans    int64
+	maxNegative   int64
+	maxDuplicates int64
+	maxGaps       int64
+}
+
+// tableColumns colunas da tabela (vazio = tabela não existe).
+func tableColumns(ctx context.Context, pool *pgxpool.Pool, table pgx.Identifier) (map[string]bool, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT column_name FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2`,
+		schemaOf(table), table[len(table)-1])
+	if err != nil {
+		return nil, err
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	cols := make(map[string]bool, len(names))
+	for _, n := range names {
+		cols[n] = true
+	}
+	return cols, nil
+}
+
+// querySample linhas de amostra como mapas coluna -> valor.
+func querySample(ctx context.Context, pool *pgxpool.Pool, sql string, args ...any) ([]map[string]any, error) {
+	rows, err := pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToMap)
+}
+
+// checkOrphansView conta os NCMs de v_quality_orphans_ncm (schema legado trade_ncm_year).
+func checkOrphansView(ctx context.Context, pool *pgxpool.Pool, opts qualityOpts) (checkResult, error) {
+	res := checkResult{Check: "orphans_ncm", Table: "v_quality_orphans_ncm", Threshold: opts.maxOrphans}
+	var exists bool
+	if err := pool.QueryRow(ctx, `SELECT to_regclass('v_quality_orphans_ncm') IS NOT NULL`).Scan(&exists); err != nil {
+		return res, err
+	}
+	if !exists {
+		res.Status, res.Detail = checkSkipped, "view não existe"
+		return res, nil
+	}
+	if err := pool.QueryRow(ctx, `SELECT count(*) FROM v_quality_orphans_ncm`).Scan(&res.Count); err != nil {
+		return res, err
+	}
+	sample, err := querySample(ctx, pool, `SELECT ncm FROM v_quality_orphans_ncm ORDER BY ncm LIMIT $1`, opts.sample)
+	if err != nil {
+		return res, err
+	}
+	res.Sample = sample
+	res.judge()
+	return res, nil
+}
+
+// checkOrphans NCMs da tabela fora de ncm_lookup (pulado se ncm_lookup estiver vazia).
+func checkOrphans(ctx context.Context, pool *pgxpool.Pool, table pgx.Identifier, opts qualityOpts) (checkResult, error) {
+	res := checkResult{Check: "orphans_ncm", Table: tableName(table), Threshold: opts.maxOrphans}
+	var lookup int64
+	err := pool.QueryRow(ctx, `
+		SELECT CASE WHEN to_regclass('ncm_lookup') IS NULL THEN -1
+		            ELSE (SELECT count(*) FROM (SELECT 1 FROM ncm_lookup LIMIT 1) l) END`).Scan(&lookup)
+	if err != nil {
+		return res, err
+	}
+	if lookup <= 0 {
+		res.Status, res.Detail = checkSkipped, "ncm_lookup vazia ou inexistente"
+		return res, nil
+	}
+
+	from := orphansFrom(table)
+	if err := pool.QueryRow(ctx, `SELECT count(*) `+from).Scan(&res.Count); err != nil {
+		return res, err
+	}
+	if res.Sample, err = querySample(ctx, pool, `SELECT co_ncm `+from+` ORDER BY co_ncm LIMIT $1`, opts.sample); err != nil {
+		return res, err
+	}
+	res.judge()
+	return res, nil
+}
+
+// orphansFrom FROM/WHERE dos NCMs distintos da tabela sem linha em ncm_lookup.
+func orphansFrom(table pgx.Identifier) string {
+	return fmt.Sprintf(`
+		FROM (SELECT DISTINCT co_ncm FROM %s) t
+		WHERE NOT EXISTS (SELECT 1 FROM ncm_lookup l WHERE l.co_ncm = t.co_ncm)`, table.Sanitize())
+}
+
+// negativeWhere FROM/WHERE das linhas com algum valor negativo e as colunas de valor
+// que a tabela tem (nenhuma = where vazio).
+func negativeWhere(table pgx.Identifier, cols map[string]bool) (string, []string) {
+	var conds, present []string
+	for _, c := range []string{"vl_fob", "kg_liquido", "qt_estat", "vl_frete", "vl_seguro"} {
+		if cols[c] {
+			conds = append(conds, c+" < 0")
+			present = append(present, c)
+		}
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " FROM " + table.Sanitize() + " WHERE " + strings.Join(conds, " OR "), present
+}
+
+// checkNegative linhas com algum valor negativo (só as colunas que a tabela tem).
+func checkNegative(ctx context.Context, pool *pgxpool.Pool, table pgx.Identifier, cols map[string]bool, opts qualityOpts) (checkResult, error) {
+	res := checkResult{Check: "negative_values", Table: tableName(table), Threshold: opts.maxNegative}
+	where, present := negativeWhere(table, cols)
+	if where == "" {
+		res.Status, res.Detail = checkSkipped, "tabela sem colunas de valor"
+		return res, nil
+	}
+
+	if err := pool.QueryRow(ctx, `SELECT count(*)`+where).Scan(&res.Count); err != nil {
+		return res, err
+	}
+	var err error
+	sql := `SELECT co_ano, co_mes, co_pais, co_ncm, ` + strings.Join(present, ", ") + where + ` ORDER BY co_ano, co_mes, co_ncm LIMIT $1`
+	if res.Sample, err = querySample(ctx, pool, sql, opts.sample); err != nil {
+		return res, err
+	}
+	res.judge()
+	return res, nil
+}
+
+// monthGapsSQL um registro por NCM com meses faltando entre o primeiro e o último mês
+// carregado. Meses viram um índice contínuo (ano*12 + mês-1) para a conta não quebrar
+// na virada do ano.
+func monthGapsSQL(table pgx.Identifier) string {
+	return fmt.Sprintf(`
+		WITH m AS (
+		  SELECT co_ncm, co_ano * 12 + co_mes - 1 AS mes
+		  FROM %s GROUP BY 1, 2
+		), r AS (
+		  SELECT co_ncm, min(mes) AS primeiro, max(mes) AS ultimo, count(*) AS meses
+		  FROM m GROUP BY co_ncm
+		)
+		SELECT co_ncm,
+		       (ultimo - primeiro + 1 - meses)::bigint AS missing_months,
+		       to_char(make_date(primeiro / 12, primeiro %% 12 + 1, 1), 'YYYY-MM') AS first_month,
+		       to_char(make_date(ultimo / 12, ultimo %% 12 + 1, 1), 'YYYY-MM') AS last_month
+		FROM r WHERE ultimo - primeiro + 1 > meses`, table.Sanitize())
+}
+
+// checkMonthGaps NCMs com meses sem linhas entre o primeiro e o último mês do NCM.
+func checkMonthGaps(ctx context.Context, pool *pgxpool.Pool, table pgx.Identifier, opts qualityOpts) (checkResult, error) {
+	res := checkResult{Check: "month_gaps", Table: tableName(table), Threshold: opts.maxGaps}
+	gaps := monthGapsSQL(table)
+
+	var missing int64
+	if err := pool.QueryRow(ctx, `SELECT count(*), coalesce(sum(missing_months), 0)::bigint FROM (`+gaps+`) g`).Scan(&res.Count, &missing); err != nil {
+		return res, err
+	}
+	var err error
+	if res.Sample, err = querySample(ctx, pool, gaps+` ORDER BY missing_months DESC, co_ncm LIMIT $1`, opts.sample); err != nil {
+		return res, err
+	}
+	if res.Count > 0 {
+		res.Detail = fmt.Sprintf("%d meses faltando no total", missing)
+	}
+	res.judge()
+	return res, nil
+}
+
+// duplicatesSQL chaves (ano, mês, país, NCM e UF se houver) com mais de uma linha.
+func duplicatesSQL(table pgx.Identifier, cols map[string]bool) string {
+	key := "co_ano, co_mes, co_pais, co_ncm"
+	if cols["sg_uf_ncm"] {
+		key += ", sg_uf_ncm" // GROUP BY agrupa NULLs (mesma regra do índice NULLS NOT DISTINCT)
+	}
+	return fmt.Sprintf(`SELECT %s, count(*) AS n FROM %s GROUP BY %s HAVING count(*) > 1`, key, table.Sanitize(), key)
+}
+
+// checkDuplicates linhas extras com a mesma chave (ano, mês, país, NCM, UF).
+func checkDuplicates(ctx context.Context, pool *pgxpool.Pool, table pgx.Identifier, cols map[string]bool, opts qualityOpts) (checkResult, error) {
+	res := checkResult{Check: "duplicates", Table: tableName(table), Threshold: opts.maxDuplicates}
+	dups := duplicatesSQL(table, cols)
+
+	if err := pool.QueryRow(ctx, `SELECT coalesce(sum(n - 1), 0)::bigint FROM (`+dups+`) d`).Scan(&res.Count); err != nil {
+		return res, err
+	}
+	var err error
+	if res.Sample, err = querySample(ctx, pool, dups+` ORDER BY n DESC LIMIT $1`, opts.sample); err != nil {
+		return res, err
+	}
+	res.judge()
+	return res, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Comando: quality-check
+////////////////////////////////////////////////////////////////////////////////
+
+func cmdQualityCheck(args []string) error {
+	fs := flag.NewFlagSet("quality-check", flag.ContinueOnError)
+	tables := fs.String("tables", "stg.exportacao,stg.importacao", "tabelas verificadas (schema ComexStat)")
+	sample := fs.Int("sample", 10, "linhas de exemplo por verificação")
+	maxOrphans := fs.Int64("max-orphans", 0, "NCMs órfãos aceitos (-1 = sem limite)")
+	maxNegative := fs.Int64("max-negative", 0, "linhas com valores negativos aceitas (-1 = sem limite)")
+	maxDuplicates := fs.Int64("max-duplicates", 0, "linhas duplicadas aceitas (-1 = sem limite)")
+	maxGaps := fs.Int64("max-gaps", -1, "NCMs com meses faltando aceitos (-1 = só reporta)")
+	timeout := fs.Duration("timeout", 10*time.Minute, "tempo máximo das verificações")
+	fs.SetOutput(new(nopWriter))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts := qualityOpts{
+		sample:        *sample,
+		maxOrphans:    *maxOrphans,
+		maxNegative:   *maxNegative,
+		maxDuplicates: *maxDuplicates,
+		maxGaps:       *maxGaps,
+	}
+	for _, t := range strings.Split(*tables, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			opts.tables = append(opts.tables, t)
+		}
+	}
+
+	pool, err := connect()
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	start := time.Now()
+	var checks []checkResult
+	res, err := checkOrphansView(ctx, pool, opts)
+	if err != nil {
+		return fmt.Errorf("orphans_ncm: %w", err)
+	}
+	checks = append(checks, res)
+
+	for _, name := range opts.tables {
+		table, err := parseTable(name)
+		if err != nil {
+			return err
+		}
+		cols, err := tableColumns(ctx, pool, table)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if !cols["co_ncm"] || !cols["co_ano"] || !cols["co_mes"] || !cols["co_pais"] {
+			checks = append(checks, checkResult{Check: "table", Table: tableName(table), Status: checkSkipped,
+				Detail: "tabela inexistente ou fora do schema ComexStat"})
+			continue
+		}
+
+		for _, run := range []struct {
+			name string
+			fn   func() (checkResult, error)
+		}{
+			{"orphans_ncm", func() (checkResult, error) { return checkOrphans(ctx, pool, table, opts) }},
+			{"negative_values", func() (checkResult, error) { return checkNegative(ctx, pool, table, cols, opts) }},
+			{"month_gaps", func() (checkResult, error) { return checkMonthGaps(ctx, pool, table, opts) }},
+			{"duplicates", func() (checkResult, error) { return checkDuplicates(ctx, pool, table, cols, opts) }},
+		} {
+			res, err := run.fn()
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", run.name, name, err)
+			}
+			checks = append(checks, res)
+		}
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if c.Status == checkFailed {
+			failed++
+		}
+	}
+	status := checkOK
+	if failed > 0 {
+		status = checkFailed
+	}
+	printJSON(map[string]any{
+		"status":      status,
+		"failed":      failed,
+		"checks":      checks,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+	if failed > 0 {
+		return fmt.Errorf("quality-check: %d verificações acima do limite", failed)
+	}
+	return nil
+}
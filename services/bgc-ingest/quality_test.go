@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// squish normaliza espaços e quebras de linha do SQL gerado.
+func squish(sql string) string { return strings.Join(strings.Fields(sql), " ") }
+
+func TestCheckResultJudge(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int64
+		threshold int64
+		want      string
+	}{
+		{name: "zero tolerance clean", count: 0, threshold: 0, want: checkOK},
+		{name: "zero tolerance", count: 1, threshold: 0, want: checkFailed},
+		{name: "at threshold", count: 5, threshold: 5, want: checkOK},
+		{name: "above threshold", count: 6, threshold: 5, want: checkFailed},
+		{name: "report only", count: 1000, threshold: -1, want: checkOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := checkResult{Count: tt.count, Threshold: tt.threshold, Status: checkSkipped}
+			res.judge()
+			if res.Status != tt.want {
+				t.Errorf("judge(count=%d, threshold=%d) = %q, want %q", tt.count, tt.threshold, res.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrphansFrom(t *testing.T) {
+	got := squish(orphansFrom(pgx.Identifier{"stg", "exportacao"}))
+	want := `FROM (SELECT DISTINCT co_ncm FROM "stg"."exportacao") t ` +
+		`WHERE NOT EXISTS (SELECT 1 FROM ncm_lookup l WHERE l.co_ncm = t.co_ncm)`
+	if got != want {
+		t.Errorf("orphansFrom:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestNegativeWhere(t *testing.T) {
+	table := pgx.Identifier{"stg", "importacao"}
+	cols := map[string]bool{"co_ano": true, "vl_fob": true, "kg_liquido": true, "vl_frete": true, "vl_seguro": true}
+	where, present := negativeWhere(table, cols)
+	want := ` FROM "stg"."importacao" WHERE vl_fob < 0 OR kg_liquido < 0 OR vl_frete < 0 OR vl_seguro < 0`
+	if where != want {
+		t.Errorf("negativeWhere:\n got %s\nwant %s", where, want)
+	}
+	if wantCols := []string{"vl_fob", "kg_liquido", "vl_frete", "vl_seguro"}; !reflect.DeepEqual(present, wantCols) {
+		t.Errorf("colunas = %v, want %v", present, wantCols)
+	}
+
+	if where, present := negativeWhere(table, map[string]bool{"co_ano": true}); where != "" || present != nil {
+		t.Errorf("sem colunas de valor: (%q, %v), esperava vazio", where, present)
+	}
+}
+
+func TestMonthGapsSQL(t *testing.T) {
+	got := squish(monthGapsSQL(pgx.Identifier{"stg", "exportacao"}))
+	want := `WITH m AS ( SELECT co_ncm, co_ano * 12 + co_mes - 1 AS mes FROM "stg"."exportacao" GROUP BY 1, 2 ), ` +
+		`r AS ( SELECT co_ncm, min(mes) AS primeiro, max(mes) AS ultimo, count(*) AS meses FROM m GROUP BY co_ncm ) ` +
+		`SELECT co_ncm, (ultimo - primeiro + 1 - meses)::bigint AS missing_months, ` +
+		`to_char(make_date(primeiro / 12, primeiro % 12 + 1, 1), 'YYYY-MM') AS first_month, ` +
+		`to_char(make_date(ultimo / 12, ultimo % 12 + 1, 1), 'YYYY-MM') AS last_month ` +
+		`FROM r WHERE ultimo - primeiro + 1 > meses`
+	if got != want {
+		t.Errorf("monthGapsSQL:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestDuplicatesSQL(t *testing.T) {
+	table := pgx.Identifier{"stg", "exportacao"}
+	tests := []struct {
+		name string
+		cols map[string]bool
+		want string
+	}{
+		{
+			name: "com UF",
+			cols: map[string]bool{"sg_uf_ncm": true},
+			want: `SELECT co_ano, co_mes, co_pais, co_ncm, sg_uf_ncm, count(*) AS n FROM "stg"."exportacao" ` +
+				`GROUP BY co_ano, co_mes, co_pais, co_ncm, sg_uf_ncm HAVING count(*) > 1`,
+		},
+		{
+			name: "sem UF",
+			cols: map[string]bool{},
+			want: `SELECT co_ano, co_mes, co_pais, co_ncm, count(*) AS n FROM "stg"."exportacao" ` +
+				`GROUP BY co_ano, co_mes, co_pais, co_ncm HAVING count(*) > 1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := duplicatesSQL(table, tt.cols); got != tt.want {
+				t.Errorf("duplicatesSQL:\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
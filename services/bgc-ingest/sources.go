@@ -0,0 +1,343 @@
+// sources.go — leitura dos arquivos de entrada (CSV, .csv.gz, .zip e Parquet)
+//
+// load-csv e load-comexstat leem registros ([]string) de um recordSource, então o
+// mesmo pipeline (conversão legada ou --mapping, validação, rejeitos, COPY) vale
+// para qualquer formato. O formato é detectado pelos primeiros bytes do arquivo
+// (--format força):
+//   - gzip (.csv.gz): CSV descomprimido sob demanda;
+//   - zip (downloads do MDIC): todos os membros na ordem do arquivo, ou só --member
+//     (nome ou glob, ex.: EXP_2024.csv, *.csv); cada membro tem o próprio cabeçalho;
+//   - Parquet (data lake): cabeçalho = nomes das colunas (ver parquet.go);
+//   - demais: CSV puro.
+
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// Formatos de entrada (--format)
+const (
+	formatAuto    = "auto"
+	formatCSV     = "csv"
+	formatGzip    = "gzip"
+	formatZip     = "zip"
+	formatParquet = "parquet"
+)
+
+// inputOpts como abrir o arquivo de entrada.
+type inputOpts struct {
+	format string // auto | csv | gzip | zip | parquet
+	member string // zip: membro (nome ou glob); vazio = todos
+	sep    rune   // separador do CSV
+	header bool   // CSV: primeira linha (de cada membro) é cabeçalho
+}
+
+// recordSource registros de um arquivo de entrada, um por vez.
+type recordSource interface {
+	header() []string        // cabeçalho (nil = arquivo sem cabeçalho)
+	read() ([]string, error) // próximo registro (io.EOF no fim; o slice pode ser reaproveitado)
+	line() int               // linha (ou registro, no Parquet) do último read
+	done() float64           // fração do arquivo já lida (progresso)
+	close() error
+}
+
+// detectFormat identifica o formato pelos magic bytes.
+func detectFormat(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	n, _ := io.ReadFull(f, magic)
+	magic = magic[:n]
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return formatGzip, nil
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+		return formatZip, nil
+	case bytes.HasPrefix(magic, []byte("PAR1")):
+		return formatParquet, nil
+	}
+	return formatCSV, nil
+}
+
+// openRecords abre o arquivo no formato pedido (ou detectado).
+func openRecords(path string, in inputOpts) (recordSource, error) {
+	format := in.format
+	if format == "" || format == formatAuto {
+		var err error
+		if format, err = detectFormat(path); err != nil {
+			return nil, err
+		}
+	}
+	if in.member != "" && format != formatZip {
+		return nil, errors.New("--member só vale para arquivos .zip")
+	}
+
+	switch format {
+	case formatCSV, formatGzip:
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		st, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		// Progresso pelos bytes do arquivo (comprimidos, no gzip)
+		counter := &countingReader{r: f}
+		var r io.Reader = counter
+		if format == formatGzip {
+			gz, err := gzip.NewReader(bufio.NewReader(counter))
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("gzip: %w", err)
+			}
+			r = gz
+		}
+		src, err := newCSVSource(r, in)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		src.closer = f
+		src.progress = func() float64 { return fraction(counter.n, st.Size()) }
+		return src, nil
+	case formatZip:
+		return openZip(path, in)
+	case formatParquet:
+		return openParquet(path)
+	}
+	return nil, fmt.Errorf("--format inválido: %q (use auto, csv, gzip, zip ou parquet)", format)
+}
+
+func fraction(n, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CSV (puro ou descomprimido)
+////////////////////////////////////////////////////////////////////////////////
+
+type csvSource struct {
+	r        *csv.Reader
+	hdr      []string
+	n        int // linha do último registro lido
+	progress func() float64
+	closer   io.Closer
+}
+
+// newCSVSource prepara o csv.Reader e lê o cabeçalho (se houver).
+func newCSVSource(r io.Reader, in inputOpts) (*csvSource, error) {
+	cr := csv.NewReader(skipBOM(bufio.NewReader(r)))
+	cr.Comma = in.sep
+	cr.FieldsPerRecord = -1 // linhas com contagem de campos variável (CSVs "imperfeitos")
+	cr.ReuseRecord = true   // reaproveita slice internamente (menos GC)
+
+	src := &csvSource{r: cr}
+	if in.header {
+		h, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("ler cabeçalho: %w", err)
+		}
+		src.hdr = append([]string(nil), h...) // cópia: ReuseRecord reaproveita o slice
+		src.n = 1
+	}
+	return src, nil
+}
+
+func (s *csvSource) header() []string { return s.hdr }
+func (s *csvSource) line() int        { return s.n }
+
+func (s *csvSource) read() ([]string, error) {
+	rec, err := s.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	s.n++
+	// Diferença na quantidade de campos não é erro de leitura (vira rejeito na conversão)
+	if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+		return nil, fmt.Errorf("csv read: %w", err)
+	}
+	return rec, nil
+}
+
+func (s *csvSource) done() float64 {
+	if s.progress == nil {
+		return 0
+	}
+	return s.progress()
+}
+
+func (s *csvSource) close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// ZIP (membros CSV, em sequência)
+////////////////////////////////////////////////////////////////////////////////
+
+type zipSource struct {
+	zr      *zip.ReadCloser
+	in      inputOpts
+	members []*zip.File
+	next    int // próximo membro a abrir
+
+	cur     *csvSource
+	rc      io.ReadCloser
+	counter *countingReader
+	hdr     []string
+
+	doneBytes  int64 // bytes (descomprimidos) dos membros já lidos
+	totalBytes int64
+}
+
+// openZip seleciona os membros (--member ou todos) e abre o primeiro.
+func openZip(file string, in inputOpts) (*zipSource, error) {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("zip: %w", err)
+	}
+	s := &zipSource{zr: zr, in: in}
+	for _, f := range zr.File {
+		name := f.Name
+		if f.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+		if in.member != "" {
+			ok, err := matchMember(in.member, name)
+			if err != nil {
+				zr.Close()
+				return nil, fmt.Errorf("--member: %w", err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		s.members = append(s.members, f)
+		s.totalBytes += int64(f.UncompressedSize64)
+	}
+	if len(s.members) == 0 {
+		zr.Close()
+		if in.member != "" {
+			return nil, fmt.Errorf("zip: nenhum membro corresponde a %q", in.member)
+		}
+		return nil, errors.New("zip: arquivo vazio")
+	}
+	if err := s.advance(); err != nil {
+		s.close()
+		return nil, err
+	}
+	s.hdr = s.cur.header()
+	return s, nil
+}
+
+// matchMember compara --member com o caminho do membro ou só com o nome do arquivo.
+func matchMember(pattern, name string) (bool, error) {
+	for _, candidate := range []string{name, path.Base(name)} {
+		ok, err := path.Match(pattern, candidate)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// advance fecha o membro atual e abre o próximo (io.EOF se acabaram).
+func (s *zipSource) advance() error {
+	if s.rc != nil {
+		s.doneBytes += s.counter.n
+		s.rc.Close()
+		s.rc, s.cur = nil, nil
+	}
+	if s.next >= len(s.members) {
+		return io.EOF
+	}
+	f := s.members[s.next]
+	s.next++
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("zip %s: %w", f.Name, err)
+	}
+	s.rc = rc
+	s.counter = &countingReader{r: rc}
+	if s.cur, err = newCSVSource(s.counter, s.in); err != nil {
+		return fmt.Errorf("zip %s: %w", f.Name, err)
+	}
+	// Mesmas colunas em todos os membros (o mapeamento é resolvido no 1º cabeçalho)
+	if s.hdr != nil && !sameHeader(s.hdr, s.cur.header()) {
+		return fmt.Errorf("zip %s: cabeçalho diferente do primeiro membro", f.Name)
+	}
+	return nil
+}
+
+func sameHeader(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if normalizeHeader(a[i]) != normalizeHeader(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *zipSource) header() []string { return s.hdr }
+
+func (s *zipSource) line() int {
+	if s.cur == nil {
+		return 0
+	}
+	return s.cur.line()
+}
+
+func (s *zipSource) read() ([]string, error) {
+	for s.cur != nil {
+		rec, err := s.cur.read()
+		if err != io.EOF {
+			return rec, err
+		}
+		if err := s.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return nil, io.EOF
+}
+
+func (s *zipSource) done() float64 {
+	n := s.doneBytes
+	if s.counter != nil && s.rc != nil {
+		n += s.counter.n
+	}
+	return fraction(n, s.totalBytes)
+}
+
+func (s *zipSource) close() error {
+	if s.rc != nil {
+		s.rc.Close()
+		s.rc = nil
+	}
+	return s.zr.Close()
+}
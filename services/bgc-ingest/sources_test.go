@@ -0,0 +1,203 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gzipFile grava content comprimido com gzip.
+func gzipFile(t *testing.T, name, content string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(content))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return writeFile(t, name, buf.String())
+}
+
+// zipFile grava um .zip com os membros na ordem dada (nome, conteúdo).
+func zipFile(t *testing.T, name string, members ...[2]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, m := range members {
+		w, err := zw.Create(m[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(m[1]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readAll lê todos os registros da origem.
+func readAll(t *testing.T, src recordSource) [][]string {
+	t.Helper()
+	var out [][]string
+	for {
+		rec, err := src.read()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		out = append(out, append([]string(nil), rec...))
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		path func(t *testing.T) string
+		want string
+	}{
+		{name: "csv", path: func(t *testing.T) string { return writeFile(t, "a.csv", "a;b\n1;2\n") }, want: formatCSV},
+		{name: "empty", path: func(t *testing.T) string { return writeFile(t, "a.csv", "") }, want: formatCSV},
+		{name: "gzip", path: func(t *testing.T) string { return gzipFile(t, "a.csv.gz", "a\n") }, want: formatGzip},
+		{name: "zip", path: func(t *testing.T) string { return zipFile(t, "a.zip", [2]string{"a.csv", "a\n"}) }, want: formatZip},
+		{name: "parquet", path: func(t *testing.T) string { return "testdata/exportacao.parquet" }, want: formatParquet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := detectFormat(tt.path(t))
+			if err != nil || got != tt.want {
+				t.Errorf("detectFormat = (%q, %v), want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenRecordsCSV(t *testing.T) {
+	content := "\ufeff\"CO_ANO\";\"CO_PAIS\"\n2024;160\n2023\n"
+	want := [][]string{{"2024", "160"}, {"2023"}}
+
+	for name, path := range map[string]func(t *testing.T) string{
+		"csv":  func(t *testing.T) string { return writeFile(t, "EXP_2024.csv", content) },
+		"gzip": func(t *testing.T) string { return gzipFile(t, "EXP_2024.csv.gz", content) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			src, err := openRecords(path(t), inputOpts{sep: ';', header: true})
+			if err != nil {
+				t.Fatalf("openRecords: %v", err)
+			}
+			defer src.close()
+			if got := src.header(); !reflect.DeepEqual(got, []string{"CO_ANO", "CO_PAIS"}) {
+				t.Errorf("header = %q", got)
+			}
+			if got := readAll(t, src); !reflect.DeepEqual(got, want) {
+				t.Errorf("registros = %q, want %q", got, want)
+			}
+			if src.line() != 3 || src.done() != 1 {
+				t.Errorf("line = %d, done = %v", src.line(), src.done())
+			}
+		})
+	}
+}
+
+func TestOpenRecordsZip(t *testing.T) {
+	path := zipFile(t, "EXP_2024.zip",
+		[2]string{"EXP_2024.csv", "CO_ANO;CO_PAIS\n2024;160\n"},
+		[2]string{"__MACOSX/._EXP_2024.csv", "lixo"},
+		[2]string{"dados/EXP_2024_B.csv", "co_ano;co_pais\n2024;249\n2024;63\n"},
+		[2]string{"LEIAME.txt", "CO_ANO;CO_PAIS\n"},
+	)
+
+	tests := []struct {
+		name    string
+		member  string
+		want    [][]string
+		wantErr string
+	}{
+		{name: "all members", want: [][]string{{"2024", "160"}, {"2024", "249"}, {"2024", "63"}}},
+		{name: "member by base name", member: "EXP_2024_B.csv", want: [][]string{{"2024", "249"}, {"2024", "63"}}},
+		{name: "member glob", member: "*.csv", want: [][]string{{"2024", "160"}, {"2024", "249"}, {"2024", "63"}}},
+		{name: "no match", member: "IMP_*.csv", wantErr: "nenhum membro"},
+		{name: "bad glob", member: "[", wantErr: "--member"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := openRecords(path, inputOpts{member: tt.member, sep: ';', header: true})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("openRecords: %v", err)
+			}
+			defer src.close()
+			if got := readAll(t, src); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("registros = %q, want %q", got, tt.want)
+			}
+			if src.done() != 1 {
+				t.Errorf("done = %v, want 1", src.done())
+			}
+		})
+	}
+}
+
+func TestOpenRecordsZipHeaderMismatch(t *testing.T) {
+	path := zipFile(t, "EXP_2024.zip",
+		[2]string{"a.csv", "CO_ANO;CO_PAIS\n2024;160\n"},
+		[2]string{"b.csv", "CO_ANO;VL_FOB\n2024;10\n"},
+	)
+	src, err := openRecords(path, inputOpts{sep: ';', header: true})
+	if err != nil {
+		t.Fatalf("openRecords: %v", err)
+	}
+	defer src.close()
+
+	if _, err := src.read(); err != nil {
+		t.Fatalf("1º registro: %v", err)
+	}
+	if _, err := src.read(); err == nil || !strings.Contains(err.Error(), "cabeçalho diferente") {
+		t.Errorf("err = %v, want cabeçalho diferente", err)
+	}
+}
+
+func TestOpenRecordsMemberOutsideZip(t *testing.T) {
+	if _, err := openRecords(writeFile(t, "a.csv", "a\n"), inputOpts{member: "x.csv", sep: ';'}); err == nil {
+		t.Error("--member em CSV: esperava erro")
+	}
+	if _, err := openRecords(writeFile(t, "a.csv", "a\n"), inputOpts{format: "xml", sep: ';'}); err == nil {
+		t.Error("--format inválido: esperava erro")
+	}
+}
+
+func TestSameHeader(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		want bool
+	}{
+		{a: []string{"CO_ANO", "CO_PAIS"}, b: []string{" co_ano", `"CO_PAIS"`}, want: true},
+		{a: []string{"\ufeffCO_ANO"}, b: []string{"CO_ANO"}, want: true},
+		{a: []string{"CO_ANO"}, b: []string{"CO_ANO", "CO_PAIS"}, want: false},
+		{a: []string{"CO_ANO", "CO_PAIS"}, b: []string{"CO_PAIS", "CO_ANO"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := sameHeader(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameHeader(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,611 @@
+// sync.go — comando sync-comexstat (busca mês a mês no Integration Gateway)
+//
+// Em vez de arquivo montado no pod, chama o connector comexstat do gateway
+// (exportacao_mes / importacao_mes) para cada mês de --from a --to e grava em
+// stg.exportacao / stg.importacao pelo mesmo pipeline das cargas (loader.go):
+// validação e rejeitos, lote com proveniência (batches.go) e upsert agregado por
+// (ano, mês, país, NCM, UF). Cada mês buscado substitui o mês inteiro no destino.
+//
+// Watermark (stg.sync_watermark, migration 0016): hash do conteúdo de cada mês já
+// sincronizado. Sem --from, a execução começa no mês seguinte ao último sincronizado;
+// meses já sincronizados só são buscados de novo se estiverem entre os --revise mais
+// recentes (o MDIC revisa os últimos meses) e só são regravados se o hash mudou.
+// Essas novas buscas (e as de --full) ignoram o cache do gateway (?refresh=true):
+// os ttl_rules do connector guardariam a versão antiga por até 7 dias.
+//
+// Países: o connector devolve CO_PAIS numérico; --countries (PAIS.csv) é obrigatório
+// e um código fora da tabela interrompe o mês (nada é gravado) em vez de virar ZZ.
+//
+// Paginação: o connector devolve uma página por chamada (?page=N) e total_records;
+// as páginas são buscadas até somar total_records. Um mês que não fecha o total
+// interrompe a execução antes de apagar o mês no destino ou gravar o watermark.
+//
+// Rate limit: no máximo --rate chamadas por minuto (o connector aceita 4/min);
+// 429/503 do gateway respeitam Retry-After e são repetidos até --retries vezes.
+//
+// Exemplo:
+//   bgc-ingest sync-comexstat --from 2023-01 --to 2024-12
+//   bgc-ingest sync-comexstat                 # incremental (cron)
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// syncConnector connector do gateway e endpoint por fluxo.
+const syncConnector = "comexstat"
+
+var syncEndpoints = map[string]string{
+	"exp": "exportacao_mes",
+	"imp": "importacao_mes",
+}
+
+// Status de um mês no resultado do sync
+const (
+	syncLoaded       = "loaded"        // mês novo gravado
+	syncRevised      = "revised"       // mês já sincronizado, conteúdo mudou
+	syncUnchanged    = "unchanged"     // buscado de novo, mesmo conteúdo
+	syncUpToDate     = "up_to_date"    // já sincronizado e fora da janela de revisão
+	syncNotPublished = "not_published" // upstream sem dados para o mês (404)
+)
+
+// syncOpts opções de sync-comexstat.
+type syncOpts struct {
+	from      time.Time // zero = a partir do watermark
+	to        time.Time
+	flows     []string
+	countries string // PAIS.csv (CO_PAIS numérico -> ISO alpha-2)
+	revise    int
+	full      bool
+	rate      float64
+	retries   int
+	timeout   time.Duration
+	copy      copyOpts
+}
+
+// parseSyncOpts lê as flags; --to default = mês anterior (último mês fechado).
+func parseSyncOpts(args []string) (*syncOpts, error) {
+	fs := flag.NewFlagSet("sync-comexstat", flag.ContinueOnError)
+	from := fs.String("from", "", "primeiro mês YYYY-MM (default: após o último mês sincronizado)")
+	to := fs.String("to", "", "último mês YYYY-MM (default: mês anterior)")
+	flows := fs.String("flow", "exp,imp", "fluxos: exp, imp ou exp,imp")
+	countries := fs.String("countries", "", "tabela PAIS.csv da ComexStat (obrigatório: o connector envia CO_PAIS numérico)")
+	revise := fs.Int("revise", 3, "meses mais recentes buscados de novo para captar revisões do MDIC")
+	full := fs.Bool("full", false, "busca de novo todos os meses do intervalo")
+	rate := fs.Float64("rate", 4, "máximo de chamadas ao gateway por minuto")
+	retries := fs.Int("retries", 5, "tentativas extras em 429/503 do gateway")
+	timeout := fs.Duration("timeout", 2*time.Minute, "timeout por chamada ao gateway")
+	copyOptions := copyFlags(fs)
+	fs.SetOutput(new(nopWriter))
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	opts := &syncOpts{countries: *countries, revise: *revise, full: *full, rate: *rate, retries: *retries, timeout: *timeout}
+	var err error
+	if *from != "" {
+		if opts.from, err = parseMonth(*from); err != nil {
+			return nil, fmt.Errorf("--from: %w", err)
+		}
+	}
+	opts.to = firstOfMonth(time.Now()).AddDate(0, -1, 0)
+	if *to != "" {
+		if opts.to, err = parseMonth(*to); err != nil {
+			return nil, fmt.Errorf("--to: %w", err)
+		}
+	}
+	if !opts.from.IsZero() && opts.from.After(opts.to) {
+		return nil, errors.New("--from depois de --to")
+	}
+	for _, f := range strings.Split(*flows, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if _, ok := syncEndpoints[f]; !ok {
+			return nil, fmt.Errorf("--flow inválido: %q (use exp, imp ou exp,imp)", f)
+		}
+		opts.flows = append(opts.flows, f)
+	}
+	if opts.countries == "" {
+		return nil, errors.New("--countries obrigatório (tabela PAIS.csv da ComexStat)")
+	}
+	if opts.rate <= 0 {
+		return nil, errors.New("--rate deve ser > 0")
+	}
+	if opts.revise < 0 || opts.retries < 0 {
+		return nil, errors.New("--revise e --retries devem ser >= 0")
+	}
+
+	copyOpt, err := copyOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts.copy = copyOpt
+	return opts, nil
+}
+
+// parseMonth lê YYYY-MM.
+func parseMonth(s string) (time.Time, error) {
+	t, err := time.Parse("2006-01", strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("mês inválido %q (use YYYY-MM)", s)
+	}
+	return t, nil
+}
+
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// cmdSyncComexStat sincroniza os meses pedidos (um JSON por mês + resumo no stdout).
+func cmdSyncComexStat(args []string) error {
+	opts, err := parseSyncOpts(args)
+	if err != nil {
+		return err
+	}
+	client, err := newGatewayClient(opts.timeout)
+	if err != nil {
+		return err
+	}
+	countries, err := loadCountryMap(opts.countries)
+	if err != nil {
+		return err
+	}
+
+	pool, err := connect()
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	if err := saveCountryMap(ctx, pool, countries); err != nil {
+		return err
+	}
+	marks, err := loadWatermarks(ctx, pool)
+	if err != nil {
+		return fmt.Errorf("ler watermark: %w", err)
+	}
+
+	// Janela de revisão: os --revise meses fechados mais recentes
+	reviseFrom := firstOfMonth(time.Now()).AddDate(0, -opts.revise, 0)
+	if opts.from, err = syncStart(marks, opts, reviseFrom); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	pace := &pacer{every: time.Duration(float64(time.Minute) / opts.rate)}
+	counts := map[string]int{}
+
+	for m := opts.from; !m.After(opts.to); m = m.AddDate(0, 1, 0) {
+		for _, flow := range opts.flows {
+			mark, synced := marks[watermarkKey(flow, m)]
+			out := map[string]any{"flow": flow, "month": m.Format("2006-01")}
+
+			if !needsFetch(opts, synced, m, reviseFrom) {
+				out["status"] = syncUpToDate
+				counts[syncUpToDate]++
+				printJSON(out)
+				continue
+			}
+
+			rows, total, err := fetchMonth(ctx, client, pace, opts, flow, m, synced || opts.full)
+			var ge *gatewayError
+			if errors.As(err, &ge) && ge.notFound() {
+				out["status"] = syncNotPublished
+				counts[syncNotPublished]++
+				printJSON(out)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", flow, m.Format("2006-01"), err)
+			}
+			if codes := unmappedCountries(rows, countries); len(codes) > 0 {
+				return fmt.Errorf("%s %s: CO_PAIS fora de %s: %s (atualize a tabela de países)",
+					flow, m.Format("2006-01"), opts.countries, strings.Join(codes, ", "))
+			}
+			out["rows"] = len(rows)
+
+			hash := contentHash(rows)
+			status, batchID := syncLoaded, ""
+			switch {
+			case synced && mark.hash == hash && !opts.copy.force:
+				status, batchID = syncUnchanged, mark.batchID
+			default:
+				res, err := loadMonth(ctx, pool, opts, flow, m, rows, hash, countries)
+				if err != nil {
+					return fmt.Errorf("%s %s: %w", flow, m.Format("2006-01"), err)
+				}
+				batchID = res.BatchID
+				switch {
+				case !res.Finished:
+					status = syncUnchanged // mesmo conteúdo já carregado em outro lote
+				case synced:
+					status = syncRevised
+				}
+				if res.Rejected > 0 {
+					out["rejected"] = res.Rejected
+					out["rejected_by_rule"] = res.RejectRules
+					out["rejects_file"] = res.RejectsFile
+				}
+			}
+			if err := saveWatermark(ctx, pool, flow, m, int64(len(rows)), total, hash, batchID); err != nil {
+				return fmt.Errorf("gravar watermark: %w", err)
+			}
+			out["status"] = status
+			out["batch_id"] = batchID
+			counts[status]++
+			printJSON(out)
+		}
+	}
+
+	summary := map[string]any{
+		"summary":  true,
+		"from":     opts.from.Format("2006-01"),
+		"to":       opts.to.Format("2006-01"),
+		"requests": pace.calls,
+		"elapsed":  time.Since(start).Round(time.Millisecond).String(),
+	}
+	for status, n := range counts {
+		summary[status] = n
+	}
+	printJSON(summary)
+	return nil
+}
+
+// syncStart primeiro mês da execução: --from ou o mês seguinte ao watermark,
+// recuado até o início da janela de revisão.
+func syncStart(marks map[string]watermark, opts *syncOpts, reviseFrom time.Time) (time.Time, error) {
+	if !opts.from.IsZero() {
+		return opts.from, nil
+	}
+	next, ok := nextUnsynced(marks, opts.flows)
+	if !ok {
+		return time.Time{}, errors.New("--from obrigatório na primeira sincronização")
+	}
+	if reviseFrom.Before(next) {
+		next = reviseFrom
+	}
+	return next, nil
+}
+
+// needsFetch mês novo, dentro da janela de revisão ou --full: busca no gateway.
+func needsFetch(opts *syncOpts, synced bool, m, reviseFrom time.Time) bool {
+	return !synced || opts.full || !m.Before(reviseFrom)
+}
+
+// fetchMonth busca todas as páginas de um mês (o connector pagina por ?page=N e informa
+// total_records). Mês incompleto é erro: nada é apagado no destino nem vai ao watermark.
+// refresh=true ignora o cache do gateway (mês já sincronizado: captar revisões).
+func fetchMonth(ctx context.Context, client *gatewayClient, pace *pacer, opts *syncOpts, flow string, m time.Time, refresh bool) ([]map[string]any, int64, error) {
+	rows := []map[string]any{}
+	var total int64
+	firstHash := ""
+	for page := 1; ; page++ {
+		pageRows, pageTotal, err := fetchPage(ctx, client, pace, opts, flow, m, page, refresh)
+		var ge *gatewayError
+		if page > 1 && errors.As(err, &ge) && ge.notFound() {
+			// 404 só significa "mês não publicado" na primeira página
+			return nil, 0, fmt.Errorf("página %d: %s", page, ge.Error())
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(pageRows) == 0 {
+			break
+		}
+		hash := contentHash(pageRows)
+		if page == 1 {
+			firstHash = hash
+		} else if hash == firstHash {
+			return nil, 0, fmt.Errorf("página %d repete a página 1 (o connector ignora ?page?)", page)
+		}
+		rows = append(rows, pageRows...)
+		total = pageTotal
+		if int64(len(rows)) >= total {
+			break
+		}
+	}
+	if total > int64(len(rows)) {
+		return nil, 0, fmt.Errorf("mês incompleto: %d de %d linhas (total_records)", len(rows), total)
+	}
+	return rows, total, nil
+}
+
+// fetchPage busca uma página do mês, respeitando o rate limit e repetindo em 429/503.
+func fetchPage(ctx context.Context, client *gatewayClient, pace *pacer, opts *syncOpts, flow string, m time.Time, page int, refresh bool) ([]map[string]any, int64, error) {
+	params := map[string]any{"ano": m.Year(), "mes": int(m.Month()), "page": page}
+	for attempt := 1; ; attempt++ {
+		if err := pace.wait(ctx); err != nil {
+			return nil, 0, err
+		}
+		data, err := client.execute(ctx, syncConnector, syncEndpoints[flow], params, refresh)
+		if err == nil {
+			return monthRows(data)
+		}
+
+		var ge *gatewayError
+		if !errors.As(err, &ge) || !ge.retryable() || attempt > opts.retries {
+			return nil, 0, err
+		}
+		wait := ge.retryAfter
+		if wait == 0 {
+			wait = time.Duration(attempt) * pace.every
+		}
+		retry, _ := json.Marshal(map[string]any{
+			"retry": attempt, "flow": flow, "month": m.Format("2006-01"), "page": page,
+			"error": ge.Error(), "wait": wait.String(),
+		})
+		fmt.Fprintln(os.Stderr, string(retry))
+		if err := sleepCtx(ctx, wait); err != nil {
+			return nil, 0, err
+		}
+	}
+}
+
+// monthRows extrai `rows` (linhas normalizadas pelo connector) e `total_records`.
+func monthRows(data map[string]any) ([]map[string]any, int64, error) {
+	items, ok := data["rows"].([]any)
+	if !ok && data["rows"] != nil {
+		return nil, 0, errors.New("resposta do gateway: rows não é uma lista")
+	}
+	rows := make([]map[string]any, 0, len(items))
+	for i, item := range items {
+		row, ok := item.(map[string]any)
+		if !ok {
+			return nil, 0, fmt.Errorf("resposta do gateway: rows[%d] não é um objeto", i)
+		}
+		rows = append(rows, row)
+	}
+	total, _ := strconv.ParseInt(jsonString(data["total_records"]), 10, 64)
+	return rows, total, nil
+}
+
+// unmappedCountries CO_PAIS das linhas que não estão na tabela de países (ordenados).
+func unmappedCountries(rows []map[string]any, countries countryMap) []string {
+	seen := map[string]bool{}
+	var codes []string
+	for _, row := range rows {
+		code := jsonString(row["co_pais"])
+		if _, ok := countries.lookup(code); !ok && !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// contentHash sha256 das linhas (json.Marshal ordena as chaves: mesmo conteúdo, mesmo hash).
+func contentHash(rows []map[string]any) string {
+	b, _ := json.Marshal(rows)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadMonth grava as linhas de um mês como um lote (substitui o mês no destino).
+func loadMonth(ctx context.Context, pool *pgxpool.Pool, opts *syncOpts, flow string, m time.Time, rows []map[string]any,
+	hash string, countries countryMap) (*copyResult, error) {
+	table, err := parseTable(comexTables[flow])
+	if err != nil {
+		return nil, err
+	}
+
+	// Métricas opcionais: só as que o upstream enviou (ex.: vl_frete só na importação)
+	columns := []string{"co_ano", "co_mes", "co_pais", "sg_uf_ncm", "co_ncm", "co_sh4", "co_sh2", "vl_fob", "kg_liquido"}
+	sums := []string{"vl_fob", "kg_liquido"}
+	var optional []string
+	for _, name := range []string{"QT_ESTAT", "VL_FRETE", "VL_SEGURO"} {
+		for _, row := range rows {
+			if row[strings.ToLower(name)] != nil {
+				optional = append(optional, name)
+				columns = append(columns, strings.ToLower(name))
+				sums = append(sums, strings.ToLower(name))
+				break
+			}
+		}
+	}
+
+	validator := newRowValidator(columns)
+	unmapped := map[string]int64{} // vazio: códigos já conferidos em unmappedCountries
+	i := 0
+	next := func() ([]any, error) {
+		for i < len(rows) {
+			raw := rows[i]
+			i++
+			get := func(col string) string {
+				return jsonString(raw[strings.ToLower(col)])
+			}
+
+			row, err := comexRow(get, countries, unmapped, m.Year(), optional)
+			if err == nil && row[1] != int(m.Month()) {
+				err = &fieldError{"co_mes", ruleMonthFetch, fmt.Sprintf("mês %v diferente do mês buscado %d", row[1], m.Month())}
+			}
+			if err == nil {
+				err = validator.check(row)
+			}
+			if err != nil {
+				record, _ := json.Marshal(raw)
+				return nil, rejectRow(i, []string{string(record)}, err)
+			}
+			return row, nil
+		}
+		return nil, io.EOF
+	}
+	done := func() float64 {
+		if len(rows) == 0 {
+			return 0
+		}
+		return float64(i) / float64(len(rows))
+	}
+
+	// Carga do zero: o mês buscado substitui o mês no destino (linhas revisadas/removidas)
+	prepare := func(ctx context.Context, tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE co_ano = $1 AND co_mes = $2`, table.Sanitize()),
+			m.Year(), int(m.Month()))
+		return err
+	}
+
+	return runCopy(ctx, pool, copyJob{
+		kind:        "gateway",
+		source:      fmt.Sprintf("%s.%s@%s", syncConnector, syncEndpoints[flow], m.Format("2006-01")),
+		fingerprint: hash,
+		hash:        hash,
+		table:       table,
+		columns:     columns,
+		next:        next,
+		done:        done,
+		opts:        opts.copy,
+		merge:       &mergeSpec{conflict: comexConflict, sum: sums},
+		prepare:     prepare,
+	})
+}
+
+// jsonString valor de uma coluna da resposta como texto (nil = vazio).
+func jsonString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(x)
+	case json.Number:
+		return x.String()
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Rate limit
+////////////////////////////////////////////////////////////////////////////////
+
+// pacer espaça as chamadas ao gateway em pelo menos `every`.
+type pacer struct {
+	every time.Duration
+	last  time.Time
+	calls int
+}
+
+func (p *pacer) wait(ctx context.Context) error {
+	if !p.last.IsZero() {
+		if d := p.every - time.Since(p.last); d > 0 {
+			if err := sleepCtx(ctx, d); err != nil {
+				return err
+			}
+		}
+	}
+	p.last = time.Now()
+	p.calls++
+	return nil
+}
+
+// sleepCtx espera d ou o cancelamento do ctx.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Watermark (stg.sync_watermark)
+////////////////////////////////////////////////////////////////////////////////
+
+type watermark struct {
+	month   time.Time
+	hash    string
+	batchID string
+}
+
+func watermarkKey(flow string, m time.Time) string {
+	return flow + "@" + m.Format("2006-01")
+}
+
+// loadWatermarks meses já sincronizados do connector, por fluxo e mês.
+func loadWatermarks(ctx context.Context, pool *pgxpool.Pool) (map[string]watermark, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT flow, co_ano, co_mes, content_hash, COALESCE(batch_id::text, '')
+		FROM stg.sync_watermark
+		WHERE source = $1`, syncConnector)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	marks := map[string]watermark{}
+	for rows.Next() {
+		var flow string
+		var year, month int
+		var w watermark
+		if err := rows.Scan(&flow, &year, &month, &w.hash, &w.batchID); err != nil {
+			return nil, err
+		}
+		w.month = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		marks[watermarkKey(flow, w.month)] = w
+	}
+	return marks, rows.Err()
+}
+
+// nextUnsynced mês seguinte ao último sincronizado (o menor entre os fluxos).
+// ok=false se algum fluxo nunca foi sincronizado.
+func nextUnsynced(marks map[string]watermark, flows []string) (time.Time, bool) {
+	var next time.Time
+	for _, flow := range flows {
+		var last time.Time
+		for key, w := range marks {
+			if strings.HasPrefix(key, flow+"@") && w.month.After(last) {
+				last = w.month
+			}
+		}
+		if last.IsZero() {
+			return time.Time{}, false
+		}
+		if n := last.AddDate(0, 1, 0); next.IsZero() || n.Before(next) {
+			next = n
+		}
+	}
+	return next, true
+}
+
+// saveWatermark registra a busca do mês; changed_at só muda se o conteúdo mudou.
+func saveWatermark(ctx context.Context, pool *pgxpool.Pool, flow string, m time.Time, rows, total int64, hash, batchID string) error {
+	_, err := pool.Exec(ctx, `
+		INSERT INTO stg.sync_watermark (source, flow, co_ano, co_mes, rows_fetched, total_records, content_hash, batch_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::uuid)
+		ON CONFLICT (source, flow, co_ano, co_mes) DO UPDATE SET
+			changed_at    = CASE WHEN stg.sync_watermark.content_hash = EXCLUDED.content_hash
+			                     THEN stg.sync_watermark.changed_at ELSE now() END,
+			rows_fetched  = EXCLUDED.rows_fetched,
+			total_records = EXCLUDED.total_records,
+			content_hash  = EXCLUDED.content_hash,
+			batch_id      = COALESCE(EXCLUDED.batch_id, stg.sync_watermark.batch_id),
+			fetched_at    = now()`,
+		syncConnector, flow, m.Year(), int(m.Month()), rows, total, hash, batchID)
+	return err
+}
+
+// printJSON imprime uma linha JSON no stdout.
+func printJSON(v any) {
+	b, _ := json.Marshal(v)
+	fmt.Println(string(b))
+}
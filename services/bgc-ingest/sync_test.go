@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func month(s string) time.Time {
+	t, err := parseMonth(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseSyncOpts(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantFrom  string
+		wantTo    string
+		wantFlows []string
+		wantErr   bool
+	}{
+		{
+			name:     "explicit range",
+			args:     []string{"--countries=PAIS.csv", "--from=2024-01", "--to=2024-03", "--flow=imp, EXP"},
+			wantFrom: "2024-01", wantTo: "2024-03", wantFlows: []string{"imp", "exp"},
+		},
+		{
+			name:   "defaults",
+			args:   []string{"--countries=PAIS.csv"},
+			wantTo: firstOfMonth(time.Now()).AddDate(0, -1, 0).Format("2006-01"), wantFlows: []string{"exp", "imp"},
+		},
+		{name: "missing countries", args: []string{"--from=2024-01"}, wantErr: true},
+		{name: "invalid month", args: []string{"--countries=PAIS.csv", "--from=2024-13"}, wantErr: true},
+		{name: "from after to", args: []string{"--countries=PAIS.csv", "--from=2024-05", "--to=2024-03"}, wantErr: true},
+		{name: "invalid flow", args: []string{"--countries=PAIS.csv", "--flow=exp,bal"}, wantErr: true},
+		{name: "zero rate", args: []string{"--countries=PAIS.csv", "--rate=0"}, wantErr: true},
+		{name: "negative revise", args: []string{"--countries=PAIS.csv", "--revise=-1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseSyncOpts(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("esperava erro, got %+v", opts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSyncOpts: %v", err)
+			}
+			from := ""
+			if !opts.from.IsZero() {
+				from = opts.from.Format("2006-01")
+			}
+			if from != tt.wantFrom || opts.to.Format("2006-01") != tt.wantTo || !reflect.DeepEqual(opts.flows, tt.wantFlows) {
+				t.Errorf("got from=%q to=%s flows=%v", from, opts.to.Format("2006-01"), opts.flows)
+			}
+		})
+	}
+}
+
+func TestNextUnsynced(t *testing.T) {
+	marks := map[string]watermark{
+		watermarkKey("exp", month("2024-01")): {month: month("2024-01")},
+		watermarkKey("exp", month("2024-03")): {month: month("2024-03")},
+		watermarkKey("imp", month("2024-02")): {month: month("2024-02")},
+	}
+
+	tests := []struct {
+		name   string
+		flows  []string
+		want   string
+		wantOK bool
+	}{
+		{name: "single flow", flows: []string{"exp"}, want: "2024-04", wantOK: true},
+		{name: "slowest flow wins", flows: []string{"exp", "imp"}, want: "2024-03", wantOK: true},
+		{name: "never synced", flows: []string{"exp", "bal"}, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := nextUnsynced(marks, tt.flows)
+			if ok != tt.wantOK || (ok && got.Format("2006-01") != tt.want) {
+				t.Errorf("nextUnsynced = (%v, %v), want (%s, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSyncStart(t *testing.T) {
+	marks := map[string]watermark{
+		watermarkKey("exp", month("2024-03")): {month: month("2024-03")},
+	}
+
+	tests := []struct {
+		name       string
+		from       string
+		flows      []string
+		reviseFrom string
+		want       string
+		wantErr    bool
+	}{
+		{name: "explicit from", from: "2023-01", flows: []string{"exp"}, reviseFrom: "2024-01", want: "2023-01"},
+		{name: "after watermark", flows: []string{"exp"}, reviseFrom: "2024-06", want: "2024-04"},
+		{name: "back to revise window", flows: []string{"exp"}, reviseFrom: "2024-01", want: "2024-01"},
+		{name: "first sync", flows: []string{"imp"}, reviseFrom: "2024-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &syncOpts{flows: tt.flows}
+			if tt.from != "" {
+				opts.from = month(tt.from)
+			}
+			got, err := syncStart(marks, opts, month(tt.reviseFrom))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("esperava erro, got %v", got)
+				}
+				return
+			}
+			if err != nil || got.Format("2006-01") != tt.want {
+				t.Errorf("syncStart = (%v, %v), want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeedsFetch(t *testing.T) {
+	reviseFrom := month("2024-04")
+
+	tests := []struct {
+		name   string
+		full   bool
+		synced bool
+		month  string
+		want   bool
+	}{
+		{name: "new month", synced: false, month: "2023-01", want: true},
+		{name: "synced before window", synced: true, month: "2024-03", want: false},
+		{name: "synced in window", synced: true, month: "2024-04", want: true},
+		{name: "full", full: true, synced: true, month: "2023-01", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsFetch(&syncOpts{full: tt.full}, tt.synced, month(tt.month), reviseFrom); got != tt.want {
+				t.Errorf("needsFetch = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonthRows(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      map[string]any
+		wantRows  int
+		wantTotal int64
+		wantErr   bool
+	}{
+		{
+			name:     "rows and total",
+			data:     map[string]any{"rows": []any{map[string]any{"co_pais": "160"}, map[string]any{}}, "total_records": json.Number("5")},
+			wantRows: 2, wantTotal: 5,
+		},
+		{name: "no rows", data: map[string]any{}, wantRows: 0},
+		{name: "rows not a list", data: map[string]any{"rows": "x"}, wantErr: true},
+		{name: "row not an object", data: map[string]any{"rows": []any{"x"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, total, err := monthRows(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("esperava erro")
+				}
+				return
+			}
+			if err != nil || len(rows) != tt.wantRows || total != tt.wantTotal {
+				t.Errorf("monthRows = (%d rows, %d, %v), want (%d, %d)", len(rows), total, err, tt.wantRows, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestUnmappedCountries(t *testing.T) {
+	countries := countryMap{160: "CN", 249: "US"}
+	rows := []map[string]any{
+		{"co_pais": json.Number("160")},
+		{"co_pais": "999"},
+		{"co_pais": json.Number("777")},
+		{"co_pais": "999"},
+		{"co_pais": "AR"},
+		{"co_pais": nil},
+	}
+
+	got := unmappedCountries(rows, countries)
+	want := []string{"", "777", "999"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unmappedCountries = %q, want %q", got, want)
+	}
+	if got := unmappedCountries(rows[:1], countries); got != nil {
+		t.Errorf("todos mapeados: got %q", got)
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	a := []map[string]any{{"co_pais": "160", "vl_fob": json.Number("10")}}
+	b := []map[string]any{{"vl_fob": json.Number("10"), "co_pais": "160"}}
+	c := []map[string]any{{"co_pais": "160", "vl_fob": json.Number("11")}}
+
+	if contentHash(a) != contentHash(b) {
+		t.Error("mesmo conteúdo em outra ordem de chaves: hash diferente")
+	}
+	if contentHash(a) == contentHash(c) {
+		t.Error("conteúdo diferente: mesmo hash")
+	}
+}
+
+func TestJSONString(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{in: nil, want: ""},
+		{in: " SP ", want: "SP"},
+		{in: json.Number("12345678901234567890"), want: "12345678901234567890"},
+		{in: 160.0, want: "160"},
+		{in: 1.5, want: "1.5"},
+		{in: true, want: "true"},
+	}
+	for _, tt := range tests {
+		if got := jsonString(tt.in); got != tt.want {
+			t.Errorf("jsonString(%#v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGatewayError(t *testing.T) {
+	tests := []struct {
+		err           gatewayError
+		wantRetryable bool
+		wantNotFound  bool
+	}{
+		{err: gatewayError{status: 0}, wantRetryable: true},
+		{err: gatewayError{status: http.StatusTooManyRequests, code: "rate_limited"}, wantRetryable: true},
+		{err: gatewayError{status: http.StatusServiceUnavailable, code: "circuit_open"}, wantRetryable: true},
+		{err: gatewayError{status: http.StatusGatewayTimeout}, wantRetryable: true},
+		{err: gatewayError{status: http.StatusBadGateway}},
+		{err: gatewayError{status: http.StatusNotFound, code: "upstream_not_found"}, wantNotFound: true},
+		{err: gatewayError{status: http.StatusNotFound, code: "connector_not_found"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := tt.err.retryable(); got != tt.wantRetryable {
+				t.Errorf("retryable = %v, want %v", got, tt.wantRetryable)
+			}
+			if got := tt.err.notFound(); got != tt.wantNotFound {
+				t.Errorf("notFound = %v, want %v", got, tt.wantNotFound)
+			}
+		})
+	}
+}
+
+func TestFetchMonth(t *testing.T) {
+	twoRows := `[{"co_pais": 160}, {"co_pais": 249}]`
+	tests := []struct {
+		name      string
+		responses []int    // status por chamada (200 = sucesso)
+		pages     []string // rows de cada página (default: uma página com 2 linhas)
+		total     int
+		retries   int
+		refresh   bool
+		wantCalls int
+		wantRows  int
+		wantErr   string
+		wantGwErr func(*gatewayError) bool
+	}{
+		{name: "success", responses: []int{200}, wantCalls: 1, wantRows: 2},
+		{name: "refresh", responses: []int{200}, refresh: true, wantCalls: 1, wantRows: 2},
+		{name: "retry rate limit", responses: []int{429, 503, 200}, retries: 2, wantCalls: 3, wantRows: 2},
+		{
+			name: "retries exhausted", responses: []int{429, 429}, retries: 1, wantCalls: 2,
+			wantGwErr: func(e *gatewayError) bool { return e.status == http.StatusTooManyRequests },
+		},
+		{
+			name: "not published", responses: []int{404}, retries: 3, wantCalls: 1,
+			wantGwErr: func(e *gatewayError) bool { return e.notFound() },
+		},
+		{
+			name: "pages", responses: []int{200, 429, 200}, retries: 1, total: 3,
+			pages:     []string{twoRows, `[{"co_pais": 63}]`},
+			wantCalls: 3, wantRows: 3,
+		},
+		{
+			name: "incomplete month", responses: []int{200, 200}, total: 3,
+			pages:     []string{twoRows, `[]`},
+			wantCalls: 2, wantErr: "mês incompleto: 2 de 3",
+		},
+		{
+			name: "page ignored", responses: []int{200, 200}, total: 4,
+			pages:     []string{twoRows, twoRows},
+			wantCalls: 2, wantErr: "repete a página 1",
+		},
+		{
+			name: "later page not found", responses: []int{200, 404}, total: 3,
+			pages:     []string{twoRows},
+			wantCalls: 2, wantErr: "página 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.pages == nil {
+				tt.pages, tt.total = []string{twoRows}, 2
+			}
+			calls, page := 0, 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/connectors/comexstat/exportacao_mes" {
+					t.Errorf("path = %s", r.URL.Path)
+				}
+				if got := r.URL.Query().Get("refresh") == "true"; got != tt.refresh {
+					t.Errorf("refresh = %v, want %v", got, tt.refresh)
+				}
+				if r.Header.Get("X-API-Key") != "key" {
+					t.Errorf("X-API-Key = %q", r.Header.Get("X-API-Key"))
+				}
+				var params map[string]any
+				_ = json.NewDecoder(r.Body).Decode(&params)
+				if params["ano"] != 2024.0 || params["mes"] != 3.0 {
+					t.Errorf("params = %v", params)
+				}
+
+				status := tt.responses[calls]
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				switch status {
+				case 200:
+					if params["page"] != float64(page+1) {
+						t.Errorf("page = %v, want %d", params["page"], page+1)
+					}
+					rows := "[]"
+					if page < len(tt.pages) {
+						rows = tt.pages[page]
+					}
+					page++
+					_, _ = fmt.Fprintf(w, `{"data": {"rows": %s, "total_records": %d}}`, rows, tt.total)
+				case 404:
+					w.WriteHeader(status)
+					_, _ = w.Write([]byte(`{"error": "not found", "code": "upstream_not_found"}`))
+				default:
+					w.WriteHeader(status)
+					_, _ = w.Write([]byte(`{"error": "slow down", "code": "rate_limited"}`))
+				}
+			}))
+			defer srv.Close()
+
+			client := &gatewayClient{baseURL: srv.URL, apiKey: "key", http: srv.Client()}
+			pace := &pacer{every: time.Millisecond}
+			opts := &syncOpts{retries: tt.retries}
+			rows, total, err := fetchMonth(context.Background(), client, pace, opts, "exp", month("2024-03"), tt.refresh)
+
+			if calls != tt.wantCalls || pace.calls != tt.wantCalls {
+				t.Errorf("calls = %d (pacer %d), want %d", calls, pace.calls, tt.wantCalls)
+			}
+			if tt.wantGwErr != nil {
+				var ge *gatewayError
+				if !errors.As(err, &ge) || !tt.wantGwErr(ge) {
+					t.Fatalf("err = %v", err)
+				}
+				return
+			}
+			if tt.wantErr != "" {
+				var ge *gatewayError
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || errors.As(err, &ge) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fetchMonth: %v", err)
+			}
+			if len(rows) != tt.wantRows || total != int64(tt.wantRows) || rows[0]["co_pais"] != json.Number("160") {
+				t.Errorf("rows = %v, total = %d", rows, total)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+# Gera testdata/exportacao.parquet (fixture de parquet_test.go) seguindo parquet.thrift
+# (compact protocol), só com a biblioteca padrão: python3 testdata/exportacao_parquet.py testdata/exportacao.parquet
+import struct, zlib, gzip, io, datetime, sys
+
+def uvarint(n):
+    out = bytearray()
+    while True:
+        b = n & 0x7f
+        n >>= 7
+        if n:
+            out.append(b | 0x80)
+        else:
+            out.append(b)
+            return bytes(out)
+
+def zz(n):
+    return uvarint((n << 1) ^ (n >> 63))
+
+# Compact protocol types
+BOOL_T, BOOL_F, I8, I16, I32, I64, DBL, BIN, LIST, SET, MAP, STRUCT = 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12
+
+class S:
+    """struct: lista de (id, tipo, valor)"""
+    def __init__(self, *fields):
+        self.fields = [f for f in fields if f is not None]
+
+def enc_value(typ, v):
+    if typ in (I16, I32, I64):
+        return zz(v)
+    if typ == I8:
+        return struct.pack('b', v)
+    if typ == BIN:
+        if isinstance(v, str):
+            v = v.encode()
+        return uvarint(len(v)) + v
+    if typ == STRUCT:
+        return enc_struct(v)
+    if typ == LIST:
+        etyp, items = v
+        h = bytes([(len(items) << 4) | etyp]) if len(items) < 15 else bytes([0xf0 | etyp]) + uvarint(len(items))
+        return h + b''.join(enc_value(etyp, i) for i in items)
+    raise ValueError(typ)
+
+def enc_struct(s):
+    out = bytearray()
+    last = 0
+    for fid, typ, v in sorted(s.fields, key=lambda f: f[0]):
+        if typ == 'bool':
+            typ, v = (BOOL_T if v else BOOL_F), None
+        delta = fid - last
+        if 0 < delta <= 15:
+            out.append((delta << 4) | typ)
+        else:
+            out.append(typ)
+            out += zz(fid)
+        last = fid
+        if v is not None:
+            out += enc_value(typ, v)
+    out.append(0)
+    return bytes(out)
+
+def snappy_literal(data):
+    # snappy válido só com literais: preâmbulo varint + tags literais
+    out = bytearray(uvarint(len(data)))
+    for i in range(0, len(data), 60):
+        chunk = data[i:i+60]
+        out.append((len(chunk) - 1) << 2)
+        out += chunk
+    return bytes(out)
+
+def compress(codec, data):
+    if codec == 0:
+        return data
+    if codec == 1:
+        return snappy_literal(data)
+    if codec == 2:
+        buf = io.BytesIO()
+        with gzip.GzipFile(fileobj=buf, mode='wb', mtime=0) as g:
+            g.write(data)
+        return buf.getvalue()
+    raise ValueError(codec)
+
+def bitpack(values, width):
+    # hybrid RLE/bit-packed: um run bit-packed com grupos de 8
+    groups = (len(values) + 7) // 8
+    vals = list(values) + [0] * (groups * 8 - len(values))
+    bits = 0
+    for i, v in enumerate(vals):
+        bits |= v << (i * width)
+    return uvarint((groups << 1) | 1) + bits.to_bytes(groups * width, 'little')
+
+def rle_run(value, count, width):
+    return uvarint(count << 1) + value.to_bytes((width + 7) // 8, 'little')
+
+# Tipos físicos
+BOOLEAN, INT32, INT64, INT96, FLOAT, DOUBLE, BYTE_ARRAY, FLBA = range(8)
+
+def plain(phys, values, length=0):
+    out = bytearray()
+    for v in values:
+        if phys == INT32: out += struct.pack('<i', v)
+        elif phys == INT64: out += struct.pack('<q', v)
+        elif phys == DOUBLE: out += struct.pack('<d', v)
+        elif phys == BYTE_ARRAY:
+            b = v.encode(); out += struct.pack('<I', len(b)) + b
+        elif phys == FLBA: out += v.to_bytes(length, 'big', signed=True)
+        else: raise ValueError(phys)
+    return bytes(out)
+
+day = lambda s: (datetime.date.fromisoformat(s) - datetime.date(1970, 1, 1)).days
+micros = lambda s: int(datetime.datetime.fromisoformat(s).replace(tzinfo=datetime.timezone.utc).timestamp()) * 1_000_000
+
+groups = [
+    [  # co_ano, co_pais, vl_fob (decimal 2), data, kg_liquido, embarque, frete (fixed decimal 2)
+        (2024, 'CN', 150025, day('2024-03-15'), 10.5, micros('2024-03-15T12:30:00'), -150),
+        (2024, 'US', 5, day('2024-03-16'), None, micros('2024-03-16T00:00:00'), 12345),
+        (2024, None, -1, day('2024-03-17'), 0.0, micros('2024-03-17T23:59:59'), 0),
+    ],
+    [
+        (2023, 'CN', 0, day('2023-12-31'), 1000000.0, micros('2023-12-31T08:00:00'), 7),
+        (2023, 'AR', 99, day('2023-01-01'), 2.25, micros('2023-01-01T00:00:01'), -1),
+    ],
+]
+
+# (nome, físico, opcional, codec, estilo de página, schema extra)
+columns = [
+    ('co_ano',     INT32,      False, 1, 'v1'),
+    ('co_pais',    BYTE_ARRAY, True,  1, 'dict'),
+    ('vl_fob',     INT64,      False, 2, 'v2'),
+    ('data',       INT32,      False, 0, 'v1'),
+    ('kg_liquido', DOUBLE,     True,  0, 'v2'),
+    ('embarque',   INT64,      False, 0, 'v1'),
+    ('vl_frete',   FLBA,       False, 0, 'v1'),
+]
+FLBA_LEN = 4
+
+schema = [S((4, BIN, 'schema'), (5, I32, len(columns)))]
+schema.append(S((1, I32, INT32), (3, I32, 0), (4, BIN, 'co_ano')))
+schema.append(S((1, I32, BYTE_ARRAY), (3, I32, 1), (4, BIN, 'co_pais'), (6, I32, 0),
+                (10, STRUCT, S((1, STRUCT, S())))))                       # LogicalType STRING
+schema.append(S((1, I32, INT64), (3, I32, 0), (4, BIN, 'vl_fob'),
+                (10, STRUCT, S((5, STRUCT, S((1, I32, 2), (2, I32, 18)))))))  # DECIMAL(18,2)
+schema.append(S((1, I32, INT32), (3, I32, 0), (4, BIN, 'data'), (6, I32, 6)))  # ConvertedType DATE
+schema.append(S((1, I32, DOUBLE), (3, I32, 1), (4, BIN, 'kg_liquido')))
+schema.append(S((1, I32, INT64), (3, I32, 0), (4, BIN, 'embarque'),
+                (10, STRUCT, S((8, STRUCT, S((1, 'bool', True), (2, STRUCT, S((2, STRUCT, S())))))))))  # TIMESTAMP micros
+schema.append(S((1, I32, FLBA), (2, I32, FLBA_LEN), (3, I32, 0), (4, BIN, 'vl_frete'),
+                (6, I32, 5), (7, I32, 2), (8, I32, 9)))                  # ConvertedType DECIMAL(9,2)
+
+out = bytearray(b'PAR1')
+row_groups = []
+for rows in groups:
+    chunks = []
+    for ci, (name, phys, optional, codec, style) in enumerate(columns):
+        values = [r[ci] for r in rows]
+        present = [v for v in values if v is not None]
+        defs = [0 if v is None else 1 for v in values]
+        start = len(out)
+        dict_offset = None
+
+        if style == 'dict':
+            dictionary = sorted(set(present))
+            raw = plain(phys, dictionary)
+            comp = compress(codec, raw)
+            ph = S((1, I32, 2), (2, I32, len(raw)), (3, I32, len(comp)),
+                   (7, STRUCT, S((1, I32, len(dictionary)), (2, I32, 0))))
+            dict_offset = len(out)
+            out += enc_struct(ph) + comp
+            width = max(1, (len(dictionary) - 1).bit_length())
+            levels = bitpack(defs, 1)
+            raw = struct.pack('<I', len(levels)) + levels + bytes([width]) + bitpack([dictionary.index(v) for v in present], width)
+            comp = compress(codec, raw)
+            ph = S((1, I32, 0), (2, I32, len(raw)), (3, I32, len(comp)),
+                   (5, STRUCT, S((1, I32, len(values)), (2, I32, 8), (3, I32, 3), (4, I32, 3))))
+            data_offset = len(out)
+            out += enc_struct(ph) + comp
+        elif style == 'v1':
+            raw = plain(phys, present, FLBA_LEN)
+            if optional:
+                levels = bitpack(defs, 1)
+                raw = struct.pack('<I', len(levels)) + levels + raw
+            comp = compress(codec, raw)
+            ph = S((1, I32, 0), (2, I32, len(raw)), (3, I32, len(comp)),
+                   (5, STRUCT, S((1, I32, len(values)), (2, I32, 0), (3, I32, 3), (4, I32, 3))))
+            data_offset = len(out)
+            out += enc_struct(ph) + comp
+        else:  # v2: levels fora da compressão
+            levels = rle_run(1, len(values), 1) if not optional else bitpack(defs, 1)
+            raw = plain(phys, present)
+            comp = compress(codec, raw)
+            ph = S((1, I32, 3), (2, I32, len(levels) + len(raw)), (3, I32, len(levels) + len(comp)),
+                   (8, STRUCT, S((1, I32, len(values)), (2, I32, len(values) - len(present)), (3, I32, len(values)),
+                                 (4, I32, 0), (5, I32, len(levels)), (6, I32, 0), (7, 'bool', codec != 0))))
+            data_offset = len(out)
+            out += enc_struct(ph) + levels + comp
+
+        size = len(out) - start
+        meta = S((1, I32, phys), (2, LIST, (I32, [0, 3] + ([8] if style == 'dict' else []))),
+                 (3, LIST, (BIN, [name])), (4, I32, codec), (5, I64, len(values)),
+                 (6, I64, size), (7, I64, size), (9, I64, data_offset),
+                 (11, I64, dict_offset) if dict_offset is not None else None)
+        chunks.append(S((2, I64, start), (3, STRUCT, meta)))
+    row_groups.append(S((1, LIST, (STRUCT, chunks)), (2, I64, 0), (3, I64, len(rows))))
+
+footer = enc_struct(S((1, I32, 1), (2, LIST, (STRUCT, schema)), (3, I64, sum(len(g) for g in groups)),
+                      (4, LIST, (STRUCT, row_groups)), (6, BIN, 'bgc-ingest testdata')))
+out += footer + struct.pack('<I', len(footer)) + b'PAR1'
+open(sys.argv[1], 'wb').write(out)
@@ -0,0 +1,228 @@
+// validate.go — validação por linha e arquivo de rejeitos
+//
+// Linhas inválidas não abortam a carga nem viram zero: vão para o arquivo de rejeitos
+// (--rejects, CSV com linha, regra, motivo e o registro original) e a carga segue até
+// --max-errors rejeições. As regras espelham as constraints da migration 0011
+// (NOT NULL, valid_year, valid_month, valid_ncm_length, valid_fob, valid_weight).
+
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Regras de validação (coluna rule do arquivo de rejeitos e do resumo)
+const (
+	ruleRequired    = "required"       // coluna obrigatória vazia
+	ruleParse       = "parse"          // número/data inválido
+	ruleShortRecord = "short_record"   // linha com menos colunas que o esperado
+	ruleNCMLength   = "ncm_length"     // NCM sem 8 dígitos
+	ruleYearRange   = "year_range"     // ano fora de 2020..2030
+	ruleMonthRange  = "month_range"    // mês fora de 1..12
+	ruleCountry     = "country"        // país fora da ISO 3166-1 alpha-2
+	ruleNegative    = "negative"       // valor/peso negativo
+	ruleLookup      = "lookup"         // valor sem de/para no lookup do mapping
+	ruleYearFile    = "year_mismatch"  // ano diferente do ano do arquivo (load-comexstat)
+	ruleMonthFetch  = "month_mismatch" // mês diferente do mês buscado (sync-comexstat)
+)
+
+// Faixa de ano aceita por stg.exportacao/stg.importacao (CHECK valid_year)
+const (
+	minYear = 2020
+	maxYear = 2030
+)
+
+// fieldError erro de uma coluna (vira rowError com o registro original).
+type fieldError struct {
+	column string
+	rule   string
+	msg    string
+}
+
+func (e *fieldError) Error() string {
+	if e.column == "" {
+		return e.msg
+	}
+	return fmt.Sprintf("coluna %s: %s", e.column, e.msg)
+}
+
+// rowError linha rejeitada: vai para o arquivo de rejeitos, não aborta a carga.
+type rowError struct {
+	line   int
+	rule   string
+	reason string
+	record []string
+}
+
+func (e *rowError) Error() string {
+	return fmt.Sprintf("linha %d: %s", e.line, e.reason)
+}
+
+// rejectRow monta o rowError de uma linha (copia o registro: readers reaproveitam o slice).
+func rejectRow(line int, record []string, err error) *rowError {
+	rule := ruleParse
+	var fe *fieldError
+	if errors.As(err, &fe) {
+		rule = fe.rule
+	}
+	return &rowError{
+		line:   line,
+		rule:   rule,
+		reason: err.Error(),
+		record: append([]string(nil), record...),
+	}
+}
+
+// knownCountries ISO alpha-2 aceitos em co_pais (+ ZZ para país não identificado).
+var knownCountries = func() map[string]bool {
+	m := map[string]bool{unknownCountry: true}
+	for _, iso2 := range iso3to2 {
+		m[iso2] = true
+	}
+	return m
+}()
+
+// rowValidator valida os valores convertidos pelas colunas de destino da ComexStat.
+// Colunas com outros nomes (ex.: layout legado) só passam pela conversão.
+type rowValidator struct {
+	columns []string
+}
+
+func newRowValidator(columns []string) *rowValidator {
+	return &rowValidator{columns: columns}
+}
+
+// check devolve o primeiro problema da linha (nil = linha válida).
+func (v *rowValidator) check(row []any) error {
+	for i, col := range v.columns {
+		if i >= len(row) {
+			break
+		}
+		val := row[i]
+
+		switch col {
+		case "co_ano", "co_mes", "co_pais", "co_ncm", "vl_fob", "kg_liquido":
+			if val == nil || val == "" {
+				return &fieldError{col, ruleRequired, "obrigatória"}
+			}
+		}
+		if val == nil {
+			continue
+		}
+
+		switch col {
+		case "co_ano":
+			if n, ok := asInt(val); !ok || n < minYear || n > maxYear {
+				return &fieldError{col, ruleYearRange, fmt.Sprintf("ano %v fora de %d..%d", val, minYear, maxYear)}
+			}
+		case "co_mes":
+			if n, ok := asInt(val); !ok || n < 1 || n > 12 {
+				return &fieldError{col, ruleMonthRange, fmt.Sprintf("mês %v fora de 1..12", val)}
+			}
+		case "co_ncm":
+			if s, _ := val.(string); len(s) != 8 || strings.Trim(s, "0123456789") != "" {
+				return &fieldError{col, ruleNCMLength, fmt.Sprintf("NCM %q deve ter 8 dígitos", val)}
+			}
+		case "co_pais":
+			if s, _ := val.(string); !knownCountries[strings.ToUpper(s)] {
+				return &fieldError{col, ruleCountry, fmt.Sprintf("país %q desconhecido (ISO 3166-1 alpha-2)", val)}
+			}
+		case "vl_fob", "kg_liquido", "vl_frete", "vl_seguro", "qt_estat":
+			if f, ok := asFloat(val); ok && f < 0 {
+				return &fieldError{col, ruleNegative, fmt.Sprintf("valor negativo %v", val)}
+			}
+		}
+	}
+	return nil
+}
+
+func asInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), float64(int64(n)) == n
+	}
+	return 0, false
+}
+
+func asFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Arquivo de rejeitos
+////////////////////////////////////////////////////////////////////////////////
+
+// rejectWriter grava as linhas rejeitadas em CSV (criado só na 1ª rejeição).
+type rejectWriter struct {
+	path   string
+	append bool // retomada: mantém os rejeitos da execução anterior
+	file   *os.File
+	w      *csv.Writer
+
+	total  int64
+	byRule map[string]int64
+}
+
+func newRejectWriter(path string, appendMode bool) *rejectWriter {
+	return &rejectWriter{path: path, append: appendMode, byRule: map[string]int64{}}
+}
+
+// write registra uma linha rejeitada.
+func (r *rejectWriter) write(e *rowError) error {
+	if r.w == nil {
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if r.append {
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		f, err := os.OpenFile(r.path, flags, 0o644)
+		if err != nil {
+			return fmt.Errorf("abrir arquivo de rejeitos: %w", err)
+		}
+		r.file = f
+		r.w = csv.NewWriter(f)
+		if st, err := f.Stat(); err == nil && st.Size() == 0 {
+			_ = r.w.Write([]string{"line", "rule", "reason", "record"})
+		}
+	}
+
+	r.total++
+	r.byRule[e.rule]++
+	rec := append([]string{fmt.Sprint(e.line), e.rule, e.reason}, e.record...)
+	return r.w.Write(rec)
+}
+
+// close grava o buffer e fecha o arquivo (se houve rejeição).
+func (r *rejectWriter) close() error {
+	if r.w == nil {
+		return nil
+	}
+	w, f := r.w, r.file
+	r.w, r.file = nil, nil
+	w.Flush()
+	if err := w.Error(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// summary contagem de rejeições por regra.
+func (r *rejectWriter) summary() map[string]int64 {
+	return r.byRule
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRowValidatorCheck(t *testing.T) {
+	v := newRowValidator([]string{"co_ano", "co_mes", "co_pais", "co_ncm", "vl_fob", "kg_liquido", "qt_estat", "sg_uf_ncm"})
+	valid := []any{2024, 3, "CN", "02011000", 1000.0, 10.5, int64(7), "SP"}
+
+	with := func(i int, val any) []any {
+		row := append([]any(nil), valid...)
+		row[i] = val
+		return row
+	}
+
+	tests := []struct {
+		name     string
+		row      []any
+		wantRule string
+	}{
+		{name: "valid", row: valid},
+		{name: "lower case country", row: with(2, "cn")},
+		{name: "unknown country ZZ", row: with(2, "ZZ")},
+		{name: "null optional", row: with(6, nil)},
+		{name: "null uf", row: with(7, nil)},
+		{name: "float year", row: with(0, 2024.0)},
+		{name: "short row", row: valid[:4]},
+
+		{name: "missing year", row: with(0, nil), wantRule: ruleRequired},
+		{name: "empty country", row: with(2, ""), wantRule: ruleRequired},
+		{name: "missing fob", row: with(4, nil), wantRule: ruleRequired},
+		{name: "year below range", row: with(0, 2019), wantRule: ruleYearRange},
+		{name: "year above range", row: with(0, int64(2031)), wantRule: ruleYearRange},
+		{name: "fractional year", row: with(0, 2024.5), wantRule: ruleYearRange},
+		{name: "year as text", row: with(0, "2024"), wantRule: ruleYearRange},
+		{name: "month zero", row: with(1, 0), wantRule: ruleMonthRange},
+		{name: "month 13", row: with(1, 13), wantRule: ruleMonthRange},
+		{name: "short ncm", row: with(3, "2011000"), wantRule: ruleNCMLength},
+		{name: "ncm with letters", row: with(3, "0201A000"), wantRule: ruleNCMLength},
+		{name: "invalid country", row: with(2, "XX"), wantRule: ruleCountry},
+		{name: "negative fob", row: with(4, -1.0), wantRule: ruleNegative},
+		{name: "negative weight", row: with(5, -0.5), wantRule: ruleNegative},
+		{name: "negative quantity", row: with(6, int64(-1)), wantRule: ruleNegative},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.check(tt.row)
+			if tt.wantRule == "" {
+				if err != nil {
+					t.Fatalf("check: %v", err)
+				}
+				return
+			}
+			var fe *fieldError
+			if !errors.As(err, &fe) || fe.rule != tt.wantRule {
+				t.Errorf("check = %v, want rule %q", err, tt.wantRule)
+			}
+		})
+	}
+
+	// Colunas fora do layout da ComexStat só passam pela conversão
+	legacy := newRowValidator([]string{"ano", "valor"})
+	if err := legacy.check([]any{1999, -1.0}); err != nil {
+		t.Errorf("layout legado: check = %v", err)
+	}
+}
+
+func TestRejectRow(t *testing.T) {
+	record := []string{"2024", "xx"}
+	got := rejectRow(7, record, &fieldError{"CO_MES", ruleParse, `inteiro inválido "xx"`})
+	record[1] = "reaproveitado"
+
+	want := &rowError{line: 7, rule: ruleParse, reason: `coluna CO_MES: inteiro inválido "xx"`, record: []string{"2024", "xx"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rejectRow = %+v, want %+v", got, want)
+	}
+
+	// Erro sem regra própria conta como parse
+	if got := rejectRow(1, nil, errors.New("campo faltando")); got.rule != ruleParse {
+		t.Errorf("rule = %q, want %q", got.rule, ruleParse)
+	}
+}
+
+func TestRejectWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rejects.csv")
+
+	// Sem rejeição: nenhum arquivo criado
+	w := newRejectWriter(path, false)
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("arquivo criado sem rejeição: %v", err)
+	}
+
+	w = newRejectWriter(path, false)
+	for _, e := range []*rowError{
+		{line: 2, rule: ruleParse, reason: "a", record: []string{"x", "y"}},
+		{line: 5, rule: ruleCountry, reason: "b", record: []string{"z"}},
+		{line: 9, rule: ruleParse, reason: "c"},
+	} {
+		if err := w.write(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+	if w.total != 3 || !reflect.DeepEqual(w.summary(), map[string]int64{ruleParse: 2, ruleCountry: 1}) {
+		t.Errorf("total = %d, summary = %v", w.total, w.summary())
+	}
+
+	// Retomada: acrescenta sem repetir o cabeçalho
+	w = newRejectWriter(path, true)
+	if err := w.write(&rowError{line: 12, rule: ruleNegative, reason: "d"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	got, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"line", "rule", "reason", "record"},
+		{"2", ruleParse, "a", "x", "y"},
+		{"5", ruleCountry, "b", "z"},
+		{"9", ruleParse, "c"},
+		{"12", ruleNegative, "d"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rejeitos = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+// views.go — refresh das materialized views (substitui os jobs bgc-mviews-*)
+//
+// Comando:
+//   bgc-ingest refresh-views [--views v_tam_by_year_chapter,rpt.mv_exportacao_resumo]
+//                            [--concurrently=false] [--timeout 30m]
+//
+// Sem --views, atualiza todas as mviews do banco (pg_matviews). Cada view usa
+// REFRESH ... CONCURRENTLY quando possível (já populada e com índice único sem
+// WHERE/expressão; ver migration 0017); senão, o REFRESH comum (bloqueia leituras).
+// Imprime um JSON por view (modo, duração) e um resumo; falha em uma view não
+// impede as demais, mas o comando termina com erro.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// mview materialized view e se aceita REFRESH CONCURRENTLY.
+type mview struct {
+	schema    string
+	name      string
+	populated bool // WITH NO DATA ainda não populada: CONCURRENTLY não vale
+	unique    bool // índice único utilizável pelo CONCURRENTLY
+}
+
+func (v mview) ident() pgx.Identifier { return pgx.Identifier{v.schema, v.name} }
+
+// listMViews mviews do banco (ou só as pedidas), na ordem de --views.
+func listMViews(ctx context.Context, pool *pgxpool.Pool, names []string) ([]mview, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT m.schemaname, m.matviewname, m.ispopulated,
+		       EXISTS (
+		         SELECT 1 FROM pg_index i
+		         WHERE i.indrelid = format('%I.%I', m.schemaname, m.matviewname)::regclass
+		           AND i.indisunique AND i.indisvalid
+		           AND i.indpred IS NULL AND i.indexprs IS NULL
+		       )
+		FROM pg_matviews m
+		WHERE m.schemaname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY m.schemaname, m.matviewname`)
+	if err != nil {
+		return nil, fmt.Errorf("listar mviews: %w", err)
+	}
+	defer rows.Close()
+
+	var all []mview
+	for rows.Next() {
+		var v mview
+		if err := rows.Scan(&v.schema, &v.name, &v.populated, &v.unique); err != nil {
+			return nil, fmt.Errorf("ler mview: %w", err)
+		}
+		all = append(all, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return all, nil
+	}
+
+	// --views: nome com schema (rpt.mv_x) ou só o nome (v_tam_by_year_chapter -> public)
+	var out []mview
+	for _, n := range names {
+		table, err := parseTable(n)
+		if err != nil {
+			return nil, err
+		}
+		schema, name := schemaOf(table), table[len(table)-1]
+		found := false
+		for _, v := range all {
+			if v.schema == schema && v.name == name {
+				out, found = append(out, v), true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("materialized view %s.%s não encontrada", schema, name)
+		}
+	}
+	return out, nil
+}
+
+// viewResult linha impressa por view.
+type viewResult struct {
+	View       string `json:"view"`
+	Mode       string `json:"mode"`             // concurrently | blocking
+	Reason     string `json:"reason,omitempty"` // por que não foi CONCURRENTLY
+	Status     string `json:"status"`           // ok | error
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// refreshMView executa o REFRESH (CONCURRENTLY se possível e permitido).
+func refreshMView(ctx context.Context, pool *pgxpool.Pool, v mview, concurrently bool) viewResult {
+	res := viewResult{View: v.schema + "." + v.name, Mode: "blocking"}
+	switch {
+	case !concurrently:
+		res.Reason = "--concurrently=false"
+	case !v.populated:
+		res.Reason = "não populada"
+	case !v.unique:
+		res.Reason = "sem índice único"
+	default:
+		res.Mode = "concurrently"
+	}
+
+	sql := "REFRESH MATERIALIZED VIEW " + v.ident().Sanitize()
+	if res.Mode == "concurrently" {
+		sql = "REFRESH MATERIALIZED VIEW CONCURRENTLY " + v.ident().Sanitize()
+	}
+	start := time.Now()
+	_, err := pool.Exec(ctx, sql)
+	res.DurationMs = time.Since(start).Milliseconds()
+	res.Status = "ok"
+	if err != nil {
+		res.Status, res.Error = "error", err.Error()
+	}
+	return res
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Comando: refresh-views
+////////////////////////////////////////////////////////////////////////////////
+
+func cmdRefreshViews(args []string) error {
+	fs := flag.NewFlagSet("refresh-views", flag.ContinueOnError)
+	views := fs.String("views", "", "mviews separadas por vírgula (default: todas)")
+	concurrently := fs.Bool("concurrently", true, "usar REFRESH CONCURRENTLY quando possível")
+	timeout := fs.Duration("timeout", 30*time.Minute, "tempo máximo por view")
+	fs.SetOutput(new(nopWriter))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var names []string
+	for _, n := range strings.Split(*views, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+
+	pool, err := connect()
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	list, err := listMViews(ctx, pool, names)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return errors.New("nenhuma materialized view no banco")
+	}
+
+	start := time.Now()
+	failed := 0
+	for _, v := range list {
+		vctx, cancel := context.WithTimeout(ctx, *timeout)
+		res := refreshMView(vctx, pool, v, *concurrently)
+		cancel()
+		if res.Status != "ok" {
+			failed++
+		}
+		printJSON(res)
+	}
+	printJSON(map[string]any{
+		"summary":     true,
+		"views":       len(list),
+		"failed":      failed,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+	if failed > 0 {
+		return fmt.Errorf("refresh-views: %d de %d views falharam", failed, len(list))
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/bgc/integration-gateway/internal/access"
+	"github.com/bgc/integration-gateway/internal/cache"
+	"github.com/bgc/integration-gateway/internal/framework"
+	"github.com/bgc/integration-gateway/internal/observability"
+	"github.com/bgc/integration-gateway/internal/registry"
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWarmParams limite de conjuntos de params por pre-warm
+const maxWarmParams = 200
+
+// warmRequest corpo do POST /v1/admin/cache/:id/warm
+type warmRequest struct {
+	Endpoint string                   `json:"endpoint" binding:"required"`
+	Version  string                   `json:"version"` // vazio = versão estável
+	Params   []map[string]interface{} `json:"params" binding:"required"`
+}
+
+// warmResult resultado do pre-warm de um conjunto de params
+type warmResult struct {
+	Params map[string]interface{} `json:"params"`
+	Status string                 `json:"status"` // ok, error
+	Error  string                 `json:"error,omitempty"`
+}
+
+// registerAdminRoutes rotas administrativas de cache (/v1/admin), restritas a callers admin
+func registerAdminRoutes(
+	v1 *gin.RouterGroup,
+	guard *access.Guard,
+	auditor access.Auditor,
+	caches *cache.Namespaces,
+	executor *framework.Executor,
+	reg *registry.Registry,
+	environment string,
+) {
+	admin := v1.Group("/admin", access.RequireAdmin(guard, auditor))
+
+	// Estatísticas de todos os caches visíveis ao caller
+	admin.GET("/cache", func(c *gin.Context) {
+		policy := access.CallerFrom(c).Policy
+		result := make([]gin.H, 0)
+		for _, id := range caches.Connectors() {
+			if !policy.AllowsConnector(id) {
+				continue
+			}
+			manager, _ := caches.Get(id)
+			result = append(result, gin.H{"connector": id, "levels": manager.GetStats(c.Request.Context())})
+		}
+		c.JSON(http.StatusOK, result)
+	})
+
+	// Estatísticas do cache de um connector
+	admin.GET("/cache/:id", withCache(caches, func(c *gin.Context, manager *cache.MultiLevelCacheManager) {
+		c.JSON(http.StatusOK, gin.H{"connector": c.Param("id"), "levels": manager.GetStats(c.Request.Context())})
+	}))
+
+	// Invalida uma chave exata (?key=) ou um padrão glob (?pattern=)
+	admin.DELETE("/cache/:id/keys", withCache(caches, func(c *gin.Context, manager *cache.MultiLevelCacheManager) {
+		connectorID := c.Param("id")
+		key, pattern := c.Query("key"), c.Query("pattern")
+		if (key == "") == (pattern == "") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of key or pattern is required"})
+			return
+		}
+
+		if key != "" {
+			if err := manager.Delete(c.Request.Context(), key); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			observability.Info("Cache key invalidated", "connector", connectorID, "key", key, "caller", access.CallerFrom(c).ID)
+			c.JSON(http.StatusOK, gin.H{"connector": connectorID, "key": key})
+			return
+		}
+
+		deleted, err := manager.DeleteMatching(c.Request.Context(), pattern)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "deleted": deleted})
+			return
+		}
+		observability.Info("Cache keys invalidated", "connector", connectorID, "pattern", pattern, "deleted", deleted, "caller", access.CallerFrom(c).ID)
+		c.JSON(http.StatusOK, gin.H{"connector": connectorID, "pattern": pattern, "deleted": deleted})
+	}))
+
+	// Limpa todo o namespace do connector (L1, L2 e L3)
+	admin.DELETE("/cache/:id", withCache(caches, func(c *gin.Context, manager *cache.MultiLevelCacheManager) {
+		connectorID := c.Param("id")
+		if err := manager.Clear(c.Request.Context()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		observability.Info("Cache namespace purged", "connector", connectorID, "caller", access.CallerFrom(c).ID)
+		c.JSON(http.StatusOK, gin.H{"connector": connectorID, "purged": true})
+	}))
+
+	// Pre-warm: executa o endpoint para cada conjunto de params e grava no cache
+	admin.POST("/cache/:id/warm", withCache(caches, func(c *gin.Context, manager *cache.MultiLevelCacheManager) {
+		connectorID := c.Param("id")
+
+		var req warmRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+		if len(req.Params) > maxWarmParams {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "too many param sets", "max": maxWarmParams})
+			return
+		}
+
+		conn, err := reg.GetVersion(connectorID, req.Version)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "connector not found"})
+			return
+		}
+		if _, exists := conn.Integration.Endpoints[req.Endpoint]; !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "endpoint not found"})
+			return
+		}
+		if !access.CallerFrom(c).Policy.AllowsEndpoint(connectorID, req.Endpoint) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "endpoint not allowed", "reason": access.ReasonEndpointNotAllowed})
+			return
+		}
+
+		results := make([]warmResult, 0, len(req.Params))
+		failed := 0
+		for _, params := range req.Params {
+			_, err := executor.Execute(&types.ExecutionContext{
+				ConnectorID:  connectorID,
+				Version:      conn.Version,
+				EndpointName: req.Endpoint,
+				Environment:  environment,
+				Params:       params,
+				Context:      c.Request.Context(),
+				RefreshCache: true,
+			})
+
+			result := warmResult{Params: params, Status: "ok"}
+			if err != nil {
+				result.Status = "error"
+				result.Error = err.Error()
+				failed++
+			}
+			results = append(results, result)
+		}
+
+		observability.Info("Cache warmed", "connector", connectorID, "endpoint", req.Endpoint,
+			"warmed", len(results)-failed, "failed", failed, "caller", access.CallerFrom(c).ID)
+		c.JSON(http.StatusOK, gin.H{
+			"connector": connectorID,
+			"version":   conn.Version,
+			"endpoint":  req.Endpoint,
+			"warmed":    len(results) - failed,
+			"failed":    failed,
+			"results":   results,
+		})
+	}))
+}
+
+// withCache resolve o cache do connector da rota (404 se o connector não usa cache)
+func withCache(caches *cache.Namespaces, handler func(*gin.Context, *cache.MultiLevelCacheManager)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager, ok := caches.Get(c.Param("id"))
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "connector has no cache"})
+			return
+		}
+		handler(c, manager)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bgc/integration-gateway/internal/auth"
+	"github.com/bgc/integration-gateway/internal/contract"
+	"github.com/bgc/integration-gateway/internal/framework"
+	"github.com/bgc/integration-gateway/internal/observability"
+	"github.com/bgc/integration-gateway/internal/registry"
+	"github.com/bgc/integration-gateway/internal/replay"
+)
+
+// runContractTests executa "gateway test <connector>": roda os exemplos de cada
+// endpoint contra as fixtures gravadas (sem rede) e valida a saída mapeada
+func runContractTests(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: gateway test <connector>")
+		return 2
+	}
+	connectorID := args[0]
+
+	configDir := getEnv("CONFIG_DIR", "./config/connectors")
+	fixturesDir := getEnv("FIXTURES_DIR", "./fixtures")
+	environment := getEnv("ENVIRONMENT", "development")
+	observability.SetLogLevel(getEnv("LOG_LEVEL", "warn"))
+
+	reg := registry.NewRegistry(configDir)
+	if err := reg.LoadAll(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load connectors: %v\n", err)
+		return 2
+	}
+
+	connector, err := reg.Get(connectorID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	transformEngine, err := newTransformEngine(configDir, reg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load WASM plugins: %v\n", err)
+		return 2
+	}
+
+	// Replay não autentica: credenciais nunca são gravadas nas fixtures
+	executor := framework.NewExecutor(reg, auth.NewEngine(nil, nil), transformEngine,
+		framework.WithReplay(replay.NewReplayer(replay.NewStore(fixturesDir))))
+
+	report := contract.Run(executor, connector, environment)
+	if len(report.Results) == 0 {
+		fmt.Printf("%s: no examples declared\n", connectorID)
+		return 0
+	}
+
+	failed := 0
+	for _, result := range report.Results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Printf("%s %s/%s/%s (%s)\n", status, connectorID, result.Endpoint, result.Example, result.Duration)
+		for _, failure := range result.Failures {
+			fmt.Printf("    %s\n", failure)
+		}
+	}
+
+	fmt.Printf("%d examples, %d failed\n", len(report.Results), failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
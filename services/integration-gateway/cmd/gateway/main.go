@@ -1,26 +1,50 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"math"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/bgc/integration-gateway/internal/access"
 	"github.com/bgc/integration-gateway/internal/auth"
+	"github.com/bgc/integration-gateway/internal/cache"
 	"github.com/bgc/integration-gateway/internal/framework"
 	"github.com/bgc/integration-gateway/internal/observability"
+	"github.com/bgc/integration-gateway/internal/openapi"
 	"github.com/bgc/integration-gateway/internal/registry"
+	"github.com/bgc/integration-gateway/internal/replay"
+	"github.com/bgc/integration-gateway/internal/scheduler"
 	"github.com/bgc/integration-gateway/internal/transform"
 	"github.com/bgc/integration-gateway/internal/types"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func main() {
+	// Subcomando: gateway test <connector>
+	if len(os.Args) > 1 && os.Args[1] == "test" {
+		os.Exit(runContractTests(os.Args[2:]))
+	}
+
 	// Configuração
 	configDir := getEnv("CONFIG_DIR", "./config/connectors")
 	certsDir := getEnv("CERTS_DIR", "./certs")
 	port := getEnv("PORT", "8081")
 	environment := getEnv("ENVIRONMENT", "development")
 	logLevel := getEnv("LOG_LEVEL", "info")
+	mode := getEnv("GATEWAY_MODE", "live") // live, record, replay
+	fixturesDir := getEnv("FIXTURES_DIR", "./fixtures")
+	callersFile := getEnv("CALLERS_FILE", "./config/callers.yaml")
+	auditLog := getEnv("AUDIT_LOG", "")       // vazio = stdout
+	databaseURL := getEnv("DATABASE_URL", "") // vazio = scheduler desabilitado
 
 	// Configura log level
 	observability.SetLogLevel(logLevel)
@@ -30,8 +54,16 @@ func main() {
 		"certs_dir", certsDir,
 		"environment", environment,
 		"log_level", logLevel,
+		"mode", mode,
 	)
 
+	// Tracing (contexto recebido do caller é propagado até o upstream)
+	shutdownTracer, err := observability.InitTracer(environment)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracer: %v", err)
+	}
+	defer shutdownTracer(context.Background())
+
 	// Inicializa componentes
 	reg := registry.NewRegistry(configDir)
 	if err := reg.LoadAll(); err != nil {
@@ -44,16 +76,45 @@ func main() {
 	secretStore := auth.NewSimpleSecretStore()
 	authEngine := auth.NewEngine(certManager, secretStore)
 
-	transformEngine := transform.NewEngine()
-	// Registra built-in plugins
-	transformEngine.RegisterPlugin("format_cnpj", &transform.FormatCNPJPlugin{})
-	transformEngine.RegisterPlugin("format_cpf", &transform.FormatCPFPlugin{})
-	transformEngine.RegisterPlugin("format_cep", &transform.FormatCEPPlugin{})
-	transformEngine.RegisterPlugin("to_upper", &transform.ToUpperPlugin{})
-	transformEngine.RegisterPlugin("to_lower", &transform.ToLowerPlugin{})
-	transformEngine.RegisterPlugin("trim", &transform.TrimPlugin{})
+	transformEngine, err := newTransformEngine(configDir, reg)
+	if err != nil {
+		observability.Error("Failed to load WASM plugins", "error", err)
+		log.Fatalf("Failed to load WASM plugins: %v", err)
+	}
 
-	executor := framework.NewExecutor(reg, authEngine, transformEngine)
+	executorOpts, err := executorOptions(mode, fixturesDir)
+	if err != nil {
+		log.Fatalf("Invalid gateway mode: %v", err)
+	}
+
+	// Cache multinível por connector (L1 memória, L2 Redis)
+	caches, err := newCaches(reg)
+	if err != nil {
+		observability.Error("Failed to initialize cache", "error", err)
+		log.Fatalf("Failed to initialize cache: %v", err)
+	}
+	executorOpts = append(executorOpts, framework.WithCache(caches))
+
+	executor := framework.NewExecutor(reg, authEngine, transformEngine, executorOpts...)
+
+	// Autenticação de callers (bgc-api, jobs internos)
+	guard, err := newGuard(callersFile, environment, secretStore)
+	if err != nil {
+		observability.Error("Failed to load callers", "error", err)
+		log.Fatalf("Failed to load callers: %v", err)
+	}
+
+	auditor, err := newAuditor(auditLog)
+	if err != nil {
+		log.Fatalf("Failed to open audit log: %v", err)
+	}
+
+	// Jobs agendados (carga em Postgres)
+	sched, err := newScheduler(databaseURL, executor, reg, environment)
+	if err != nil {
+		observability.Error("Failed to start scheduler", "error", err)
+		log.Fatalf("Failed to start scheduler: %v", err)
+	}
 
 	// Configura Gin
 	if environment == "production" {
@@ -61,6 +122,9 @@ func main() {
 	}
 
 	router := gin.Default()
+	router.Use(otelgin.Middleware(observability.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/health" && r.URL.Path != "/metrics"
+	})))
 
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
@@ -73,70 +137,166 @@ func main() {
 	// Prometheus metrics
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
-	// Lista conectores
-	router.GET("/v1/connectors", func(c *gin.Context) {
+	// OpenAPI gerado do registry (público: geradores de client não têm credencial);
+	// lista todos os connectors, a policy do caller vale na execução em /v1
+	router.GET("/openapi.json", func(c *gin.Context) {
+		connectors := reg.List()
+		versions := make(map[string][]string, len(connectors))
+		for _, conn := range connectors {
+			for _, info := range reg.Versions(conn.ID) {
+				versions[conn.ID] = append(versions[conn.ID], info.Version)
+			}
+		}
+		c.JSON(200, openapi.Generate(openapi.Info{Title: "BGC Integration Gateway", Version: "1.0.0"}, connectors, versions))
+	})
+
+	// Rotas /v1 exigem caller autenticado
+	v1 := router.Group("/v1", access.Middleware(guard, auditor))
+
+	// Lista conectores (apenas os permitidos ao caller)
+	v1.GET("/connectors", func(c *gin.Context) {
+		policy := access.CallerFrom(c).Policy
 		connectors := reg.List()
 		result := make([]gin.H, 0, len(connectors))
 		for _, conn := range connectors {
+			if !policy.AllowsConnector(conn.ID) {
+				continue
+			}
 			result = append(result, gin.H{
-				"id":       conn.ID,
-				"name":     conn.Name,
-				"version":  conn.Version,
-				"provider": conn.Provider,
-				"endpoints": getEndpointNames(conn),
+				"id":        conn.ID,
+				"name":      conn.Name,
+				"version":   conn.Version,
+				"versions":  reg.Versions(conn.ID),
+				"provider":  conn.Provider,
+				"endpoints": getEndpointNames(conn, &policy),
 			})
 		}
 		c.JSON(200, result)
 	})
 
-	// Detalhes de um connector
-	router.GET("/v1/connectors/:id", func(c *gin.Context) {
-		id := c.Param("id")
-		conn, err := reg.Get(id)
-		if err != nil {
-			c.JSON(404, gin.H{"error": "connector not found"})
+	// Detalhes de um connector (versão estável ou id@versão)
+	v1.GET("/connectors/:id", func(c *gin.Context) {
+		policy := access.CallerFrom(c).Policy
+		conn, err := reg.Get(c.Param("id"))
+		if err != nil || !policy.AllowsConnector(conn.ID) {
+			c.JSON(404, gin.H{"error": "connector not found", "code": framework.CodeConnectorNotFound, "connector": c.Param("id")})
 			return
 		}
-		c.JSON(200, conn)
+		c.JSON(200, visibleConnector(conn, &policy))
 	})
 
-	// Executa endpoint de um connector
-	router.POST("/v1/connectors/:id/:endpoint", func(c *gin.Context) {
-		connectorID := c.Param("id")
+	// Executa endpoint de um connector (versão fixada por id@versão ou X-Connector-Version)
+	// ?dry_run=true retorna a request montada sem chamar o upstream; ?explain=true detalha a execução;
+	// ?refresh=true ignora o cache na leitura e grava o resultado novo (ex.: revisões do upstream);
+	// só para admins ou callers com policy.refresh (checado em RequireEndpoint)
+	v1.POST("/connectors/:id/:endpoint", access.RequireEndpoint(guard, auditor, environment), func(c *gin.Context) {
+		connectorID, version := types.SplitConnectorRef(c.Param("id"))
+		if version == "" {
+			version = strings.TrimPrefix(c.GetHeader(types.VersionHeader), "v")
+		}
 		endpointName := c.Param("endpoint")
 
 		// Parse request body (params)
 		var params map[string]interface{}
 		if err := c.ShouldBindJSON(&params); err != nil {
-			c.JSON(400, gin.H{"error": "invalid request body"})
+			c.JSON(400, gin.H{"error": "invalid request body", "code": "invalid_request", "connector": connectorID, "endpoint": endpointName})
 			return
 		}
 
 		// Executa
 		ctx := &types.ExecutionContext{
 			ConnectorID:  connectorID,
+			Version:      version,
 			EndpointName: endpointName,
 			Environment:  environment,
 			Params:       params,
+			Context:      c.Request.Context(),
+			DryRun:       queryFlag(c, "dry_run"),
+			Explain:      queryFlag(c, "explain"),
+			RefreshCache: queryFlag(c, "refresh"),
 		}
 
 		result, err := executor.Execute(ctx)
 		if err != nil {
-			errorResponse := gin.H{"error": err.Error()}
+			// Erro tipado: status HTTP, code e status upstream para o caller reagir
+			execErr := framework.AsExecutionError(err)
+			errorResponse := gin.H{
+				"error":     err.Error(),
+				"code":      execErr.Code,
+				"connector": connectorID,
+				"endpoint":  endpointName,
+			}
+			if execErr.UpstreamStatus != 0 {
+				errorResponse["upstream_status"] = execErr.UpstreamStatus
+			}
+			if execErr.RetryAfter > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(execErr.RetryAfter.Seconds()))))
+			}
 			if result != nil {
 				errorResponse["duration"] = result.Duration.String()
+				if result.Explain != nil {
+					errorResponse["explain"] = result.Explain
+				}
 			}
-			c.JSON(500, errorResponse)
+
+			// Resposta upstream fora do contrato (modo strict)
+			var validationErr *framework.ResponseValidationError
+			if errors.As(err, &validationErr) {
+				errorResponse["violations"] = validationErr.Violations
+			}
+
+			c.JSON(execErr.HTTPStatus(), errorResponse)
 			return
 		}
 
-		c.JSON(200, gin.H{
+		c.Header(types.VersionHeader, result.Version)
+		if result.Plan != nil {
+			c.JSON(200, gin.H{
+				"dry_run":  result.Plan,
+				"duration": result.Duration.String(),
+				"version":  result.Version,
+			})
+			return
+		}
+
+		response := gin.H{
 			"data":        result.Data,
 			"status_code": result.StatusCode,
 			"duration":    result.Duration.String(),
-		})
+			"version":     result.Version,
+		}
+		if result.Explain != nil {
+			response["explain"] = result.Explain
+		}
+		c.JSON(200, response)
 	})
 
+	// Status dos jobs agendados (apenas dos conectores permitidos ao caller)
+	v1.GET("/jobs", func(c *gin.Context) {
+		if sched == nil {
+			c.JSON(200, gin.H{"enabled": false, "jobs": []scheduler.JobStatus{}})
+			return
+		}
+
+		statuses, err := sched.Status(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		policy := access.CallerFrom(c).Policy
+		visible := make([]scheduler.JobStatus, 0, len(statuses))
+		for _, status := range statuses {
+			if policy.AllowsConnector(status.Connector) {
+				visible = append(visible, status)
+			}
+		}
+		c.JSON(200, gin.H{"enabled": true, "jobs": visible})
+	})
+
+	// Administração de cache (callers admin)
+	registerAdminRoutes(v1, guard, auditor, caches, executor, reg, environment)
+
 	// Inicia servidor
 	addr := ":" + port
 	observability.Info("Server starting", "address", addr)
@@ -147,6 +307,45 @@ func main() {
 	}
 }
 
+// newTransformEngine cria o engine de transformação com plugins built-in e WASM
+func newTransformEngine(configDir string, reg *registry.Registry) (*transform.Engine, error) {
+	transformEngine := transform.NewEngine()
+	// Registra built-in plugins
+	transformEngine.RegisterPlugin("format_cnpj", &transform.FormatCNPJPlugin{})
+	transformEngine.RegisterPlugin("format_cpf", &transform.FormatCPFPlugin{})
+	transformEngine.RegisterPlugin("format_cep", &transform.FormatCEPPlugin{})
+	transformEngine.RegisterPlugin("to_upper", &transform.ToUpperPlugin{})
+	transformEngine.RegisterPlugin("to_lower", &transform.ToLowerPlugin{})
+	transformEngine.RegisterPlugin("trim", &transform.TrimPlugin{})
+
+	// Registra plugins WASM declarados nos conectores
+	if err := transformEngine.RegisterWASMPlugins(context.Background(), configDir, reg.ListAll()); err != nil {
+		return nil, err
+	}
+
+	return transformEngine, nil
+}
+
+// executorOptions configura record/replay de fixtures conforme o modo do gateway
+func executorOptions(mode, fixturesDir string) ([]framework.ExecutorOption, error) {
+	store := replay.NewStore(fixturesDir)
+
+	switch mode {
+	case "live":
+		return nil, nil
+	case "record":
+		return []framework.ExecutorOption{
+			framework.WithTransport(func(next http.RoundTripper) http.RoundTripper {
+				return replay.NewRecorder(store, next)
+			}),
+		}, nil
+	case "replay":
+		return []framework.ExecutorOption{framework.WithReplay(replay.NewReplayer(store))}, nil
+	default:
+		return nil, fmt.Errorf("unknown mode %q (expected live, record or replay)", mode)
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -154,10 +353,119 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-func getEndpointNames(conn *types.ConnectorConfig) []string {
+func getEndpointNames(conn *types.ConnectorConfig, policy *access.PolicyConfig) []string {
 	names := make([]string, 0, len(conn.Integration.Endpoints))
 	for name := range conn.Integration.Endpoints {
-		names = append(names, name)
+		if policy.AllowsEndpoint(conn.ID, name) {
+			names = append(names, name)
+		}
 	}
 	return names
 }
+
+// queryFlag flag booleana da query string (?dry_run=true, ?explain=1)
+func queryFlag(c *gin.Context, name string) bool {
+	enabled, _ := strconv.ParseBool(c.Query(name))
+	return enabled
+}
+
+// visibleConnector cópia do connector apenas com os endpoints permitidos ao caller
+func visibleConnector(conn *types.ConnectorConfig, policy *access.PolicyConfig) *types.ConnectorConfig {
+	visible := *conn
+	visible.Integration.Endpoints = make(map[string]types.EndpointConfig, len(conn.Integration.Endpoints))
+	for name, endpoint := range conn.Integration.Endpoints {
+		if policy.AllowsEndpoint(conn.ID, name) {
+			visible.Integration.Endpoints[name] = endpoint
+		}
+	}
+	return &visible
+}
+
+// newGuard carrega os callers; sem arquivo, o gateway fica aberto (exceto em produção)
+func newGuard(callersFile, environment string, secrets auth.SecretStore) (*access.Guard, error) {
+	if _, err := os.Stat(callersFile); os.IsNotExist(err) {
+		if environment == "production" {
+			return nil, fmt.Errorf("callers file %s is required in production", callersFile)
+		}
+		observability.Warn("Callers file not found, gateway running WITHOUT caller authentication", "callers_file", callersFile)
+		return access.NewOpenGuard(), nil
+	}
+
+	callers, err := access.LoadCallers(callersFile)
+	if err != nil {
+		return nil, err
+	}
+	observability.Info("Callers loaded", "count", len(callers.Callers))
+
+	return access.NewGuard(callers, secrets)
+}
+
+// newCaches cria os caches dos conectores; sem Redis acessível, o gateway segue apenas com L1
+func newCaches(reg *registry.Registry) (*cache.Namespaces, error) {
+	l1Config := cache.DefaultL1Config()
+	if sizeMB, err := strconv.Atoi(getEnv("CACHE_L1_MAX_SIZE_MB", "")); err == nil && sizeMB > 0 {
+		l1Config.MaxSize = int64(sizeMB) * 1024 * 1024
+		l1Config.NumCounters = l1Config.MaxSize / 10
+	}
+
+	l2Config := cache.DefaultL2Config()
+	l2Config.Addr = getEnv("REDIS_ADDR", l2Config.Addr)
+	l2Config.Password = getEnv("REDIS_PASSWORD", "")
+	if db, err := strconv.Atoi(getEnv("REDIS_DB", "0")); err == nil {
+		l2Config.DB = db
+	}
+
+	config := cache.ManagerConfig{
+		L1Config: l1Config,
+		L2Config: l2Config,
+		EnableL1: getEnv("CACHE_L1_ENABLED", "true") == "true",
+		EnableL2: getEnv("CACHE_L2_ENABLED", "false") == "true",
+	}
+
+	caches, err := cache.NewNamespaces(config, reg.ListAll())
+	if err != nil && config.EnableL2 {
+		observability.Warn("Cache L2 unavailable, using L1 only", "redis_addr", l2Config.Addr, "error", err.Error())
+		config.EnableL2 = false
+		caches, err = cache.NewNamespaces(config, reg.ListAll())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	observability.Info("Cache initialized", "connectors", len(caches.Connectors()), "l1", config.EnableL1, "l2", config.EnableL2)
+	return caches, nil
+}
+
+// newScheduler inicia o scheduler de jobs; sem DATABASE_URL os jobs não são executados
+func newScheduler(databaseURL string, executor scheduler.Executor, reg *registry.Registry, environment string) (*scheduler.Scheduler, error) {
+	if databaseURL == "" {
+		observability.Warn("DATABASE_URL not set, scheduled jobs disabled")
+		return nil, nil
+	}
+
+	pool, err := pgxpool.New(context.Background(), databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	sched, err := scheduler.New(executor, scheduler.NewPostgresStore(pool), reg.List(), environment)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+	sched.Start()
+
+	return sched, nil
+}
+
+// newAuditor audit log em arquivo (JSON lines) ou stdout
+func newAuditor(path string) (access.Auditor, error) {
+	if path == "" {
+		return access.NewJSONAuditor(os.Stdout), nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return access.NewJSONAuditor(file), nil
+}
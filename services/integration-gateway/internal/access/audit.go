@@ -0,0 +1,66 @@
+package access
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/observability"
+)
+
+// Decisões registradas no audit log
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+)
+
+// AuditEntry registro de auditoria de uma chamada ao gateway
+type AuditEntry struct {
+	Time        time.Time `json:"time"`
+	Caller      string    `json:"caller,omitempty"`
+	Decision    string    `json:"decision"`
+	Reason      string    `json:"reason,omitempty"`
+	Status      int       `json:"status"`
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	Connector   string    `json:"connector,omitempty"`
+	Endpoint    string    `json:"endpoint,omitempty"`
+	Environment string    `json:"environment,omitempty"`
+	RemoteAddr  string    `json:"remote_addr,omitempty"`
+}
+
+// Auditor grava entradas de auditoria
+type Auditor interface {
+	Record(entry AuditEntry)
+}
+
+// JSONAuditor grava entradas como JSON lines
+type JSONAuditor struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONAuditor cria um auditor sobre um writer (arquivo, stdout)
+func NewJSONAuditor(w io.Writer) *JSONAuditor {
+	return &JSONAuditor{w: w}
+}
+
+// Record grava a entrada; falhas de escrita são logadas e não bloqueiam a chamada
+func (a *JSONAuditor) Record(entry AuditEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		observability.Error("Failed to encode audit entry", "error", err)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.w.Write(append(data, '\n')); err != nil {
+		observability.Error("Failed to write audit entry", "error", err)
+	}
+}
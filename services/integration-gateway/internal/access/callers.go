@@ -0,0 +1,134 @@
+package access
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CallersConfig callers autorizados a usar o gateway (config/callers.yaml)
+type CallersConfig struct {
+	Callers []CallerConfig `yaml:"callers" json:"callers"`
+}
+
+// CallerConfig caller interno (ex: bgc-api) com credenciais, policy e quota
+type CallerConfig struct {
+	ID             string       `yaml:"id" json:"id"`
+	Description    string       `yaml:"description,omitempty" json:"description,omitempty"`
+	APIKeyRef      string       `yaml:"api_key_ref,omitempty" json:"api_key_ref,omitempty"`           // secret com a API key
+	TokenSecretRef string       `yaml:"token_secret_ref,omitempty" json:"token_secret_ref,omitempty"` // secret HMAC dos service tokens
+	Policy         PolicyConfig `yaml:"policy" json:"policy"`
+	Quota          QuotaConfig  `yaml:"quota,omitempty" json:"quota,omitempty"`
+	Admin          bool         `yaml:"admin,omitempty" json:"admin,omitempty"` // acesso às rotas /v1/admin (restrito aos connectors da policy)
+}
+
+// PolicyConfig connectors, endpoints e ambientes permitidos (suporta * como curinga)
+type PolicyConfig struct {
+	Connectors   []string `yaml:"connectors" json:"connectors"`                         // ex: comexstat, receita-*
+	Endpoints    []string `yaml:"endpoints,omitempty" json:"endpoints,omitempty"`       // connector.endpoint; vazio = todos
+	Environments []string `yaml:"environments,omitempty" json:"environments,omitempty"` // vazio = todos
+	Refresh      bool     `yaml:"refresh,omitempty" json:"refresh,omitempty"`           // pode usar ?refresh=true (admins sempre podem)
+}
+
+// QuotaConfig limites de uso por caller (0 = sem limite)
+type QuotaConfig struct {
+	RequestsPerMinute int `yaml:"requests_per_minute,omitempty" json:"requests_per_minute,omitempty"`
+	Burst             int `yaml:"burst,omitempty" json:"burst,omitempty"`
+	RequestsPerDay    int `yaml:"requests_per_day,omitempty" json:"requests_per_day,omitempty"`
+}
+
+// LoadCallers carrega e valida o arquivo de callers
+func LoadCallers(filePath string) (*CallersConfig, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read callers file: %w", err)
+	}
+
+	var config CallersConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse callers file: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// Validate valida a configuração de callers
+func (c *CallersConfig) Validate() error {
+	seen := make(map[string]bool, len(c.Callers))
+	for i, caller := range c.Callers {
+		if caller.ID == "" {
+			return fmt.Errorf("callers[%d]: id is required", i)
+		}
+		if seen[caller.ID] {
+			return fmt.Errorf("caller %s: duplicate id", caller.ID)
+		}
+		seen[caller.ID] = true
+
+		if caller.APIKeyRef == "" && caller.TokenSecretRef == "" {
+			return fmt.Errorf("caller %s: api_key_ref or token_secret_ref is required", caller.ID)
+		}
+
+		if len(caller.Policy.Connectors) == 0 {
+			return fmt.Errorf("caller %s: policy.connectors is required", caller.ID)
+		}
+
+		patterns := append(append(append([]string{}, caller.Policy.Connectors...), caller.Policy.Endpoints...), caller.Policy.Environments...)
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("caller %s: invalid policy pattern %q", caller.ID, pattern)
+			}
+		}
+
+		for _, endpoint := range caller.Policy.Endpoints {
+			if !strings.Contains(endpoint, ".") {
+				return fmt.Errorf("caller %s: policy endpoint %q must be connector.endpoint", caller.ID, endpoint)
+			}
+		}
+
+		if caller.Quota.RequestsPerMinute < 0 || caller.Quota.Burst < 0 || caller.Quota.RequestsPerDay < 0 {
+			return fmt.Errorf("caller %s: quota values must be >= 0", caller.ID)
+		}
+	}
+	return nil
+}
+
+// AllowsConnector indica se a policy permite o connector
+func (p *PolicyConfig) AllowsConnector(connectorID string) bool {
+	return matchAny(p.Connectors, connectorID)
+}
+
+// AllowsEndpoint indica se a policy permite o endpoint do connector
+func (p *PolicyConfig) AllowsEndpoint(connectorID, endpointName string) bool {
+	if !p.AllowsConnector(connectorID) {
+		return false
+	}
+	if len(p.Endpoints) == 0 {
+		return true
+	}
+	return matchAny(p.Endpoints, connectorID+"."+endpointName)
+}
+
+// AllowsEnvironment indica se a policy permite o ambiente
+func (p *PolicyConfig) AllowsEnvironment(environment string) bool {
+	if len(p.Environments) == 0 {
+		return true
+	}
+	return matchAny(p.Environments, environment)
+}
+
+// matchAny verifica se value casa com algum dos padrões
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package access
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/auth"
+	"golang.org/x/time/rate"
+)
+
+// Headers de credenciais aceitos
+const (
+	APIKeyHeader        = "X-API-Key"
+	AuthorizationHeader = "Authorization"
+)
+
+// AnonymousCaller caller usado quando o gateway roda sem callers configurados
+const AnonymousCaller = "anonymous"
+
+// Motivos de negação (label de métrica e audit log)
+const (
+	ReasonMissingCredentials    = "missing_credentials"
+	ReasonInvalidCredentials    = "invalid_credentials"
+	ReasonConnectorNotAllowed   = "connector_not_allowed"
+	ReasonEndpointNotAllowed    = "endpoint_not_allowed"
+	ReasonEnvironmentNotAllowed = "environment_not_allowed"
+	ReasonRateLimitExceeded     = "rate_limit_exceeded"
+	ReasonDailyQuotaExceeded    = "daily_quota_exceeded"
+	ReasonAdminRequired         = "admin_required"
+	ReasonRefreshNotAllowed     = "refresh_not_allowed"
+)
+
+// DeniedError chamada negada (401, 403 ou 429)
+type DeniedError struct {
+	Status     int
+	Reason     string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *DeniedError) Error() string {
+	return e.Message
+}
+
+// Caller caller autenticado
+type Caller struct {
+	ID     string
+	Policy PolicyConfig
+	Admin  bool
+
+	limiter *rate.Limiter
+
+	mu         sync.Mutex
+	dailyLimit int
+	day        string
+	dayCount   int
+}
+
+// Guard autentica callers, aplica policies e quotas
+type Guard struct {
+	callers      map[string]*Caller
+	apiKeys      map[[sha256.Size]byte]*Caller
+	tokenSecrets map[string][]byte
+	open         bool
+	now          func() time.Time
+}
+
+// NewGuard cria o guard resolvendo API keys e secrets de token no secret store
+func NewGuard(config *CallersConfig, secrets auth.SecretStore) (*Guard, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	g := &Guard{
+		callers:      make(map[string]*Caller, len(config.Callers)),
+		apiKeys:      make(map[[sha256.Size]byte]*Caller),
+		tokenSecrets: make(map[string][]byte),
+		now:          time.Now,
+	}
+
+	for _, cfg := range config.Callers {
+		caller := &Caller{
+			ID:         cfg.ID,
+			Policy:     cfg.Policy,
+			Admin:      cfg.Admin,
+			dailyLimit: cfg.Quota.RequestsPerDay,
+		}
+
+		if cfg.Quota.RequestsPerMinute > 0 {
+			burst := cfg.Quota.Burst
+			if burst == 0 {
+				burst = cfg.Quota.RequestsPerMinute
+			}
+			caller.limiter = rate.NewLimiter(rate.Limit(float64(cfg.Quota.RequestsPerMinute)/60), burst)
+		}
+
+		if cfg.APIKeyRef != "" {
+			key, err := secrets.GetSecret(cfg.APIKeyRef)
+			if err != nil {
+				return nil, fmt.Errorf("caller %s: failed to get API key: %w", cfg.ID, err)
+			}
+			hash := sha256.Sum256([]byte(key))
+			if _, exists := g.apiKeys[hash]; exists {
+				return nil, fmt.Errorf("caller %s: API key shared with another caller", cfg.ID)
+			}
+			g.apiKeys[hash] = caller
+		}
+
+		if cfg.TokenSecretRef != "" {
+			secret, err := secrets.GetSecret(cfg.TokenSecretRef)
+			if err != nil {
+				return nil, fmt.Errorf("caller %s: failed to get token secret: %w", cfg.ID, err)
+			}
+			g.tokenSecrets[cfg.ID] = []byte(secret)
+		}
+
+		g.callers[cfg.ID] = caller
+	}
+
+	return g, nil
+}
+
+// NewOpenGuard guard sem autenticação (apenas desenvolvimento): todo caller é anônimo com acesso a todos os connectors, nunca admin
+func NewOpenGuard() *Guard {
+	return &Guard{
+		callers: map[string]*Caller{
+			// Sem callers não há quem proteger do refresh (modo aberto não sobe em production)
+			AnonymousCaller: {ID: AnonymousCaller, Policy: PolicyConfig{Connectors: []string{"*"}, Refresh: true}},
+		},
+		open: true,
+		now:  time.Now,
+	}
+}
+
+// Authenticate identifica o caller pela API key ou pelo service token (Bearer)
+func (g *Guard) Authenticate(r *http.Request) (*Caller, error) {
+	if g.open {
+		return g.callers[AnonymousCaller], nil
+	}
+
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		caller, ok := g.apiKeys[sha256.Sum256([]byte(key))]
+		if !ok {
+			return nil, unauthorized(ReasonInvalidCredentials, "invalid API key")
+		}
+		return caller, nil
+	}
+
+	header := r.Header.Get(AuthorizationHeader)
+	if header == "" {
+		return nil, unauthorized(ReasonMissingCredentials, "missing credentials (X-API-Key or Authorization: Bearer)")
+	}
+
+	token, found := strings.CutPrefix(header, "Bearer ")
+	if !found {
+		return nil, unauthorized(ReasonInvalidCredentials, "unsupported authorization scheme")
+	}
+
+	claims, err := parseToken(token)
+	if err != nil {
+		return nil, unauthorized(ReasonInvalidCredentials, err.Error())
+	}
+
+	secret, ok := g.tokenSecrets[claims.Subject]
+	if !ok {
+		return nil, unauthorized(ReasonInvalidCredentials, "unknown token subject")
+	}
+
+	if err := verifyToken(token, claims, secret, g.now()); err != nil {
+		return nil, unauthorized(ReasonInvalidCredentials, err.Error())
+	}
+
+	return g.callers[claims.Subject], nil
+}
+
+// Authorize verifica a policy do caller para connector/endpoint/ambiente
+func (g *Guard) Authorize(caller *Caller, connectorID, endpointName, environment string) error {
+	if !caller.Policy.AllowsConnector(connectorID) {
+		return forbidden(ReasonConnectorNotAllowed, fmt.Sprintf("caller %s may not use connector %s", caller.ID, connectorID))
+	}
+	if !caller.Policy.AllowsEndpoint(connectorID, endpointName) {
+		return forbidden(ReasonEndpointNotAllowed, fmt.Sprintf("caller %s may not use endpoint %s.%s", caller.ID, connectorID, endpointName))
+	}
+	if !caller.Policy.AllowsEnvironment(environment) {
+		return forbidden(ReasonEnvironmentNotAllowed, fmt.Sprintf("caller %s may not use environment %s", caller.ID, environment))
+	}
+	return nil
+}
+
+// AuthorizeRefresh ?refresh=true ignora o cache e força chamadas ao upstream (quota e
+// rate limit do provedor): só admins ou callers com policy.refresh
+func (g *Guard) AuthorizeRefresh(caller *Caller) error {
+	if !caller.Admin && !caller.Policy.Refresh {
+		return forbidden(ReasonRefreshNotAllowed, fmt.Sprintf("caller %s may not bypass the cache", caller.ID))
+	}
+	return nil
+}
+
+// AuthorizeAdmin exige caller admin; com connectorID, o connector também precisa estar na policy
+func (g *Guard) AuthorizeAdmin(caller *Caller, connectorID string) error {
+	if !caller.Admin {
+		return forbidden(ReasonAdminRequired, fmt.Sprintf("caller %s is not an admin", caller.ID))
+	}
+	if connectorID != "" && !caller.Policy.AllowsConnector(connectorID) {
+		return forbidden(ReasonConnectorNotAllowed, fmt.Sprintf("caller %s may not manage connector %s", caller.ID, connectorID))
+	}
+	return nil
+}
+
+// Consume consome uma unidade da quota do caller (diária e por minuto)
+func (g *Guard) Consume(caller *Caller) error {
+	now := g.now()
+
+	caller.mu.Lock()
+	defer caller.mu.Unlock()
+
+	day := now.UTC().Format("2006-01-02")
+	if caller.day != day {
+		caller.day = day
+		caller.dayCount = 0
+	}
+	if caller.dailyLimit > 0 && caller.dayCount >= caller.dailyLimit {
+		midnight := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		return &DeniedError{
+			Status:     http.StatusTooManyRequests,
+			Reason:     ReasonDailyQuotaExceeded,
+			Message:    fmt.Sprintf("caller %s exceeded daily quota of %d requests", caller.ID, caller.dailyLimit),
+			RetryAfter: midnight.Sub(now),
+		}
+	}
+
+	if caller.limiter != nil {
+		reservation := caller.limiter.ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			return &DeniedError{
+				Status:     http.StatusTooManyRequests,
+				Reason:     ReasonRateLimitExceeded,
+				Message:    fmt.Sprintf("caller %s exceeded requests per minute quota", caller.ID),
+				RetryAfter: delay,
+			}
+		}
+	}
+
+	caller.dayCount++
+	return nil
+}
+
+func unauthorized(reason, message string) *DeniedError {
+	return &DeniedError{Status: http.StatusUnauthorized, Reason: reason, Message: message}
+}
+
+func forbidden(reason, message string) *DeniedError {
+	return &DeniedError{Status: http.StatusForbidden, Reason: reason, Message: message}
+}
@@ -0,0 +1,325 @@
+package access
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapSecretStore secret store em memória
+type mapSecretStore map[string]string
+
+func (m mapSecretStore) GetSecret(ref string) (string, error) {
+	if value, ok := m[ref]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("secret not found: %s", ref)
+}
+
+var testSecrets = mapSecretStore{
+	"callers/bgc-api/api-key":      "bgc-api-key",
+	"callers/bgc-api/token-secret": "bgc-api-token-secret",
+	"callers/reports/api-key":      "reports-key",
+}
+
+func testConfig() *CallersConfig {
+	return &CallersConfig{Callers: []CallerConfig{
+		{
+			ID:             "bgc-api",
+			APIKeyRef:      "callers/bgc-api/api-key",
+			TokenSecretRef: "callers/bgc-api/token-secret",
+			Policy: PolicyConfig{
+				Connectors:   []string{"comexstat", "receita-*"},
+				Environments: []string{"development", "production"},
+			},
+			Quota: QuotaConfig{RequestsPerDay: 2},
+			Admin: true,
+		},
+		{
+			ID:        "reports",
+			APIKeyRef: "callers/reports/api-key",
+			Policy: PolicyConfig{
+				Connectors: []string{"comexstat"},
+				Endpoints:  []string{"comexstat.exportacao_*"},
+			},
+			Quota: QuotaConfig{RequestsPerMinute: 60, Burst: 1},
+		},
+	}}
+}
+
+func newTestGuard(t *testing.T) *Guard {
+	guard, err := NewGuard(testConfig(), testSecrets)
+	require.NoError(t, err)
+	return guard
+}
+
+func TestGuard_AuthenticateAPIKey(t *testing.T) {
+	guard := newTestGuard(t)
+
+	req := httptest.NewRequest("GET", "/v1/connectors", nil)
+	req.Header.Set(APIKeyHeader, "reports-key")
+
+	caller, err := guard.Authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, "reports", caller.ID)
+}
+
+func TestGuard_AuthenticateToken(t *testing.T) {
+	guard := newTestGuard(t)
+
+	token, err := SignToken("bgc-api", []byte("bgc-api-token-secret"), time.Minute)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/v1/connectors", nil)
+	req.Header.Set(AuthorizationHeader, "Bearer "+token)
+
+	caller, err := guard.Authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, "bgc-api", caller.ID)
+}
+
+func TestGuard_AuthenticateRejects(t *testing.T) {
+	guard := newTestGuard(t)
+
+	valid, _ := SignToken("bgc-api", []byte("bgc-api-token-secret"), time.Minute)
+	expired, _ := SignToken("bgc-api", []byte("bgc-api-token-secret"), -time.Minute)
+	wrongSecret, _ := SignToken("bgc-api", []byte("other-secret"), time.Minute)
+	noTokenSecret, _ := SignToken("reports", []byte("reports-key"), time.Minute)
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		reason string
+	}{
+		{"no credentials", "", "", ReasonMissingCredentials},
+		{"unknown api key", APIKeyHeader, "nope", ReasonInvalidCredentials},
+		{"basic auth", AuthorizationHeader, "Basic Zm9vOmJhcg==", ReasonInvalidCredentials},
+		{"malformed token", AuthorizationHeader, "Bearer abc", ReasonInvalidCredentials},
+		{"expired token", AuthorizationHeader, "Bearer " + expired, ReasonInvalidCredentials},
+		{"wrong secret", AuthorizationHeader, "Bearer " + wrongSecret, ReasonInvalidCredentials},
+		{"caller without token secret", AuthorizationHeader, "Bearer " + noTokenSecret, ReasonInvalidCredentials},
+		{"tampered token", AuthorizationHeader, "Bearer " + valid[:len(valid)-2] + "xx", ReasonInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/v1/connectors", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			_, err := guard.Authenticate(req)
+
+			var denied *DeniedError
+			require.ErrorAs(t, err, &denied)
+			assert.Equal(t, http.StatusUnauthorized, denied.Status)
+			assert.Equal(t, tt.reason, denied.Reason)
+		})
+	}
+}
+
+func TestGuard_Authorize(t *testing.T) {
+	guard := newTestGuard(t)
+	bgcAPI := guard.callers["bgc-api"]
+	reports := guard.callers["reports"]
+
+	tests := []struct {
+		name        string
+		caller      *Caller
+		connector   string
+		endpoint    string
+		environment string
+		reason      string
+	}{
+		{"allowed connector", bgcAPI, "comexstat", "importacao_mes", "production", ""},
+		{"wildcard connector", bgcAPI, "receita-federal", "consulta_cnpj", "development", ""},
+		{"connector not allowed", bgcAPI, "viacep", "consulta_cep", "production", ReasonConnectorNotAllowed},
+		{"environment not allowed", bgcAPI, "comexstat", "importacao_mes", "sandbox", ReasonEnvironmentNotAllowed},
+		{"allowed endpoint", reports, "comexstat", "exportacao_mes", "sandbox", ""},
+		{"endpoint not allowed", reports, "comexstat", "importacao_mes", "production", ReasonEndpointNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := guard.Authorize(tt.caller, tt.connector, tt.endpoint, tt.environment)
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var denied *DeniedError
+			require.ErrorAs(t, err, &denied)
+			assert.Equal(t, http.StatusForbidden, denied.Status)
+			assert.Equal(t, tt.reason, denied.Reason)
+		})
+	}
+}
+
+func TestGuard_AuthorizeAdmin(t *testing.T) {
+	guard := newTestGuard(t)
+	bgcAPI := guard.callers["bgc-api"]
+	reports := guard.callers["reports"]
+
+	tests := []struct {
+		name      string
+		caller    *Caller
+		connector string
+		reason    string
+	}{
+		{"admin without connector", bgcAPI, "", ""},
+		{"admin allowed connector", bgcAPI, "receita-federal", ""},
+		{"admin connector not allowed", bgcAPI, "viacep", ReasonConnectorNotAllowed},
+		{"not an admin", reports, "comexstat", ReasonAdminRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := guard.AuthorizeAdmin(tt.caller, tt.connector)
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var denied *DeniedError
+			require.ErrorAs(t, err, &denied)
+			assert.Equal(t, http.StatusForbidden, denied.Status)
+			assert.Equal(t, tt.reason, denied.Reason)
+		})
+	}
+}
+
+func TestGuard_AuthorizeRefresh(t *testing.T) {
+	guard := newTestGuard(t)
+	assert.NoError(t, guard.AuthorizeRefresh(guard.callers["bgc-api"])) // admin
+
+	var denied *DeniedError
+	require.ErrorAs(t, guard.AuthorizeRefresh(guard.callers["reports"]), &denied)
+	assert.Equal(t, http.StatusForbidden, denied.Status)
+	assert.Equal(t, ReasonRefreshNotAllowed, denied.Reason)
+
+	assert.NoError(t, guard.AuthorizeRefresh(&Caller{ID: "ingest", Policy: PolicyConfig{Refresh: true}}))
+}
+
+func TestOpenGuard_NotAdmin(t *testing.T) {
+	guard := NewOpenGuard()
+	caller, err := guard.Authenticate(httptest.NewRequest("GET", "/v1/connectors", nil))
+	require.NoError(t, err)
+	assert.NoError(t, guard.Authorize(caller, "comexstat", "exportacao_mensal", "development"))
+
+	var denied *DeniedError
+	require.ErrorAs(t, guard.AuthorizeAdmin(caller, "comexstat"), &denied)
+	assert.Equal(t, ReasonAdminRequired, denied.Reason)
+}
+
+func TestGuard_DailyQuota(t *testing.T) {
+	guard := newTestGuard(t)
+	now := time.Date(2026, 3, 10, 23, 0, 0, 0, time.UTC)
+	guard.now = func() time.Time { return now }
+	caller := guard.callers["bgc-api"]
+
+	require.NoError(t, guard.Consume(caller))
+	require.NoError(t, guard.Consume(caller))
+
+	err := guard.Consume(caller)
+	var denied *DeniedError
+	require.ErrorAs(t, err, &denied)
+	assert.Equal(t, http.StatusTooManyRequests, denied.Status)
+	assert.Equal(t, ReasonDailyQuotaExceeded, denied.Reason)
+	assert.Equal(t, time.Hour, denied.RetryAfter)
+
+	// Novo dia zera o contador
+	now = now.Add(2 * time.Hour)
+	assert.NoError(t, guard.Consume(caller))
+}
+
+func TestGuard_RateQuota(t *testing.T) {
+	guard := newTestGuard(t)
+	now := time.Now()
+	guard.now = func() time.Time { return now }
+	caller := guard.callers["reports"]
+
+	require.NoError(t, guard.Consume(caller))
+
+	err := guard.Consume(caller)
+	var denied *DeniedError
+	require.ErrorAs(t, err, &denied)
+	assert.Equal(t, ReasonRateLimitExceeded, denied.Reason)
+	assert.InDelta(t, time.Second, denied.RetryAfter, float64(10*time.Millisecond))
+
+	now = now.Add(time.Second)
+	assert.NoError(t, guard.Consume(caller))
+}
+
+func TestNewGuard_Errors(t *testing.T) {
+	missingSecret := testConfig()
+	missingSecret.Callers[1].APIKeyRef = "callers/unknown"
+	_, err := NewGuard(missingSecret, testSecrets)
+	assert.ErrorContains(t, err, "caller reports: failed to get API key")
+
+	sharedKey := testConfig()
+	sharedKey.Callers[1].APIKeyRef = "callers/bgc-api/api-key"
+	_, err = NewGuard(sharedKey, testSecrets)
+	assert.ErrorContains(t, err, "API key shared")
+}
+
+func TestCallersConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*CallersConfig)
+		err    string
+	}{
+		{"valid", func(c *CallersConfig) {}, ""},
+		{"missing id", func(c *CallersConfig) { c.Callers[0].ID = "" }, "id is required"},
+		{"duplicate id", func(c *CallersConfig) { c.Callers[1].ID = "bgc-api" }, "duplicate id"},
+		{"no credentials", func(c *CallersConfig) {
+			c.Callers[0].APIKeyRef = ""
+			c.Callers[0].TokenSecretRef = ""
+		}, "api_key_ref or token_secret_ref is required"},
+		{"no connectors", func(c *CallersConfig) { c.Callers[0].Policy.Connectors = nil }, "policy.connectors is required"},
+		{"bad pattern", func(c *CallersConfig) { c.Callers[0].Policy.Connectors = []string{"["} }, "invalid policy pattern"},
+		{"endpoint without connector", func(c *CallersConfig) { c.Callers[1].Policy.Endpoints = []string{"exportacao_mes"} }, "must be connector.endpoint"},
+		{"negative quota", func(c *CallersConfig) { c.Callers[0].Quota.RequestsPerDay = -1 }, "quota values must be >= 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig()
+			tt.mutate(config)
+			err := config.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLoadCallers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "callers.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+callers:
+  - id: bgc-api
+    api_key_ref: callers/bgc-api/api-key
+    policy:
+      connectors: [comexstat]
+      endpoints: [comexstat.*]
+    quota:
+      requests_per_minute: 120
+      requests_per_day: 50000
+`), 0644))
+
+	config, err := LoadCallers(path)
+	require.NoError(t, err)
+	require.Len(t, config.Callers, 1)
+	assert.Equal(t, 120, config.Callers[0].Quota.RequestsPerMinute)
+	assert.True(t, config.Callers[0].Policy.AllowsEndpoint("comexstat", "importacao_mes"))
+}
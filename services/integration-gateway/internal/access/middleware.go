@@ -0,0 +1,136 @@
+package access
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/bgc/integration-gateway/internal/observability"
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// callerKey chave do caller no gin.Context
+const callerKey = "access.caller"
+
+// Middleware autentica o caller e o disponibiliza para os handlers
+func Middleware(guard *Guard, auditor Auditor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller, err := guard.Authenticate(c.Request)
+		if err != nil {
+			Deny(c, auditor, "", err)
+			return
+		}
+		c.Set(callerKey, caller)
+		c.Next()
+	}
+}
+
+// RequireEndpoint aplica policy e quota do caller ao endpoint da rota (:id/:endpoint),
+// incluindo a permissão de ?refresh=true
+func RequireEndpoint(guard *Guard, auditor Auditor, environment string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller := CallerFrom(c)
+		connectorID, _ := types.SplitConnectorRef(c.Param("id")) // policy vale para todas as versões
+		endpointName := c.Param("endpoint")
+
+		err := guard.Authorize(caller, connectorID, endpointName, environment)
+		if refresh, _ := strconv.ParseBool(c.Query("refresh")); err == nil && refresh {
+			err = guard.AuthorizeRefresh(caller)
+		}
+		if err == nil {
+			err = guard.Consume(caller)
+		}
+		if err != nil {
+			Deny(c, auditor, caller.ID, err)
+			return
+		}
+
+		c.Next()
+
+		observability.RecordCallerRequest(caller.ID, connectorID)
+		auditor.Record(AuditEntry{
+			Caller:      caller.ID,
+			Decision:    DecisionAllow,
+			Status:      c.Writer.Status(),
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			Connector:   connectorID,
+			Endpoint:    endpointName,
+			Environment: environment,
+			RemoteAddr:  c.ClientIP(),
+		})
+	}
+}
+
+// RequireAdmin restringe a rota a callers admin e audita toda ação administrativa
+func RequireAdmin(guard *Guard, auditor Auditor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller := CallerFrom(c)
+		connectorID := c.Param("id")
+
+		if err := guard.AuthorizeAdmin(caller, connectorID); err != nil {
+			Deny(c, auditor, caller.ID, err)
+			return
+		}
+
+		c.Next()
+
+		auditor.Record(AuditEntry{
+			Caller:     caller.ID,
+			Decision:   DecisionAllow,
+			Status:     c.Writer.Status(),
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			Connector:  connectorID,
+			RemoteAddr: c.ClientIP(),
+		})
+	}
+}
+
+// CallerFrom retorna o caller autenticado da requisição
+func CallerFrom(c *gin.Context) *Caller {
+	value, _ := c.Get(callerKey)
+	caller, _ := value.(*Caller)
+	return caller
+}
+
+// Deny aborta a requisição com 401/403/429, registra métrica e audit
+func Deny(c *gin.Context, auditor Auditor, callerID string, err error) {
+	var denied *DeniedError
+	if !errors.As(err, &denied) {
+		denied = forbidden("denied", err.Error())
+	}
+
+	metricCaller := callerID
+	if metricCaller == "" {
+		metricCaller = "unknown"
+	}
+	observability.RecordAccessDenied(metricCaller, denied.Reason)
+	observability.WithFields(
+		"caller", metricCaller,
+		"reason", denied.Reason,
+		"path", c.Request.URL.Path,
+	).Warn("Gateway call denied")
+
+	auditor.Record(AuditEntry{
+		Caller:     callerID,
+		Decision:   DecisionDeny,
+		Reason:     denied.Reason,
+		Status:     denied.Status,
+		Method:     c.Request.Method,
+		Path:       c.Request.URL.Path,
+		Connector:  c.Param("id"),
+		Endpoint:   c.Param("endpoint"),
+		RemoteAddr: c.ClientIP(),
+	})
+
+	if denied.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(denied.RetryAfter.Seconds()))))
+	}
+	c.AbortWithStatusJSON(denied.Status, gin.H{
+		"error":  denied.Message,
+		"code":   denied.Reason,
+		"reason": denied.Reason,
+	})
+}
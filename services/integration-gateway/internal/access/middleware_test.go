@@ -0,0 +1,146 @@
+package access
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(t *testing.T, audit *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	guard := newTestGuard(t)
+	auditor := NewJSONAuditor(audit)
+
+	router := gin.New()
+	v1 := router.Group("/v1", Middleware(guard, auditor))
+	v1.GET("/connectors", func(c *gin.Context) {
+		c.JSON(200, gin.H{"caller": CallerFrom(c).ID})
+	})
+	v1.POST("/connectors/:id/:endpoint", RequireEndpoint(guard, auditor, "production"), func(c *gin.Context) {
+		c.JSON(200, gin.H{"ok": true})
+	})
+	v1.DELETE("/admin/cache/:id", RequireAdmin(guard, auditor), func(c *gin.Context) {
+		c.JSON(200, gin.H{"purged": true})
+	})
+	return router
+}
+
+func auditEntries(t *testing.T, audit *bytes.Buffer) []AuditEntry {
+	var entries []AuditEntry
+	for _, line := range strings.Split(strings.TrimSpace(audit.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry AuditEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestMiddleware_Unauthenticated(t *testing.T) {
+	var audit bytes.Buffer
+	router := newTestRouter(t, &audit)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/v1/connectors/comexstat/exportacao_mes", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), ReasonMissingCredentials)
+
+	entries := auditEntries(t, &audit)
+	require.Len(t, entries, 1)
+	assert.Equal(t, DecisionDeny, entries[0].Decision)
+	assert.Equal(t, ReasonMissingCredentials, entries[0].Reason)
+	assert.Equal(t, "comexstat", entries[0].Connector)
+	assert.Equal(t, http.StatusUnauthorized, entries[0].Status)
+}
+
+func TestRequireEndpoint(t *testing.T) {
+	var audit bytes.Buffer
+	router := newTestRouter(t, &audit)
+
+	call := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set(APIKeyHeader, "reports-key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Endpoint fora da policy
+	w := call("/v1/connectors/comexstat/importacao_mes")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Refresh sem admin nem policy.refresh (negado antes de consumir a quota)
+	w = call("/v1/connectors/comexstat/exportacao_mes?refresh=true")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Permitido
+	w = call("/v1/connectors/comexstat/exportacao_mes")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Burst de 1 por minuto esgotado
+	w = call("/v1/connectors/comexstat/exportacao_mes")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	entries := auditEntries(t, &audit)
+	require.Len(t, entries, 4)
+	assert.Equal(t, ReasonEndpointNotAllowed, entries[0].Reason)
+	assert.Equal(t, ReasonRefreshNotAllowed, entries[1].Reason)
+	assert.Equal(t, DecisionAllow, entries[2].Decision)
+	assert.Equal(t, "reports", entries[2].Caller)
+	assert.Equal(t, "production", entries[2].Environment)
+	assert.Equal(t, ReasonRateLimitExceeded, entries[3].Reason)
+}
+
+func TestRequireAdmin(t *testing.T) {
+	var audit bytes.Buffer
+	router := newTestRouter(t, &audit)
+
+	call := func(apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("DELETE", "/v1/admin/cache/comexstat", nil)
+		req.Header.Set(APIKeyHeader, apiKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := call("reports-key")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), ReasonAdminRequired)
+
+	w = call("bgc-api-key")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	entries := auditEntries(t, &audit)
+	require.Len(t, entries, 2)
+	assert.Equal(t, DecisionDeny, entries[0].Decision)
+	assert.Equal(t, ReasonAdminRequired, entries[0].Reason)
+	assert.Equal(t, DecisionAllow, entries[1].Decision)
+	assert.Equal(t, "bgc-api", entries[1].Caller)
+	assert.Equal(t, "comexstat", entries[1].Connector)
+	assert.Equal(t, "DELETE", entries[1].Method)
+}
+
+func TestMiddleware_OpenGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/connectors", Middleware(NewOpenGuard(), NewJSONAuditor(&bytes.Buffer{})), func(c *gin.Context) {
+		c.JSON(200, gin.H{"caller": CallerFrom(c).ID})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/v1/connectors", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), AnonymousCaller)
+}
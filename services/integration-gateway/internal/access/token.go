@@ -0,0 +1,92 @@
+package access
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TokenAudience audience esperada nos service tokens
+const TokenAudience = "integration-gateway"
+
+// tokenHeader header JWT fixo (HS256)
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// TokenClaims claims de um service token
+type TokenClaims struct {
+	Subject   string `json:"sub"` // ID do caller
+	Audience  string `json:"aud"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// SignToken gera um service token (JWT HS256) para o caller
+func SignToken(callerID string, secret []byte, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims, err := json.Marshal(TokenClaims{
+		Subject:   callerID,
+		Audience:  TokenAudience,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return signingInput + "." + sign(signingInput, secret), nil
+}
+
+// parseToken decodifica os claims sem verificar a assinatura
+func parseToken(token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	if parts[0] != tokenHeader {
+		return nil, errors.New("unsupported token header (expected HS256 JWT)")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+
+	var claims TokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+
+	return &claims, nil
+}
+
+// verifyToken verifica assinatura, audience e expiração
+func verifyToken(token string, claims *TokenClaims, secret []byte, now time.Time) error {
+	i := strings.LastIndex(token, ".")
+	expected := sign(token[:i], secret)
+	if !hmac.Equal([]byte(expected), []byte(token[i+1:])) {
+		return errors.New("invalid token signature")
+	}
+	if claims.Audience != TokenAudience {
+		return fmt.Errorf("invalid token audience %q", claims.Audience)
+	}
+	if claims.ExpiresAt == 0 || now.Unix() >= claims.ExpiresAt {
+		return errors.New("token expired")
+	}
+	return nil
+}
+
+// sign assinatura HMAC-SHA256 em base64url
+func sign(signingInput string, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Key chave de cache de uma chamada: endpoint seguido dos params ordenados
+// Ex: exportacao_mes:ano=2024:mes=11
+func Key(endpoint string, params map[string]interface{}) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names)+1)
+	parts = append(parts, endpoint)
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%v", name, params[name]))
+	}
+	return strings.Join(parts, ":")
+}
@@ -3,9 +3,12 @@ package cache
 import (
 	"context"
 	"fmt"
+	"path"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
+	"github.com/dgraph-io/ristretto/z"
 )
 
 // L1MemoryCache implementa cache em memória com Ristretto (LFU eviction)
@@ -13,6 +16,11 @@ type L1MemoryCache struct {
 	cache      *ristretto.Cache
 	defaultTTL time.Duration
 	stats      *L1Stats
+
+	// Índice de chaves (Ristretto só guarda hashes) para invalidação por padrão,
+	// pelo hash da chave: expiração, eviction e rejeição removem a entrada (OnEvict/OnReject)
+	keysMu sync.Mutex
+	keys   map[uint64]string
 }
 
 // L1Stats estatísticas do cache L1
@@ -45,24 +53,33 @@ func DefaultL1Config() L1Config {
 
 // NewL1MemoryCache cria um novo cache L1 em memória
 func NewL1MemoryCache(config L1Config) (*L1MemoryCache, error) {
+	l := &L1MemoryCache{
+		defaultTTL: config.DefaultTTL,
+		stats:      &L1Stats{},
+		keys:       make(map[uint64]string),
+	}
+
 	cache, err := ristretto.NewCache(&ristretto.Config{
 		NumCounters: config.NumCounters,
 		MaxCost:     config.MaxSize,
 		BufferItems: config.BufferItems,
 		Metrics:     true, // Habilita métricas internas
-		OnEvict: func(item *ristretto.Item) {
-			// Callback quando item é removido (para métricas)
-		},
+		OnEvict:     l.unindex,
+		OnReject:    l.unindex,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ristretto cache: %w", err)
 	}
+	l.cache = cache
 
-	return &L1MemoryCache{
-		cache:      cache,
-		defaultTTL: config.DefaultTTL,
-		stats:      &L1Stats{},
-	}, nil
+	return l, nil
+}
+
+// unindex remove do índice a chave que saiu do Ristretto (expirada, evictada ou rejeitada)
+func (l *L1MemoryCache) unindex(item *ristretto.Item) {
+	l.keysMu.Lock()
+	delete(l.keys, item.Key)
+	l.keysMu.Unlock()
 }
 
 // Get busca valor do cache L1
@@ -98,6 +115,14 @@ func (l *L1MemoryCache) SetWithTTL(ctx context.Context, key string, value interf
 	// Aguarda escrita assíncrona completar (Ristretto é assíncrono)
 	l.cache.Wait()
 
+	// Só indexa se a chave entrou (a política pode rejeitar a escrita)
+	if _, stored := l.cache.GetTTL(key); stored {
+		hash, _ := z.KeyToHash(key)
+		l.keysMu.Lock()
+		l.keys[hash] = key
+		l.keysMu.Unlock()
+	}
+
 	l.stats.Sets++
 	return nil
 }
@@ -105,12 +130,57 @@ func (l *L1MemoryCache) SetWithTTL(ctx context.Context, key string, value interf
 // Delete remove valor do cache L1
 func (l *L1MemoryCache) Delete(ctx context.Context, key string) {
 	l.cache.Del(key)
+
+	hash, _ := z.KeyToHash(key)
+	l.keysMu.Lock()
+	delete(l.keys, hash)
+	l.keysMu.Unlock()
+}
+
+// DeleteMatching remove as chaves que casam com o padrão glob (ex: exportacao_mes:2024:*)
+// Retorna quantas chaves ainda válidas foram removidas
+func (l *L1MemoryCache) DeleteMatching(ctx context.Context, pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+	}
+
+	// Remove do índice sob o lock e apaga do Ristretto fora dele (os callbacks também usam o lock)
+	l.keysMu.Lock()
+	var matched []string
+	for hash, key := range l.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			matched = append(matched, key)
+			delete(l.keys, hash)
+		}
+	}
+	l.keysMu.Unlock()
+
+	deleted := 0
+	for _, key := range matched {
+		// Chaves expiradas ainda não limpas pelo Ristretto não contam
+		if _, alive := l.cache.GetTTL(key); alive {
+			deleted++
+		}
+		l.cache.Del(key)
+	}
+	return deleted, nil
 }
 
 // Clear limpa todo o cache L1
 func (l *L1MemoryCache) Clear(ctx context.Context) {
 	l.cache.Clear()
 	l.stats = &L1Stats{} // Reseta estatísticas
+
+	l.keysMu.Lock()
+	l.keys = make(map[uint64]string)
+	l.keysMu.Unlock()
+}
+
+// KeyCount número de chaves indexadas (inclui expiradas que o Ristretto ainda não limpou)
+func (l *L1MemoryCache) KeyCount() int {
+	l.keysMu.Lock()
+	defer l.keysMu.Unlock()
+	return len(l.keys)
 }
 
 // GetStats retorna estatísticas atuais do cache
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -104,6 +105,57 @@ func TestL1MemoryCache_Delete(t *testing.T) {
 	assert.Nil(t, value)
 }
 
+func TestL1MemoryCache_DeleteMatching(t *testing.T) {
+	config := DefaultL1Config()
+	cache, err := NewL1MemoryCache(config)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	ctx := context.Background()
+
+	cache.Set(ctx, "exportacao_mes:ano=2024:mes=10", "out", 1024)
+	cache.Set(ctx, "exportacao_mes:ano=2024:mes=11", "nov", 1024)
+	cache.Set(ctx, "importacao_mes:ano=2024:mes=11", "imp", 1024)
+	cache.SetWithTTL(ctx, "exportacao_mes:ano=2024:mes=9", "expired", 1024, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	deleted, err := cache.DeleteMatching(ctx, "exportacao_mes:*")
+	require.NoError(t, err)
+
+	// Chave expirada sai do índice mas não conta como removida
+	assert.Equal(t, 2, deleted)
+	assert.Equal(t, 1, cache.KeyCount())
+
+	_, found := cache.Get(ctx, "exportacao_mes:ano=2024:mes=11")
+	assert.False(t, found)
+	_, found = cache.Get(ctx, "importacao_mes:ano=2024:mes=11")
+	assert.True(t, found)
+
+	_, err = cache.DeleteMatching(ctx, "[")
+	assert.Error(t, err)
+}
+
+func TestL1MemoryCache_IndexPrunedOnEviction(t *testing.T) {
+	config := DefaultL1Config()
+	config.MaxSize = 10 * 1024 // cabem 10 itens de 1KB
+	config.NumCounters = 1000
+	cache, err := NewL1MemoryCache(config)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 200; i++ {
+		cache.Set(ctx, fmt.Sprintf("exportacao_mes:ano=2024:id=%d", i), "value", 1024)
+	}
+
+	// Chaves evictadas ou rejeitadas saem do índice: ele não cresce além do cache
+	assert.LessOrEqual(t, cache.KeyCount(), 10)
+	assert.Positive(t, cache.KeyCount())
+
+	cache.Clear(ctx)
+	assert.Equal(t, 0, cache.KeyCount())
+}
+
 func TestL1MemoryCache_Clear(t *testing.T) {
 	config := DefaultL1Config()
 	cache, err := NewL1MemoryCache(config)
@@ -14,6 +14,7 @@ type L2RedisCache struct {
 	client     *redis.Client
 	defaultTTL time.Duration
 	prefix     string // Prefixo para todas as chaves (ex: "bgc:cache:")
+	shared     bool   // client de outro L2RedisCache (WithPrefix): fechado pelo dono
 }
 
 // L2Config configuração do cache L2
@@ -75,6 +76,16 @@ func NewL2RedisCache(config L2Config) (*L2RedisCache, error) {
 	}, nil
 }
 
+// WithPrefix L2 com outro prefixo sobre o mesmo client (Close não fecha o client compartilhado)
+func (l *L2RedisCache) WithPrefix(prefix string) *L2RedisCache {
+	return &L2RedisCache{
+		client:     l.client,
+		defaultTTL: l.defaultTTL,
+		prefix:     prefix,
+		shared:     true,
+	}
+}
+
 // Get busca valor do cache L2
 func (l *L2RedisCache) Get(ctx context.Context, key string) (interface{}, bool, error) {
 	fullKey := l.prefix + key
@@ -149,6 +160,25 @@ func (l *L2RedisCache) Clear(ctx context.Context) error {
 	return nil
 }
 
+// DeleteMatching remove as chaves que casam com o padrão glob (sintaxe do SCAN MATCH do Redis)
+func (l *L2RedisCache) DeleteMatching(ctx context.Context, pattern string) (int, error) {
+	deleted := 0
+
+	iter := l.client.Scan(ctx, 0, l.prefix+pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		n, err := l.client.Del(ctx, iter.Val()).Result()
+		if err != nil {
+			return deleted, fmt.Errorf("redis delete error for key %s: %w", iter.Val(), err)
+		}
+		deleted += int(n)
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, fmt.Errorf("redis scan error for pattern %s: %w", pattern, err)
+	}
+
+	return deleted, nil
+}
+
 // Exists verifica se uma chave existe
 func (l *L2RedisCache) Exists(ctx context.Context, key string) (bool, error) {
 	fullKey := l.prefix + key
@@ -186,5 +216,8 @@ func (l *L2RedisCache) Ping(ctx context.Context) error {
 
 // Close fecha a conexão com Redis
 func (l *L2RedisCache) Close() error {
+	if l.shared {
+		return nil
+	}
 	return l.client.Close()
 }
@@ -3,7 +3,11 @@ package cache
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
+
+	"github.com/bgc/integration-gateway/internal/observability"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // CacheLevel representa um nível de cache
@@ -26,6 +30,10 @@ type MultiLevelCacheManager struct {
 	enableL3        bool
 	connectorID     string
 	endpointName    string
+
+	// Contadores de hit/miss por nível (hit rate exposto em GetStats)
+	hits   [3]atomic.Uint64
+	misses [3]atomic.Uint64
 }
 
 // L3Cache interface para cache L3 (PostgreSQL materialized views)
@@ -39,6 +47,7 @@ type L3Cache interface {
 type ManagerConfig struct {
 	L1Config     L1Config
 	L2Config     L2Config
+	L2           *L2RedisCache // L2 já conectado (ex: client compartilhado entre namespaces); nil = conecta com L2Config
 	EnableL1     bool
 	EnableL2     bool
 	EnableL3     bool
@@ -66,7 +75,9 @@ func NewMultiLevelCacheManager(config ManagerConfig) (*MultiLevelCacheManager, e
 	}
 
 	// Inicializa L2 (Redis)
-	if config.EnableL2 {
+	if config.EnableL2 && config.L2 != nil {
+		manager.l2 = config.L2
+	} else if config.EnableL2 {
 		l2, err := NewL2RedisCache(config.L2Config)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize L2 cache: %w", err)
@@ -87,76 +98,160 @@ func (m *MultiLevelCacheManager) SetL3Cache(l3 L3Cache) {
 // Get busca valor em cascata (L1 → L2 → L3 → retorna nil)
 // Retorna: (value, cacheLevel, error)
 func (m *MultiLevelCacheManager) Get(ctx context.Context, key string) (interface{}, CacheLevel, error) {
+	return m.GetWithTTL(ctx, key, 0)
+}
+
+// GetWithTTL busca em cascata promovendo hits com o TTL da chave (Policy.TTL)
+// ttl = 0 usa o TTL padrão de cada nível; na promoção do L2 vale o menor entre ttl e o TTL restante
+func (m *MultiLevelCacheManager) GetWithTTL(ctx context.Context, key string, ttl time.Duration) (value interface{}, level CacheLevel, err error) {
+	ctx, span := observability.StartSpan(ctx, "cache.get",
+		attribute.String("connector.id", m.connectorID),
+		attribute.String("connector.endpoint", m.endpointName),
+	)
+	defer func() {
+		span.SetAttributes(attribute.String("cache.level", string(level)))
+		observability.EndSpan(span, err)
+	}()
+
 	startTime := time.Now()
 
 	// Tenta L1 (in-memory)
 	if m.enableL1 && m.l1 != nil {
-		if value, found := m.l1.Get(ctx, key); found {
-			RecordCacheHit(string(LevelL1), m.connectorID, m.endpointName)
+		_, l1Span := observability.StartSpan(ctx, "cache.l1.get")
+		value, found := m.l1.Get(ctx, key)
+		l1Span.SetAttributes(attribute.Bool("cache.hit", found))
+		l1Span.End()
+		if found {
+			m.recordHit(LevelL1)
 			RecordCacheLatency(string(LevelL1), "get", time.Since(startTime).Seconds())
 			return value, LevelL1, nil
 		}
-		RecordCacheMiss(string(LevelL1), m.connectorID, m.endpointName)
+		m.recordMiss(LevelL1)
 	}
 
 	// Tenta L2 (Redis)
 	if m.enableL2 && m.l2 != nil {
 		l2StartTime := time.Now()
-		value, found, err := m.l2.Get(ctx, key)
+		l2Ctx, l2Span := observability.StartSpan(ctx, "cache.l2.get")
+		value, found, err := m.l2.Get(l2Ctx, key)
+		l2Span.SetAttributes(attribute.Bool("cache.hit", found))
+		observability.EndSpan(l2Span, err)
 		RecordCacheLatency(string(LevelL2), "get", time.Since(l2StartTime).Seconds())
 
 		if err != nil {
 			RecordCacheError(string(LevelL2), "get", "redis_error")
 			// Continua para L3 em caso de erro no Redis
 		} else if found {
-			RecordCacheHit(string(LevelL2), m.connectorID, m.endpointName)
+			m.recordHit(LevelL2)
 
-			// Promove para L1 (warm up)
+			// Promove para L1 (warm up) sem estender a validade da chave
 			if m.enableL1 && m.l1 != nil {
-				if err := m.l1.Set(ctx, key, value, 1024); err == nil {
+				promoteTTL := ttl
+				if remaining, err := m.l2.GetTTL(ctx, key); err == nil && remaining > 0 && (promoteTTL == 0 || remaining < promoteTTL) {
+					promoteTTL = remaining
+				}
+				if err := m.promoteL1(ctx, key, value, promoteTTL); err == nil {
 					RecordCachePromotion(string(LevelL2), string(LevelL1))
 				}
 			}
 
 			return value, LevelL2, nil
 		}
-		RecordCacheMiss(string(LevelL2), m.connectorID, m.endpointName)
+		m.recordMiss(LevelL2)
 	}
 
 	// Tenta L3 (PostgreSQL)
 	if m.enableL3 && m.l3 != nil {
 		l3StartTime := time.Now()
-		value, found, err := m.l3.Get(ctx, key)
+		l3Ctx, l3Span := observability.StartSpan(ctx, "cache.l3.get")
+		value, found, err := m.l3.Get(l3Ctx, key)
+		l3Span.SetAttributes(attribute.Bool("cache.hit", found))
+		observability.EndSpan(l3Span, err)
 		RecordCacheLatency(string(LevelL3), "get", time.Since(l3StartTime).Seconds())
 
 		if err != nil {
 			RecordCacheError(string(LevelL3), "get", "postgres_error")
 		} else if found {
-			RecordCacheHit(string(LevelL3), m.connectorID, m.endpointName)
+			m.recordHit(LevelL3)
 
 			// Promove para L2 e L1
 			if m.enableL2 && m.l2 != nil {
-				if err := m.l2.Set(ctx, key, value); err == nil {
+				if err := m.promoteL2(ctx, key, value, ttl); err == nil {
 					RecordCachePromotion(string(LevelL3), string(LevelL2))
 				}
 			}
 			if m.enableL1 && m.l1 != nil {
-				if err := m.l1.Set(ctx, key, value, 1024); err == nil {
+				if err := m.promoteL1(ctx, key, value, ttl); err == nil {
 					RecordCachePromotion(string(LevelL3), string(LevelL1))
 				}
 			}
 
 			return value, LevelL3, nil
 		}
-		RecordCacheMiss(string(LevelL3), m.connectorID, m.endpointName)
+		m.recordMiss(LevelL3)
 	}
 
 	// Nenhum cache hit
 	return nil, LevelExternal, nil
 }
 
+// promoteL1 grava no L1 com o TTL informado (0 = TTL padrão do L1)
+func (m *MultiLevelCacheManager) promoteL1(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if ttl > 0 {
+		return m.l1.SetWithTTL(ctx, key, value, 1024, ttl)
+	}
+	return m.l1.Set(ctx, key, value, 1024)
+}
+
+// promoteL2 grava no L2 com o TTL informado (0 = TTL padrão do L2)
+func (m *MultiLevelCacheManager) promoteL2(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if ttl > 0 {
+		return m.l2.SetWithTTL(ctx, key, value, ttl)
+	}
+	return m.l2.Set(ctx, key, value)
+}
+
+// levelIndex índice do nível nos contadores (L1=0, L2=1, L3=2)
+func levelIndex(level CacheLevel) int {
+	switch level {
+	case LevelL2:
+		return 1
+	case LevelL3:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func (m *MultiLevelCacheManager) recordHit(level CacheLevel) {
+	m.hits[levelIndex(level)].Add(1)
+	RecordCacheHit(string(level), m.connectorID, m.endpointName)
+}
+
+func (m *MultiLevelCacheManager) recordMiss(level CacheLevel) {
+	m.misses[levelIndex(level)].Add(1)
+	RecordCacheMiss(string(level), m.connectorID, m.endpointName)
+}
+
+// hitRate taxa de hit do nível desde o início do processo (0.0 a 1.0)
+func (m *MultiLevelCacheManager) hitRate(level CacheLevel) float64 {
+	hits := m.hits[levelIndex(level)].Load()
+	total := hits + m.misses[levelIndex(level)].Load()
+	if total == 0 {
+		return 0.0
+	}
+	return float64(hits) / float64(total)
+}
+
 // Set armazena valor em todos os níveis habilitados (cascata reversa)
-func (m *MultiLevelCacheManager) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+func (m *MultiLevelCacheManager) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) (err error) {
+	ctx, span := observability.StartSpan(ctx, "cache.set",
+		attribute.String("connector.id", m.connectorID),
+		attribute.String("connector.endpoint", m.endpointName),
+		attribute.String("cache.ttl", ttl.String()),
+	)
+	defer func() { observability.EndSpan(span, err) }()
+
 	var errs []error
 
 	// Armazena em L1
@@ -229,6 +324,31 @@ func (m *MultiLevelCacheManager) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// DeleteMatching remove de L1 e L2 as chaves que casam com o padrão glob
+// L3 não suporta padrões (apenas Delete por chave exata)
+func (m *MultiLevelCacheManager) DeleteMatching(ctx context.Context, pattern string) (int, error) {
+	deleted := 0
+
+	if m.enableL1 && m.l1 != nil {
+		n, err := m.l1.DeleteMatching(ctx, pattern)
+		if err != nil {
+			return 0, err
+		}
+		deleted += n
+	}
+
+	if m.enableL2 && m.l2 != nil {
+		n, err := m.l2.DeleteMatching(ctx, pattern)
+		deleted += n
+		if err != nil {
+			RecordCacheError(string(LevelL2), "delete", "redis_error")
+			return deleted, fmt.Errorf("L2 delete error: %w", err)
+		}
+	}
+
+	return deleted, nil
+}
+
 // Clear limpa todos os níveis de cache
 func (m *MultiLevelCacheManager) Clear(ctx context.Context) error {
 	var errs []error
@@ -269,6 +389,7 @@ func (m *MultiLevelCacheManager) GetStats(ctx context.Context) map[string]interf
 			"evictions":  l1Stats.Evictions,
 			"size_bytes": l1Stats.Size,
 			"hit_rate":   m.l1.GetHitRate(),
+			"keys":       m.l1.KeyCount(),
 		}
 
 		// Atualiza gauge Prometheus
@@ -280,11 +401,19 @@ func (m *MultiLevelCacheManager) GetStats(ctx context.Context) map[string]interf
 		l2Stats, err := m.l2.GetStats(ctx)
 		if err == nil {
 			stats["l2"] = map[string]interface{}{
+				"hit_rate":    m.hitRate(LevelL2),
 				"pool_hits":   l2Stats.Hits,
 				"pool_misses": l2Stats.Misses,
 				"pool_total":  l2Stats.TotalConns,
 				"pool_idle":   l2Stats.IdleConns,
 			}
+			SetCacheHitRate(string(LevelL2), m.hitRate(LevelL2))
+		}
+	}
+
+	if m.enableL3 && m.l3 != nil {
+		stats["l3"] = map[string]interface{}{
+			"hit_rate": m.hitRate(LevelL3),
 		}
 	}
 
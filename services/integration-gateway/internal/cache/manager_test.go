@@ -336,6 +336,84 @@ func TestMultiLevelCacheManager_GetStats(t *testing.T) {
 	assert.Greater(t, l1Map["sets"].(uint64), uint64(0))
 }
 
+func TestMultiLevelCacheManager_DeleteMatching(t *testing.T) {
+	manager, err := NewMultiLevelCacheManager(ManagerConfig{
+		L1Config:    DefaultL1Config(),
+		EnableL1:    true,
+		ConnectorID: "test-connector",
+	})
+	require.NoError(t, err)
+	defer manager.Close()
+
+	ctx := context.Background()
+	manager.Set(ctx, "exportacao_mes:ano=2024:mes=11", "nov", 5*time.Minute)
+	manager.Set(ctx, "exportacao_mes:ano=2025:mes=1", "jan", 5*time.Minute)
+
+	deleted, err := manager.DeleteMatching(ctx, "exportacao_mes:ano=2024:*")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	_, level, _ := manager.Get(ctx, "exportacao_mes:ano=2024:mes=11")
+	assert.Equal(t, LevelExternal, level)
+	_, level, _ = manager.Get(ctx, "exportacao_mes:ano=2025:mes=1")
+	assert.Equal(t, LevelL1, level)
+}
+
+func TestMultiLevelCacheManager_GetWithTTL_PromotesWithKeyTTL(t *testing.T) {
+	manager, err := NewMultiLevelCacheManager(ManagerConfig{
+		L1Config:    DefaultL1Config(),
+		EnableL1:    true,
+		EnableL3:    true,
+		ConnectorID: "test-connector",
+	})
+	require.NoError(t, err)
+	defer manager.Close()
+
+	l3 := NewMockL3Cache()
+	manager.SetL3Cache(l3)
+
+	ctx := context.Background()
+	l3.Set(ctx, "mes_atual", "value", time.Hour)
+
+	_, level, err := manager.GetWithTTL(ctx, "mes_atual", 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, LevelL3, level)
+
+	// Promovido para L1 com o TTL da chave, não com o TTL padrão do L1
+	_, level, _ = manager.Get(ctx, "mes_atual")
+	assert.Equal(t, LevelL1, level)
+
+	time.Sleep(100 * time.Millisecond)
+	_, found := manager.l1.Get(ctx, "mes_atual")
+	assert.False(t, found)
+}
+
+func TestMultiLevelCacheManager_HitRatePerLevel(t *testing.T) {
+	manager, err := NewMultiLevelCacheManager(ManagerConfig{
+		L1Config:    DefaultL1Config(),
+		EnableL1:    true,
+		EnableL3:    true,
+		ConnectorID: "test-connector",
+	})
+	require.NoError(t, err)
+	defer manager.Close()
+
+	l3 := NewMockL3Cache()
+	manager.SetL3Cache(l3)
+
+	ctx := context.Background()
+	l3.Set(ctx, "only-l3", "value", time.Hour)
+
+	manager.Get(ctx, "only-l3") // L1 miss, L3 hit (promove para L1)
+	manager.Get(ctx, "only-l3") // L1 hit
+	manager.Get(ctx, "missing") // L1 miss, L3 miss
+
+	stats := manager.GetStats(ctx)
+	l3Stats := stats["l3"].(map[string]interface{})
+	assert.InDelta(t, 0.5, l3Stats["hit_rate"].(float64), 0.001)
+	assert.InDelta(t, 1.0/3.0, manager.hitRate(LevelL1), 0.001)
+}
+
 func TestMultiLevelCacheManager_ConcurrentAccess(t *testing.T) {
 	config := ManagerConfig{
 		L1Config:     DefaultL1Config(),
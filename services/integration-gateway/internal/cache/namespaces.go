@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bgc/integration-gateway/internal/types"
+)
+
+// Namespaces caches por connector (um namespace por connector com cache.enabled)
+// Cada namespace tem seu L1 e um prefixo próprio no Redis ({prefix}{connector}:),
+// permitindo estatísticas e purge por connector. O orçamento do L1 (L1Config.MaxSize)
+// é dividido entre os conectores e o client Redis é único
+type Namespaces struct {
+	managers map[string]*MultiLevelCacheManager
+	policies map[string]*Policy
+	l2       *L2RedisCache // client Redis compartilhado (nil sem L2)
+}
+
+// NewNamespaces cria os caches dos conectores com cache habilitado
+// Versões do mesmo connector compartilham o namespace; cada versão tem sua Policy
+func NewNamespaces(base ManagerConfig, connectors []*types.ConnectorConfig) (*Namespaces, error) {
+	n := &Namespaces{
+		managers: make(map[string]*MultiLevelCacheManager),
+		policies: make(map[string]*Policy),
+	}
+
+	cached := make(map[string]bool)
+	for _, connector := range connectors {
+		if connector.Integration.Cache.Enabled {
+			cached[connector.ID] = true
+		}
+	}
+	l1Config := splitL1(base.L1Config, len(cached))
+
+	if base.EnableL2 && len(cached) > 0 {
+		l2, err := NewL2RedisCache(base.L2Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize L2 cache: %w", err)
+		}
+		n.l2 = l2
+	}
+
+	for _, connector := range connectors {
+		if !connector.Integration.Cache.Enabled {
+			continue
+		}
+
+		policy, err := NewPolicy(connector, DefaultTTL)
+		if err != nil {
+			n.Close()
+			return nil, fmt.Errorf("connector %s@%s: %w", connector.ID, connector.Version, err)
+		}
+		n.policies[policyKey(connector)] = policy
+
+		if _, exists := n.managers[connector.ID]; exists {
+			continue
+		}
+
+		config := base
+		config.ConnectorID = connector.ID
+		config.EndpointName = "*"
+		config.L1Config = l1Config
+		config.L2Config.Prefix = base.L2Config.Prefix + connector.ID + ":"
+		if n.l2 != nil {
+			config.L2 = n.l2.WithPrefix(config.L2Config.Prefix)
+		}
+
+		manager, err := NewMultiLevelCacheManager(config)
+		if err != nil {
+			n.Close()
+			return nil, fmt.Errorf("connector %s: %w", connector.ID, err)
+		}
+		n.managers[connector.ID] = manager
+	}
+
+	return n, nil
+}
+
+// splitL1 divide tamanho e contadores do L1 entre n conectores
+func splitL1(config L1Config, n int) L1Config {
+	if n <= 1 {
+		return config
+	}
+	config.MaxSize = max(config.MaxSize/int64(n), 1)
+	config.NumCounters = max(config.NumCounters/int64(n), 1)
+	return config
+}
+
+// Get cache do connector (false se o connector não tem cache)
+func (n *Namespaces) Get(connectorID string) (*MultiLevelCacheManager, bool) {
+	if n == nil {
+		return nil, false
+	}
+	manager, ok := n.managers[connectorID]
+	return manager, ok
+}
+
+// Policy chave e TTL de cache da versão do connector (false se a versão não tem cache)
+func (n *Namespaces) Policy(connector *types.ConnectorConfig) (*Policy, bool) {
+	if n == nil {
+		return nil, false
+	}
+	policy, ok := n.policies[policyKey(connector)]
+	return policy, ok
+}
+
+// policyKey chave da policy (id@versão)
+func policyKey(connector *types.ConnectorConfig) string {
+	return connector.ID + "@" + connector.Version
+}
+
+// Connectors IDs dos conectores com cache, ordenados
+func (n *Namespaces) Connectors() []string {
+	if n == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(n.managers))
+	for id := range n.managers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Close fecha os caches de todos os conectores e o client Redis compartilhado
+func (n *Namespaces) Close() error {
+	var errs []error
+	for _, manager := range n.managers {
+		if err := manager.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n.l2 != nil {
+		if err := n.l2.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("cache close errors: %v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespaces(t *testing.T) {
+	connectors := []*types.ConnectorConfig{
+		{ID: "comexstat", Integration: types.IntegrationConfig{Cache: types.CacheConfig{Enabled: true}}},
+		{ID: "viacep", Integration: types.IntegrationConfig{Cache: types.CacheConfig{Enabled: true}}},
+		{ID: "receita-cnpj"},
+	}
+
+	namespaces, err := NewNamespaces(ManagerConfig{L1Config: DefaultL1Config(), EnableL1: true}, connectors)
+	require.NoError(t, err)
+	defer namespaces.Close()
+
+	assert.Equal(t, []string{"comexstat", "viacep"}, namespaces.Connectors())
+
+	_, ok := namespaces.Get("receita-cnpj")
+	assert.False(t, ok)
+
+	// Cada connector tem seu próprio L1: purge de um não afeta o outro
+	ctx := context.Background()
+	comexstat, _ := namespaces.Get("comexstat")
+	viacep, _ := namespaces.Get("viacep")
+	comexstat.Set(ctx, "key", "comexstat", time.Minute)
+	viacep.Set(ctx, "key", "viacep", time.Minute)
+
+	require.NoError(t, comexstat.Clear(ctx))
+
+	_, level, _ := comexstat.Get(ctx, "key")
+	assert.Equal(t, LevelExternal, level)
+	value, level, _ := viacep.Get(ctx, "key")
+	assert.Equal(t, LevelL1, level)
+	assert.Equal(t, "viacep", value)
+}
+
+func TestSplitL1(t *testing.T) {
+	config := DefaultL1Config()
+
+	assert.Equal(t, config, splitL1(config, 1))
+	assert.Equal(t, config, splitL1(config, 0))
+
+	// Dois conectores com cache: 50MB cada (o total continua o configurado)
+	split := splitL1(config, 2)
+	assert.Equal(t, config.MaxSize/2, split.MaxSize)
+	assert.Equal(t, config.NumCounters/2, split.NumCounters)
+	assert.Equal(t, config.DefaultTTL, split.DefaultTTL)
+}
+
+func TestNamespaces_NilSafe(t *testing.T) {
+	var namespaces *Namespaces
+
+	_, ok := namespaces.Get("comexstat")
+	assert.False(t, ok)
+	assert.Empty(t, namespaces.Connectors())
+}
+
+func TestKey(t *testing.T) {
+	key := Key("exportacao_mes", map[string]interface{}{"mes": 11, "ano": 2024})
+	assert.Equal(t, "exportacao_mes:ano=2024:mes=11", key)
+	assert.Equal(t, "exportacao_mes", Key("exportacao_mes", nil))
+}
@@ -0,0 +1,323 @@
+package cache
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/paramtmpl"
+	"github.com/bgc/integration-gateway/internal/types"
+)
+
+// DefaultTTL TTL usado quando o connector não define cache.ttl
+const DefaultTTL = 5 * time.Minute
+
+// EndpointPlaceholder placeholder do key_pattern com o nome do endpoint
+const EndpointPlaceholder = "endpoint"
+
+// placeholderRegex placeholders {param} do key_pattern
+var placeholderRegex = regexp.MustCompile(`\{([a-zA-Z0-9_]+)\}`)
+
+// Policy chave e TTL de cache de um connector (cache.key_pattern, cache.ttl e cache.ttl_rules)
+// A mesma policy vale para L1, L2 e L3: o TTL calculado é usado na gravação e nas promoções
+type Policy struct {
+	connector *types.ConnectorConfig
+	pattern   string
+	ttl       time.Duration
+	location  *time.Location
+	rules     []ttlRule
+	now       func() time.Time
+}
+
+// ttlRule regra de TTL: aplica se todos os params de when batem (primeira regra que bate vence)
+type ttlRule struct {
+	name      string
+	endpoints map[string]bool // vazio = todos
+	when      map[string]*template.Template
+	ttl       time.Duration
+}
+
+// NewPolicy valida e compila a configuração de cache do connector
+func NewPolicy(connector *types.ConnectorConfig, defaultTTL time.Duration) (*Policy, error) {
+	config := connector.Integration.Cache
+	p := &Policy{
+		connector: connector,
+		pattern:   config.KeyPattern,
+		ttl:       defaultTTL,
+		location:  time.UTC,
+		now:       time.Now,
+	}
+
+	if config.TTL != "" {
+		ttl, err := ParseTTL(config.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache ttl: %w", err)
+		}
+		p.ttl = ttl
+	}
+
+	if config.Timezone != "" {
+		location, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache timezone %s: %w", config.Timezone, err)
+		}
+		p.location = location
+	}
+
+	if err := p.validatePattern(); err != nil {
+		return nil, err
+	}
+
+	for i, ruleConfig := range config.TTLRules {
+		rule, err := p.compileRule(ruleConfig)
+		if err != nil {
+			name := ruleConfig.Name
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			return nil, fmt.Errorf("ttl rule %s: %w", name, err)
+		}
+		p.rules = append(p.rules, rule)
+	}
+
+	return p, nil
+}
+
+// validatePattern exige que o key_pattern identifique a chamada: placeholders conhecidos,
+// todos os params dos endpoints presentes e {endpoint} quando há mais de um endpoint
+func (p *Policy) validatePattern() error {
+	if p.pattern == "" {
+		return nil
+	}
+
+	used := make(map[string]bool)
+	for _, match := range placeholderRegex.FindAllStringSubmatch(p.pattern, -1) {
+		used[match[1]] = true
+	}
+
+	declared := p.declaredParams()
+	for name := range used {
+		if name != EndpointPlaceholder && !declared[name] {
+			return fmt.Errorf("key_pattern: unknown placeholder {%s}", name)
+		}
+	}
+
+	if len(p.connector.Integration.Endpoints) > 1 && !used[EndpointPlaceholder] {
+		return fmt.Errorf("key_pattern: {%s} is required when the connector has more than one endpoint", EndpointPlaceholder)
+	}
+
+	for _, name := range sortedEndpoints(p.connector.Integration.Endpoints) {
+		for _, param := range endpointParams(p.connector.Integration.Endpoints[name]) {
+			if !used[param.Name] {
+				return fmt.Errorf("key_pattern: param %s of endpoint %s is missing (responses would collide)", param.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// compileRule valida endpoints, params e templates de uma regra de TTL
+func (p *Policy) compileRule(config types.CacheTTLRuleConfig) (ttlRule, error) {
+	rule := ttlRule{name: config.Name, endpoints: make(map[string]bool)}
+
+	if config.TTL == "" {
+		return rule, fmt.Errorf("ttl is required")
+	}
+	ttl, err := ParseTTL(config.TTL)
+	if err != nil {
+		return rule, fmt.Errorf("invalid ttl: %w", err)
+	}
+	rule.ttl = ttl
+
+	if len(config.When) == 0 {
+		return rule, fmt.Errorf("when is required")
+	}
+
+	for _, endpoint := range config.Endpoints {
+		if _, exists := p.connector.Integration.Endpoints[endpoint]; !exists {
+			return rule, fmt.Errorf("endpoint %s not found", endpoint)
+		}
+		rule.endpoints[endpoint] = true
+	}
+
+	declared := p.declaredParams()
+	for name := range config.When {
+		if !declared[name] {
+			return rule, fmt.Errorf("unknown param %s", name)
+		}
+	}
+
+	// Valores de when usam os mesmos templates de data dos params de jobs
+	rule.when, err = paramtmpl.Parse(config.When)
+	if err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+// Key chave de cache da chamada (key_pattern com valores normalizados, ou endpoint + params ordenados)
+func (p *Policy) Key(endpoint string, params map[string]interface{}) string {
+	paramConfigs := paramTypes(p.connector.Integration.Endpoints[endpoint])
+
+	if p.pattern == "" {
+		normalized := make(map[string]interface{}, len(params))
+		for name, value := range params {
+			normalized[name] = normalizeValue(value, paramConfigs[name])
+		}
+		return Key(endpoint, normalized)
+	}
+
+	return placeholderRegex.ReplaceAllStringFunc(p.pattern, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if name == EndpointPlaceholder {
+			return endpoint
+		}
+		param := paramConfigs[name]
+		value, ok := params[name]
+		if !ok {
+			value = param.Default
+		}
+		return normalizeValue(value, param)
+	})
+}
+
+// TTL TTL da chamada: primeira regra que bate, senão cache.ttl
+func (p *Policy) TTL(endpoint string, params map[string]interface{}) time.Duration {
+	if len(p.rules) == 0 {
+		return p.ttl
+	}
+
+	paramConfigs := paramTypes(p.connector.Integration.Endpoints[endpoint])
+	now := p.now().In(p.location)
+
+	for _, rule := range p.rules {
+		if len(rule.endpoints) > 0 && !rule.endpoints[endpoint] {
+			continue
+		}
+		expected, err := paramtmpl.Render(rule.when, now)
+		if err != nil {
+			continue
+		}
+		if matches(expected, params, paramConfigs) {
+			return rule.ttl
+		}
+	}
+	return p.ttl
+}
+
+// matches todos os params esperados presentes e iguais após normalização
+func matches(expected, params map[string]interface{}, paramConfigs map[string]types.ParameterConfig) bool {
+	for name, want := range expected {
+		value, ok := params[name]
+		if !ok || value == nil {
+			return false
+		}
+		if normalizeValue(value, paramConfigs[name]) != normalizeValue(want, paramConfigs[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// declaredParams params declarados em algum endpoint do connector
+func (p *Policy) declaredParams() map[string]bool {
+	declared := make(map[string]bool)
+	for _, endpoint := range p.connector.Integration.Endpoints {
+		for _, param := range endpointParams(endpoint) {
+			declared[param.Name] = true
+		}
+	}
+	return declared
+}
+
+// endpointParams params de path e query do endpoint
+func endpointParams(endpoint types.EndpointConfig) []types.ParameterConfig {
+	params := make([]types.ParameterConfig, 0, len(endpoint.PathParams)+len(endpoint.QueryParams))
+	params = append(params, endpoint.PathParams...)
+	return append(params, endpoint.QueryParams...)
+}
+
+// paramTypes configuração dos params do endpoint por nome
+func paramTypes(endpoint types.EndpointConfig) map[string]types.ParameterConfig {
+	byName := make(map[string]types.ParameterConfig)
+	for _, param := range endpointParams(endpoint) {
+		byName[param.Name] = param
+	}
+	return byName
+}
+
+// sortedEndpoints nomes dos endpoints em ordem (mensagens de erro determinísticas)
+func sortedEndpoints(endpoints map[string]types.EndpointConfig) []string {
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalizeValue normaliza o valor de um param para a chave
+// integer/number: forma canônica ("05" e 5 viram "5"); boolean: true/false;
+// string: sem espaços nas pontas, só dígitos se format digits_only, minúscula só se
+// case_insensitive (o upstream pode distinguir "ABC" de "abc");
+// ':' e espaços internos viram '_' para não quebrar os segmentos da chave
+func normalizeValue(value interface{}, param types.ParameterConfig) string {
+	if value == nil {
+		return ""
+	}
+	text := strings.TrimSpace(fmt.Sprintf("%v", value))
+
+	switch param.Type {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+				return strconv.FormatInt(int64(f), 10)
+			}
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(text); err == nil {
+			return strconv.FormatBool(b)
+		}
+	}
+
+	if param.Format == "digits_only" {
+		text = strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, text)
+	}
+
+	if param.CaseInsensitive {
+		text = strings.ToLower(text)
+	}
+	text = strings.Join(strings.Fields(text), "_")
+	return strings.ReplaceAll(text, ":", "_")
+}
+
+// ParseTTL converte TTL do YAML (aceita sufixo d de dias, ex: 7d)
+func ParseTTL(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	ttl, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if ttl <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return ttl, nil
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// policyConnector connector no formato do comexstat (ano/mes inteiros, dois endpoints)
+func policyConnector(cache types.CacheConfig) *types.ConnectorConfig {
+	params := []types.ParameterConfig{
+		{Name: "ano", Type: "integer"},
+		{Name: "mes", Type: "integer"},
+	}
+	return &types.ConnectorConfig{
+		ID: "comexstat",
+		Integration: types.IntegrationConfig{
+			Endpoints: map[string]types.EndpointConfig{
+				"exportacao_mes": {PathParams: params},
+				"importacao_mes": {PathParams: params},
+			},
+			Cache: cache,
+		},
+	}
+}
+
+func TestNewPolicy_Validation(t *testing.T) {
+	rule := func(when map[string]string, ttl string) []types.CacheTTLRuleConfig {
+		return []types.CacheTTLRuleConfig{{Name: "mes_atual", When: when, TTL: ttl}}
+	}
+
+	tests := []struct {
+		name        string
+		cache       types.CacheConfig
+		errContains string
+	}{
+		{"valid", types.CacheConfig{TTL: "168h", KeyPattern: "comexstat:{endpoint}:{ano}:{mes}", TTLRules: rule(map[string]string{"ano": "{{ year .Now }}"}, "6h")}, ""},
+		{"days ttl", types.CacheConfig{TTL: "7d"}, ""},
+		{"invalid ttl", types.CacheConfig{TTL: "sete dias"}, "invalid cache ttl"},
+		{"invalid timezone", types.CacheConfig{Timezone: "America/Nowhere"}, "invalid cache timezone"},
+		{"unknown placeholder", types.CacheConfig{KeyPattern: "comexstat:{endpoint}:{ano}:{mes}:{ncm}"}, "unknown placeholder {ncm}"},
+		{"missing endpoint placeholder", types.CacheConfig{KeyPattern: "comexstat:exp:{ano}:{mes}"}, "{endpoint} is required"},
+		{"missing param", types.CacheConfig{KeyPattern: "comexstat:{endpoint}:{ano}"}, "param mes of endpoint exportacao_mes is missing"},
+		{"rule without ttl", types.CacheConfig{TTLRules: rule(map[string]string{"ano": "2024"}, "")}, "ttl rule mes_atual: ttl is required"},
+		{"rule without when", types.CacheConfig{TTLRules: rule(nil, "6h")}, "when is required"},
+		{"rule unknown param", types.CacheConfig{TTLRules: rule(map[string]string{"ncm": "0101"}, "6h")}, "unknown param ncm"},
+		{"rule invalid template", types.CacheConfig{TTLRules: rule(map[string]string{"ano": "{{ year .Now"}, "6h")}, "param ano"},
+		{"rule unknown endpoint", types.CacheConfig{TTLRules: []types.CacheTTLRuleConfig{{Endpoints: []string{"missing"}, When: map[string]string{"ano": "2024"}, TTL: "6h"}}}, "ttl rule 0: endpoint missing not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPolicy(policyConnector(tt.cache), DefaultTTL)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestPolicy_Key(t *testing.T) {
+	policy, err := NewPolicy(policyConnector(types.CacheConfig{KeyPattern: "comexstat:{endpoint}:{ano}:{mes}"}), DefaultTTL)
+	require.NoError(t, err)
+
+	// Valores equivalentes geram a mesma chave
+	assert.Equal(t, "comexstat:exportacao_mes:2024:5", policy.Key("exportacao_mes", map[string]interface{}{"ano": 2024, "mes": 5}))
+	assert.Equal(t, "comexstat:exportacao_mes:2024:5", policy.Key("exportacao_mes", map[string]interface{}{"ano": "2024", "mes": " 05"}))
+	assert.Equal(t, "comexstat:exportacao_mes:2024:5", policy.Key("exportacao_mes", map[string]interface{}{"ano": 2024.0, "mes": 5.0}))
+	assert.Equal(t, "comexstat:importacao_mes:2024:5", policy.Key("importacao_mes", map[string]interface{}{"ano": 2024, "mes": 5}))
+
+	// Sem key_pattern: endpoint + params ordenados, também normalizados
+	policy, err = NewPolicy(policyConnector(types.CacheConfig{}), DefaultTTL)
+	require.NoError(t, err)
+	assert.Equal(t, "exportacao_mes:ano=2024:mes=5", policy.Key("exportacao_mes", map[string]interface{}{"mes": "05", "ano": 2024}))
+}
+
+func TestNormalizeValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		param types.ParameterConfig
+		want  string
+	}{
+		{"integer string", "007", types.ParameterConfig{Type: "integer"}, "7"},
+		{"integer float", 11.0, types.ParameterConfig{Type: "integer"}, "11"},
+		{"number", "1.50", types.ParameterConfig{Type: "number"}, "1.5"},
+		{"boolean", "TRUE", types.ParameterConfig{Type: "boolean"}, "true"},
+		{"string", "  São Paulo ", types.ParameterConfig{Type: "string"}, "São_Paulo"},
+		{"case insensitive", "  São Paulo ", types.ParameterConfig{Type: "string", CaseInsensitive: true}, "são_paulo"},
+		{"separator", "a:b", types.ParameterConfig{Type: "string"}, "a_b"},
+		{"digits only", "12.345.678/0001-90", types.ParameterConfig{Type: "string", Format: "digits_only"}, "12345678000190"},
+		{"leading zeros kept in strings", "01001000", types.ParameterConfig{Type: "string"}, "01001000"},
+		{"nil", nil, types.ParameterConfig{Type: "integer"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeValue(tt.value, tt.param))
+		})
+	}
+}
+
+func TestPolicy_TTL(t *testing.T) {
+	policy, err := NewPolicy(policyConnector(types.CacheConfig{
+		TTL:      "168h",
+		Timezone: "America/Sao_Paulo",
+		TTLRules: []types.CacheTTLRuleConfig{
+			{Name: "mes_atual", When: map[string]string{"ano": "{{ year .Now }}", "mes": "{{ month .Now }}"}, TTL: "6h"},
+			{Name: "ano_atual", When: map[string]string{"ano": "{{ year .Now }}"}, TTL: "24h"},
+			{Name: "importacao", Endpoints: []string{"importacao_mes"}, When: map[string]string{"ano": "2020"}, TTL: "30d"},
+		},
+	}), DefaultTTL)
+	require.NoError(t, err)
+
+	// 01/12/2024 02:00 UTC ainda é 30/11 em São Paulo
+	policy.now = func() time.Time { return time.Date(2024, 12, 1, 2, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		endpoint string
+		params   map[string]interface{}
+		want     time.Duration
+	}{
+		{"current month", "exportacao_mes", map[string]interface{}{"ano": 2024, "mes": 11}, 6 * time.Hour},
+		{"current month as strings", "exportacao_mes", map[string]interface{}{"ano": "2024", "mes": "11"}, 6 * time.Hour},
+		{"closed month in current year", "exportacao_mes", map[string]interface{}{"ano": 2024, "mes": 10}, 24 * time.Hour},
+		{"historical", "exportacao_mes", map[string]interface{}{"ano": 2023, "mes": 11}, 168 * time.Hour},
+		{"rule restricted to endpoint", "importacao_mes", map[string]interface{}{"ano": 2020, "mes": 1}, 30 * 24 * time.Hour},
+		{"rule of other endpoint", "exportacao_mes", map[string]interface{}{"ano": 2020, "mes": 1}, 168 * time.Hour},
+		{"missing param", "exportacao_mes", map[string]interface{}{"mes": 11}, 168 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.TTL(tt.endpoint, tt.params))
+		})
+	}
+
+	// Sem cache.ttl vale o TTL padrão
+	policy, err = NewPolicy(policyConnector(types.CacheConfig{}), DefaultTTL)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTTL, policy.TTL("exportacao_mes", nil))
+}
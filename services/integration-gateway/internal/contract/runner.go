@@ -0,0 +1,162 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/transform"
+	"github.com/bgc/integration-gateway/internal/types"
+)
+
+// Executor executa chamadas de connector (framework.Executor em modo replay)
+type Executor interface {
+	Execute(ctx *types.ExecutionContext) (*types.ExecutionResult, error)
+}
+
+// Result resultado de um exemplo
+type Result struct {
+	Endpoint string        `json:"endpoint"`
+	Example  string        `json:"example"`
+	Passed   bool          `json:"passed"`
+	Failures []string      `json:"failures,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report resultado dos contract tests de um connector
+type Report struct {
+	Connector string   `json:"connector"`
+	Results   []Result `json:"results"`
+}
+
+// Passed indica se todos os exemplos passaram
+func (r *Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Run executa os exemplos declarados em cada endpoint e compara com a saída mapeada
+func Run(executor Executor, connector *types.ConnectorConfig, environment string) *Report {
+	report := &Report{Connector: connector.ID}
+
+	endpoints := make([]string, 0, len(connector.Integration.Endpoints))
+	for name := range connector.Integration.Endpoints {
+		endpoints = append(endpoints, name)
+	}
+	sort.Strings(endpoints)
+
+	for _, endpoint := range endpoints {
+		for _, example := range connector.Integration.Endpoints[endpoint].Examples {
+			report.Results = append(report.Results, runExample(executor, connector.ID, endpoint, environment, &example))
+		}
+	}
+
+	return report
+}
+
+// runExample executa um exemplo
+func runExample(executor Executor, connectorID, endpoint, environment string, example *types.ExampleConfig) Result {
+	result := Result{Endpoint: endpoint, Example: example.Name}
+
+	start := time.Now()
+	execution, err := executor.Execute(&types.ExecutionContext{
+		ConnectorID:  connectorID,
+		EndpointName: endpoint,
+		Environment:  environment,
+		Params:       example.Params,
+		StartTime:    start,
+	})
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		result.Failures = []string{err.Error()}
+		return result
+	}
+
+	fields := make([]string, 0, len(example.Expect))
+	for field := range example.Expect {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		actual, exists := transform.GetField(execution.Data, field)
+		if !exists {
+			result.Failures = append(result.Failures, fmt.Sprintf("%s: missing in mapped output", field))
+			continue
+		}
+		result.Failures = append(result.Failures, Match(example.Expect[field], actual, field)...)
+	}
+
+	result.Passed = len(result.Failures) == 0
+	return result
+}
+
+// Match compara expected com actual (subconjunto para objetos, elemento a elemento para arrays)
+func Match(expected, actual interface{}, path string) []string {
+	return match(normalize(expected), normalize(actual), path)
+}
+
+func match(expected, actual interface{}, path string) []string {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object, got %v", path, actual)}
+		}
+		var failures []string
+		keys := make([]string, 0, len(exp))
+		for key := range exp {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, exists := act[key]
+			if !exists {
+				failures = append(failures, fmt.Sprintf("%s.%s: missing in mapped output", path, key))
+				continue
+			}
+			failures = append(failures, match(exp[key], value, path+"."+key)...)
+		}
+		return failures
+
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array, got %v", path, actual)}
+		}
+		if len(act) != len(exp) {
+			return []string{fmt.Sprintf("%s: expected %d items, got %d", path, len(exp), len(act))}
+		}
+		var failures []string
+		for i := range exp {
+			failures = append(failures, match(exp[i], act[i], fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return failures
+
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return []string{fmt.Sprintf("%s: expected %v, got %v", path, expected, actual)}
+		}
+		return nil
+	}
+}
+
+// normalize converte valores para tipos JSON (YAML decodifica int, ojg int64, etc.)
+func normalize(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
@@ -0,0 +1,176 @@
+package contract
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bgc/integration-gateway/internal/auth"
+	"github.com/bgc/integration-gateway/internal/framework"
+	"github.com/bgc/integration-gateway/internal/registry"
+	"github.com/bgc/integration-gateway/internal/replay"
+	"github.com/bgc/integration-gateway/internal/transform"
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const connectorTemplate = `
+id: comexstat-test
+name: ComexStat Test
+version: 1.0.0
+
+integration:
+  type: rest_api
+  auth:
+    type: api_key
+    api_key:
+      header_name: X-API-Key
+      key_ref: comexstat-test/api-key
+  endpoints:
+    exportacao_mes:
+      method: POST
+      path: /api/exp/{ano}/{mes}
+      body:
+        content_type: application/json
+        template: '{"co_ano": {{ano}}, "co_mes": {{mes}}}'
+      response:
+        success_status: [200]
+        mapping:
+          total_records: $.total
+        arrays:
+          - field: rows
+            path: $.data[*]
+            mapping:
+              co_pais: $.co_pais
+              vl_fob: $.vl_fob
+      examples:
+        - name: novembro_2024
+          params: {ano: 2024, mes: 11}
+          expect:
+            total_records: 1
+            rows:
+              - co_pais: CN
+                vl_fob: 1500
+
+environments:
+  development:
+    base_url: BASE_URL
+`
+
+func TestRun_RecordThenReplay(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/exp/2024/11", r.URL.Path)
+		w.Write([]byte(`{"total": 1, "data": [{"co_pais": "CN", "vl_fob": 1500}]}`))
+	}))
+
+	configDir := t.TempDir()
+	config := strings.Replace(connectorTemplate, "BASE_URL", upstream.URL, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "comexstat-test.yaml"), []byte(config), 0644))
+
+	reg := registry.NewRegistry(configDir)
+	require.NoError(t, reg.LoadAll())
+	connector, err := reg.Get("comexstat-test")
+	require.NoError(t, err)
+
+	t.Setenv("SECRET_COMEXSTAT_TESTAPI_KEY", "super-secret")
+	store := replay.NewStore(t.TempDir())
+
+	// Record: chamada real com autenticação
+	recorder := framework.NewExecutor(reg,
+		auth.NewEngine(nil, auth.NewSimpleSecretStore()),
+		transform.NewEngine(),
+		framework.WithTransport(func(next http.RoundTripper) http.RoundTripper {
+			return replay.NewRecorder(store, next)
+		}),
+	)
+	report := Run(recorder, connector, "development")
+	require.True(t, report.Passed(), "%+v", report.Results)
+
+	// Replay: upstream fora do ar e sem credenciais
+	upstream.Close()
+	os.Unsetenv("SECRET_COMEXSTAT_TESTAPI_KEY")
+
+	replayer := framework.NewExecutor(reg, auth.NewEngine(nil, nil), transform.NewEngine(),
+		framework.WithReplay(replay.NewReplayer(store)))
+	report = Run(replayer, connector, "development")
+
+	require.Len(t, report.Results, 1)
+	assert.True(t, report.Passed(), "%+v", report.Results)
+	assert.Equal(t, "exportacao_mes", report.Results[0].Endpoint)
+	assert.Equal(t, "novembro_2024", report.Results[0].Example)
+}
+
+// fakeExecutor executor com saída fixa
+type fakeExecutor struct {
+	data map[string]interface{}
+	err  error
+}
+
+func (f *fakeExecutor) Execute(ctx *types.ExecutionContext) (*types.ExecutionResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &types.ExecutionResult{Data: f.data, StatusCode: 200}, nil
+}
+
+func TestRun_ReportsMismatches(t *testing.T) {
+	connector := &types.ConnectorConfig{
+		ID: "viacep",
+		Integration: types.IntegrationConfig{
+			Endpoints: map[string]types.EndpointConfig{
+				"consulta_cep": {
+					Examples: []types.ExampleConfig{
+						{
+							Name: "paulista",
+							Expect: map[string]interface{}{
+								"logradouro":  "Avenida Paulista",
+								"cidade.uf":   "SP",
+								"complemento": "",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	executor := &fakeExecutor{data: map[string]interface{}{
+		"logradouro": "Rua Augusta",
+		"cidade":     map[string]interface{}{"uf": "SP"},
+	}}
+
+	report := Run(executor, connector, "development")
+
+	require.Len(t, report.Results, 1)
+	assert.False(t, report.Passed())
+	assert.Equal(t, []string{
+		"complemento: missing in mapped output",
+		"logradouro: expected Avenida Paulista, got Rua Augusta",
+	}, report.Results[0].Failures)
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected interface{}
+		actual   interface{}
+		failures int
+	}{
+		{"equal numbers of different types", 1500, int64(1500), 0},
+		{"object subset", map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1, "b": 2}, 0},
+		{"missing key", map[string]interface{}{"c": 1}, map[string]interface{}{"a": 1}, 1},
+		{"array length", []interface{}{1, 2}, []interface{}{1}, 1},
+		{"array elements", []interface{}{1, 2}, []interface{}{1, 3}, 1},
+		{"type mismatch", map[string]interface{}{"a": 1}, "a", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, Match(tt.expected, tt.actual, "field"), tt.failures)
+		})
+	}
+}
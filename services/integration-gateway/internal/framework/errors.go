@@ -0,0 +1,173 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/transform"
+	"github.com/bgc/integration-gateway/internal/types"
+)
+
+// Códigos de erro da execução (campo code do corpo de erro do gateway)
+const (
+	CodeConnectorNotFound   = "connector_not_found"   // 404
+	CodeEndpointNotFound    = "endpoint_not_found"    // 404
+	CodeEnvironmentNotFound = "environment_not_found" // 500 (connector sem o ambiente do gateway)
+	CodeInvalidParams       = "invalid_params"        // 400
+	CodeAuthFailed          = "auth_failed"           // 502 (credenciais do gateway no upstream)
+	CodeRateLimited         = "rate_limited"          // 429 (rate limit do connector no gateway)
+	CodeCircuitOpen         = "circuit_open"          // 503
+	CodeUpstreamNotFound    = "upstream_not_found"    // 404
+	CodeUpstreamRejected    = "upstream_rejected"     // 400 (upstream recusou os params: 400, 422...)
+	CodeUpstreamAuthFailed  = "upstream_auth_failed"  // 502 (upstream respondeu 401/403)
+	CodeUpstreamRateLimited = "upstream_rate_limited" // 429
+	CodeUpstreamUnavailable = "upstream_unavailable"  // 503
+	CodeUpstreamTimeout     = "upstream_timeout"      // 504
+	CodeUpstreamUnreachable = "upstream_unreachable"  // 502 (DNS, conexão recusada, TLS)
+	CodeUpstreamError       = "upstream_error"        // 502
+	CodeTransformFailed     = "transform_failed"      // 502
+	CodeContractViolation   = "contract_violation"    // 502
+	CodeInternal            = "internal_error"        // 500
+)
+
+// ExecutionError erro tipado da execução de um connector
+type ExecutionError struct {
+	Code           string
+	ConnectorID    string
+	EndpointName   string
+	UpstreamStatus int           // status HTTP do upstream (0 = sem resposta)
+	RetryAfter     time.Duration // 429/503: quando tentar de novo (0 = desconhecido)
+	Err            error
+}
+
+func (e *ExecutionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus status HTTP devolvido ao caller do gateway
+func (e *ExecutionError) HTTPStatus() int {
+	switch e.Code {
+	case CodeConnectorNotFound, CodeEndpointNotFound, CodeUpstreamNotFound:
+		return http.StatusNotFound
+	case CodeInvalidParams, CodeUpstreamRejected:
+		return http.StatusBadRequest
+	case CodeRateLimited, CodeUpstreamRateLimited:
+		return http.StatusTooManyRequests
+	case CodeCircuitOpen, CodeUpstreamUnavailable:
+		return http.StatusServiceUnavailable
+	case CodeUpstreamTimeout:
+		return http.StatusGatewayTimeout
+	case CodeAuthFailed, CodeUpstreamAuthFailed, CodeUpstreamUnreachable, CodeUpstreamError,
+		CodeTransformFailed, CodeContractViolation:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// AsExecutionError erro tipado da execução (erros não tipados viram internal_error)
+func AsExecutionError(err error) *ExecutionError {
+	var execErr *ExecutionError
+	if errors.As(err, &execErr) {
+		return execErr
+	}
+	return &ExecutionError{Code: CodeInternal, Err: err}
+}
+
+// newExecutionError tipa err com connector e endpoint da chamada
+// Se err já carrega um ExecutionError (ex: do HTTPClient), mantém seu code, status e Retry-After
+func newExecutionError(ctx *types.ExecutionContext, code string, err error) *ExecutionError {
+	execErr := &ExecutionError{
+		Code:         code,
+		ConnectorID:  ctx.ConnectorID,
+		EndpointName: ctx.EndpointName,
+		Err:          err,
+	}
+
+	var inner *ExecutionError
+	if errors.As(err, &inner) {
+		execErr.Code = inner.Code
+		execErr.UpstreamStatus = inner.UpstreamStatus
+		execErr.RetryAfter = inner.RetryAfter
+	}
+	return execErr
+}
+
+// statusError erro para uma resposta upstream fora de success_status
+func statusError(resp *http.Response, err error) *ExecutionError {
+	return &ExecutionError{
+		Code:           upstreamStatusCode(resp.StatusCode),
+		UpstreamStatus: resp.StatusCode,
+		RetryAfter:     parseRetryAfter(resp.Header.Get("Retry-After")),
+		Err:            err,
+	}
+}
+
+// upstreamStatusCode code de erro para o status HTTP do upstream
+func upstreamStatusCode(status int) string {
+	switch {
+	case status == http.StatusNotFound:
+		return CodeUpstreamNotFound
+	case status == http.StatusTooManyRequests:
+		return CodeUpstreamRateLimited
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return CodeUpstreamAuthFailed
+	case status == http.StatusServiceUnavailable:
+		return CodeUpstreamUnavailable
+	case status == http.StatusGatewayTimeout || status == http.StatusRequestTimeout:
+		return CodeUpstreamTimeout
+	case status >= 400 && status < 500:
+		return CodeUpstreamRejected
+	default:
+		return CodeUpstreamError
+	}
+}
+
+// transportCode code de erro para falhas sem resposta do upstream
+func transportCode(err error) string {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return CodeUpstreamTimeout
+	}
+	return CodeUpstreamUnreachable
+}
+
+// parseRetryAfter interpreta o header Retry-After (segundos ou data HTTP)
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// ResponseValidationError resposta upstream viola o contrato do endpoint (modo strict)
+type ResponseValidationError struct {
+	ConnectorID  string
+	EndpointName string
+	Violations   []transform.Violation
+}
+
+func (e *ResponseValidationError) Error() string {
+	return fmt.Sprintf("response from %s.%s violates contract (%d violations)",
+		e.ConnectorID, e.EndpointName, len(e.Violations))
+}
@@ -8,13 +8,17 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bgc/integration-gateway/internal/auth"
+	"github.com/bgc/integration-gateway/internal/cache"
 	"github.com/bgc/integration-gateway/internal/observability"
 	"github.com/bgc/integration-gateway/internal/registry"
+	"github.com/bgc/integration-gateway/internal/replay"
 	"github.com/bgc/integration-gateway/internal/transform"
 	"github.com/bgc/integration-gateway/internal/types"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Executor orquestra a execução de requests usando conectores
@@ -22,7 +26,42 @@ type Executor struct {
 	registry    *registry.Registry
 	authEngine  *auth.Engine
 	transformer *transform.Engine
-	httpClient  *HTTPClient
+
+	// HTTP client por connector@versão: circuit breaker e rate limit valem entre requests
+	clientsMu sync.Mutex
+	clients   map[string]*sharedClient
+
+	// Record/replay de fixtures
+	wrapTransport func(http.RoundTripper) http.RoundTripper
+	replay        http.RoundTripper
+
+	// Cache multinível por connector (nil = sem cache)
+	caches *cache.Namespaces
+}
+
+// ExecutorOption configuração opcional do executor
+type ExecutorOption func(*Executor)
+
+// WithTransport envolve o transport HTTP usado nas chamadas upstream (ex: gravação de fixtures)
+func WithTransport(wrap func(http.RoundTripper) http.RoundTripper) ExecutorOption {
+	return func(e *Executor) {
+		e.wrapTransport = wrap
+	}
+}
+
+// WithReplay serve as chamadas upstream a partir de fixtures gravadas
+// Em replay não há autenticação, rate limit, retry nem circuit breaker upstream
+func WithReplay(transport http.RoundTripper) ExecutorOption {
+	return func(e *Executor) {
+		e.replay = transport
+	}
+}
+
+// WithCache habilita o cache (read-through) dos conectores com cache.enabled
+func WithCache(caches *cache.Namespaces) ExecutorOption {
+	return func(e *Executor) {
+		e.caches = caches
+	}
 }
 
 // NewExecutor cria um novo executor
@@ -30,17 +69,48 @@ func NewExecutor(
 	reg *registry.Registry,
 	authEngine *auth.Engine,
 	transformer *transform.Engine,
+	opts ...ExecutorOption,
 ) *Executor {
-	return &Executor{
+	e := &Executor{
 		registry:    reg,
 		authEngine:  authEngine,
 		transformer: transformer,
-		httpClient:  nil, // Será criado por request (pode variar por config)
+		clients:     make(map[string]*sharedClient),
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
 // Execute executa uma chamada ao connector
 func (e *Executor) Execute(ctx *types.ExecutionContext) (*types.ExecutionResult, error) {
+	parent := ctx.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	// Span da execução (filho do trace recebido do caller)
+	spanCtx, span := observability.StartSpan(parent, "connector.execute",
+		attribute.String("connector.id", ctx.ConnectorID),
+		attribute.String("connector.endpoint", ctx.EndpointName),
+		attribute.String("connector.environment", ctx.Environment),
+	)
+
+	result, err := e.execute(spanCtx, ctx)
+	if result != nil && result.StatusCode != 0 {
+		span.SetAttributes(attribute.Int("http.status_code", result.StatusCode))
+	}
+	if result != nil && result.Version != "" {
+		span.SetAttributes(attribute.String("connector.version", result.Version))
+	}
+	observability.EndSpan(span, err)
+
+	return result, err
+}
+
+// execute executa a chamada dentro do span do connector
+func (e *Executor) execute(spanCtx context.Context, ctx *types.ExecutionContext) (result *types.ExecutionResult, err error) {
 	startTime := time.Now()
 
 	// Log início
@@ -50,61 +120,106 @@ func (e *Executor) Execute(ctx *types.ExecutionContext) (*types.ExecutionResult,
 		"environment", ctx.Environment,
 	).Info("Executing connector request")
 
-	// 1. Carrega configuração do connector
-	connectorConfig, err := e.registry.Get(ctx.ConnectorID)
-	if err != nil {
-		observability.RecordError(ctx.ConnectorID, ctx.EndpointName, "connector_not_found")
-		return nil, fmt.Errorf("connector not found: %w", err)
+	// 1-3. Carrega connector, endpoint e ambiente do registry
+	_, lookupSpan := observability.StartSpan(spanCtx, "registry.lookup")
+	connectorConfig, endpointConfig, environment, err := e.resolve(ctx)
+	if connectorConfig != nil {
+		lookupSpan.SetAttributes(attribute.String("connector.version", connectorConfig.Version))
 	}
-
-	// 2. Obtém configuração do endpoint
-	endpointConfig, exists := connectorConfig.Integration.Endpoints[ctx.EndpointName]
-	if !exists {
-		return nil, fmt.Errorf("endpoint not found: %s", ctx.EndpointName)
+	observability.EndSpan(lookupSpan, err)
+	if err != nil {
+		return nil, err
 	}
 
-	// 3. Obtém URL base do ambiente
-	environment, exists := connectorConfig.Environments[ctx.Environment]
-	if !exists {
-		return nil, fmt.Errorf("environment not found: %s", ctx.Environment)
+	// Dry run: request montada, sem upstream, cache nem métricas
+	if ctx.DryRun {
+		return e.plan(spanCtx, ctx, connectorConfig, endpointConfig, environment, startTime)
 	}
 
-	// 4. Cria HTTP Client com resiliência
-	httpClient := NewHTTPClient(&connectorConfig.Integration.Resilience)
+	trace := newExplainTrace(ctx.Explain)
+	trace.step("resolve", startTime)
+
+	// Métricas por versão (comparação canary × estável antes do cutover)
+	defer func() {
+		status := "success"
+		switch {
+		case err != nil:
+			status = "error"
+		case result != nil && result.CacheHit:
+			status = "cache_hit"
+		}
+		observability.RecordVersionRequest(ctx.ConnectorID, connectorConfig.Version, ctx.EndpointName, status, time.Since(startTime).Seconds())
+		if trace != nil {
+			// Explain acompanha também as falhas
+			if result == nil {
+				result = &types.ExecutionResult{Error: err, Duration: time.Since(startTime)}
+			}
+			result.Explain = &trace.explanation
+		}
+		if result != nil {
+			result.Version = connectorConfig.Version
+		}
+	}()
 
-	// 5. Configura autenticação
-	authenticator, err := e.authEngine.GetAuthenticator(&connectorConfig.Integration.Auth)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get authenticator: %w", err)
+	// Cache (read-through) dos conectores com cache habilitado
+	// Explain ignora o cache na leitura (sempre chama o upstream)
+	cacheManager, cacheKey, cacheTTL := e.cacheFor(connectorConfig, ctx)
+	if trace != nil {
+		trace.explanation.CacheKey = cacheKey
+	}
+	if cacheManager != nil && !ctx.RefreshCache && !ctx.Explain {
+		if result := e.fromCache(spanCtx, cacheManager, cacheKey, cacheTTL, ctx, startTime); result != nil {
+			return result, nil
+		}
 	}
 
-	// 6. Constrói URL
+	// 4. Constrói URL
+	stepStart := time.Now()
 	url := e.buildURL(environment.BaseURL, endpointConfig.Path, ctx.Params)
 
-	// 7. Constrói request
-	req, err := e.buildRequest(context.Background(), &endpointConfig, url, ctx.Params)
+	// 5. Constrói request
+	reqCtx := replay.WithTarget(spanCtx, ctx.ConnectorID, ctx.EndpointName)
+	req, err := e.buildRequest(reqCtx, &endpointConfig, url, ctx.Params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build request: %w", err)
+		return nil, newExecutionError(ctx, CodeInvalidParams, fmt.Errorf("failed to build request: %w", err))
 	}
-
-	// 8. Aplica autenticação
-	if err := authenticator.Authenticate(req); err != nil {
-		return nil, fmt.Errorf("authentication failed: %w", err)
+	trace.step("build_request", stepStart)
+
+	// 6. Cria HTTP Client e aplica autenticação (ou serve de fixtures em replay)
+	stepStart = time.Now()
+	var httpClient *HTTPClient
+	if e.replay != nil {
+		httpClient = NewHTTPClient(nil)
+		httpClient.client.Transport = e.replay
+	} else {
+		_, authSpan := observability.StartSpan(spanCtx, "connector.auth",
+			attribute.String("auth.type", connectorConfig.Integration.Auth.Type),
+		)
+		httpClient, err = e.authenticate(req, connectorConfig)
+		observability.EndSpan(authSpan, err)
+		if err != nil {
+			return nil, newExecutionError(ctx, CodeAuthFailed, err)
+		}
 	}
-
-	// 9. Configura TLS para mTLS se necessário
-	if mtlsAuth, ok := authenticator.(*auth.MTLSAuthenticator); ok {
-		httpClient = e.createMTLSClient(mtlsAuth.GetTLSConfig(), &connectorConfig.Integration.Resilience)
+	trace.step("auth", stepStart)
+	if trace != nil {
+		httpClient = httpClient.withObserver(trace.attempt)
 	}
 
-	// 10. Parse timeout
+	// 7. Parse timeout
 	timeout, _ := parseDuration(endpointConfig.Timeout)
 	if timeout == 0 {
 		timeout = 30 * time.Second
 	}
 
-	// 11. Executa request com resiliência
+	// 8. Executa request com resiliência
+	stepStart = time.Now()
 	resp, err := httpClient.Do(req, timeout)
+	trace.step("upstream", stepStart)
+	if trace != nil {
+		// Breaker compartilhado do connector@versão (estado acumulado entre requests)
+		trace.explanation.CircuitBreaker = httpClient.BreakerState()
+	}
 	if err != nil {
 		duration := time.Since(startTime).Seconds()
 		observability.RecordRequest(ctx.ConnectorID, ctx.EndpointName, "error", duration)
@@ -116,28 +231,36 @@ func (e *Executor) Execute(ctx *types.ExecutionContext) (*types.ExecutionResult,
 			"duration", duration,
 		).Error("HTTP request failed")
 
+		execErr := newExecutionError(ctx, transportCode(err), err)
 		return &types.ExecutionResult{
-			Error:    err,
-			Duration: time.Since(startTime),
-		}, err
+			StatusCode: execErr.UpstreamStatus,
+			Error:      execErr,
+			Duration:   time.Since(startTime),
+		}, execErr
 	}
 	defer resp.Body.Close()
 
-	// 12. Lê response body
+	// 9. Lê response body
+	stepStart = time.Now()
 	body, err := io.ReadAll(resp.Body)
+	trace.step("read_body", stepStart)
 	if err != nil {
 		duration := time.Since(startTime).Seconds()
 		observability.RecordRequest(ctx.ConnectorID, ctx.EndpointName, "error", duration)
 		observability.RecordError(ctx.ConnectorID, ctx.EndpointName, "response_read_failed")
 
+		execErr := newExecutionError(ctx, CodeUpstreamError, fmt.Errorf("failed to read response: %w", err))
+		execErr.UpstreamStatus = resp.StatusCode
 		return &types.ExecutionResult{
 			StatusCode: resp.StatusCode,
-			Error:      fmt.Errorf("failed to read response: %w", err),
+			Error:      execErr,
 			Duration:   time.Since(startTime),
-		}, err
+		}, execErr
 	}
 
-	// 13. Verifica status code
+	trace.raw(body)
+
+	// 10. Verifica status code
 	if !e.isSuccessStatus(resp.StatusCode, endpointConfig.Response.SuccessStatus) {
 		duration := time.Since(startTime).Seconds()
 		observability.RecordRequest(ctx.ConnectorID, ctx.EndpointName, "http_error", duration)
@@ -149,15 +272,33 @@ func (e *Executor) Execute(ctx *types.ExecutionContext) (*types.ExecutionResult,
 			"duration", duration,
 		).Warn("Request returned non-success status code")
 
+		execErr := newExecutionError(ctx, CodeUpstreamError, statusError(resp, fmt.Errorf("request failed with status %d", resp.StatusCode)))
 		return &types.ExecutionResult{
 			StatusCode: resp.StatusCode,
-			Error:      fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body)),
+			Error:      execErr, // sem o body do upstream (vai para logs e respostas)
 			Duration:   time.Since(startTime),
-		}, fmt.Errorf("request failed with status %d", resp.StatusCode)
+		}, execErr
 	}
 
-	// 14. Transforma response (JSONPath + plugins)
+	// 11. Valida payload upstream contra JSON Schema (se configurado)
+	stepStart = time.Now()
+	_, transformSpan := observability.StartSpan(spanCtx, "connector.transform",
+		attribute.Int("http.response_size", len(body)),
+	)
+	violations := e.transformer.ValidateSchema(body, &endpointConfig.Response)
+
+	// 12. Transforma response (JSONPath + plugins)
 	data, err := e.transformer.Transform(body, &endpointConfig.Response)
+	transformSpan.SetAttributes(attribute.Int("transform.violations", len(violations)))
+	observability.EndSpan(transformSpan, err)
+	trace.step("transform", stepStart)
+	if trace != nil {
+		trace.explanation.Mapped = data
+	}
+	if err != nil && len(violations) > 0 && endpointConfig.Response.Validation == transform.ValidationStrict {
+		// Payload fora do contrato: reporta as violações em vez do erro de transformação
+		return e.handleViolations(ctx, &endpointConfig.Response, violations, resp.StatusCode, startTime)
+	}
 	if err != nil {
 		duration := time.Since(startTime).Seconds()
 		observability.RecordRequest(ctx.ConnectorID, ctx.EndpointName, "transform_error", duration)
@@ -168,14 +309,30 @@ func (e *Executor) Execute(ctx *types.ExecutionContext) (*types.ExecutionResult,
 			"error", err.Error(),
 		).Error("Failed to transform response")
 
+		execErr := newExecutionError(ctx, CodeTransformFailed, fmt.Errorf("failed to transform response: %w", err))
+		execErr.UpstreamStatus = resp.StatusCode
 		return &types.ExecutionResult{
 			StatusCode: resp.StatusCode,
-			Error:      fmt.Errorf("failed to transform response: %w", err),
+			Error:      execErr,
 			Duration:   time.Since(startTime),
-		}, err
+		}, execErr
+	}
+
+	// 13. Verifica contrato da saída mapeada
+	violations = append(violations, transform.CheckRequiredFields(data, &endpointConfig.Response)...)
+	if len(violations) > 0 {
+		if result, err := e.handleViolations(ctx, &endpointConfig.Response, violations, resp.StatusCode, startTime); err != nil {
+			return result, err
+		}
+	}
+
+	// 14. Sucesso! Grava no cache e registra métricas
+	if cacheManager != nil {
+		if err := cacheManager.Set(spanCtx, cacheKey, data, cacheTTL); err != nil {
+			observability.WithFields("connector", ctx.ConnectorID, "key", cacheKey, "error", err.Error()).Warn("Failed to store response in cache")
+		}
 	}
 
-	// 15. Sucesso! Registra métricas
 	duration := time.Since(startTime).Seconds()
 	observability.RecordRequest(ctx.ConnectorID, ctx.EndpointName, "success", duration)
 	observability.WithFields(
@@ -185,7 +342,7 @@ func (e *Executor) Execute(ctx *types.ExecutionContext) (*types.ExecutionResult,
 		"duration", duration,
 	).Info("Request completed successfully")
 
-	// 16. Retorna resultado
+	// 15. Retorna resultado
 	return &types.ExecutionResult{
 		Data:       data,
 		StatusCode: resp.StatusCode,
@@ -194,6 +351,181 @@ func (e *Executor) Execute(ctx *types.ExecutionContext) (*types.ExecutionResult,
 	}, nil
 }
 
+// cacheFor cache, chave e TTL da chamada (key_pattern e ttl_rules; nil se o connector não usa cache)
+func (e *Executor) cacheFor(connectorConfig *types.ConnectorConfig, ctx *types.ExecutionContext) (*cache.MultiLevelCacheManager, string, time.Duration) {
+	if !connectorConfig.Integration.Cache.Enabled {
+		return nil, "", 0
+	}
+	manager, ok := e.caches.Get(connectorConfig.ID)
+	if !ok {
+		return nil, "", 0
+	}
+	policy, ok := e.caches.Policy(connectorConfig)
+	if !ok {
+		return nil, "", 0
+	}
+	// Sufixo @versão: versões lado a lado não compartilham respostas
+	key := policy.Key(ctx.EndpointName, ctx.Params) + "@" + connectorConfig.Version
+	return manager, key, policy.TTL(ctx.EndpointName, ctx.Params)
+}
+
+// fromCache resultado em cache (nil em caso de miss)
+func (e *Executor) fromCache(
+	spanCtx context.Context,
+	manager *cache.MultiLevelCacheManager,
+	key string,
+	ttl time.Duration,
+	ctx *types.ExecutionContext,
+	startTime time.Time,
+) *types.ExecutionResult {
+	value, level, err := manager.GetWithTTL(spanCtx, key, ttl)
+	if err != nil || level == cache.LevelExternal {
+		return nil
+	}
+
+	data, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	observability.RecordRequest(ctx.ConnectorID, ctx.EndpointName, "cache_hit", time.Since(startTime).Seconds())
+	observability.Debug("Response served from cache", "connector", ctx.ConnectorID, "key", key, "level", level)
+
+	return &types.ExecutionResult{
+		Data:       data,
+		StatusCode: http.StatusOK,
+		Duration:   time.Since(startTime),
+		CacheHit:   true,
+	}
+}
+
+// resolve carrega configuração do connector, do endpoint e URL base do ambiente
+func (e *Executor) resolve(ctx *types.ExecutionContext) (*types.ConnectorConfig, types.EndpointConfig, types.Environment, error) {
+	connectorConfig, err := e.registry.Resolve(ctx.ConnectorID, ctx.Version)
+	if err != nil {
+		observability.RecordError(ctx.ConnectorID, ctx.EndpointName, "connector_not_found")
+		return nil, types.EndpointConfig{}, types.Environment{}, newExecutionError(ctx, CodeConnectorNotFound, fmt.Errorf("connector not found: %w", err))
+	}
+
+	endpointConfig, exists := connectorConfig.Integration.Endpoints[ctx.EndpointName]
+	if !exists {
+		return connectorConfig, types.EndpointConfig{}, types.Environment{}, newExecutionError(ctx, CodeEndpointNotFound, fmt.Errorf("endpoint not found: %s", ctx.EndpointName))
+	}
+
+	environment, exists := connectorConfig.Environments[ctx.Environment]
+	if !exists {
+		return connectorConfig, endpointConfig, types.Environment{}, newExecutionError(ctx, CodeEnvironmentNotFound, fmt.Errorf("environment not found: %s", ctx.Environment))
+	}
+
+	if err := validateParams(endpointConfig, ctx.Params); err != nil {
+		return connectorConfig, endpointConfig, environment, newExecutionError(ctx, CodeInvalidParams, err)
+	}
+
+	return connectorConfig, endpointConfig, environment, nil
+}
+
+// validateParams exige os params obrigatórios do endpoint (sem default)
+func validateParams(endpointConfig types.EndpointConfig, params map[string]interface{}) error {
+	for _, list := range [][]types.ParameterConfig{endpointConfig.PathParams, endpointConfig.QueryParams} {
+		for _, param := range list {
+			if !param.Required || param.Default != nil {
+				continue
+			}
+			if value, ok := params[param.Name]; !ok || value == nil {
+				return fmt.Errorf("missing required param: %s", param.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// authenticate aplica autenticação na request e retorna o HTTP client com resiliência do connector
+// (com TLS de cliente quando o connector usa mTLS)
+func (e *Executor) authenticate(req *http.Request, connectorConfig *types.ConnectorConfig) (*HTTPClient, error) {
+	authenticator, err := e.authEngine.GetAuthenticator(&connectorConfig.Integration.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authenticator: %w", err)
+	}
+
+	if err := authenticator.Authenticate(req); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	return e.clientFor(connectorConfig, func() *HTTPClient {
+		if mtlsAuth, ok := authenticator.(*auth.MTLSAuthenticator); ok {
+			return e.createMTLSClient(mtlsAuth.GetTLSConfig(), &connectorConfig.Integration.Resilience)
+		}
+		return NewHTTPClient(&connectorConfig.Integration.Resilience)
+	}), nil
+}
+
+// sharedClient HTTP client de um connector@versão e o config que o originou
+type sharedClient struct {
+	config *types.ConnectorConfig
+	client *HTTPClient
+}
+
+// clientFor HTTP client compartilhado do connector@versão (mesmo breaker e rate limiter em todas as requests)
+// Recriado com newClient na primeira chamada ou quando o registry recarrega o connector
+func (e *Executor) clientFor(connectorConfig *types.ConnectorConfig, newClient func() *HTTPClient) *HTTPClient {
+	key := connectorConfig.ID + "@" + connectorConfig.Version
+
+	e.clientsMu.Lock()
+	defer e.clientsMu.Unlock()
+
+	if shared, ok := e.clients[key]; ok && shared.config == connectorConfig {
+		return shared.client
+	}
+
+	client := newClient()
+	if e.wrapTransport != nil {
+		client.client.Transport = e.wrapTransport(client.client.Transport)
+	}
+	e.clients[key] = &sharedClient{config: connectorConfig, client: client}
+	return client
+}
+
+// handleViolations registra violações de contrato e, em modo strict, falha a execução
+func (e *Executor) handleViolations(
+	ctx *types.ExecutionContext,
+	response *types.ResponseConfig,
+	violations []transform.Violation,
+	statusCode int,
+	startTime time.Time,
+) (*types.ExecutionResult, error) {
+	for _, violation := range violations {
+		observability.RecordResponseViolation(ctx.ConnectorID, ctx.EndpointName, violation.Type)
+	}
+
+	observability.WithFields(
+		"connector", ctx.ConnectorID,
+		"endpoint", ctx.EndpointName,
+		"violations", len(violations),
+		"first_violation", fmt.Sprintf("%s %s: %s", violations[0].Type, violations[0].Location, violations[0].Message),
+		"mode", response.Validation,
+	).Warn("Response violates endpoint contract")
+
+	if response.Validation != transform.ValidationStrict {
+		return nil, nil
+	}
+
+	duration := time.Since(startTime).Seconds()
+	observability.RecordRequest(ctx.ConnectorID, ctx.EndpointName, "validation_error", duration)
+	observability.RecordError(ctx.ConnectorID, ctx.EndpointName, "response_validation_failed")
+
+	err := newExecutionError(ctx, CodeContractViolation, &ResponseValidationError{
+		ConnectorID:  ctx.ConnectorID,
+		EndpointName: ctx.EndpointName,
+		Violations:   violations,
+	})
+	err.UpstreamStatus = statusCode
+	return &types.ExecutionResult{
+		StatusCode: statusCode,
+		Error:      err,
+		Duration:   time.Since(startTime),
+	}, err
+}
+
 // buildURL constrói URL substituindo placeholders
 func (e *Executor) buildURL(baseURL, path string, params map[string]interface{}) string {
 	url := baseURL + path
@@ -0,0 +1,628 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/auth"
+	"github.com/bgc/integration-gateway/internal/cache"
+	"github.com/bgc/integration-gateway/internal/observability"
+	"github.com/bgc/integration-gateway/internal/registry"
+	"github.com/bgc/integration-gateway/internal/transform"
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const tracingConnector = `
+id: tracing-test
+name: Tracing Test
+version: 1.0.0
+
+integration:
+  type: rest_api
+  auth:
+    type: none
+  endpoints:
+    exportacao_mes:
+      method: GET
+      path: /api/exp/{ano}
+      response:
+        success_status: [200]
+        mapping:
+          total_records: $.total
+  resilience:
+    retry:
+      max_attempts: 3
+      backoff: constant
+      initial_interval: 1ms
+
+environments:
+  development:
+    base_url: BASE_URL
+`
+
+// setupTracing registra um tracer provider em memória durante o teste
+func setupTracing(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func TestExecute_PropagatesTraceToUpstream(t *testing.T) {
+	recorder := setupTracing(t)
+
+	var calls int32
+	var traceparents []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"total": 3}`))
+	}))
+	defer upstream.Close()
+
+	configDir := t.TempDir()
+	config := strings.Replace(tracingConnector, "BASE_URL", upstream.URL, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "tracing-test.yaml"), []byte(config), 0644))
+
+	reg := registry.NewRegistry(configDir)
+	require.NoError(t, reg.LoadAll())
+	executor := NewExecutor(reg, auth.NewEngine(nil, auth.NewSimpleSecretStore()), transform.NewEngine())
+
+	// Span do caller (ex: bgc-api)
+	parentCtx, parent := otel.Tracer("test").Start(context.Background(), "caller")
+	result, err := executor.Execute(&types.ExecutionContext{
+		ConnectorID:  "tracing-test",
+		EndpointName: "exportacao_mes",
+		Environment:  "development",
+		Params:       map[string]interface{}{"ano": 2024},
+		Context:      parentCtx,
+	})
+	parent.End()
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result.Data["total_records"])
+
+	traceID := parent.SpanContext().TraceID().String()
+
+	// Cada tentativa propaga o trace do caller com o próprio span
+	require.Len(t, traceparents, 2)
+	for _, traceparent := range traceparents {
+		assert.Contains(t, traceparent, traceID)
+	}
+	assert.NotEqual(t, traceparents[0], traceparents[1])
+
+	spans := make(map[string]int)
+	for _, span := range recorder.Ended() {
+		assert.Equal(t, traceID, span.SpanContext().TraceID().String(), span.Name())
+		spans[span.Name()]++
+	}
+	assert.Equal(t, 1, spans["connector.execute"])
+	assert.Equal(t, 1, spans["registry.lookup"])
+	assert.Equal(t, 1, spans["connector.auth"])
+	assert.Equal(t, 2, spans["http.attempt"])
+	assert.Equal(t, 1, spans["connector.transform"])
+}
+
+func TestExecute_RecordsLookupErrorOnSpan(t *testing.T) {
+	recorder := setupTracing(t)
+
+	reg := registry.NewRegistry(t.TempDir())
+	executor := NewExecutor(reg, auth.NewEngine(nil, auth.NewSimpleSecretStore()), transform.NewEngine())
+
+	_, err := executor.Execute(&types.ExecutionContext{ConnectorID: "missing", EndpointName: "x", Environment: "development"})
+	require.Error(t, err)
+
+	var execute sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "connector.execute" {
+			execute = span
+		}
+	}
+	require.NotNil(t, execute)
+	assert.Equal(t, "Error", execute.Status().Code.String())
+	assert.Contains(t, execute.Status().Description, "connector not found")
+}
+
+func TestExecute_ServesFromCache(t *testing.T) {
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"total": 3}`))
+	}))
+	defer upstream.Close()
+
+	configDir := t.TempDir()
+	config := strings.Replace(tracingConnector, "BASE_URL", upstream.URL, 1)
+	config = strings.Replace(config, "  resilience:", "  cache:\n    enabled: true\n    ttl: 1m\n  resilience:", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "tracing-test.yaml"), []byte(config), 0644))
+
+	reg := registry.NewRegistry(configDir)
+	require.NoError(t, reg.LoadAll())
+
+	caches, err := cache.NewNamespaces(cache.ManagerConfig{L1Config: cache.DefaultL1Config(), EnableL1: true}, reg.List())
+	require.NoError(t, err)
+	defer caches.Close()
+
+	executor := NewExecutor(reg, auth.NewEngine(nil, auth.NewSimpleSecretStore()), transform.NewEngine(), WithCache(caches))
+	execute := func(refresh bool) *types.ExecutionResult {
+		result, err := executor.Execute(&types.ExecutionContext{
+			ConnectorID:  "tracing-test",
+			EndpointName: "exportacao_mes",
+			Environment:  "development",
+			Params:       map[string]interface{}{"ano": 2024},
+			RefreshCache: refresh,
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.False(t, execute(false).CacheHit)
+
+	// Segunda chamada servida pelo L1
+	cached := execute(false)
+	assert.True(t, cached.CacheHit)
+	assert.EqualValues(t, 3, cached.Data["total_records"])
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+	// RefreshCache ignora o cache e regrava a resposta
+	assert.False(t, execute(true).CacheHit)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestExecute_RoutesVersions(t *testing.T) {
+	upstream := func(total int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"total": %d}`, total)
+		}))
+	}
+	stable, canary := upstream(1), upstream(2)
+	defer stable.Close()
+	defer canary.Close()
+
+	// Canary com 100% do tráfego sem versão fixada
+	configDir := t.TempDir()
+	v1 := strings.Replace(tracingConnector, "BASE_URL", stable.URL, 1)
+	v2 := strings.Replace(tracingConnector, "BASE_URL", canary.URL, 1)
+	v2 = strings.Replace(v2, "version: 1.0.0", "version: 2.0.0\nrollout:\n  weight: 100", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "tracing-test.yaml"), []byte(v1), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "tracing-test@2.0.0.yaml"), []byte(v2), 0644))
+
+	reg := registry.NewRegistry(configDir)
+	require.NoError(t, reg.LoadAll())
+	executor := NewExecutor(reg, auth.NewEngine(nil, auth.NewSimpleSecretStore()), transform.NewEngine())
+
+	execute := func(version string) *types.ExecutionResult {
+		result, err := executor.Execute(&types.ExecutionContext{
+			ConnectorID:  "tracing-test",
+			Version:      version,
+			EndpointName: "exportacao_mes",
+			Environment:  "development",
+			Params:       map[string]interface{}{"ano": 2024},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	routed := execute("")
+	assert.Equal(t, "2.0.0", routed.Version)
+	assert.EqualValues(t, 2, routed.Data["total_records"])
+
+	before := testutil.ToFloat64(observability.ConnectorVersionRequests.WithLabelValues("tracing-test", "1.0.0", "exportacao_mes", "success"))
+	pinned := execute("1.0.0")
+	assert.Equal(t, "1.0.0", pinned.Version)
+	assert.EqualValues(t, 1, pinned.Data["total_records"])
+	assert.Equal(t, before+1, testutil.ToFloat64(observability.ConnectorVersionRequests.WithLabelValues("tracing-test", "1.0.0", "exportacao_mes", "success")))
+
+	_, err := executor.Execute(&types.ExecutionContext{ConnectorID: "tracing-test", Version: "9.0.0", EndpointName: "exportacao_mes", Environment: "development"})
+	assert.ErrorContains(t, err, "connector version not found")
+}
+
+func TestExecute_DryRun(t *testing.T) {
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer upstream.Close()
+
+	t.Setenv("SECRET_DRY_RUN_KEY", "segredo")
+	configDir := t.TempDir()
+	config := strings.Replace(tracingConnector, "BASE_URL", upstream.URL, 1)
+	config = strings.Replace(config, "    type: none", "    type: api_key\n    api_key:\n      header_name: X-Api-Key\n      key_ref: dry-run-key", 1)
+	config = strings.Replace(config, "  resilience:", "  cache:\n    enabled: true\n    ttl: 1m\n  resilience:", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "tracing-test.yaml"), []byte(config), 0644))
+
+	reg := registry.NewRegistry(configDir)
+	require.NoError(t, reg.LoadAll())
+
+	caches, err := cache.NewNamespaces(cache.ManagerConfig{L1Config: cache.DefaultL1Config(), EnableL1: true}, reg.List())
+	require.NoError(t, err)
+	defer caches.Close()
+
+	executor := NewExecutor(reg, auth.NewEngine(nil, auth.NewSimpleSecretStore()), transform.NewEngine(), WithCache(caches))
+	result, err := executor.Execute(&types.ExecutionContext{
+		ConnectorID:  "tracing-test",
+		EndpointName: "exportacao_mes",
+		Environment:  "development",
+		Params:       map[string]interface{}{"ano": 2024},
+		DryRun:       true,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Plan)
+
+	plan := result.Plan
+	assert.Equal(t, "GET", plan.Method)
+	assert.Equal(t, upstream.URL+"/api/exp/2024", plan.URL)
+	assert.Equal(t, types.MaskedValue, plan.Headers["X-Api-Key"])
+	assert.Equal(t, "development", plan.Environment)
+	assert.Equal(t, "1.0.0", plan.Version)
+	assert.Equal(t, "exportacao_mes:ano=2024@1.0.0", plan.CacheKey)
+	assert.Equal(t, "1m0s", plan.CacheTTL)
+	assert.Equal(t, "30s", plan.Timeout)
+	assert.Equal(t, 3, plan.Resilience.Retry.MaxAttempts)
+	assert.EqualValues(t, 0, atomic.LoadInt32(&calls), "dry run não chama o upstream")
+
+	// Secret ausente aparece no dry run
+	t.Setenv("SECRET_DRY_RUN_KEY", "")
+	_, err = executor.Execute(&types.ExecutionContext{ConnectorID: "tracing-test", EndpointName: "exportacao_mes", Environment: "development", DryRun: true})
+	assert.ErrorContains(t, err, "secret not found")
+}
+
+func TestExecute_Explain(t *testing.T) {
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Write([]byte(`{"total": 3}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`not found`))
+		}
+	}))
+	defer upstream.Close()
+
+	configDir := t.TempDir()
+	config := strings.Replace(tracingConnector, "BASE_URL", upstream.URL, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "tracing-test.yaml"), []byte(config), 0644))
+
+	reg := registry.NewRegistry(configDir)
+	require.NoError(t, reg.LoadAll())
+	executor := NewExecutor(reg, auth.NewEngine(nil, auth.NewSimpleSecretStore()), transform.NewEngine())
+
+	execute := func() (*types.ExecutionResult, error) {
+		return executor.Execute(&types.ExecutionContext{
+			ConnectorID:  "tracing-test",
+			EndpointName: "exportacao_mes",
+			Environment:  "development",
+			Params:       map[string]interface{}{"ano": 2024},
+			Explain:      true,
+		})
+	}
+
+	result, err := execute()
+	require.NoError(t, err)
+	require.NotNil(t, result.Explain)
+
+	explain := result.Explain
+	steps := make([]string, 0, len(explain.Steps))
+	for _, step := range explain.Steps {
+		steps = append(steps, step.Name)
+	}
+	assert.Equal(t, []string{"resolve", "build_request", "auth", "upstream", "read_body", "transform"}, steps)
+	require.Len(t, explain.Attempts, 2)
+	assert.Equal(t, http.StatusServiceUnavailable, explain.Attempts[0].StatusCode)
+	assert.Equal(t, http.StatusOK, explain.Attempts[1].StatusCode)
+	assert.Equal(t, 1, explain.Retries)
+	assert.Equal(t, map[string]interface{}{"total": float64(3)}, explain.Raw)
+	assert.EqualValues(t, 3, explain.Mapped["total_records"])
+
+	// Falhas também trazem o explain (payload upstream em texto)
+	result, err = execute()
+	require.Error(t, err)
+	require.NotNil(t, result.Explain)
+	assert.Equal(t, "not found", result.Explain.Raw)
+}
+
+func TestExecute_ErrorTaxonomy(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		connectorID    string
+		params         map[string]interface{}
+		code           string
+		status         int
+		upstreamStatus int
+		retryAfter     time.Duration
+	}{
+		{"connector not found", nil, "missing", nil, CodeConnectorNotFound, http.StatusNotFound, 0, 0},
+		{"upstream 404", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, "", nil, CodeUpstreamNotFound, http.StatusNotFound, http.StatusNotFound, 0},
+		{"upstream 429", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}, "", nil, CodeUpstreamRateLimited, http.StatusTooManyRequests, http.StatusTooManyRequests, 30 * time.Second},
+		{"upstream 422", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"token": "upstream-secret"}`))
+		}, "", nil, CodeUpstreamRejected, http.StatusBadRequest, http.StatusUnprocessableEntity, 0},
+		{"upstream 401", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}, "", nil, CodeUpstreamAuthFailed, http.StatusBadGateway, http.StatusUnauthorized, 0},
+		{"upstream 503 after retries", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, "", nil, CodeUpstreamUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, 5 * time.Second},
+		{"upstream 500 after retries", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}, "", nil, CodeUpstreamError, http.StatusBadGateway, http.StatusInternalServerError, 0},
+		{"transform failed", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`not json`))
+		}, "", nil, CodeTransformFailed, http.StatusBadGateway, http.StatusOK, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := tt.handler
+			if handler == nil {
+				handler = func(w http.ResponseWriter, r *http.Request) {}
+			}
+			upstream := httptest.NewServer(handler)
+			defer upstream.Close()
+
+			configDir := t.TempDir()
+			config := strings.Replace(tracingConnector, "BASE_URL", upstream.URL, 1)
+			require.NoError(t, os.WriteFile(filepath.Join(configDir, "tracing-test.yaml"), []byte(config), 0644))
+
+			reg := registry.NewRegistry(configDir)
+			require.NoError(t, reg.LoadAll())
+			executor := NewExecutor(reg, auth.NewEngine(nil, auth.NewSimpleSecretStore()), transform.NewEngine())
+
+			connectorID := tt.connectorID
+			if connectorID == "" {
+				connectorID = "tracing-test"
+			}
+			result, err := executor.Execute(&types.ExecutionContext{
+				ConnectorID:  connectorID,
+				EndpointName: "exportacao_mes",
+				Environment:  "development",
+				Params:       map[string]interface{}{"ano": 2024},
+			})
+			require.Error(t, err)
+			if result != nil && result.Error != nil {
+				// Resultado e erro retornado são o mesmo erro, sem o body do upstream
+				assert.Equal(t, err, result.Error)
+				assert.NotContains(t, result.Error.Error(), "upstream-secret")
+			}
+
+			execErr := AsExecutionError(err)
+			assert.Equal(t, tt.code, execErr.Code)
+			assert.Equal(t, tt.status, execErr.HTTPStatus())
+			assert.Equal(t, tt.upstreamStatus, execErr.UpstreamStatus)
+			assert.Equal(t, tt.retryAfter, execErr.RetryAfter)
+			assert.Equal(t, connectorID, execErr.ConnectorID)
+			assert.Equal(t, "exportacao_mes", execErr.EndpointName)
+		})
+	}
+}
+
+const resilienceConnector = `
+id: resilience-test
+name: Resilience Test
+version: 1.0.0
+
+integration:
+  type: rest_api
+  auth:
+    type: none
+  endpoints:
+    exportacao_mes:
+      method: GET
+      path: /api/exp/{ano}
+      timeout: 200ms
+      response:
+        success_status: [200]
+        mapping:
+          total_records: $.total
+  resilience:
+    circuit_breaker:
+      success_threshold: 1
+      timeout: 1m
+    rate_limit:
+      requests_per_minute: RPM
+      burst: 1
+
+environments:
+  development:
+    base_url: BASE_URL
+`
+
+// newResilienceExecutor executor com o resilience-test apontando para o upstream
+func newResilienceExecutor(t *testing.T, upstreamURL string, requestsPerMinute int) *Executor {
+	configDir := t.TempDir()
+	config := strings.NewReplacer("BASE_URL", upstreamURL, "RPM", fmt.Sprint(requestsPerMinute)).Replace(resilienceConnector)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "resilience-test.yaml"), []byte(config), 0644))
+
+	reg := registry.NewRegistry(configDir)
+	require.NoError(t, reg.LoadAll())
+	return NewExecutor(reg, auth.NewEngine(nil, auth.NewSimpleSecretStore()), transform.NewEngine())
+}
+
+func TestExecute_CircuitBreakerSharedAcrossRequests(t *testing.T) {
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer upstream.Close()
+
+	executor := newResilienceExecutor(t, upstream.URL, 6000)
+	execute := func() error {
+		_, err := executor.Execute(&types.ExecutionContext{
+			ConnectorID:  "resilience-test",
+			EndpointName: "exportacao_mes",
+			Environment:  "development",
+			Params:       map[string]interface{}{"ano": 2024},
+		})
+		return err
+	}
+
+	// Três falhas em requests distintas abrem o circuito (>= 3 requests, >= 60% de falhas)
+	for i := 0; i < 3; i++ {
+		err := execute()
+		require.Error(t, err)
+		assert.Equal(t, CodeUpstreamError, AsExecutionError(err).Code)
+	}
+
+	err := execute()
+	require.Error(t, err)
+	execErr := AsExecutionError(err)
+	assert.Equal(t, CodeCircuitOpen, execErr.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, execErr.HTTPStatus())
+	assert.Equal(t, time.Minute, execErr.RetryAfter)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls), "open circuit must not call the upstream")
+}
+
+func TestExecute_CircuitOpenSpan(t *testing.T) {
+	recorder := setupTracing(t)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer upstream.Close()
+
+	executor := newResilienceExecutor(t, upstream.URL, 6000)
+	for i := 0; i < 4; i++ {
+		_, err := executor.Execute(&types.ExecutionContext{
+			ConnectorID:  "resilience-test",
+			EndpointName: "exportacao_mes",
+			Environment:  "development",
+			Params:       map[string]interface{}{"ano": 2024},
+		})
+		require.Error(t, err)
+	}
+
+	// Só a request rejeitada pelo breaker aberto gera o span http.circuit_breaker
+	var rejected []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "http.circuit_breaker" {
+			rejected = append(rejected, span)
+		}
+	}
+	require.Len(t, rejected, 1)
+	assert.Equal(t, codes.Error, rejected[0].Status().Code)
+	assert.Contains(t, rejected[0].Attributes(), attribute.String("circuit_breaker.state", "open"))
+}
+
+func TestExecute_ExplainCircuitBreakerState(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer upstream.Close()
+
+	executor := newResilienceExecutor(t, upstream.URL, 6000)
+	explain := func() *types.Explanation {
+		result, err := executor.Execute(&types.ExecutionContext{
+			ConnectorID:  "resilience-test",
+			EndpointName: "exportacao_mes",
+			Environment:  "development",
+			Params:       map[string]interface{}{"ano": 2024},
+			Explain:      true,
+		})
+		require.Error(t, err)
+		require.NotNil(t, result.Explain)
+		return result.Explain
+	}
+
+	assert.Equal(t, "closed", explain().CircuitBreaker)
+	explain()
+	explain()
+
+	// Estado do breaker compartilhado do connector, não de um breaker novo por request
+	opened := explain()
+	assert.Equal(t, "open", opened.CircuitBreaker)
+	assert.Empty(t, opened.Attempts)
+}
+
+func TestExecute_RateLimitSharedAcrossRequests(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"total": 1}`))
+	}))
+	defer upstream.Close()
+
+	// 1 request por minuto (burst 1): a segunda espera mais que o timeout do endpoint
+	executor := newResilienceExecutor(t, upstream.URL, 1)
+	execute := func() error {
+		_, err := executor.Execute(&types.ExecutionContext{
+			ConnectorID:  "resilience-test",
+			EndpointName: "exportacao_mes",
+			Environment:  "development",
+			Params:       map[string]interface{}{"ano": 2024},
+		})
+		return err
+	}
+
+	require.NoError(t, execute())
+
+	err := execute()
+	require.Error(t, err)
+	execErr := AsExecutionError(err)
+	assert.Equal(t, CodeRateLimited, execErr.Code)
+	assert.Equal(t, http.StatusTooManyRequests, execErr.HTTPStatus())
+}
+
+func TestExecute_MissingRequiredParam(t *testing.T) {
+	configDir := t.TempDir()
+	config := strings.Replace(tracingConnector, "BASE_URL", "http://localhost", 1)
+	config = strings.Replace(config, "      path: /api/exp/{ano}", "      path: /api/exp/{ano}\n      path_params:\n        - name: ano\n          type: integer\n          required: true", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "tracing-test.yaml"), []byte(config), 0644))
+
+	reg := registry.NewRegistry(configDir)
+	require.NoError(t, reg.LoadAll())
+	executor := NewExecutor(reg, auth.NewEngine(nil, auth.NewSimpleSecretStore()), transform.NewEngine())
+
+	_, err := executor.Execute(&types.ExecutionContext{ConnectorID: "tracing-test", EndpointName: "exportacao_mes", Environment: "development", Params: map[string]interface{}{}})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "missing required param: ano")
+	assert.Equal(t, http.StatusBadRequest, AsExecutionError(err).HTTPStatus())
+}
+
+func TestAsExecutionError(t *testing.T) {
+	execErr := AsExecutionError(fmt.Errorf("boom"))
+	assert.Equal(t, CodeInternal, execErr.Code)
+	assert.Equal(t, http.StatusInternalServerError, execErr.HTTPStatus())
+
+	assert.Equal(t, 10*time.Second, parseRetryAfter("10"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-1"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("amanhã"))
+	assert.InDelta(t, float64(time.Minute), float64(parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))), float64(2*time.Second))
+}
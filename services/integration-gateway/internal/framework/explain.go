@@ -0,0 +1,149 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/auth"
+	"github.com/bgc/integration-gateway/internal/types"
+)
+
+// explainTrace coleta etapas e tentativas de uma execução com explain (nil = explain desligado)
+type explainTrace struct {
+	explanation types.Explanation
+}
+
+func newExplainTrace(enabled bool) *explainTrace {
+	if !enabled {
+		return nil
+	}
+	return &explainTrace{explanation: types.Explanation{Steps: []types.StepTiming{}}}
+}
+
+// step registra a duração de uma etapa iniciada em start
+func (t *explainTrace) step(name string, start time.Time) {
+	if t == nil {
+		return
+	}
+	t.explanation.Steps = append(t.explanation.Steps, types.StepTiming{Name: name, Duration: time.Since(start).String()})
+}
+
+// attempt registra uma tentativa HTTP (observer do HTTPClient)
+func (t *explainTrace) attempt(trace types.AttemptTrace) {
+	t.explanation.Attempts = append(t.explanation.Attempts, trace)
+	if trace.Attempt > 1 {
+		t.explanation.Retries++
+	}
+}
+
+// raw guarda o payload upstream (JSON decodificado, senão texto)
+func (t *explainTrace) raw(body []byte) {
+	if t == nil {
+		return
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		decoded = string(body)
+	}
+	t.explanation.Raw = decoded
+}
+
+// plan monta a request como seria enviada, sem chamar o upstream (dry run)
+// Não consome rate limit nem altera o circuit breaker; oauth2 não busca token
+func (e *Executor) plan(
+	spanCtx context.Context,
+	ctx *types.ExecutionContext,
+	connectorConfig *types.ConnectorConfig,
+	endpointConfig types.EndpointConfig,
+	environment types.Environment,
+	startTime time.Time,
+) (*types.ExecutionResult, error) {
+	url := e.buildURL(environment.BaseURL, endpointConfig.Path, ctx.Params)
+	req, err := e.buildRequest(spanCtx, &endpointConfig, url, ctx.Params)
+	if err != nil {
+		return nil, newExecutionError(ctx, CodeInvalidParams, fmt.Errorf("failed to build request: %w", err))
+	}
+
+	var body []byte
+	if req.GetBody != nil {
+		reader, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		body, _ = io.ReadAll(reader)
+	}
+
+	// Em replay não há autenticação (mesmo comportamento da execução)
+	masked := map[string]bool{}
+	if e.replay == nil {
+		masked, err = e.planAuth(req, connectorConfig)
+		if err != nil {
+			return nil, newExecutionError(ctx, CodeAuthFailed, err)
+		}
+	}
+
+	headers := make(map[string]string, len(req.Header))
+	for name, values := range req.Header {
+		headers[name] = strings.Join(values, ", ")
+		if masked[name] {
+			headers[name] = types.MaskedValue
+		}
+	}
+
+	timeout, _ := parseDuration(endpointConfig.Timeout)
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	plan := &types.RequestPlan{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		Headers:     headers,
+		Body:        string(body),
+		Environment: ctx.Environment,
+		Version:     connectorConfig.Version,
+		AuthType:    connectorConfig.Integration.Auth.Type,
+		Timeout:     timeout.String(),
+		Resilience:  connectorConfig.Integration.Resilience,
+	}
+	if cacheManager, cacheKey, cacheTTL := e.cacheFor(connectorConfig, ctx); cacheManager != nil {
+		plan.CacheKey = cacheKey
+		plan.CacheTTL = cacheTTL.String()
+	}
+
+	return &types.ExecutionResult{
+		Duration: time.Since(startTime),
+		Version:  connectorConfig.Version,
+		Plan:     plan,
+	}, nil
+}
+
+// planAuth aplica a autenticação sem chamadas de rede e retorna os headers a mascarar
+func (e *Executor) planAuth(req *http.Request, connectorConfig *types.ConnectorConfig) (map[string]bool, error) {
+	config := &connectorConfig.Integration.Auth
+	authenticator, err := e.authEngine.GetAuthenticator(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authenticator: %w", err)
+	}
+
+	masked := map[string]bool{"Authorization": true, "Proxy-Authorization": true}
+	if config.APIKey != nil {
+		masked[http.CanonicalHeaderKey(config.APIKey.HeaderName)] = true
+	}
+
+	// OAuth2 buscaria o token no provedor: o header entra já mascarado
+	if _, ok := authenticator.(*auth.OAuth2Authenticator); ok {
+		req.Header.Set("Authorization", "Bearer "+types.MaskedValue)
+		return masked, nil
+	}
+
+	if err := authenticator.Authenticate(req); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+	return masked, nil
+}
@@ -2,23 +2,33 @@ package framework
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
 	"time"
 
+	"github.com/bgc/integration-gateway/internal/observability"
 	"github.com/bgc/integration-gateway/internal/types"
 	"github.com/sony/gobreaker"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/time/rate"
 )
 
+// errServerStatus resposta 5xx contada como falha pelo circuit breaker
+var errServerStatus = errors.New("server error status")
+
 // HTTPClient cliente HTTP genérico com resiliência
 type HTTPClient struct {
 	client         *http.Client
 	circuitBreaker *gobreaker.CircuitBreaker
+	breakerTimeout time.Duration // tempo em open antes de half-open (Retry-After do 503)
 	rateLimiter    *rate.Limiter
 	retryConfig    *types.RetryConfig
+
+	// Observa cada tentativa ao upstream (explain)
+	observe func(types.AttemptTrace)
 }
 
 // NewHTTPClient cria um novo cliente HTTP com resiliência
@@ -53,6 +63,10 @@ func NewHTTPClient(resilience *types.ResilienceConfig) *HTTPClient {
 		}
 
 		hc.circuitBreaker = gobreaker.NewCircuitBreaker(settings)
+		hc.breakerTimeout = timeout
+		if hc.breakerTimeout == 0 {
+			hc.breakerTimeout = 60 * time.Second // default do gobreaker
+		}
 	}
 
 	// Configura Rate Limiter
@@ -87,20 +101,46 @@ func (c *HTTPClient) Do(req *http.Request, timeout time.Duration) (*http.Respons
 
 	// Rate limiting
 	if c.rateLimiter != nil {
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limit exceeded: %w", err)
+		_, span := observability.StartSpan(ctx, "http.rate_limit")
+		err := c.rateLimiter.Wait(ctx)
+		observability.EndSpan(span, err)
+		if err != nil {
+			// Retry-After: intervalo até o próximo token
+			retryAfter := time.Second
+			if limit := c.rateLimiter.Limit(); limit > 0 {
+				retryAfter = time.Duration(float64(time.Second) / float64(limit))
+			}
+			return nil, &ExecutionError{Code: CodeRateLimited, RetryAfter: retryAfter, Err: fmt.Errorf("rate limit exceeded: %w", err)}
 		}
 	}
 
 	// Circuit breaker + retry
 	if c.circuitBreaker != nil {
-		result, err := c.circuitBreaker.Execute(func() (interface{}, error) {
-			return c.doWithRetry(req)
+		var resp *http.Response
+		_, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+			var err error
+			resp, err = c.doWithRetry(req)
+			if err == nil && resp.StatusCode >= 500 {
+				// 5xx sem retry configurado: falha para o breaker, resposta segue para o executor
+				return resp, errServerStatus
+			}
+			return resp, err
 		})
+		if errors.Is(err, errServerStatus) {
+			return resp, nil
+		}
+		if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+			// Rejeitada pelo breaker sem chamar o upstream
+			_, span := observability.StartSpan(ctx, "http.circuit_breaker",
+				attribute.String("circuit_breaker.state", c.circuitBreaker.State().String()),
+			)
+			observability.EndSpan(span, err)
+			return nil, &ExecutionError{Code: CodeCircuitOpen, RetryAfter: c.breakerTimeout, Err: fmt.Errorf("circuit breaker open: %w", err)}
+		}
 		if err != nil {
 			return nil, err
 		}
-		return result.(*http.Response), nil
+		return resp, nil
 	}
 
 	return c.doWithRetry(req)
@@ -109,7 +149,7 @@ func (c *HTTPClient) Do(req *http.Request, timeout time.Duration) (*http.Respons
 // doWithRetry executa requisição com retry
 func (c *HTTPClient) doWithRetry(req *http.Request) (*http.Response, error) {
 	if c.retryConfig == nil {
-		return c.client.Do(req)
+		return c.doAttempt(req, 1)
 	}
 
 	var lastErr error
@@ -132,7 +172,7 @@ func (c *HTTPClient) doWithRetry(req *http.Request) (*http.Response, error) {
 		// Clone request for retry (body can only be read once)
 		reqClone := req.Clone(req.Context())
 
-		resp, err := c.client.Do(reqClone)
+		resp, err := c.doAttempt(reqClone, attempt)
 
 		// Success
 		if err == nil && resp.StatusCode < 500 {
@@ -142,7 +182,7 @@ func (c *HTTPClient) doWithRetry(req *http.Request) (*http.Response, error) {
 		// Store error
 		lastErr = err
 		if err == nil {
-			lastErr = fmt.Errorf("server error: %d", resp.StatusCode)
+			lastErr = statusError(resp, fmt.Errorf("server error: %d", resp.StatusCode))
 			resp.Body.Close()
 		}
 
@@ -166,6 +206,56 @@ func (c *HTTPClient) doWithRetry(req *http.Request) (*http.Response, error) {
 	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
+// doAttempt executa uma tentativa HTTP em um span próprio, propagando o trace ao upstream
+func (c *HTTPClient) doAttempt(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, span := observability.StartSpan(req.Context(), "http.attempt",
+		attribute.Int("http.attempt", attempt),
+		attribute.String("http.method", req.Method),
+		attribute.String("http.host", req.URL.Host),
+		attribute.String("http.route", req.URL.Path),
+	)
+	observability.InjectTraceHeaders(ctx, req.Header)
+
+	start := time.Now()
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if c.observe != nil {
+		trace := types.AttemptTrace{Attempt: attempt, Duration: time.Since(start).String()}
+		if err != nil {
+			trace.Error = err.Error()
+		} else {
+			trace.StatusCode = resp.StatusCode
+		}
+		c.observe(trace)
+	}
+
+	// 5xx marca o span com erro (será retentada)
+	spanErr := err
+	if err == nil {
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+		if resp.StatusCode >= 500 {
+			spanErr = fmt.Errorf("server error: %d", resp.StatusCode)
+		}
+	}
+	observability.EndSpan(span, spanErr)
+
+	return resp, err
+}
+
+// withObserver cópia do client que observa as tentativas (mesmo transport, breaker e rate limiter)
+func (c *HTTPClient) withObserver(observe func(types.AttemptTrace)) *HTTPClient {
+	observed := *c
+	observed.observe = observe
+	return &observed
+}
+
+// BreakerState estado do circuit breaker (vazio = sem breaker)
+func (c *HTTPClient) BreakerState() string {
+	if c.circuitBreaker == nil {
+		return ""
+	}
+	return c.circuitBreaker.State().String()
+}
+
 // calculateBackoff calcula tempo de espera baseado na estratégia
 func (c *HTTPClient) calculateBackoff(attempt int, initial, max time.Duration) time.Duration {
 	var wait time.Duration
@@ -25,6 +25,25 @@ var (
 		[]string{"connector", "endpoint"},
 	)
 
+	// ConnectorVersionRequests requisições por versão do connector (comparação canary × estável)
+	ConnectorVersionRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bgc_connector_version_requests_total",
+			Help: "Total number of requests per connector version",
+		},
+		[]string{"connector", "version", "endpoint", "status"},
+	)
+
+	// ConnectorVersionDuration duração das requisições por versão do connector
+	ConnectorVersionDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "bgc_connector_version_duration_seconds",
+			Help:    "Request duration in seconds per connector version",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"connector", "version"},
+	)
+
 	// ConnectorCircuitBreakerState estado do circuit breaker
 	ConnectorCircuitBreakerState = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -79,6 +98,61 @@ var (
 		[]string{"connector", "endpoint", "error_type"},
 	)
 
+	// ConnectorResponseViolations violações de contrato da resposta
+	ConnectorResponseViolations = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bgc_connector_response_violations_total",
+			Help: "Total number of response contract violations (schema, required fields)",
+		},
+		[]string{"connector", "endpoint", "type"},
+	)
+
+	// GatewayAccessDenied chamadas negadas por autenticação, policy ou quota
+	GatewayAccessDenied = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bgc_gateway_access_denied_total",
+			Help: "Total number of gateway calls denied by authentication, policy or quota",
+		},
+		[]string{"caller", "reason"},
+	)
+
+	// GatewayCallerRequests chamadas autorizadas por caller
+	GatewayCallerRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bgc_gateway_caller_requests_total",
+			Help: "Total number of authorized gateway calls per caller",
+		},
+		[]string{"caller", "connector"},
+	)
+
+	// JobRuns execuções de jobs agendados
+	JobRuns = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bgc_gateway_job_runs_total",
+			Help: "Total number of scheduled job runs by status",
+		},
+		[]string{"job", "status"},
+	)
+
+	// JobDuration duração das execuções de jobs agendados
+	JobDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "bgc_gateway_job_duration_seconds",
+			Help:    "Scheduled job run duration in seconds (including retries)",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+		},
+		[]string{"job"},
+	)
+
+	// JobRowsUpserted linhas gravadas pelos jobs agendados
+	JobRowsUpserted = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bgc_gateway_job_rows_upserted_total",
+			Help: "Total number of rows upserted by scheduled jobs",
+		},
+		[]string{"job"},
+	)
+
 	// CertificateExpiryDays dias até expiração do certificado
 	CertificateExpiryDays = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -105,6 +179,12 @@ func RecordRequest(connector, endpoint, status string, duration float64) {
 	ConnectorDuration.WithLabelValues(connector, endpoint).Observe(duration)
 }
 
+// RecordVersionRequest registra uma requisição atendida por uma versão do connector (success, cache_hit, error)
+func RecordVersionRequest(connector, version, endpoint, status string, duration float64) {
+	ConnectorVersionRequests.WithLabelValues(connector, version, endpoint, status).Inc()
+	ConnectorVersionDuration.WithLabelValues(connector, version).Observe(duration)
+}
+
 // RecordCacheHit registra cache hit
 func RecordCacheHit(connector, endpoint string) {
 	ConnectorCacheHits.WithLabelValues(connector, endpoint).Inc()
@@ -125,6 +205,28 @@ func RecordError(connector, endpoint, errorType string) {
 	ConnectorErrors.WithLabelValues(connector, endpoint, errorType).Inc()
 }
 
+// RecordResponseViolation registra violação de contrato da resposta
+func RecordResponseViolation(connector, endpoint, violationType string) {
+	ConnectorResponseViolations.WithLabelValues(connector, endpoint, violationType).Inc()
+}
+
+// RecordAccessDenied registra chamada negada
+func RecordAccessDenied(caller, reason string) {
+	GatewayAccessDenied.WithLabelValues(caller, reason).Inc()
+}
+
+// RecordCallerRequest registra chamada autorizada de um caller
+func RecordCallerRequest(caller, connector string) {
+	GatewayCallerRequests.WithLabelValues(caller, connector).Inc()
+}
+
+// RecordJobRun registra execução de job agendado
+func RecordJobRun(job, status string, duration float64, rows int64) {
+	JobRuns.WithLabelValues(job, status).Inc()
+	JobDuration.WithLabelValues(job).Observe(duration)
+	JobRowsUpserted.WithLabelValues(job).Add(float64(rows))
+}
+
 // SetCircuitBreakerState atualiza estado do circuit breaker
 // 0=closed, 1=half-open, 2=open
 func SetCircuitBreakerState(connector string, state float64) {
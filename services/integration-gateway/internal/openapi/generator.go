@@ -0,0 +1,353 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bgc/integration-gateway/internal/types"
+)
+
+// Version versão do OpenAPI gerado
+const Version = "3.1.0"
+
+// Schema objeto JSON Schema / OpenAPI (serializado com chaves ordenadas)
+type Schema = map[string]interface{}
+
+// Info metadados do documento
+type Info struct {
+	Title   string
+	Version string
+}
+
+// Generate gera o documento OpenAPI com uma operação por endpoint de connector
+// versions lista as versões de cada connector (header X-Connector-Version, opcional)
+func Generate(info Info, connectors []*types.ConnectorConfig, versions map[string][]string) Schema {
+	sorted := make([]*types.ConnectorConfig, len(connectors))
+	copy(sorted, connectors)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	paths := Schema{}
+	tags := make([]interface{}, 0, len(sorted))
+	for _, connector := range sorted {
+		tags = append(tags, Schema{
+			"name":        connector.ID,
+			"description": fmt.Sprintf("%s (%s) v%s", connector.Name, connector.Provider, connector.Version),
+		})
+
+		for _, name := range sortedKeys(connector.Integration.Endpoints) {
+			endpoint := connector.Integration.Endpoints[name]
+			path := fmt.Sprintf("/v1/connectors/%s/%s", connector.ID, name)
+			paths[path] = Schema{"post": operation(connector, name, endpoint, versions[connector.ID])}
+		}
+	}
+
+	return Schema{
+		"openapi": Version,
+		"info": Schema{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": "Gerado a partir das definições YAML dos conectores (config/connectors)",
+		},
+		"tags":     tags,
+		"paths":    paths,
+		"security": []interface{}{Schema{"apiKey": []interface{}{}}, Schema{"bearer": []interface{}{}}},
+		"components": Schema{
+			"securitySchemes": Schema{
+				"apiKey": Schema{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"bearer": Schema{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+			"schemas": Schema{
+				"Error": Schema{
+					"type":     "object",
+					"required": []interface{}{"error", "code"},
+					"properties": Schema{
+						"error":           Schema{"type": "string"},
+						"code":            Schema{"type": "string", "description": "Ex: connector_not_found, upstream_not_found, upstream_timeout, circuit_open"},
+						"connector":       Schema{"type": "string"},
+						"endpoint":        Schema{"type": "string"},
+						"upstream_status": Schema{"type": "integer", "description": "Status HTTP do upstream (ausente se não houve resposta)"},
+						"duration":        Schema{"type": "string"},
+						"violations":      Schema{"type": "array", "items": Schema{"type": "object"}},
+						"explain":         Schema{"type": "object"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// operation operação de um endpoint: params no body, campos mapeados na resposta
+func operation(connector *types.ConnectorConfig, name string, endpoint types.EndpointConfig, versions []string) Schema {
+	errorResponse := func(description string) Schema {
+		return Schema{
+			"description": description,
+			"content":     Schema{"application/json": Schema{"schema": Schema{"$ref": "#/components/schemas/Error"}}},
+		}
+	}
+	retryResponse := func(description string) Schema {
+		response := errorResponse(description)
+		response["headers"] = Schema{"Retry-After": Schema{"description": "Segundos até tentar de novo", "schema": Schema{"type": "integer"}}}
+		return response
+	}
+
+	op := Schema{
+		"operationId": operationID(connector.ID, name),
+		"tags":        []interface{}{connector.ID},
+		"summary":     fmt.Sprintf("%s: %s", connector.Name, name),
+		"description": fmt.Sprintf("Upstream: %s %s", endpoint.Method, endpoint.Path),
+		"requestBody": Schema{
+			"required": true,
+			"content":  Schema{"application/json": Schema{"schema": requestSchema(endpoint)}},
+		},
+		"responses": Schema{
+			"200": Schema{
+				"description": "Resposta mapeada do connector",
+				"headers": Schema{
+					types.VersionHeader: Schema{"description": "Versão do connector que atendeu", "schema": Schema{"type": "string"}},
+				},
+				"content": Schema{"application/json": Schema{"schema": Schema{
+					"type":     "object",
+					"required": []interface{}{"duration", "version"},
+					"properties": Schema{
+						"data":        responseSchema(endpoint.Response),
+						"status_code": Schema{"type": "integer"},
+						"duration":    Schema{"type": "string"},
+						"version":     Schema{"type": "string"},
+						"dry_run":     Schema{"type": "object", "description": "Request montada (apenas com ?dry_run=true)"},
+						"explain":     Schema{"type": "object", "description": "Detalhes da execução (apenas com ?explain=true)"},
+					},
+				}}},
+			},
+			"400": errorResponse("Body ou params inválidos (invalid_request, invalid_params, upstream_rejected)"),
+			"401": errorResponse("Caller não autenticado"),
+			"403": errorResponse("Fora da policy do caller"),
+			"404": errorResponse("Connector, endpoint ou recurso upstream não encontrado"),
+			"429": retryResponse("Quota do caller ou rate limit (do connector ou do upstream) excedido"),
+			"500": errorResponse("Erro interno do gateway"),
+			"502": errorResponse("Falha upstream, de autenticação, de transformação ou de contrato"),
+			"503": retryResponse("Circuit breaker aberto ou upstream indisponível"),
+			"504": errorResponse("Timeout do upstream"),
+		},
+	}
+
+	parameters := []interface{}{
+		Schema{
+			"name":        "dry_run",
+			"in":          "query",
+			"required":    false,
+			"description": "Retorna a request montada (em dry_run) sem chamar o upstream",
+			"schema":      Schema{"type": "boolean"},
+		},
+		Schema{
+			"name":        "explain",
+			"in":          "query",
+			"required":    false,
+			"description": "Executa sem ler o cache e detalha etapas, tentativas e payload upstream (em explain)",
+			"schema":      Schema{"type": "boolean"},
+		},
+	}
+	if len(versions) > 1 {
+		enum := make([]interface{}, 0, len(versions))
+		for _, version := range versions {
+			enum = append(enum, version)
+		}
+		parameters = append(parameters, Schema{
+			"name":        types.VersionHeader,
+			"in":          "header",
+			"required":    false,
+			"description": "Fixa a versão do connector (default: roteamento canary)",
+			"schema":      Schema{"type": "string", "enum": enum},
+		})
+	}
+	op["parameters"] = parameters
+
+	return op
+}
+
+// requestSchema params de path e query do endpoint (enviados no body JSON)
+func requestSchema(endpoint types.EndpointConfig) Schema {
+	properties := Schema{}
+	required := []interface{}{}
+
+	params := append(append([]types.ParameterConfig{}, endpoint.PathParams...), endpoint.QueryParams...)
+	for _, param := range params {
+		properties[param.Name] = paramSchema(param)
+		if param.Required {
+			required = append(required, param.Name)
+		}
+	}
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// paramSchema schema de um ParameterConfig
+func paramSchema(param types.ParameterConfig) Schema {
+	schema := Schema{}
+	switch param.Type {
+	case "integer", "number", "boolean", "string":
+		schema["type"] = param.Type
+	default:
+		schema["type"] = "string"
+	}
+	if param.Format != "" {
+		schema["format"] = param.Format
+	}
+	if param.Pattern != "" {
+		schema["pattern"] = param.Pattern
+	}
+	if param.MinLength > 0 {
+		schema["minLength"] = param.MinLength
+	}
+	if param.MaxLength > 0 {
+		schema["maxLength"] = param.MaxLength
+	}
+	if param.Default != nil {
+		schema["default"] = param.Default
+	}
+	return schema
+}
+
+// responseSchema campos da saída mapeada (tipos inferidos do schema upstream quando declarado)
+func responseSchema(response types.ResponseConfig) Schema {
+	root := Schema{"type": "object", "properties": Schema{}}
+	transformed := transformedFields(response.Transforms)
+
+	for field, path := range response.Mapping {
+		schema := Schema{}
+		if !transformed[field] {
+			schema = resolvePath(response.Schema, path)
+		}
+		setField(root, field, schema)
+	}
+
+	for _, array := range response.Arrays {
+		element := resolvePath(response.Schema, array.Path)
+		arrayTransformed := transformedFields(array.Transforms)
+
+		item := Schema{"type": "object", "properties": Schema{}}
+		for field, path := range array.Mapping {
+			schema := Schema{}
+			if !arrayTransformed[field] {
+				schema = resolvePath(element, path)
+			}
+			setField(item, field, schema)
+		}
+		for _, expression := range array.Expressions {
+			setField(item, expression.Field, Schema{"description": "Calculado: " + expression.Expression})
+		}
+		if required := toInterfaces(array.Required); len(required) > 0 {
+			item["required"] = required
+		}
+		setField(root, array.Field, Schema{"type": "array", "items": item})
+	}
+
+	for _, expression := range response.Expressions {
+		setField(root, expression.Field, Schema{"description": "Calculado: " + expression.Expression})
+	}
+
+	if required := toInterfaces(response.RequiredFields); len(required) > 0 {
+		root["required"] = required
+	}
+	return root
+}
+
+// setField define um campo mapeado (aceita caminho aninhado a.b)
+func setField(object Schema, field string, schema Schema) {
+	parts := strings.Split(field, ".")
+	for _, part := range parts[:len(parts)-1] {
+		properties := object["properties"].(Schema)
+		child, ok := properties[part].(Schema)
+		if !ok || child["properties"] == nil {
+			child = Schema{"type": "object", "properties": Schema{}}
+			properties[part] = child
+		}
+		object = child
+	}
+	object["properties"].(Schema)[parts[len(parts)-1]] = schema
+}
+
+// segmentRegex segmento de JSONPath simples: nome com [*] ou [n] opcionais
+var segmentRegex = regexp.MustCompile(`^([^\[\]]*)((?:\[[^\]]*\])*)$`)
+
+// resolvePath tipo de um JSONPath simples ($.a.b, $.a[*].b) no JSON Schema upstream
+// Caminhos não resolvidos viram schema vazio (qualquer valor)
+func resolvePath(schema Schema, path string) Schema {
+	if schema == nil {
+		return Schema{}
+	}
+
+	current := schema
+	for _, segment := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(path, "$"), "."), ".") {
+		if segment == "" {
+			continue
+		}
+		match := segmentRegex.FindStringSubmatch(segment)
+		if match == nil {
+			return Schema{}
+		}
+
+		if match[1] != "" {
+			properties, _ := current["properties"].(Schema)
+			next, ok := properties[match[1]].(Schema)
+			if !ok {
+				return Schema{}
+			}
+			current = next
+		}
+		for i := 0; i < strings.Count(match[2], "["); i++ {
+			items, ok := current["items"].(Schema)
+			if !ok {
+				return Schema{}
+			}
+			current = items
+		}
+	}
+
+	return copySchema(current)
+}
+
+// copySchema cópia rasa (o documento não compartilha mapas com a config do connector)
+func copySchema(schema Schema) Schema {
+	result := make(Schema, len(schema))
+	for key, value := range schema {
+		result[key] = value
+	}
+	return result
+}
+
+// transformedFields campos alterados por transforms (tipo de saída desconhecido)
+func transformedFields(transforms []types.TransformConfig) map[string]bool {
+	fields := make(map[string]bool, len(transforms))
+	for _, transform := range transforms {
+		fields[transform.Field] = true
+	}
+	return fields
+}
+
+// operationID id da operação para geradores de client (ex: receita_federal_cnpj_consulta_cnpj)
+func operationID(connectorID, endpoint string) string {
+	return strings.ReplaceAll(connectorID, "-", "_") + "_" + endpoint
+}
+
+func sortedKeys(endpoints map[string]types.EndpointConfig) []string {
+	keys := make([]string, 0, len(endpoints))
+	for key := range endpoints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		result = append(result, value)
+	}
+	return result
+}
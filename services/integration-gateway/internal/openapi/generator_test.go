@@ -0,0 +1,158 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConnector connector no formato do receita (params no path, resposta com schema, array e transform)
+func testConnector() *types.ConnectorConfig {
+	return &types.ConnectorConfig{
+		ID:       "receita-federal",
+		Name:     "Receita Federal",
+		Provider: "RFB",
+		Version:  "1.0.0",
+		Integration: types.IntegrationConfig{
+			Endpoints: map[string]types.EndpointConfig{
+				"consulta_cnpj": {
+					Method: "GET",
+					Path:   "/cnpj/{cnpj}",
+					PathParams: []types.ParameterConfig{
+						{Name: "cnpj", Type: "string", Required: true, Format: "digits_only", Pattern: `^\d{14}$`, MinLength: 14, MaxLength: 14},
+					},
+					QueryParams: []types.ParameterConfig{
+						{Name: "incluir_socios", Type: "boolean", Default: false},
+					},
+					Response: types.ResponseConfig{
+						Mapping: map[string]string{
+							"cnpj":           "$.cnpj",
+							"razao_social":   "$.razao_social",
+							"endereco.uf":    "$.endereco.uf",
+							"capital":        "$.capital_social",
+							"cnpj_formatado": "$.cnpj",
+						},
+						Transforms: []types.TransformConfig{{Field: "cnpj_formatado", Operation: "format_cnpj"}},
+						Arrays: []types.ArrayMappingConfig{{
+							Field:    "socios",
+							Path:     "$.qsa[*]",
+							Mapping:  map[string]string{"nome": "$.nome", "idade": "$.idade"},
+							Required: []string{"nome"},
+						}},
+						Expressions:    []types.ExpressionConfig{{Field: "capital_mil", Expression: "capital / 1000"}},
+						RequiredFields: []string{"cnpj", "razao_social"},
+						Schema: map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"cnpj":           map[string]interface{}{"type": "string"},
+								"razao_social":   map[string]interface{}{"type": "string"},
+								"capital_social": map[string]interface{}{"type": "number"},
+								"endereco": map[string]interface{}{
+									"type":       "object",
+									"properties": map[string]interface{}{"uf": map[string]interface{}{"type": "string"}},
+								},
+								"qsa": map[string]interface{}{
+									"type": "array",
+									"items": map[string]interface{}{
+										"type":       "object",
+										"properties": map[string]interface{}{"nome": map[string]interface{}{"type": "string"}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSchema schema de data na resposta 200 da operação
+func dataSchema(t *testing.T, op Schema) Schema {
+	t.Helper()
+	response := op["responses"].(Schema)["200"].(Schema)
+	schema := response["content"].(Schema)["application/json"].(Schema)["schema"].(Schema)
+	return schema["properties"].(Schema)["data"].(Schema)
+}
+
+func TestGenerate(t *testing.T) {
+	doc := Generate(Info{Title: "BGC Integration Gateway", Version: "1.0.0"}, []*types.ConnectorConfig{testConnector()}, nil)
+
+	assert.Equal(t, "3.1.0", doc["openapi"])
+	paths := doc["paths"].(Schema)
+	require.Contains(t, paths, "/v1/connectors/receita-federal/consulta_cnpj")
+
+	op := paths["/v1/connectors/receita-federal/consulta_cnpj"].(Schema)["post"].(Schema)
+	assert.Equal(t, "receita_federal_consulta_cnpj", op["operationId"])
+	assert.Equal(t, []interface{}{"receita-federal"}, op["tags"])
+	parameters := op["parameters"].([]interface{})
+	require.Len(t, parameters, 2, "header de versão só com mais de uma versão")
+	assert.Equal(t, "dry_run", parameters[0].(Schema)["name"])
+	assert.Equal(t, "explain", parameters[1].(Schema)["name"])
+
+	// Request: params de path e query
+	request := op["requestBody"].(Schema)["content"].(Schema)["application/json"].(Schema)["schema"].(Schema)
+	assert.Equal(t, []interface{}{"cnpj"}, request["required"])
+	properties := request["properties"].(Schema)
+	assert.Equal(t, Schema{"type": "string", "format": "digits_only", "pattern": `^\d{14}$`, "minLength": 14, "maxLength": 14}, properties["cnpj"])
+	assert.Equal(t, Schema{"type": "boolean", "default": false}, properties["incluir_socios"])
+
+	// Response: campos mapeados com tipos do schema upstream
+	data := dataSchema(t, op)
+	assert.Equal(t, []interface{}{"cnpj", "razao_social"}, data["required"])
+	fields := data["properties"].(Schema)
+	assert.Equal(t, Schema{"type": "string"}, fields["cnpj"])
+	assert.Equal(t, Schema{"type": "number"}, fields["capital"])
+	assert.Equal(t, Schema{}, fields["cnpj_formatado"], "campo com transform não herda o tipo upstream")
+	assert.Equal(t, Schema{"type": "string"}, fields["endereco"].(Schema)["properties"].(Schema)["uf"])
+	assert.Equal(t, Schema{"description": "Calculado: capital / 1000"}, fields["capital_mil"])
+
+	socios := fields["socios"].(Schema)
+	assert.Equal(t, "array", socios["type"])
+	item := socios["items"].(Schema)
+	assert.Equal(t, []interface{}{"nome"}, item["required"])
+	assert.Equal(t, Schema{"type": "string"}, item["properties"].(Schema)["nome"])
+	assert.Equal(t, Schema{}, item["properties"].(Schema)["idade"], "campo ausente do schema upstream aceita qualquer valor")
+
+	_, err := json.Marshal(doc)
+	assert.NoError(t, err)
+}
+
+func TestGenerate_Versions(t *testing.T) {
+	doc := Generate(Info{}, []*types.ConnectorConfig{testConnector()}, map[string][]string{"receita-federal": {"2.0.0", "1.0.0"}})
+
+	op := doc["paths"].(Schema)["/v1/connectors/receita-federal/consulta_cnpj"].(Schema)["post"].(Schema)
+	parameters := op["parameters"].([]interface{})
+	require.Len(t, parameters, 3)
+	header := parameters[2].(Schema)
+	assert.Equal(t, types.VersionHeader, header["name"])
+	assert.Equal(t, "header", header["in"])
+	assert.Equal(t, []interface{}{"2.0.0", "1.0.0"}, header["schema"].(Schema)["enum"])
+}
+
+func TestResolvePath(t *testing.T) {
+	schema := testConnector().Integration.Endpoints["consulta_cnpj"].Response.Schema
+
+	tests := []struct {
+		path     string
+		expected Schema
+	}{
+		{"$.cnpj", Schema{"type": "string"}},
+		{"$.endereco.uf", Schema{"type": "string"}},
+		{"$.qsa[*].nome", Schema{"type": "string"}},
+		{"$.qsa[0].nome", Schema{"type": "string"}},
+		{"$.inexistente", Schema{}},
+		{"$.cnpj[*]", Schema{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolvePath(schema, tt.path))
+		})
+	}
+
+	assert.Equal(t, Schema{}, resolvePath(nil, "$.cnpj"))
+}
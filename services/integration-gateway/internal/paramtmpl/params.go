@@ -0,0 +1,64 @@
+// Package paramtmpl templates de parâmetros com funções de data, compartilhados
+// pelos params dos jobs agendados e pelo when das regras de TTL do cache.
+package paramtmpl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// funcs funções de data disponíveis nos templates de parâmetros
+var funcs = template.FuncMap{
+	"addDays":   func(days int, t time.Time) time.Time { return t.AddDate(0, 0, days) },
+	"addMonths": func(months int, t time.Time) time.Time { return firstOfMonth(t).AddDate(0, months, 0) },
+	"year":      func(t time.Time) int { return t.Year() },
+	"month":     func(t time.Time) int { return int(t.Month()) },
+	"day":       func(t time.Time) int { return t.Day() },
+	"format":    func(layout string, t time.Time) string { return t.Format(layout) },
+}
+
+// Data dados disponíveis nos templates (.Now = horário agendado no timezone do job)
+type Data struct {
+	Now time.Time
+}
+
+// Parse compila os templates de parâmetros (nome -> template)
+func Parse(params map[string]string) (map[string]*template.Template, error) {
+	compiled := make(map[string]*template.Template, len(params))
+	for name, text := range params {
+		tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("param %s: %w", name, err)
+		}
+		compiled[name] = tmpl
+	}
+	return compiled, nil
+}
+
+// Render renderiza os parâmetros para o horário agendado
+// Valores inteiros viram int (ex: ano, mes); os demais ficam como string
+func Render(params map[string]*template.Template, now time.Time) (map[string]interface{}, error) {
+	rendered := make(map[string]interface{}, len(params))
+	for name, tmpl := range params {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, Data{Now: now}); err != nil {
+			return nil, fmt.Errorf("param %s: %w", name, err)
+		}
+
+		value := sb.String()
+		if n, err := strconv.Atoi(value); err == nil {
+			rendered[name] = n
+		} else {
+			rendered[name] = value
+		}
+	}
+	return rendered, nil
+}
+
+// firstOfMonth primeiro dia do mês (evita 31/03 - 1 mês = 03/03)
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
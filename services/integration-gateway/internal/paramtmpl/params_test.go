@@ -0,0 +1,77 @@
+package paramtmpl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		params map[string]string
+		now    time.Time
+		want   map[string]interface{}
+	}{
+		{
+			name:   "Previous month",
+			params: map[string]string{"ano": "{{ year (addMonths -1 .Now) }}", "mes": "{{ month (addMonths -1 .Now) }}"},
+			now:    time.Date(2025, 3, 5, 6, 0, 0, 0, saoPaulo),
+			want:   map[string]interface{}{"ano": 2025, "mes": 2},
+		},
+		{
+			name:   "Previous month crosses year",
+			params: map[string]string{"ano": "{{ year (addMonths -1 .Now) }}", "mes": "{{ month (addMonths -1 .Now) }}"},
+			now:    time.Date(2025, 1, 5, 6, 0, 0, 0, saoPaulo),
+			want:   map[string]interface{}{"ano": 2024, "mes": 12},
+		},
+		{
+			name:   "End of month does not skip February",
+			params: map[string]string{"mes": "{{ month (addMonths -1 .Now) }}"},
+			now:    time.Date(2025, 3, 31, 6, 0, 0, 0, time.UTC),
+			want:   map[string]interface{}{"mes": 2},
+		},
+		{
+			name:   "Formatted date stays string",
+			params: map[string]string{"data": `{{ format "2006-01-02" (addDays -1 .Now) }}`},
+			now:    time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			want:   map[string]interface{}{"data": "2025-02-28"},
+		},
+		{
+			name:   "Literal value",
+			params: map[string]string{"pais": "CN"},
+			now:    time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			want:   map[string]interface{}{"pais": "CN"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpls, err := Parse(tt.params)
+			require.NoError(t, err)
+
+			got, err := Render(tmpls, tt.now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParse_InvalidTemplate(t *testing.T) {
+	_, err := Parse(map[string]string{"ano": "{{ year .Now "})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "param ano")
+}
+
+func TestRender_UnknownField(t *testing.T) {
+	tmpls, err := Parse(map[string]string{"ano": "{{ .Yesterday }}"})
+	require.NoError(t, err)
+
+	_, err = Render(tmpls, time.Now())
+	assert.Error(t, err)
+}
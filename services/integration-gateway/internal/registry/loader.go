@@ -5,8 +5,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/bgc/integration-gateway/internal/cache"
+	"github.com/bgc/integration-gateway/internal/scheduler"
+	"github.com/bgc/integration-gateway/internal/transform"
 	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/ohler55/ojg/jp"
 	"gopkg.in/yaml.v3"
 )
 
@@ -92,6 +97,15 @@ func (l *Loader) validateConfig(config *types.ConnectorConfig) error {
 		return fmt.Errorf("invalid ID format: must be lowercase with hyphens only")
 	}
 
+	// Versão entra em referências id@versão e no header X-Connector-Version
+	if strings.ContainsAny(config.Version, "@/ ") || strings.HasPrefix(config.Version, "v") {
+		return fmt.Errorf("invalid version format: %s (use semver without v prefix, e.g. 2.0.0)", config.Version)
+	}
+
+	if config.Rollout != nil && (config.Rollout.Weight < 0 || config.Rollout.Weight > 100) {
+		return fmt.Errorf("rollout weight must be between 0 and 100")
+	}
+
 	// Valida tipo de integração
 	validTypes := map[string]bool{
 		"rest_api": true,
@@ -128,6 +142,73 @@ func (l *Loader) validateConfig(config *types.ConnectorConfig) error {
 		}
 	}
 
+	// Valida plugins WASM
+	for _, plugin := range config.Integration.WASMPlugins {
+		if err := validateWASMPlugin(&plugin); err != nil {
+			return err
+		}
+	}
+
+	// Valida cache (ttl, key_pattern e ttl_rules)
+	if _, err := cache.NewPolicy(config, cache.DefaultTTL); err != nil {
+		return fmt.Errorf("invalid cache config: %w", err)
+	}
+
+	// Valida jobs agendados
+	if err := validateJobs(config); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateJobs valida jobs agendados (schedule, params, endpoint e tabela de destino)
+func validateJobs(config *types.ConnectorConfig) error {
+	seen := make(map[string]bool, len(config.Jobs))
+	for _, job := range config.Jobs {
+		if _, err := scheduler.NewJob(config.ID, job); err != nil {
+			return fmt.Errorf("job %s: %w", job.Name, err)
+		}
+
+		if seen[job.Name] {
+			return fmt.Errorf("duplicate job %s", job.Name)
+		}
+		seen[job.Name] = true
+
+		if _, exists := config.Integration.Endpoints[job.Endpoint]; !exists {
+			return fmt.Errorf("job %s: endpoint %s not found", job.Name, job.Endpoint)
+		}
+
+		if job.Environment != "" {
+			if _, exists := config.Environments[job.Environment]; !exists {
+				return fmt.Errorf("job %s: environment %s not found", job.Name, job.Environment)
+			}
+		}
+	}
+	return nil
+}
+
+// validateWASMPlugin valida declaração de plugin WASM (o módulo é carregado na inicialização)
+func validateWASMPlugin(plugin *types.WASMPluginConfig) error {
+	if plugin.Name == "" {
+		return fmt.Errorf("WASM plugin name is required")
+	}
+
+	if plugin.Module == "" || !strings.HasSuffix(plugin.Module, ".wasm") {
+		return fmt.Errorf("WASM plugin %s must reference a .wasm module", plugin.Name)
+	}
+
+	// wasm32 endereça no máximo 4GiB
+	if plugin.MaxMemoryMB < 0 || plugin.MaxMemoryMB > 4096 {
+		return fmt.Errorf("invalid max_memory_mb for WASM plugin %s: %d", plugin.Name, plugin.MaxMemoryMB)
+	}
+
+	if plugin.Timeout != "" {
+		if _, err := time.ParseDuration(plugin.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout for WASM plugin %s: %w", plugin.Name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -159,6 +240,133 @@ func (l *Loader) validateEndpoint(name string, endpoint *types.EndpointConfig) e
 		return fmt.Errorf("at least one success status is required for endpoint %s", name)
 	}
 
+	if err := l.validateResponse(name, &endpoint.Response); err != nil {
+		return err
+	}
+
+	// Valida exemplos (contract tests)
+	examples := make(map[string]bool)
+	for _, example := range endpoint.Examples {
+		if example.Name == "" {
+			return fmt.Errorf("example name is required for endpoint %s", name)
+		}
+		if examples[example.Name] {
+			return fmt.Errorf("duplicate example %s for endpoint %s", example.Name, name)
+		}
+		examples[example.Name] = true
+	}
+
+	return nil
+}
+
+// validateResponse valida mapeamentos da resposta (JSONPath, campos e arrays)
+func (l *Loader) validateResponse(name string, response *types.ResponseConfig) error {
+	if err := validateMapping(response.Mapping); err != nil {
+		return fmt.Errorf("invalid mapping for endpoint %s: %w", name, err)
+	}
+
+	if err := validateExpressions(response.Expressions); err != nil {
+		return fmt.Errorf("invalid expressions for endpoint %s: %w", name, err)
+	}
+
+	if response.Schema != nil {
+		if _, err := transform.CompileSchema(response.Schema); err != nil {
+			return fmt.Errorf("endpoint %s: %w", name, err)
+		}
+	}
+
+	for _, field := range response.RequiredFields {
+		if !transform.ValidFieldPath(field) {
+			return fmt.Errorf("invalid required field %q for endpoint %s", field, name)
+		}
+	}
+
+	switch response.Validation {
+	case "", transform.ValidationWarn, transform.ValidationStrict:
+	default:
+		return fmt.Errorf("invalid validation mode for endpoint %s: %s", name, response.Validation)
+	}
+
+	fields := make(map[string]bool)
+	for _, array := range response.Arrays {
+		if !transform.ValidFieldPath(array.Field) {
+			return fmt.Errorf("invalid array field %q for endpoint %s", array.Field, name)
+		}
+		if fields[array.Field] {
+			return fmt.Errorf("duplicate array field %s for endpoint %s", array.Field, name)
+		}
+		fields[array.Field] = true
+
+		if array.Path == "" {
+			return fmt.Errorf("path is required for array %s in endpoint %s", array.Field, name)
+		}
+		if _, err := jp.ParseString(array.Path); err != nil {
+			return fmt.Errorf("invalid path for array %s in endpoint %s: %w", array.Field, name, err)
+		}
+
+		if len(array.Mapping) == 0 {
+			return fmt.Errorf("mapping is required for array %s in endpoint %s", array.Field, name)
+		}
+		if err := validateMapping(array.Mapping); err != nil {
+			return fmt.Errorf("invalid mapping for array %s in endpoint %s: %w", array.Field, name, err)
+		}
+
+		if err := validateExpressions(array.Expressions); err != nil {
+			return fmt.Errorf("invalid expressions for array %s in endpoint %s: %w", array.Field, name, err)
+		}
+
+		for _, required := range array.Required {
+			if _, exists := array.Mapping[required]; !exists {
+				return fmt.Errorf("required field %s is not mapped in array %s of endpoint %s", required, array.Field, name)
+			}
+		}
+
+		switch array.OnMissing {
+		case "", transform.OnMissingFail, transform.OnMissingSkip:
+		default:
+			return fmt.Errorf("invalid on_missing for array %s in endpoint %s: %s", array.Field, name, array.OnMissing)
+		}
+	}
+
+	return nil
+}
+
+// validateMapping valida nomes de campo e expressões JSONPath
+func validateMapping(mapping map[string]string) error {
+	fields := transform.SortedFields(mapping)
+	for _, field := range fields {
+		if !transform.ValidFieldPath(field) {
+			return fmt.Errorf("invalid field name %q", field)
+		}
+		if _, err := jp.ParseString(mapping[field]); err != nil {
+			return fmt.Errorf("invalid JSONPath for field %s: %w", field, err)
+		}
+	}
+
+	// x e x.y no mesmo mapping: x seria ao mesmo tempo valor e objeto
+	for _, field := range fields {
+		for _, other := range fields {
+			if strings.HasPrefix(other, field+".") {
+				return fmt.Errorf("field %s conflicts with nested field %s", field, other)
+			}
+		}
+	}
+	return nil
+}
+
+// validateExpressions valida nomes de campo e compila as expressões
+func validateExpressions(expressions []types.ExpressionConfig) error {
+	for _, expression := range expressions {
+		if !transform.ValidFieldPath(expression.Field) {
+			return fmt.Errorf("invalid field name %q", expression.Field)
+		}
+		if expression.Expression == "" {
+			return fmt.Errorf("expression is required for field %s", expression.Field)
+		}
+		if _, err := transform.CompileExpression(expression.Expression); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -61,6 +61,49 @@ environments:
 	assert.Len(t, config.Integration.Endpoints, 1)
 }
 
+func TestLoader_LoadConnector_ResponseContract(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := `
+id: contract-connector
+name: Contract Connector
+version: 1.0.0
+
+integration:
+  type: rest_api
+  auth:
+    type: none
+  endpoints:
+    listar:
+      method: GET
+      path: /items
+      response:
+        success_status: [200]
+        validation: strict
+        required_fields: [total]
+        schema:
+          type: object
+          required: [total, items]
+          properties:
+            total:
+              type: integer
+              minimum: 0
+        mapping:
+          total: $.total
+`
+
+	err := os.WriteFile(filepath.Join(tmpDir, "contract-connector.yaml"), []byte(config), 0644)
+	require.NoError(t, err)
+
+	loaded, err := NewLoader(tmpDir).LoadConnector("contract-connector")
+
+	require.NoError(t, err)
+	response := loaded.Integration.Endpoints["listar"].Response
+	assert.Equal(t, "strict", response.Validation)
+	assert.Equal(t, []string{"total"}, response.RequiredFields)
+	assert.Equal(t, "object", response.Schema["type"])
+}
+
 func TestLoader_LoadConnector_NotFound(t *testing.T) {
 	tmpDir := t.TempDir()
 	loader := NewLoader(tmpDir)
@@ -157,6 +200,197 @@ func TestLoader_ValidateConfig(t *testing.T) {
 	}
 }
 
+func TestLoader_ValidateConfig_Cache(t *testing.T) {
+	loader := NewLoader(".")
+
+	tests := []struct {
+		name        string
+		cache       types.CacheConfig
+		errContains string
+	}{
+		{
+			name: "Valid key pattern and ttl rules",
+			cache: types.CacheConfig{
+				Enabled:    true,
+				TTL:        "7d",
+				KeyPattern: "test:{ano}",
+				TTLRules:   []types.CacheTTLRuleConfig{{When: map[string]string{"ano": "{{ year .Now }}"}, TTL: "6h"}},
+			},
+		},
+		{
+			name:        "Unknown placeholder",
+			cache:       types.CacheConfig{Enabled: true, KeyPattern: "test:{ano}:{ncm}"},
+			errContains: "unknown placeholder {ncm}",
+		},
+		{
+			name:        "Invalid rule ttl",
+			cache:       types.CacheConfig{Enabled: true, TTLRules: []types.CacheTTLRuleConfig{{Name: "atual", When: map[string]string{"ano": "2024"}, TTL: "soon"}}},
+			errContains: "ttl rule atual: invalid ttl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &types.ConnectorConfig{
+				ID:      "test-connector",
+				Name:    "Test",
+				Version: "1.0.0",
+				Integration: types.IntegrationConfig{
+					Type: "rest_api",
+					Auth: types.AuthConfig{Type: "none"},
+					Endpoints: map[string]types.EndpointConfig{
+						"test": {
+							Method:     "GET",
+							Path:       "/test/{ano}",
+							PathParams: []types.ParameterConfig{{Name: "ano", Type: "integer"}},
+							Response:   types.ResponseConfig{SuccessStatus: []int{200}},
+						},
+					},
+					Cache: tt.cache,
+				},
+			}
+
+			err := loader.validateConfig(config)
+
+			if tt.errContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid cache config")
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateWASMPlugin(t *testing.T) {
+	tests := []struct {
+		name        string
+		plugin      types.WASMPluginConfig
+		errContains string
+	}{
+		{
+			name:   "Valid plugin",
+			plugin: types.WASMPluginConfig{Name: "parse_partner", Module: "plugins/partner.wasm", MaxMemoryMB: 16, Timeout: "100ms"},
+		},
+		{
+			name:        "Missing name",
+			plugin:      types.WASMPluginConfig{Module: "plugins/partner.wasm"},
+			errContains: "name is required",
+		},
+		{
+			name:        "Not a wasm module",
+			plugin:      types.WASMPluginConfig{Name: "parse_partner", Module: "plugins/partner.so"},
+			errContains: "must reference a .wasm module",
+		},
+		{
+			name:        "Memory above wasm32 limit",
+			plugin:      types.WASMPluginConfig{Name: "parse_partner", Module: "partner.wasm", MaxMemoryMB: 8192},
+			errContains: "invalid max_memory_mb",
+		},
+		{
+			name:        "Invalid timeout",
+			plugin:      types.WASMPluginConfig{Name: "parse_partner", Module: "partner.wasm", Timeout: "fast"},
+			errContains: "invalid timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWASMPlugin(&tt.plugin)
+
+			if tt.errContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateJobs(t *testing.T) {
+	validJob := func() types.JobConfig {
+		return types.JobConfig{
+			Name:     "exportacao_mensal",
+			Endpoint: "test",
+			Schedule: "0 6 5 * *",
+			Timezone: "America/Sao_Paulo",
+			Params:   map[string]string{"ano": "{{ year .Now }}"},
+			Target: types.JobTargetConfig{
+				Table:        "stg.exportacao",
+				Rows:         "rows",
+				Columns:      []string{"co_ano", "vl_fob"},
+				ConflictKeys: []string{"co_ano"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(jobs []types.JobConfig) []types.JobConfig
+		errContains string
+	}{
+		{
+			name:   "Valid job",
+			modify: func(jobs []types.JobConfig) []types.JobConfig { return jobs },
+		},
+		{
+			name: "Unknown endpoint",
+			modify: func(jobs []types.JobConfig) []types.JobConfig {
+				jobs[0].Endpoint = "missing"
+				return jobs
+			},
+			errContains: "endpoint missing not found",
+		},
+		{
+			name: "Unknown environment",
+			modify: func(jobs []types.JobConfig) []types.JobConfig {
+				jobs[0].Environment = "staging"
+				return jobs
+			},
+			errContains: "environment staging not found",
+		},
+		{
+			name: "Invalid schedule",
+			modify: func(jobs []types.JobConfig) []types.JobConfig {
+				jobs[0].Schedule = "every day"
+				return jobs
+			},
+			errContains: "invalid schedule",
+		},
+		{
+			name: "Duplicate job",
+			modify: func(jobs []types.JobConfig) []types.JobConfig {
+				return append(jobs, jobs[0])
+			},
+			errContains: "duplicate job exportacao_mensal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &types.ConnectorConfig{
+				ID: "test-connector",
+				Integration: types.IntegrationConfig{
+					Endpoints: map[string]types.EndpointConfig{"test": {Method: "GET", Path: "/test"}},
+				},
+				Environments: map[string]types.Environment{"production": {}},
+				Jobs:         tt.modify([]types.JobConfig{validJob()}),
+			}
+
+			err := validateJobs(config)
+
+			if tt.errContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestLoader_ValidateEndpoint(t *testing.T) {
 	loader := NewLoader(".")
 
@@ -216,6 +450,205 @@ func TestLoader_ValidateEndpoint(t *testing.T) {
 			wantErr:     true,
 			errContains: "at least one success status is required",
 		},
+		{
+			name: "Valid array mapping",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+					Arrays: []types.ArrayMappingConfig{
+						{
+							Field:     "rows",
+							Path:      "$.data[*]",
+							Mapping:   map[string]string{"co_ano": "$.coAno", "country.code": "$.coPais"},
+							Required:  []string{"co_ano"},
+							OnMissing: "skip",
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Array without path",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+					Arrays: []types.ArrayMappingConfig{
+						{Field: "rows", Mapping: map[string]string{"id": "$.id"}},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "path is required for array rows",
+		},
+		{
+			name: "Array required field not mapped",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+					Arrays: []types.ArrayMappingConfig{
+						{
+							Field:    "rows",
+							Path:     "$.data[*]",
+							Mapping:  map[string]string{"id": "$.id"},
+							Required: []string{"nome"},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "required field nome is not mapped",
+		},
+		{
+			name: "Array invalid on_missing",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+					Arrays: []types.ArrayMappingConfig{
+						{
+							Field:     "rows",
+							Path:      "$.data[*]",
+							Mapping:   map[string]string{"id": "$.id"},
+							OnMissing: "ignore",
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "invalid on_missing",
+		},
+		{
+			name: "Invalid nested field name",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+					Mapping:       map[string]string{"country..code": "$.pais"},
+				},
+			},
+			wantErr:     true,
+			errContains: "invalid field name",
+		},
+		{
+			name: "Mapping field conflicts with nested field",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+					Mapping:       map[string]string{"country": "$.pais", "country.code": "$.codigo", "countryName": "$.nome"},
+				},
+			},
+			wantErr:     true,
+			errContains: "field country conflicts with nested field country.code",
+		},
+		{
+			name: "Array mapping field conflicts with nested field",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+					Arrays: []types.ArrayMappingConfig{
+						{
+							Field:   "rows",
+							Path:    "$.data[*]",
+							Mapping: map[string]string{"uf": "$.uf", "uf.sigla": "$.sigla"},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "field uf conflicts with nested field uf.sigla",
+		},
+		{
+			name: "Invalid expression",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+					Expressions: []types.ExpressionConfig{
+						{Field: "price_per_kg", Expression: "vl_fob / "},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "invalid expressions for endpoint",
+		},
+		{
+			name: "Invalid array expression",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+					Arrays: []types.ArrayMappingConfig{
+						{
+							Field:   "rows",
+							Path:    "$.data[*]",
+							Mapping: map[string]string{"vl_fob": "$.vlFob"},
+							Expressions: []types.ExpressionConfig{
+								{Field: "price_per_kg", Expression: ""},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "expression is required",
+		},
+		{
+			name: "Invalid response schema",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+					Schema:        map[string]interface{}{"type": 42},
+				},
+			},
+			wantErr:     true,
+			errContains: "invalid response schema",
+		},
+		{
+			name: "Invalid validation mode",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+					Validation:    "lenient",
+				},
+			},
+			wantErr:     true,
+			errContains: "invalid validation mode",
+		},
+		{
+			name: "Duplicate example",
+			endpoint: types.EndpointConfig{
+				Method: "GET",
+				Path:   "/api/test",
+				Response: types.ResponseConfig{
+					SuccessStatus: []int{200},
+				},
+				Examples: []types.ExampleConfig{
+					{Name: "basico"},
+					{Name: "basico"},
+				},
+			},
+			wantErr:     true,
+			errContains: "duplicate example basico",
+		},
 	}
 
 	for _, tt := range tests {
@@ -2,23 +2,50 @@ package registry
 
 import (
 	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/bgc/integration-gateway/internal/types"
 )
 
 // Registry gerencia os conectores carregados
+// Um connector pode ter várias versões lado a lado (uma por arquivo YAML, ex: comexstat@2.0.0.yaml)
 type Registry struct {
 	mu         sync.RWMutex
-	connectors map[string]*types.ConnectorConfig
+	connectors map[string]*versionSet
 	loader     *Loader
+	pick       func(n int) int // sorteio do roteamento canary (0 <= pick(n) < n)
+}
+
+// versionSet versões carregadas de um connector
+type versionSet struct {
+	versions map[string]*types.ConnectorConfig
+	stable   string
+	weights  []versionWeight // roteamento do tráfego sem versão fixada
+}
+
+// versionWeight peso de uma versão no roteamento (%)
+type versionWeight struct {
+	version string
+	weight  int
+}
+
+// VersionInfo versão de um connector e seu peso no roteamento
+type VersionInfo struct {
+	Version string `json:"version"`
+	Weight  int    `json:"weight"`
+	Stable  bool   `json:"stable"`
 }
 
 // NewRegistry cria um novo registry
 func NewRegistry(configDir string) *Registry {
 	return &Registry{
-		connectors: make(map[string]*types.ConnectorConfig),
+		connectors: make(map[string]*versionSet),
 		loader:     NewLoader(configDir),
+		pick:       rand.IntN,
 	}
 }
 
@@ -29,53 +56,212 @@ func (r *Registry) LoadAll() error {
 		return fmt.Errorf("failed to load connectors: %w", err)
 	}
 
+	connectors := make(map[string]*versionSet)
+	for _, config := range configs {
+		set, exists := connectors[config.ID]
+		if !exists {
+			set = &versionSet{versions: make(map[string]*types.ConnectorConfig)}
+			connectors[config.ID] = set
+		}
+		if _, duplicate := set.versions[config.Version]; duplicate {
+			return fmt.Errorf("failed to load connectors: duplicate version %s@%s", config.ID, config.Version)
+		}
+		set.versions[config.Version] = config
+	}
+
+	for id, set := range connectors {
+		if err := set.route(); err != nil {
+			return fmt.Errorf("failed to load connectors: connector %s: %w", id, err)
+		}
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	for _, config := range configs {
-		r.connectors[config.ID] = config
+	r.connectors = connectors
+
+	return nil
+}
+
+// route define a versão estável e os pesos do roteamento
+// Estável = maior versão sem rollout; recebe o tráfego que as versões canary não ocupam
+func (s *versionSet) route() error {
+	s.stable = ""
+	s.weights = nil
+
+	canaryWeight := 0
+	for _, version := range s.sortedVersions() {
+		config := s.versions[version]
+		if config.Rollout == nil {
+			if s.stable == "" {
+				s.stable = version
+			}
+			continue
+		}
+		canaryWeight += config.Rollout.Weight
+		if config.Rollout.Weight > 0 {
+			s.weights = append(s.weights, versionWeight{version: version, weight: config.Rollout.Weight})
+		}
 	}
 
+	if s.stable == "" {
+		return fmt.Errorf("no stable version (every version declares rollout)")
+	}
+	if canaryWeight > 100 {
+		return fmt.Errorf("rollout weights sum to %d%%, maximum is 100%%", canaryWeight)
+	}
+	if stableWeight := 100 - canaryWeight; stableWeight > 0 {
+		s.weights = append(s.weights, versionWeight{version: s.stable, weight: stableWeight})
+	}
 	return nil
 }
 
-// Get obtém um connector pelo ID
-func (r *Registry) Get(id string) (*types.ConnectorConfig, error) {
+// sortedVersions versões da maior para a menor
+func (s *versionSet) sortedVersions() []string {
+	versions := make([]string, 0, len(s.versions))
+	for version := range s.versions {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) > 0
+	})
+	return versions
+}
+
+// Get obtém um connector pelo ID (versão estável) ou por id@versão
+func (r *Registry) Get(ref string) (*types.ConnectorConfig, error) {
+	id, version := types.SplitConnectorRef(ref)
+	return r.GetVersion(id, version)
+}
+
+// GetVersion obtém uma versão específica do connector (vazio = estável)
+func (r *Registry) GetVersion(id, version string) (*types.ConnectorConfig, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	config, exists := r.connectors[id]
+	set, exists := r.connectors[id]
 	if !exists {
 		return nil, fmt.Errorf("connector not found: %s", id)
 	}
 
+	if version == "" {
+		version = set.stable
+	}
+	config, exists := set.versions[version]
+	if !exists {
+		return nil, fmt.Errorf("connector version not found: %s@%s", id, version)
+	}
+
 	return config, nil
 }
 
-// List retorna todos os conectores registrados
+// Resolve versão que atende a chamada: a fixada pelo caller ou sorteada pelos pesos de rollout
+func (r *Registry) Resolve(id, version string) (*types.ConnectorConfig, error) {
+	if version != "" {
+		return r.GetVersion(id, version)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	set, exists := r.connectors[id]
+	if !exists {
+		return nil, fmt.Errorf("connector not found: %s", id)
+	}
+
+	// Sem canary a estável tem 100% (evita o sorteio)
+	if len(set.weights) == 1 {
+		return set.versions[set.weights[0].version], nil
+	}
+
+	n := r.pick(100)
+	for _, w := range set.weights {
+		if n < w.weight {
+			return set.versions[w.version], nil
+		}
+		n -= w.weight
+	}
+
+	return set.versions[set.stable], nil
+}
+
+// Versions versões do connector com seus pesos, da maior para a menor
+func (r *Registry) Versions(id string) []VersionInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	set, exists := r.connectors[id]
+	if !exists {
+		return nil
+	}
+
+	weights := make(map[string]int, len(set.weights))
+	for _, w := range set.weights {
+		weights[w.version] = w.weight
+	}
+
+	versions := set.sortedVersions()
+	result := make([]VersionInfo, 0, len(versions))
+	for _, version := range versions {
+		result = append(result, VersionInfo{
+			Version: version,
+			Weight:  weights[version],
+			Stable:  version == set.stable,
+		})
+	}
+	return result
+}
+
+// List retorna todos os conectores registrados (versão estável de cada um)
 func (r *Registry) List() []*types.ConnectorConfig {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	result := make([]*types.ConnectorConfig, 0, len(r.connectors))
-	for _, config := range r.connectors {
-		result = append(result, config)
+	for _, set := range r.connectors {
+		result = append(result, set.versions[set.stable])
 	}
 
 	return result
 }
 
-// Reload recarrega um connector específico
-func (r *Registry) Reload(id string) error {
-	config, err := r.loader.LoadConnector(id)
+// ListAll retorna todas as versões de todos os conectores
+func (r *Registry) ListAll() []*types.ConnectorConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*types.ConnectorConfig
+	for _, set := range r.connectors {
+		for _, version := range set.sortedVersions() {
+			result = append(result, set.versions[version])
+		}
+	}
+
+	return result
+}
+
+// Reload recarrega um connector específico (arquivo {ref}.yaml, ex: comexstat ou comexstat@2.0.0)
+func (r *Registry) Reload(ref string) error {
+	config, err := r.loader.LoadConnector(ref)
 	if err != nil {
-		return fmt.Errorf("failed to reload connector %s: %w", id, err)
+		return fmt.Errorf("failed to reload connector %s: %w", ref, err)
 	}
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.connectors[id] = config
+	set := &versionSet{versions: map[string]*types.ConnectorConfig{config.Version: config}}
+	if current, exists := r.connectors[config.ID]; exists {
+		for version, versionConfig := range current.versions {
+			if version != config.Version {
+				set.versions[version] = versionConfig
+			}
+		}
+	}
+	if err := set.route(); err != nil {
+		return fmt.Errorf("failed to reload connector %s: %w", ref, err)
+	}
+	r.connectors[config.ID] = set
 
 	return nil
 }
@@ -117,3 +303,31 @@ func (r *Registry) GetEnvironment(connectorID, env string) (*types.Environment,
 
 	return &environment, nil
 }
+
+// compareVersions compara versões semânticas numéricas (1.10.0 > 1.9.0); retorna -1, 0 ou 1
+func compareVersions(a, b string) int {
+	partsA := strings.Split(a, ".")
+	partsB := strings.Split(b, ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		x, y := "0", "0"
+		if i < len(partsA) {
+			x = partsA[i]
+		}
+		if i < len(partsB) {
+			y = partsB[i]
+		}
+
+		nx, errX := strconv.Atoi(x)
+		ny, errY := strconv.Atoi(y)
+		switch {
+		case errX == nil && errY == nil && nx != ny:
+			if nx > ny {
+				return 1
+			}
+			return -1
+		case (errX != nil || errY != nil) && x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,205 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeVersion grava uma versão do connector comexstat no diretório de configs
+func writeVersion(t *testing.T, dir, file, version, rollout string) {
+	t.Helper()
+	config := fmt.Sprintf(`
+id: comexstat
+name: ComexStat
+version: %s
+%s
+integration:
+  type: rest_api
+  auth:
+    type: none
+  endpoints:
+    exportacao_mes:
+      method: GET
+      path: /api/exp
+      response:
+        success_status: [200]
+`, version, rollout)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(config), 0644))
+}
+
+func TestRegistry_Versions(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion(t, dir, "comexstat.yaml", "1.0.0", "")
+	writeVersion(t, dir, "comexstat@1.10.0.yaml", "1.10.0", "")
+	writeVersion(t, dir, "comexstat@2.0.0.yaml", "2.0.0", "rollout:\n  weight: 10")
+	writeVersion(t, dir, "comexstat@3.0.0-beta.yaml", "3.0.0-beta", "rollout:\n  weight: 0")
+
+	reg := NewRegistry(dir)
+	require.NoError(t, reg.LoadAll())
+
+	// Estável = maior versão sem rollout
+	stable, err := reg.Get("comexstat")
+	require.NoError(t, err)
+	assert.Equal(t, "1.10.0", stable.Version)
+	assert.Equal(t, 1, reg.Count())
+	assert.Len(t, reg.List(), 1)
+	assert.Len(t, reg.ListAll(), 4)
+
+	pinned, err := reg.Get("comexstat@v2.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", pinned.Version)
+
+	_, err = reg.Get("comexstat@9.9.9")
+	assert.ErrorContains(t, err, "connector version not found: comexstat@9.9.9")
+
+	assert.Equal(t, []VersionInfo{
+		{Version: "3.0.0-beta", Weight: 0},
+		{Version: "2.0.0", Weight: 10},
+		{Version: "1.10.0", Weight: 90, Stable: true},
+		{Version: "1.0.0", Weight: 0},
+	}, reg.Versions("comexstat"))
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion(t, dir, "comexstat.yaml", "1.0.0", "")
+	writeVersion(t, dir, "comexstat@2.0.0.yaml", "2.0.0", "rollout:\n  weight: 10")
+
+	reg := NewRegistry(dir)
+	require.NoError(t, reg.LoadAll())
+
+	tests := []struct {
+		name    string
+		pinned  string
+		pick    int
+		version string
+	}{
+		{"canary range", "", 0, "2.0.0"},
+		{"canary upper bound", "", 9, "2.0.0"},
+		{"stable range", "", 10, "1.0.0"},
+		{"stable upper bound", "", 99, "1.0.0"},
+		{"pinned ignores routing", "1.0.0", 0, "1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg.pick = func(int) int { return tt.pick }
+			config, err := reg.Resolve("comexstat", tt.pinned)
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, config.Version)
+		})
+	}
+
+	// Distribuição aproximada com sorteio real
+	reg = NewRegistry(dir)
+	require.NoError(t, reg.LoadAll())
+	canary := 0
+	for i := 0; i < 10000; i++ {
+		config, err := reg.Resolve("comexstat", "")
+		require.NoError(t, err)
+		if config.Version == "2.0.0" {
+			canary++
+		}
+	}
+	assert.InDelta(t, 1000, canary, 200)
+}
+
+func TestRegistry_LoadAll_VersionErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(dir string)
+		errContains string
+	}{
+		{
+			name: "Duplicate version",
+			setup: func(dir string) {
+				writeVersion(t, dir, "comexstat.yaml", "1.0.0", "")
+				writeVersion(t, dir, "comexstat-copy.yaml", "1.0.0", "")
+			},
+			errContains: "duplicate version comexstat@1.0.0",
+		},
+		{
+			name: "No stable version",
+			setup: func(dir string) {
+				writeVersion(t, dir, "comexstat.yaml", "1.0.0", "rollout:\n  weight: 100")
+			},
+			errContains: "no stable version",
+		},
+		{
+			name: "Weights above 100",
+			setup: func(dir string) {
+				writeVersion(t, dir, "comexstat.yaml", "1.0.0", "")
+				writeVersion(t, dir, "comexstat@2.0.0.yaml", "2.0.0", "rollout:\n  weight: 60")
+				writeVersion(t, dir, "comexstat@3.0.0.yaml", "3.0.0", "rollout:\n  weight: 50")
+			},
+			errContains: "rollout weights sum to 110%",
+		},
+		{
+			name: "Invalid weight",
+			setup: func(dir string) {
+				writeVersion(t, dir, "comexstat.yaml", "1.0.0", "rollout:\n  weight: 150")
+			},
+			errContains: "rollout weight must be between 0 and 100",
+		},
+		{
+			name: "Version with v prefix",
+			setup: func(dir string) {
+				writeVersion(t, dir, "comexstat.yaml", "v1.0.0", "")
+			},
+			errContains: "invalid version format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(dir)
+
+			err := NewRegistry(dir).LoadAll()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestRegistry_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion(t, dir, "comexstat.yaml", "1.0.0", "")
+	writeVersion(t, dir, "comexstat@2.0.0.yaml", "2.0.0", "rollout:\n  weight: 10")
+
+	reg := NewRegistry(dir)
+	require.NoError(t, reg.LoadAll())
+
+	// Cutover: 2.0.0 deixa de ser canary e vira a estável
+	writeVersion(t, dir, "comexstat@2.0.0.yaml", "2.0.0", "")
+	require.NoError(t, reg.Reload("comexstat@2.0.0"))
+
+	stable, err := reg.Get("comexstat")
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", stable.Version)
+	assert.Len(t, reg.ListAll(), 2)
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "10.0.0", -1},
+		{"1.0", "1.0.0", 0},
+		{"1.0.1", "1.0", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, compareVersions(tt.a, tt.b))
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Redacted valor gravado no lugar de segredos
+const Redacted = "REDACTED"
+
+// Fixture troca HTTP gravada (request + response) de um endpoint
+type Fixture struct {
+	Connector  string          `json:"connector"`
+	Endpoint   string          `json:"endpoint"`
+	RecordedAt time.Time       `json:"recorded_at"`
+	Request    FixtureRequest  `json:"request"`
+	Response   FixtureResponse `json:"response"`
+}
+
+// FixtureRequest request gravada (segredos removidos)
+type FixtureRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// FixtureResponse response gravada
+type FixtureResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+}
+
+// target connector/endpoint de uma request (propagado via context)
+type target struct {
+	connector string
+	endpoint  string
+}
+
+type targetKey struct{}
+
+// WithTarget associa connector/endpoint ao context da request upstream
+func WithTarget(ctx context.Context, connectorID, endpointName string) context.Context {
+	return context.WithValue(ctx, targetKey{}, target{connector: connectorID, endpoint: endpointName})
+}
+
+// targetFromContext obtém connector/endpoint do context
+func targetFromContext(ctx context.Context) (target, error) {
+	t, ok := ctx.Value(targetKey{}).(target)
+	if !ok || t.connector == "" || t.endpoint == "" {
+		return target{}, fmt.Errorf("request has no connector/endpoint target")
+	}
+	return t, nil
+}
+
+// Store armazena fixtures em {dir}/{connector}/{endpoint}/{key}.json
+type Store struct {
+	dir string
+}
+
+// NewStore cria um store de fixtures
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// path caminho do arquivo de fixture para uma request
+func (s *Store) path(t target, method, rawURL, body string) string {
+	return filepath.Join(s.dir, t.connector, t.endpoint, fixtureKey(method, rawURL, body)+".json")
+}
+
+// Save grava uma fixture
+func (s *Store) Save(fixture *Fixture) error {
+	t := target{connector: fixture.Connector, endpoint: fixture.Endpoint}
+	path := s.path(t, fixture.Request.Method, fixture.Request.URL, fixture.Request.Body)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create fixture dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Load carrega a fixture correspondente a uma request
+func (s *Store) Load(t target, method, rawURL, body string) (*Fixture, error) {
+	path := s.path(t, method, rawURL, body)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("fixture not found for %s.%s (%s %s)", t.connector, t.endpoint, method, rawURL)
+		}
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+
+	return &fixture, nil
+}
+
+// fixtureKey identifica uma request por método, URL (já sem segredos) e body
+func fixtureKey(method, rawURL, body string) string {
+	sum := sha256.Sum256([]byte(method + " " + rawURL + "\n" + body))
+	return hex.EncodeToString(sum[:8])
+}
+
+// secretMarkers trechos de nomes (headers, query params, campos de body) que carregam credenciais
+var secretMarkers = []string{"auth", "key", "token", "secret", "password", "cookie", "signature", "x-client-cert"}
+
+// secretFieldRegex nome com marcador seguido de valor em body sem estrutura conhecida
+// (key=..., "token": ..., <password>)
+var secretFieldRegex = regexp.MustCompile(`(?i)[\w-]*(` + strings.Join(secretMarkers, "|") + `)[\w-]*["']?\s*[:=>]`)
+
+// isSecretName identifica headers/query params/campos que carregam credenciais
+func isSecretName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, marker := range secretMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTraceHeader headers de propagação de trace (OpenTelemetry)
+func isTraceHeader(name string) bool {
+	switch strings.ToLower(name) {
+	case "traceparent", "tracestate", "baggage":
+		return true
+	}
+	return false
+}
+
+// redactURL substitui valores de query params e segmentos de path sensíveis
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.Path = redactPath(u.Path)
+	redacted.RawPath = ""
+
+	query := redacted.Query()
+	for name := range query {
+		if isSecretName(name) {
+			query.Set(name, Redacted)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+
+	return redacted.String()
+}
+
+// redactPath substitui segmentos que parecem credenciais: o valor depois de um segmento
+// com nome sensível (/token/{valor}), JWTs e strings opacas longas (letras e dígitos)
+func redactPath(path string) string {
+	segments := strings.Split(path, "/")
+	afterSecretName := false
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		if afterSecretName || looksLikeToken(segment) {
+			segments[i] = Redacted
+			afterSecretName = false
+			continue
+		}
+		afterSecretName = isSecretName(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// looksLikeToken JWT ou string opaca de 24+ caracteres misturando letras e dígitos
+// (CEP, CNPJ e anos só têm dígitos e ficam como estão)
+func looksLikeToken(segment string) bool {
+	if strings.HasPrefix(segment, "eyJ") && strings.Count(segment, ".") == 2 {
+		return true
+	}
+	if len(segment) < 24 {
+		return false
+	}
+	var letters, digits bool
+	for _, r := range segment {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = true
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			letters = true
+		case r == '-' || r == '_' || r == '.' || r == '~':
+		default:
+			return false
+		}
+	}
+	return letters && digits
+}
+
+// redactBody substitui campos sensíveis de bodies JSON e de formulário. Outros formatos
+// não são entendidos: se parecem ter um campo sensível, a gravação é recusada
+func redactBody(contentType, body string) (string, error) {
+	if body == "" {
+		return body, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(body)
+		if err != nil {
+			break
+		}
+		changed := false
+		for name := range form {
+			if isSecretName(name) {
+				form.Set(name, Redacted)
+				changed = true
+			}
+		}
+		if changed {
+			return form.Encode(), nil
+		}
+		return body, nil
+	case strings.HasSuffix(mediaType, "json") || (mediaType == "" && json.Valid([]byte(body))):
+		decoder := json.NewDecoder(strings.NewReader(body))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			break
+		}
+		if !redactJSON(value) {
+			return body, nil // sem segredo: body original, byte a byte
+		}
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(value); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	}
+
+	if match := secretFieldRegex.FindString(body); match != "" {
+		return "", fmt.Errorf("body (%s) may contain credentials (%q) and cannot be redacted", contentType, match)
+	}
+	return body, nil
+}
+
+// redactJSON troca (in place) valores de campos com nome sensível; informa se trocou algum
+func redactJSON(value interface{}) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if isSecretName(name) {
+				if field != Redacted {
+					v[name] = Redacted
+					changed = true
+				}
+				continue
+			}
+			if redactJSON(field) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if redactJSON(item) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// redactHeaders copia headers substituindo valores sensíveis
+func redactHeaders(headers http.Header) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make(map[string]string, len(names))
+	for _, name := range names {
+		if isTraceHeader(name) {
+			continue // muda a cada request
+		}
+		if isSecretName(name) {
+			result[name] = Redacted
+			continue
+		}
+		result[name] = strings.Join(headers.Values(name), ", ")
+	}
+	return result
+}
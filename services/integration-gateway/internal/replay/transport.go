@@ -0,0 +1,153 @@
+package replay
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recorder RoundTripper que executa a request real e grava a troca como fixture
+type Recorder struct {
+	store *Store
+	next  http.RoundTripper
+}
+
+// NewRecorder cria um recorder sobre o transport real
+func NewRecorder(store *Store, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{store: store, next: next}
+}
+
+// RoundTrip executa a request e grava request/response (com segredos removidos de headers,
+// URL e bodies; body que não dá para redigir impede a gravação)
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	t, err := targetFromContext(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	recordedReqBody, err := redactBody(req.Header.Get("Content-Type"), reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record fixture: request %w", err)
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	recordedRespBody, err := redactBody(resp.Header.Get("Content-Type"), respBody)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to record fixture: response %w", err)
+	}
+
+	fixture := &Fixture{
+		Connector:  t.connector,
+		Endpoint:   t.endpoint,
+		RecordedAt: time.Now().UTC(),
+		Request: FixtureRequest{
+			Method:  req.Method,
+			URL:     redactURL(req.URL),
+			Headers: redactHeaders(req.Header),
+			Body:    recordedReqBody,
+		},
+		Response: FixtureResponse{
+			Status:  resp.StatusCode,
+			Headers: redactHeaders(resp.Header),
+			Body:    recordedRespBody,
+		},
+	}
+
+	if recordedRespBody != respBody && fixture.Response.Headers["Content-Length"] != "" {
+		fixture.Response.Headers["Content-Length"] = strconv.Itoa(len(recordedRespBody))
+	}
+
+	if err := r.store.Save(fixture); err != nil {
+		return nil, fmt.Errorf("failed to record fixture: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Replayer RoundTripper que serve respostas gravadas, sem acessar a rede
+type Replayer struct {
+	store *Store
+}
+
+// NewReplayer cria um replayer
+func NewReplayer(store *Store) *Replayer {
+	return &Replayer{store: store}
+}
+
+// RoundTrip responde com a fixture correspondente à request
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	t, err := targetFromContext(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	// Mesma redação da gravação: a chave da fixture usa URL e body sem segredos
+	reqBody, err = redactBody(req.Header.Get("Content-Type"), reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture, err := r.store.Load(t, req.Method, redactURL(req.URL), reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(http.Header, len(fixture.Response.Headers))
+	for name, value := range fixture.Response.Headers {
+		header.Set(name, value)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Response.Status, http.StatusText(fixture.Response.Status)),
+		StatusCode:    fixture.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(fixture.Response.Body)),
+		ContentLength: int64(len(fixture.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// readBody lê o body e o substitui por uma cópia relível
+func readBody(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return "", err
+	}
+
+	*body = io.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
@@ -0,0 +1,196 @@
+package replay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorderAndReplayer(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "secret-key", r.Header.Get("X-API-Key"))
+		assert.Equal(t, `{"co_ano": 2024}`, string(body))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Write([]byte(`{"total": 1}`))
+	}))
+
+	store := NewStore(t.TempDir())
+	ctx := WithTarget(context.Background(), "comexstat", "exportacao_mes")
+
+	newRequest := func(apiKey string) *http.Request {
+		req, err := http.NewRequestWithContext(ctx, "POST", upstream.URL+"/api/exp?token="+apiKey, strings.NewReader(`{"co_ano": 2024}`))
+		require.NoError(t, err)
+		req.Header.Set("X-API-Key", apiKey)
+		return req
+	}
+
+	// Record
+	resp, err := NewRecorder(store, http.DefaultTransport).RoundTrip(newRequest("secret-key"))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, `{"total": 1}`, string(body))
+
+	files, err := filepath.Glob(filepath.Join(store.dir, "comexstat", "exportacao_mes", "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	recorded, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(recorded), "secret-key")
+	assert.NotContains(t, string(recorded), "session=abc")
+	assert.Contains(t, string(recorded), Redacted)
+
+	// Replay sem rede (credencial diferente gera a mesma chave: valor é redigido)
+	upstream.Close()
+
+	resp, err = NewReplayer(store).RoundTrip(newRequest("other-key"))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, `{"total": 1}`, string(body))
+}
+
+func TestReplayer_FixtureNotFound(t *testing.T) {
+	ctx := WithTarget(context.Background(), "viacep", "consulta_cep")
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://viacep.com.br/ws/01310100/json/", nil)
+	require.NoError(t, err)
+
+	_, err = NewReplayer(NewStore(t.TempDir())).RoundTrip(req)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "fixture not found for viacep.consulta_cep")
+}
+
+func TestReplayer_RequiresTarget(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://viacep.com.br/ws/01310100/json/", nil)
+	require.NoError(t, err)
+
+	_, err = NewReplayer(NewStore(t.TempDir())).RoundTrip(req)
+
+	assert.Error(t, err)
+}
+
+func TestIsSecretName(t *testing.T) {
+	for _, name := range []string{"Authorization", "X-API-Key", "api_key", "access_token", "Cookie", "X-Client-Cert"} {
+		assert.True(t, isSecretName(name), name)
+	}
+	for _, name := range []string{"Accept", "Content-Type", "ano", "mes"} {
+		assert.False(t, isSecretName(name), name)
+	}
+}
+
+func TestRecorder_RedactsBodiesAndPath(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": 7, "access_token": "resp-secret"}, "items": [{"api_key": "item-secret", "ano": 2024}]}`))
+	}))
+	defer upstream.Close()
+
+	store := NewStore(t.TempDir())
+	ctx := WithTarget(context.Background(), "receita-federal", "consulta_cnpj")
+	newRequest := func(password, token string) *http.Request {
+		req, err := http.NewRequestWithContext(ctx, "POST", upstream.URL+"/v1/token/"+token+"/cnpj/12345678000190",
+			strings.NewReader(`{"user": "bgc", "password": "`+password+`"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	resp, err := NewRecorder(store, http.DefaultTransport).RoundTrip(newRequest("req-secret", "path-secret"))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "resp-secret") // o caller recebe a resposta original
+
+	files, err := filepath.Glob(filepath.Join(store.dir, "receita-federal", "consulta_cnpj", "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	recorded, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	for _, secret := range []string{"req-secret", "path-secret", "resp-secret", "item-secret"} {
+		assert.NotContains(t, string(recorded), secret)
+	}
+	assert.Contains(t, string(recorded), "12345678000190")
+
+	// Outros valores de segredo geram a mesma chave de fixture
+	upstream.Close()
+	resp, err = NewReplayer(store).RoundTrip(newRequest("other-password", "other-token"))
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"data": {"id": 7, "access_token": "REDACTED"}, "items": [{"api_key": "REDACTED", "ano": 2024}]}`, string(body))
+	assert.Equal(t, strconv.Itoa(len(body)), resp.Header.Get("Content-Length"))
+}
+
+func TestRecorder_RefusesOpaqueBodyWithSecrets(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<resposta><token>abc</token></resposta>`))
+	}))
+	defer upstream.Close()
+
+	store := NewStore(t.TempDir())
+	req, err := http.NewRequestWithContext(WithTarget(context.Background(), "legado", "consulta"), "GET", upstream.URL+"/consulta", nil)
+	require.NoError(t, err)
+
+	_, err = NewRecorder(store, http.DefaultTransport).RoundTrip(req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be redacted")
+
+	files, _ := filepath.Glob(filepath.Join(store.dir, "legado", "consulta", "*.json"))
+	assert.Empty(t, files)
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"json without secrets kept byte for byte", "application/json", `{"co_ano": 2024,  "vl": 1.50}`, `{"co_ano": 2024,  "vl": 1.50}`},
+		{"json nested", "application/json; charset=utf-8", `{"a": {"client_secret": "s"}, "b": [{"token": "t"}]}`, `{"a":{"client_secret":"REDACTED"},"b":[{"token":"REDACTED"}]}`},
+		{"json without content type", "", `{"password": "p", "url": "a&b"}`, `{"password":"REDACTED","url":"a&b"}`},
+		{"form", "application/x-www-form-urlencoded", "grant_type=client_credentials&client_secret=s", "client_secret=REDACTED&grant_type=client_credentials"},
+		{"text without secrets", "text/plain", "monkey business", "monkey business"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := redactBody(tt.contentType, tt.body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, body := range []string{"api_key=abc", `<password>x</password>`, "Authorization: Bearer x"} {
+		_, err := redactBody("text/plain", body)
+		assert.Error(t, err, body)
+	}
+}
+
+func TestRedactPath(t *testing.T) {
+	tests := map[string]string{
+		"/ws/01310100/json/":                           "/ws/01310100/json/",
+		"/api/exp/2024/05":                             "/api/exp/2024/05",
+		"/v1/token/abc/cnpj/12345678000190":            "/v1/token/REDACTED/cnpj/12345678000190",
+		"/v1/token/my-secret/cnpj":                     "/v1/token/REDACTED/cnpj",
+		"/v1/apikey/abc":                               "/v1/apikey/REDACTED",
+		"/v1/dados/a1b2c3d4e5f6a7b8c9d0e1f2a3b4":       "/v1/dados/REDACTED",
+		"/v1/eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJ4In0.sig": "/v1/REDACTED",
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, redactPath(path), path)
+	}
+}
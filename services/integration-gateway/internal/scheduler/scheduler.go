@@ -0,0 +1,585 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/observability"
+	"github.com/bgc/integration-gateway/internal/paramtmpl"
+	"github.com/bgc/integration-gateway/internal/transform"
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Executor executa chamadas de connector (framework.Executor)
+type Executor interface {
+	Execute(ctx *types.ExecutionContext) (*types.ExecutionResult, error)
+}
+
+// Job job agendado de um connector (ID: connector.nome)
+type Job struct {
+	ID        string
+	Connector string
+	Version   string // versão do connector que declara o job (execuções fixadas nela)
+	Config    types.JobConfig
+
+	schedule cron.Schedule
+	ticks    *tickSchedule // horários calculados pelo cron (Start)
+	location *time.Location
+	params   map[string]*template.Template
+	attempts int
+	backoff  time.Duration
+}
+
+// NewJob valida e compila a configuração de um job
+func NewJob(connectorID string, config types.JobConfig) (*Job, error) {
+	job := &Job{
+		ID:        connectorID + "." + config.Name,
+		Connector: connectorID,
+		Config:    config,
+		location:  time.UTC,
+		attempts:  1,
+		backoff:   time.Minute,
+	}
+
+	if config.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	if err := validateTarget(&config.Target); err != nil {
+		return nil, err
+	}
+	if err := validatePages(config.Pages); err != nil {
+		return nil, err
+	}
+
+	if config.Timezone != "" {
+		location, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %s: %w", config.Timezone, err)
+		}
+		job.location = location
+	}
+
+	// CRON_TZ faz o cron avaliar o horário no timezone do job
+	schedule, err := cron.ParseStandard("CRON_TZ=" + job.location.String() + " " + config.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", config.Schedule, err)
+	}
+	job.schedule = schedule
+
+	job.params, err = paramtmpl.Parse(config.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Retry.MaxAttempts > 0 {
+		job.attempts = config.Retry.MaxAttempts
+	}
+	if config.Retry.Backoff != "" {
+		job.backoff, err = time.ParseDuration(config.Retry.Backoff)
+		if err != nil || job.backoff < 0 {
+			return nil, fmt.Errorf("invalid retry backoff %q", config.Retry.Backoff)
+		}
+	}
+
+	return job, nil
+}
+
+// identifierPattern nomes de tabela/coluna aceitos (tabela pode ter schema)
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// validateTarget valida tabela, campo de linhas, colunas e chave de conflito
+func validateTarget(target *types.JobTargetConfig) error {
+	parts := strings.Split(target.Table, ".")
+	if target.Table == "" || len(parts) > 2 {
+		return fmt.Errorf("target.table must be table or schema.table")
+	}
+	for _, part := range parts {
+		if !identifierPattern.MatchString(part) {
+			return fmt.Errorf("invalid target.table %q", target.Table)
+		}
+	}
+
+	if !transform.ValidFieldPath(target.Rows) {
+		return fmt.Errorf("invalid target.rows %q", target.Rows)
+	}
+
+	if len(target.Columns) == 0 {
+		return fmt.Errorf("target.columns is required")
+	}
+	columns := make(map[string]bool, len(target.Columns))
+	for _, column := range target.Columns {
+		if !identifierPattern.MatchString(column) {
+			return fmt.Errorf("invalid target column %q", column)
+		}
+		if columns[column] {
+			return fmt.Errorf("duplicate target column %s", column)
+		}
+		columns[column] = true
+	}
+
+	if len(target.ConflictKeys) == 0 {
+		return fmt.Errorf("target.conflict_keys is required")
+	}
+	keys := make(map[string]bool, len(target.ConflictKeys))
+	for _, key := range target.ConflictKeys {
+		if !columns[key] {
+			return fmt.Errorf("conflict key %s is not in target.columns", key)
+		}
+		keys[key] = true
+	}
+
+	// sum: cada coluna é chave ou métrica (as linhas são agrupadas pela chave)
+	if len(target.Sum) > 0 {
+		sums := make(map[string]bool, len(target.Sum))
+		for _, column := range target.Sum {
+			if !columns[column] || keys[column] {
+				return fmt.Errorf("sum column %s must be a target column outside conflict_keys", column)
+			}
+			sums[column] = true
+		}
+		for _, column := range target.Columns {
+			if !keys[column] && !sums[column] {
+				return fmt.Errorf("column %s must be in conflict_keys or sum", column)
+			}
+		}
+	}
+
+	for _, column := range target.Replace {
+		if !keys[column] {
+			return fmt.Errorf("replace column %s is not in target.conflict_keys", column)
+		}
+	}
+
+	return nil
+}
+
+// defaultMaxPages limite de páginas por execução quando pages.max_pages não é informado
+const defaultMaxPages = 100
+
+// validatePages valida a paginação do job (opcional)
+func validatePages(pages *types.JobPagesConfig) error {
+	if pages == nil {
+		return nil
+	}
+	if pages.Param == "" {
+		return fmt.Errorf("pages.param is required")
+	}
+	if !transform.ValidFieldPath(pages.Total) {
+		return fmt.Errorf("invalid pages.total %q", pages.Total)
+	}
+	if pages.MaxPages < 0 {
+		return fmt.Errorf("invalid pages.max_pages %d", pages.MaxPages)
+	}
+	return nil
+}
+
+// Next próxima execução após t
+func (j *Job) Next(t time.Time) time.Time {
+	return j.schedule.Next(t)
+}
+
+// tickSchedule repassa o schedule ao cron e guarda os horários calculados, para a
+// execução usar o tick agendado (e não o relógio, que pode acordar atrasado)
+type tickSchedule struct {
+	cron.Schedule
+
+	mu    sync.Mutex
+	ticks []time.Time
+}
+
+// Next chamado pelo cron ao agendar a próxima execução
+func (t *tickSchedule) Next(now time.Time) time.Time {
+	next := t.Schedule.Next(now)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ticks = append(t.ticks, next)
+	return next
+}
+
+// due remove e retorna o tick mais antigo já vencido (ok=false se nenhum venceu)
+func (t *tickSchedule) due(now time.Time) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.ticks) == 0 || t.ticks[0].After(now) {
+		return time.Time{}, false
+	}
+	tick := t.ticks[0]
+	t.ticks = t.ticks[1:]
+	return tick, true
+}
+
+// JobStatus estado de um job (GET /v1/jobs)
+type JobStatus struct {
+	ID          string     `json:"id"`
+	Connector   string     `json:"connector"`
+	Endpoint    string     `json:"endpoint"`
+	Schedule    string     `json:"schedule"`
+	Timezone    string     `json:"timezone"`
+	Target      string     `json:"target"`
+	NextRun     *time.Time `json:"next_run,omitempty"`
+	RunningHere bool       `json:"running_here"` // em execução nesta réplica
+	LastRun     *Run       `json:"last_run,omitempty"`
+}
+
+// Scheduler agenda e executa os jobs declarados nos conectores
+type Scheduler struct {
+	executor    Executor
+	store       Store
+	environment string
+	replica     string
+	jobs        []*Job
+
+	cron    *cron.Cron
+	entries map[string]cron.EntryID
+
+	mu      sync.Mutex
+	running map[string]bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	now    func() time.Time
+}
+
+// New cria o scheduler com os jobs de todos os conectores
+func New(executor Executor, store Store, connectors []*types.ConnectorConfig, environment string) (*Scheduler, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	replica, _ := os.Hostname()
+
+	s := &Scheduler{
+		executor:    executor,
+		store:       store,
+		environment: environment,
+		replica:     replica,
+		cron:        cron.New(),
+		entries:     make(map[string]cron.EntryID),
+		running:     make(map[string]bool),
+		ctx:         ctx,
+		cancel:      cancel,
+		now:         time.Now,
+	}
+
+	for _, connector := range connectors {
+		for _, config := range connector.Jobs {
+			job, err := NewJob(connector.ID, config)
+			if err != nil {
+				cancel()
+				return nil, fmt.Errorf("job %s.%s: %w", connector.ID, config.Name, err)
+			}
+			job.Version = connector.Version
+			s.jobs = append(s.jobs, job)
+		}
+	}
+	sort.Slice(s.jobs, func(i, j int) bool { return s.jobs[i].ID < s.jobs[j].ID })
+
+	return s, nil
+}
+
+// Jobs jobs configurados
+func (s *Scheduler) Jobs() []*Job {
+	return s.jobs
+}
+
+// Start agenda os jobs
+func (s *Scheduler) Start() {
+	for _, job := range s.jobs {
+		job := job
+		job.ticks = &tickSchedule{Schedule: job.schedule}
+		s.entries[job.ID] = s.cron.Schedule(job.ticks, cron.FuncJob(func() {
+			// O horário agendado identifica a execução (gateway_job_runs) e renderiza os params
+			scheduledFor, ok := job.ticks.due(s.now())
+			if !ok {
+				scheduledFor = s.now().In(job.location).Truncate(time.Minute)
+			}
+			if _, err := s.RunJob(s.ctx, job, scheduledFor); err != nil {
+				observability.WithFields("job", job.ID, "error", err.Error()).Error("Scheduled job failed")
+			}
+		}))
+	}
+	s.cron.Start()
+
+	observability.Info("Scheduler started", "jobs", len(s.jobs), "replica", s.replica)
+}
+
+// Stop interrompe o agendamento e aguarda execuções em andamento (ou o ctx expirar)
+func (s *Scheduler) Stop(ctx context.Context) {
+	done := s.cron.Stop()
+	s.cancel() // interrompe esperas entre retries
+	select {
+	case <-done.Done():
+	case <-ctx.Done():
+	}
+}
+
+// RunJob executa um job para o horário agendado (retorna nil se outra réplica o executou)
+func (s *Scheduler) RunJob(ctx context.Context, job *Job, scheduledFor time.Time) (*Run, error) {
+	unlock, ok, err := s.store.TryLock(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		observability.Debug("Job locked by another replica", "job", job.ID)
+		return nil, nil
+	}
+	defer unlock()
+
+	params, err := paramtmpl.Render(job.params, scheduledFor.In(job.location))
+	if err != nil {
+		return nil, err
+	}
+
+	run := &Run{
+		JobID:        job.ID,
+		ScheduledFor: scheduledFor,
+		StartedAt:    s.now(),
+		Status:       RunRunning,
+		Params:       params,
+		Replica:      s.replica,
+	}
+	if err := s.store.StartRun(ctx, run); err != nil {
+		if errors.Is(err, ErrAlreadyRun) {
+			observability.Debug("Job already run for schedule", "job", job.ID, "scheduled_for", scheduledFor)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to record run: %w", err)
+	}
+
+	s.setRunning(job.ID, true)
+	defer s.setRunning(job.ID, false)
+
+	// Cada execução agendada é a raiz de um trace
+	ctx, span := observability.StartSpan(ctx, "job.run",
+		attribute.String("job.id", job.ID),
+		attribute.String("job.scheduled_for", scheduledFor.Format(time.RFC3339)),
+	)
+
+	observability.Info("Running job", "job", job.ID, "scheduled_for", scheduledFor.Format(time.RFC3339))
+
+	var runErr error
+	for attempt := 1; attempt <= job.attempts; attempt++ {
+		run.Attempts = attempt
+		run.RowsUpserted, runErr = s.attempt(ctx, job, params)
+		if runErr == nil {
+			break
+		}
+
+		observability.WithFields("job", job.ID, "attempt", attempt, "error", runErr.Error()).Warn("Job attempt failed")
+		if attempt < job.attempts && !sleep(ctx, job.backoff) {
+			break
+		}
+	}
+
+	finishedAt := s.now()
+	run.FinishedAt = &finishedAt
+	run.Status = RunSuccess
+	if runErr != nil {
+		run.Status = RunFailed
+		run.Error = runErr.Error()
+	}
+
+	// Registra o resultado mesmo se o ctx foi cancelado (shutdown)
+	finishCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.store.FinishRun(finishCtx, run); err != nil {
+		observability.WithFields("job", job.ID, "error", err.Error()).Error("Failed to record job result")
+	}
+
+	span.SetAttributes(attribute.Int("job.attempts", run.Attempts), attribute.Int64("job.rows_upserted", run.RowsUpserted))
+	observability.EndSpan(span, runErr)
+
+	observability.RecordJobRun(job.ID, run.Status, finishedAt.Sub(run.StartedAt).Seconds(), run.RowsUpserted)
+	observability.Info("Job finished", "job", job.ID, "status", run.Status, "attempts", run.Attempts, "rows", run.RowsUpserted)
+
+	return run, runErr
+}
+
+// attempt executa o endpoint (todas as páginas) e grava as linhas mapeadas
+func (s *Scheduler) attempt(ctx context.Context, job *Job, params map[string]interface{}) (int64, error) {
+	pages := job.Config.Pages
+	if pages == nil {
+		data, err := s.execute(ctx, job, params)
+		if err != nil {
+			return 0, err
+		}
+		rows, err := extractRows(data, job.Config.Target.Rows)
+		if err != nil {
+			return 0, err
+		}
+		return s.store.Upsert(ctx, &job.Config.Target, rows)
+	}
+
+	maxPages := pages.MaxPages
+	if maxPages == 0 {
+		maxPages = defaultMaxPages
+	}
+
+	var rows, first []map[string]interface{}
+	for page := 1; ; page++ {
+		if page > maxPages {
+			return 0, fmt.Errorf("more than %d pages", maxPages)
+		}
+
+		pageParams := make(map[string]interface{}, len(params)+1)
+		for name, value := range params {
+			pageParams[name] = value
+		}
+		pageParams[pages.Param] = page
+
+		data, err := s.execute(ctx, job, pageParams)
+		if err != nil {
+			return 0, fmt.Errorf("page %d: %w", page, err)
+		}
+		pageRows, err := extractRows(data, job.Config.Target.Rows)
+		if err != nil {
+			return 0, fmt.Errorf("page %d: %w", page, err)
+		}
+		total, err := extractTotal(data, pages.Total)
+		if err != nil {
+			return 0, fmt.Errorf("page %d: %w", page, err)
+		}
+
+		// Um endpoint que ignora o param devolve sempre a 1ª página (somaria linhas repetidas)
+		if page == 1 {
+			first = pageRows
+		} else if len(pageRows) > 0 && reflect.DeepEqual(pageRows, first) {
+			return 0, fmt.Errorf("page %d repeats page 1 (endpoint ignores %s?)", page, pages.Param)
+		}
+
+		rows = append(rows, pageRows...)
+		if int64(len(rows)) >= total {
+			break
+		}
+		if len(pageRows) == 0 {
+			return 0, fmt.Errorf("incomplete result: %d of %d rows", len(rows), total)
+		}
+	}
+
+	return s.store.Upsert(ctx, &job.Config.Target, rows)
+}
+
+// execute chama o endpoint do job e retorna a saída mapeada
+func (s *Scheduler) execute(ctx context.Context, job *Job, params map[string]interface{}) (map[string]interface{}, error) {
+	environment := job.Config.Environment
+	if environment == "" {
+		environment = s.environment
+	}
+
+	result, err := s.executor.Execute(&types.ExecutionContext{
+		ConnectorID:  job.Connector,
+		Version:      job.Version,
+		EndpointName: job.Config.Endpoint,
+		Environment:  environment,
+		Params:       params,
+		StartTime:    s.now(),
+		Context:      ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// Status estado dos jobs com a última execução registrada
+func (s *Scheduler) Status(ctx context.Context) ([]JobStatus, error) {
+	lastRuns, err := s.store.LastRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		status := JobStatus{
+			ID:          job.ID,
+			Connector:   job.Connector,
+			Endpoint:    job.Config.Endpoint,
+			Schedule:    job.Config.Schedule,
+			Timezone:    job.location.String(),
+			Target:      job.Config.Target.Table,
+			RunningHere: s.running[job.ID],
+			LastRun:     lastRuns[job.ID],
+		}
+		if _, scheduled := s.entries[job.ID]; scheduled {
+			next := job.Next(s.now())
+			status.NextRun = &next
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (s *Scheduler) setRunning(jobID string, running bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running[jobID] = running
+}
+
+// extractRows obtém as linhas (array de objetos) da saída mapeada
+func extractRows(data map[string]interface{}, field string) ([]map[string]interface{}, error) {
+	value, exists := transform.GetField(data, field)
+	if !exists || value == nil {
+		return nil, fmt.Errorf("rows field %s not found in mapped response", field)
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("rows field %s is not an array", field)
+	}
+
+	rows := make([]map[string]interface{}, 0, len(items))
+	for i, item := range items {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("rows field %s[%d] is not an object", field, i)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// extractTotal obtém o total de linhas (todas as páginas) da saída mapeada
+func extractTotal(data map[string]interface{}, field string) (int64, error) {
+	value, exists := transform.GetField(data, field)
+	if !exists || value == nil {
+		return 0, fmt.Errorf("total field %s not found in mapped response", field)
+	}
+
+	switch n := value.(type) {
+	case int:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case float64:
+		if n == float64(int64(n)) && n >= 0 {
+			return int64(n), nil
+		}
+	}
+	return 0, fmt.Errorf("total field %s is not a non-negative integer: %v", field, value)
+}
+
+// sleep espera d ou o cancelamento do ctx (false se cancelado)
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,432 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore store em memória
+type fakeStore struct {
+	mu       sync.Mutex
+	locked   map[string]bool
+	runs     []*Run
+	upserted []map[string]interface{}
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{locked: make(map[string]bool)}
+}
+
+func (s *fakeStore) TryLock(ctx context.Context, jobID string) (func(), bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locked[jobID] {
+		return nil, false, nil
+	}
+	s.locked[jobID] = true
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.locked, jobID)
+	}, true, nil
+}
+
+func (s *fakeStore) StartRun(ctx context.Context, run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.runs {
+		if existing.JobID == run.JobID && existing.ScheduledFor.Equal(run.ScheduledFor) {
+			return ErrAlreadyRun
+		}
+	}
+	run.ID = int64(len(s.runs) + 1)
+	copied := *run
+	s.runs = append(s.runs, &copied)
+	return nil
+}
+
+func (s *fakeStore) FinishRun(ctx context.Context, run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *run
+	s.runs[run.ID-1] = &copied
+	return nil
+}
+
+func (s *fakeStore) LastRuns(ctx context.Context) (map[string]*Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last := make(map[string]*Run)
+	for _, run := range s.runs {
+		last[run.JobID] = run
+	}
+	return last, nil
+}
+
+func (s *fakeStore) Upsert(ctx context.Context, target *types.JobTargetConfig, rows []map[string]interface{}) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.upserted = append(s.upserted, projectRows(rows, target.Columns)...)
+	return int64(len(rows)), nil
+}
+
+// fakeExecutor falha nas primeiras failures chamadas
+type fakeExecutor struct {
+	failures int
+	calls    []*types.ExecutionContext
+	data     map[string]interface{}
+	pages    []map[string]interface{} // resposta por página (param page), se definido
+}
+
+func (e *fakeExecutor) Execute(ctx *types.ExecutionContext) (*types.ExecutionResult, error) {
+	e.calls = append(e.calls, ctx)
+	if len(e.calls) <= e.failures {
+		return nil, fmt.Errorf("upstream unavailable")
+	}
+	if e.pages != nil {
+		page, _ := ctx.Params["page"].(int)
+		if page < 1 || page > len(e.pages) {
+			return &types.ExecutionResult{Data: map[string]interface{}{"rows": []interface{}{}, "total_records": 3}, StatusCode: 200}, nil
+		}
+		return &types.ExecutionResult{Data: e.pages[page-1], StatusCode: 200}, nil
+	}
+	return &types.ExecutionResult{Data: e.data, StatusCode: 200}, nil
+}
+
+func testConnector() *types.ConnectorConfig {
+	return &types.ConnectorConfig{
+		ID: "comexstat",
+		Jobs: []types.JobConfig{{
+			Name:     "exportacao_mensal",
+			Endpoint: "exportacao_mes",
+			Schedule: "0 6 5 * *",
+			Timezone: "America/Sao_Paulo",
+			Params: map[string]string{
+				"ano": "{{ year (addMonths -1 .Now) }}",
+				"mes": "{{ month (addMonths -1 .Now) }}",
+			},
+			Target: types.JobTargetConfig{
+				Table:        "stg.exportacao",
+				Rows:         "rows",
+				Columns:      []string{"co_ano", "co_mes", "co_pais", "vl_fob"},
+				ConflictKeys: []string{"co_ano", "co_mes", "co_pais"},
+			},
+			Retry: types.JobRetryConfig{MaxAttempts: 3, Backoff: "1ms"},
+		}},
+	}
+}
+
+func testData() map[string]interface{} {
+	return map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{"co_ano": 2025, "co_mes": 2, "co_pais": "CN", "vl_fob": 1500.0, "price_per_kg": 3.0},
+			map[string]interface{}{"co_ano": 2025, "co_mes": 2, "co_pais": "US", "vl_fob": 700.0},
+		},
+	}
+}
+
+func newTestScheduler(t *testing.T, executor Executor, store Store) (*Scheduler, *Job) {
+	t.Helper()
+	s, err := New(executor, store, []*types.ConnectorConfig{testConnector()}, "production")
+	require.NoError(t, err)
+	require.Len(t, s.Jobs(), 1)
+	return s, s.Jobs()[0]
+}
+
+func TestNewJob_Validation(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*types.JobConfig)
+		errContains string
+	}{
+		{name: "Valid job", modify: func(c *types.JobConfig) {}},
+		{name: "Missing endpoint", modify: func(c *types.JobConfig) { c.Endpoint = "" }, errContains: "endpoint is required"},
+		{name: "Invalid schedule", modify: func(c *types.JobConfig) { c.Schedule = "0 6 * *" }, errContains: "invalid schedule"},
+		{name: "Invalid timezone", modify: func(c *types.JobConfig) { c.Timezone = "Mars/Olympus" }, errContains: "invalid timezone"},
+		{name: "Invalid param template", modify: func(c *types.JobConfig) { c.Params["ano"] = "{{ year" }, errContains: "param ano"},
+		{name: "Invalid backoff", modify: func(c *types.JobConfig) { c.Retry.Backoff = "soon" }, errContains: "invalid retry backoff"},
+		{name: "Invalid table", modify: func(c *types.JobConfig) { c.Target.Table = "stg.exportacao; DROP TABLE x" }, errContains: "invalid target.table"},
+		{name: "Invalid column", modify: func(c *types.JobConfig) { c.Target.Columns = []string{"co_ano", "VL FOB"} }, errContains: "invalid target column"},
+		{name: "Duplicate column", modify: func(c *types.JobConfig) { c.Target.Columns = []string{"co_ano", "co_ano"} }, errContains: "duplicate target column"},
+		{name: "Conflict key outside columns", modify: func(c *types.JobConfig) { c.Target.ConflictKeys = []string{"co_ncm"} }, errContains: "is not in target.columns"},
+		{name: "Missing conflict keys", modify: func(c *types.JobConfig) { c.Target.ConflictKeys = nil }, errContains: "conflict_keys is required"},
+		{name: "Sum and replace", modify: func(c *types.JobConfig) {
+			c.Target.Sum = []string{"vl_fob"}
+			c.Target.Replace = []string{"co_ano", "co_mes"}
+		}},
+		{name: "Sum on conflict key", modify: func(c *types.JobConfig) { c.Target.Sum = []string{"co_pais", "vl_fob"} }, errContains: "sum column co_pais"},
+		{name: "Column outside key and sum", modify: func(c *types.JobConfig) {
+			c.Target.Columns = append(c.Target.Columns, "kg_liquido")
+			c.Target.Sum = []string{"vl_fob"}
+		}, errContains: "column kg_liquido must be in conflict_keys or sum"},
+		{name: "Replace outside conflict keys", modify: func(c *types.JobConfig) { c.Target.Replace = []string{"vl_fob"} }, errContains: "replace column vl_fob"},
+		{name: "Pages", modify: func(c *types.JobConfig) { c.Pages = &types.JobPagesConfig{Param: "page", Total: "total_records"} }},
+		{name: "Pages without param", modify: func(c *types.JobConfig) { c.Pages = &types.JobPagesConfig{Total: "total_records"} }, errContains: "pages.param is required"},
+		{name: "Pages without total", modify: func(c *types.JobConfig) { c.Pages = &types.JobPagesConfig{Param: "page"} }, errContains: "invalid pages.total"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConnector().Jobs[0]
+			tt.modify(&config)
+
+			job, err := NewJob("comexstat", config)
+
+			if tt.errContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "comexstat.exportacao_mensal", job.ID)
+			}
+		})
+	}
+}
+
+func TestJob_Next_UsesTimezone(t *testing.T) {
+	job, err := NewJob("comexstat", testConnector().Jobs[0])
+	require.NoError(t, err)
+
+	next := job.Next(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	// 05/03 06:00 em São Paulo (UTC-3)
+	assert.Equal(t, time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func TestRunJob_Success(t *testing.T) {
+	store := newFakeStore()
+	executor := &fakeExecutor{data: testData()}
+	s, job := newTestScheduler(t, executor, store)
+
+	scheduledFor := time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC)
+	run, err := s.RunJob(context.Background(), job, scheduledFor)
+	require.NoError(t, err)
+	require.NotNil(t, run)
+
+	assert.Equal(t, RunSuccess, run.Status)
+	assert.Equal(t, 1, run.Attempts)
+	assert.Equal(t, int64(2), run.RowsUpserted)
+	assert.Equal(t, map[string]interface{}{"ano": 2025, "mes": 2}, run.Params)
+
+	require.Len(t, executor.calls, 1)
+	assert.Equal(t, "exportacao_mes", executor.calls[0].EndpointName)
+	assert.Equal(t, "production", executor.calls[0].Environment)
+
+	// Apenas as colunas configuradas são gravadas
+	assert.Equal(t, map[string]interface{}{"co_ano": 2025, "co_mes": 2, "co_pais": "CN", "vl_fob": 1500.0}, store.upserted[0])
+	assert.Equal(t, RunSuccess, store.runs[0].Status)
+}
+
+func pagedConnector() *types.ConnectorConfig {
+	connector := testConnector()
+	connector.Jobs[0].Pages = &types.JobPagesConfig{Param: "page", Total: "total_records"}
+	return connector
+}
+
+func testPage(total int, countries ...string) map[string]interface{} {
+	rows := make([]interface{}, len(countries))
+	for i, country := range countries {
+		rows[i] = map[string]interface{}{"co_ano": 2025, "co_mes": 2, "co_pais": country, "vl_fob": 100.0}
+	}
+	return map[string]interface{}{"rows": rows, "total_records": float64(total)}
+}
+
+func TestRunJob_Pages(t *testing.T) {
+	tests := []struct {
+		name        string
+		pages       []map[string]interface{}
+		wantCalls   int
+		wantRows    int
+		errContains string
+	}{
+		{name: "Single page", pages: []map[string]interface{}{testPage(2, "CN", "US")}, wantCalls: 1, wantRows: 2},
+		{name: "All pages", pages: []map[string]interface{}{testPage(3, "CN", "US"), testPage(3, "AR")}, wantCalls: 2, wantRows: 3},
+		{name: "Empty month", pages: []map[string]interface{}{testPage(0)}, wantCalls: 1},
+		{name: "Incomplete", pages: []map[string]interface{}{testPage(3, "CN", "US")}, wantCalls: 2, errContains: "incomplete result: 2 of 3 rows"},
+		{name: "Page param ignored", pages: []map[string]interface{}{testPage(4, "CN", "US"), testPage(4, "CN", "US")}, wantCalls: 2, errContains: "page 2 repeats page 1"},
+		{name: "Total missing", pages: []map[string]interface{}{{"rows": []interface{}{}}}, wantCalls: 1, errContains: "total field total_records not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := pagedConnector()
+			connector.Jobs[0].Retry.MaxAttempts = 1
+			store := newFakeStore()
+			executor := &fakeExecutor{pages: tt.pages}
+			s, err := New(executor, store, []*types.ConnectorConfig{connector}, "production")
+			require.NoError(t, err)
+
+			run, err := s.RunJob(context.Background(), s.Jobs()[0], time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC))
+
+			require.Len(t, executor.calls, tt.wantCalls)
+			for i, call := range executor.calls {
+				assert.Equal(t, i+1, call.Params["page"])
+				assert.Equal(t, 2025, call.Params["ano"])
+			}
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				assert.Empty(t, store.upserted, "nothing is written for a partial result")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, RunSuccess, run.Status)
+			assert.Len(t, store.upserted, tt.wantRows)
+		})
+	}
+}
+
+func TestTickSchedule_Due(t *testing.T) {
+	job, err := NewJob("comexstat", testConnector().Jobs[0])
+	require.NoError(t, err)
+	ticks := &tickSchedule{Schedule: job.schedule}
+
+	// O cron agenda o tick de 05/03 06:00 (São Paulo)
+	tick := ticks.Next(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC), tick.UTC())
+
+	_, ok := ticks.due(tick.Add(-time.Second))
+	assert.False(t, ok, "tick not reached yet")
+
+	// Acordou 3 minutos atrasado: a execução continua identificada pelo tick
+	ticks.Next(tick.Add(3 * time.Minute))
+	got, ok := ticks.due(tick.Add(3 * time.Minute))
+	require.True(t, ok)
+	assert.True(t, tick.Equal(got))
+
+	_, ok = ticks.due(tick.Add(3 * time.Minute))
+	assert.False(t, ok, "next tick (April) is not due")
+}
+
+func TestRunJob_RetriesThenSucceeds(t *testing.T) {
+	store := newFakeStore()
+	executor := &fakeExecutor{failures: 2, data: testData()}
+	s, job := newTestScheduler(t, executor, store)
+
+	run, err := s.RunJob(context.Background(), job, time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, RunSuccess, run.Status)
+	assert.Equal(t, 3, run.Attempts)
+	assert.Len(t, executor.calls, 3)
+}
+
+func TestRunJob_FailsAfterMaxAttempts(t *testing.T) {
+	store := newFakeStore()
+	executor := &fakeExecutor{failures: 10}
+	s, job := newTestScheduler(t, executor, store)
+
+	run, err := s.RunJob(context.Background(), job, time.Now())
+	assert.Error(t, err)
+	require.NotNil(t, run)
+
+	assert.Equal(t, RunFailed, run.Status)
+	assert.Equal(t, 3, run.Attempts)
+	assert.Contains(t, store.runs[0].Error, "upstream unavailable")
+	assert.NotNil(t, store.runs[0].FinishedAt)
+}
+
+func TestRunJob_RowsFieldMissing(t *testing.T) {
+	store := newFakeStore()
+	executor := &fakeExecutor{data: map[string]interface{}{"total_records": 0}}
+	s, job := newTestScheduler(t, executor, store)
+
+	run, err := s.RunJob(context.Background(), job, time.Now())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "rows field rows not found")
+	assert.Equal(t, RunFailed, run.Status)
+}
+
+func TestRunJob_LockedByAnotherReplica(t *testing.T) {
+	store := newFakeStore()
+	executor := &fakeExecutor{data: testData()}
+	s, job := newTestScheduler(t, executor, store)
+
+	unlock, ok, err := store.TryLock(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	defer unlock()
+
+	run, err := s.RunJob(context.Background(), job, time.Now())
+	assert.NoError(t, err)
+	assert.Nil(t, run)
+	assert.Empty(t, executor.calls)
+}
+
+func TestRunJob_AlreadyRunForSchedule(t *testing.T) {
+	store := newFakeStore()
+	executor := &fakeExecutor{data: testData()}
+	s, job := newTestScheduler(t, executor, store)
+
+	scheduledFor := time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC)
+	_, err := s.RunJob(context.Background(), job, scheduledFor)
+	require.NoError(t, err)
+
+	run, err := s.RunJob(context.Background(), job, scheduledFor)
+	assert.NoError(t, err)
+	assert.Nil(t, run)
+	assert.Len(t, executor.calls, 1)
+	assert.Len(t, store.runs, 1)
+}
+
+func TestRunJob_CancelStopsRetries(t *testing.T) {
+	store := newFakeStore()
+	executor := &fakeExecutor{failures: 10}
+	connector := testConnector()
+	connector.Jobs[0].Retry.Backoff = "1h"
+	s, err := New(executor, store, []*types.ConnectorConfig{connector}, "production")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	run, err := s.RunJob(ctx, s.Jobs()[0], time.Now())
+	assert.Error(t, err)
+	assert.Equal(t, 1, run.Attempts)
+	assert.Equal(t, RunFailed, store.runs[0].Status)
+}
+
+func TestStatus(t *testing.T) {
+	store := newFakeStore()
+	executor := &fakeExecutor{data: testData()}
+	s, job := newTestScheduler(t, executor, store)
+	s.now = func() time.Time { return time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC) }
+
+	_, err := s.RunJob(context.Background(), job, time.Date(2025, 2, 5, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	s.Start()
+	defer s.Stop(context.Background())
+
+	statuses, err := s.Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+
+	status := statuses[0]
+	assert.Equal(t, "comexstat.exportacao_mensal", status.ID)
+	assert.Equal(t, "stg.exportacao", status.Target)
+	assert.Equal(t, "America/Sao_Paulo", status.Timezone)
+	assert.False(t, status.RunningHere)
+	require.NotNil(t, status.NextRun)
+	assert.Equal(t, time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC), status.NextRun.UTC())
+	require.NotNil(t, status.LastRun)
+	assert.Equal(t, RunSuccess, status.LastRun.Status)
+}
+
+func TestNew_InvalidJob(t *testing.T) {
+	connector := testConnector()
+	connector.Jobs[0].Schedule = "never"
+
+	_, err := New(&fakeExecutor{}, newFakeStore(), []*types.ConnectorConfig{connector}, "production")
+	assert.Error(t, err)
+	assert.True(t, errors.Unwrap(err) != nil)
+	assert.Contains(t, err.Error(), "job comexstat.exportacao_mensal")
+}
@@ -0,0 +1,266 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Status de uma execução
+const (
+	RunRunning = "running"
+	RunSuccess = "success"
+	RunFailed  = "failed"
+)
+
+// Run execução de um job (tabela gateway_job_runs)
+type Run struct {
+	ID           int64                  `json:"id"`
+	JobID        string                 `json:"job_id"`
+	ScheduledFor time.Time              `json:"scheduled_for"`
+	StartedAt    time.Time              `json:"started_at"`
+	FinishedAt   *time.Time             `json:"finished_at,omitempty"`
+	Status       string                 `json:"status"`
+	Attempts     int                    `json:"attempts"`
+	Params       map[string]interface{} `json:"params,omitempty"`
+	RowsUpserted int64                  `json:"rows_upserted"`
+	Error        string                 `json:"error,omitempty"`
+	Replica      string                 `json:"replica,omitempty"`
+}
+
+// ErrAlreadyRun o horário agendado já foi executado (por esta ou outra réplica)
+var ErrAlreadyRun = errors.New("scheduled run already exists")
+
+// Store persistência de locks, histórico e carga dos jobs
+type Store interface {
+	// TryLock obtém o lock exclusivo do job entre réplicas; ok=false se outra réplica o detém
+	TryLock(ctx context.Context, jobID string) (unlock func(), ok bool, err error)
+	// StartRun registra o início de uma execução (ErrAlreadyRun se o horário já foi executado)
+	StartRun(ctx context.Context, run *Run) error
+	// FinishRun registra o resultado de uma execução
+	FinishRun(ctx context.Context, run *Run) error
+	// LastRuns última execução de cada job
+	LastRuns(ctx context.Context) (map[string]*Run, error)
+	// Upsert grava as linhas na tabela de destino
+	Upsert(ctx context.Context, target *types.JobTargetConfig, rows []map[string]interface{}) (int64, error)
+}
+
+// PostgresStore store em Postgres (advisory locks + gateway_job_runs)
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresStore cria o store sobre um pool de conexões
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+// TryLock usa pg_try_advisory_lock em uma conexão dedicada (liberada no unlock)
+func (s *PostgresStore) TryLock(ctx context.Context, jobID string) (func(), bool, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtextextended($1, 0))", jobID).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("failed to lock job: %w", err)
+	}
+	if !locked {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		conn.Exec(context.Background(), "SELECT pg_advisory_unlock(hashtextextended($1, 0))", jobID)
+		conn.Release()
+	}
+	return unlock, true, nil
+}
+
+// StartRun insere a execução; o índice único (job_id, scheduled_for) impede execução dupla
+func (s *PostgresStore) StartRun(ctx context.Context, run *Run) error {
+	params, err := json.Marshal(run.Params)
+	if err != nil {
+		return fmt.Errorf("failed to encode params: %w", err)
+	}
+
+	err = s.pool.QueryRow(ctx, `
+		INSERT INTO gateway_job_runs (job_id, scheduled_for, started_at, status, attempts, params, replica)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (job_id, scheduled_for) DO NOTHING
+		RETURNING id`,
+		run.JobID, run.ScheduledFor, run.StartedAt, run.Status, run.Attempts, params, run.Replica,
+	).Scan(&run.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAlreadyRun
+	}
+	return err
+}
+
+// FinishRun atualiza status, tentativas, linhas e erro
+func (s *PostgresStore) FinishRun(ctx context.Context, run *Run) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE gateway_job_runs
+		SET finished_at = $2, status = $3, attempts = $4, rows_upserted = $5, error = NULLIF($6, '')
+		WHERE id = $1`,
+		run.ID, run.FinishedAt, run.Status, run.Attempts, run.RowsUpserted, run.Error,
+	)
+	return err
+}
+
+// LastRuns última execução de cada job
+func (s *PostgresStore) LastRuns(ctx context.Context) (map[string]*Run, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT DISTINCT ON (job_id)
+			id, job_id, scheduled_for, started_at, finished_at, status, attempts, params,
+			rows_upserted, COALESCE(error, ''), COALESCE(replica, '')
+		FROM gateway_job_runs
+		ORDER BY job_id, started_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make(map[string]*Run)
+	for rows.Next() {
+		var run Run
+		var params []byte
+		if err := rows.Scan(&run.ID, &run.JobID, &run.ScheduledFor, &run.StartedAt, &run.FinishedAt, &run.Status,
+			&run.Attempts, &params, &run.RowsUpserted, &run.Error, &run.Replica); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(params, &run.Params)
+		runs[run.JobID] = &run
+	}
+	return runs, rows.Err()
+}
+
+// Upsert grava as linhas em uma transação
+func (s *PostgresStore) Upsert(ctx context.Context, target *types.JobTargetConfig, rows []map[string]interface{}) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	payload, err := json.Marshal(projectRows(rows, target.Columns))
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode rows: %w", err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// replace: o recorte presente nas linhas (ex: ano+mês) é substituído por inteiro
+	if len(target.Replace) > 0 {
+		if _, err := tx.Exec(ctx, ReplaceSQL(target), payload); err != nil {
+			return 0, fmt.Errorf("replace in %s failed: %w", target.Table, err)
+		}
+	}
+
+	tag, err := tx.Exec(ctx, UpsertSQL(target), payload)
+	if err != nil {
+		return 0, fmt.Errorf("upsert into %s failed: %w", target.Table, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// UpsertSQL monta o upsert: as linhas chegam como JSON ($1) e o Postgres converte para os tipos das colunas.
+// Com sum, as linhas da mesma chave são agrupadas e as métricas somadas antes do upsert.
+func UpsertSQL(target *types.JobTargetConfig) string {
+	table := quoteTable(target.Table)
+	columns := quoteAll(target.Columns)
+
+	keys := make(map[string]bool, len(target.ConflictKeys))
+	for _, key := range target.ConflictKeys {
+		keys[key] = true
+	}
+
+	var updates []string
+	for _, column := range target.Columns {
+		if !keys[column] {
+			quoted := pgx.Identifier{column}.Sanitize()
+			updates = append(updates, quoted+" = EXCLUDED."+quoted)
+		}
+	}
+
+	conflict := "DO NOTHING"
+	if len(updates) > 0 {
+		conflict = "DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+
+	if len(target.Sum) == 0 {
+		return fmt.Sprintf(
+			"INSERT INTO %s (%s) SELECT %s FROM jsonb_populate_recordset(NULL::%s, $1::jsonb) ON CONFLICT (%s) %s",
+			table, columns, columns, table, quoteAll(target.ConflictKeys), conflict,
+		)
+	}
+
+	sums := make(map[string]bool, len(target.Sum))
+	for _, column := range target.Sum {
+		sums[column] = true
+	}
+	selects := make([]string, len(target.Columns))
+	for i, column := range target.Columns {
+		quoted := pgx.Identifier{column}.Sanitize()
+		selects[i] = quoted
+		if sums[column] {
+			selects[i] = "sum(" + quoted + ") AS " + quoted
+		}
+	}
+
+	// GROUP BY trata NULLs como iguais (mesma regra de um índice NULLS NOT DISTINCT)
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) SELECT %s FROM jsonb_populate_recordset(NULL::%s, $1::jsonb) GROUP BY %s ON CONFLICT (%s) %s",
+		table, columns, strings.Join(selects, ", "), table, quoteAll(target.ConflictKeys), quoteAll(target.ConflictKeys), conflict,
+	)
+}
+
+// ReplaceSQL apaga as linhas da tabela cujo recorte (colunas de replace) aparece no payload ($1)
+func ReplaceSQL(target *types.JobTargetConfig) string {
+	table := quoteTable(target.Table)
+	columns := quoteAll(target.Replace)
+
+	return fmt.Sprintf(
+		"DELETE FROM %s WHERE (%s) IN (SELECT DISTINCT %s FROM jsonb_populate_recordset(NULL::%s, $1::jsonb))",
+		table, columns, columns, table,
+	)
+}
+
+// projectRows mantém apenas as colunas configuradas
+func projectRows(rows []map[string]interface{}, columns []string) []map[string]interface{} {
+	projected := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		projected[i] = make(map[string]interface{}, len(columns))
+		for _, column := range columns {
+			projected[i][column] = row[column]
+		}
+	}
+	return projected
+}
+
+func quoteTable(table string) string {
+	return pgx.Identifier(strings.Split(table, ".")).Sanitize()
+}
+
+func quoteAll(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = pgx.Identifier{column}.Sanitize()
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpsertSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		target types.JobTargetConfig
+		want   string
+	}{
+		{
+			name: "Update non-key columns",
+			target: types.JobTargetConfig{
+				Table:        "stg.exportacao",
+				Columns:      []string{"co_ano", "co_pais", "vl_fob"},
+				ConflictKeys: []string{"co_ano", "co_pais"},
+			},
+			want: `INSERT INTO "stg"."exportacao" ("co_ano", "co_pais", "vl_fob") ` +
+				`SELECT "co_ano", "co_pais", "vl_fob" FROM jsonb_populate_recordset(NULL::"stg"."exportacao", $1::jsonb) ` +
+				`ON CONFLICT ("co_ano", "co_pais") DO UPDATE SET "vl_fob" = EXCLUDED."vl_fob"`,
+		},
+		{
+			name: "Only key columns",
+			target: types.JobTargetConfig{
+				Table:        "paises",
+				Columns:      []string{"co_pais"},
+				ConflictKeys: []string{"co_pais"},
+			},
+			want: `INSERT INTO "paises" ("co_pais") SELECT "co_pais" FROM jsonb_populate_recordset(NULL::"paises", $1::jsonb) ` +
+				`ON CONFLICT ("co_pais") DO NOTHING`,
+		},
+		{
+			name: "Sum metrics per key",
+			target: types.JobTargetConfig{
+				Table:        "stg.exportacao_gateway",
+				Columns:      []string{"co_ano", "co_pais", "vl_fob"},
+				ConflictKeys: []string{"co_ano", "co_pais"},
+				Sum:          []string{"vl_fob"},
+			},
+			want: `INSERT INTO "stg"."exportacao_gateway" ("co_ano", "co_pais", "vl_fob") ` +
+				`SELECT "co_ano", "co_pais", sum("vl_fob") AS "vl_fob" FROM jsonb_populate_recordset(NULL::"stg"."exportacao_gateway", $1::jsonb) ` +
+				`GROUP BY "co_ano", "co_pais" ON CONFLICT ("co_ano", "co_pais") DO UPDATE SET "vl_fob" = EXCLUDED."vl_fob"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UpsertSQL(&tt.target))
+		})
+	}
+}
+
+func TestReplaceSQL(t *testing.T) {
+	target := types.JobTargetConfig{
+		Table:        "stg.exportacao_gateway",
+		Columns:      []string{"co_ano", "co_mes", "co_pais", "vl_fob"},
+		ConflictKeys: []string{"co_ano", "co_mes", "co_pais"},
+		Replace:      []string{"co_ano", "co_mes"},
+	}
+
+	assert.Equal(t,
+		`DELETE FROM "stg"."exportacao_gateway" WHERE ("co_ano", "co_mes") IN `+
+			`(SELECT DISTINCT "co_ano", "co_mes" FROM jsonb_populate_recordset(NULL::"stg"."exportacao_gateway", $1::jsonb))`,
+		ReplaceSQL(&target))
+}
+
+func TestProjectRows(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"co_ano": 2024, "vl_fob": 10.5, "price_per_kg": 2.1},
+		{"co_ano": 2024},
+	}
+
+	got := projectRows(rows, []string{"co_ano", "vl_fob"})
+
+	assert.Equal(t, []map[string]interface{}{
+		{"co_ano": 2024, "vl_fob": 10.5},
+		{"co_ano": 2024, "vl_fob": nil},
+	}, got)
+}
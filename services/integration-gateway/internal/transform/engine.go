@@ -42,24 +42,30 @@ func (e *Engine) Transform(data interface{}, config *types.ResponseConfig) (map[
 	}
 
 	// Aplica mapeamentos JSONPath
-	for field, jsonPath := range config.Mapping {
-		value, err := e.extractValue(jsonData, jsonPath)
+	if err := e.applyMapping(jsonData, config.Mapping, result); err != nil {
+		return nil, err
+	}
+
+	// Aplica mapeamentos por elemento de array
+	for i := range config.Arrays {
+		array := &config.Arrays[i]
+		items, err := e.mapArray(jsonData, array)
 		if err != nil {
-			// Log warning mas não falha (campo pode ser opcional)
-			continue
+			return nil, fmt.Errorf("failed to map array %s: %w", array.Field, err)
+		}
+		if err := SetField(result, array.Field, items); err != nil {
+			return nil, err
 		}
-		result[field] = value
 	}
 
 	// Aplica transformações
-	for _, transform := range config.Transforms {
-		if value, exists := result[transform.Field]; exists {
-			transformed, err := e.applyTransform(value, &transform)
-			if err != nil {
-				return nil, fmt.Errorf("failed to transform field %s: %w", transform.Field, err)
-			}
-			result[transform.Field] = transformed
-		}
+	if err := e.applyTransforms(result, config.Transforms); err != nil {
+		return nil, err
+	}
+
+	// Calcula campos derivados (após transformações)
+	if err := e.applyExpressions(result, config.Expressions); err != nil {
+		return nil, err
 	}
 
 	return result, nil
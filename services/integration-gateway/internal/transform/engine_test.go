@@ -284,3 +284,247 @@ func TestEngine_ExtractArray(t *testing.T) {
 	assert.Equal(t, "Item 1", items[0])
 	assert.Equal(t, "Item 2", items[1])
 }
+
+func TestEngine_Transform_NestedFields(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterPlugin("to_upper", &ToUpperPlugin{})
+
+	jsonData := map[string]interface{}{
+		"pais":  "br",
+		"nome":  "Brasil",
+		"total": 10,
+	}
+
+	config := &types.ResponseConfig{
+		Mapping: map[string]string{
+			"country.code": "$.pais",
+			"country.name": "$.nome",
+			"total":        "$.total",
+		},
+		Transforms: []types.TransformConfig{
+			{Field: "country.code", Operation: "to_upper"},
+		},
+	}
+
+	result, err := engine.Transform(jsonData, config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"code": "BR", "name": "Brasil"}, result["country"])
+	assert.Equal(t, 10, result["total"])
+}
+
+func TestEngine_Transform_ArrayMapping(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterPlugin("trim", &TrimPlugin{})
+
+	jsonString := `{
+		"total": 2,
+		"data": [
+			{"coAno": 2024, "coMes": 11, "coPais": "CN", "coNcm": " 17011400 ", "vlFob": 1500.5},
+			{"coAno": 2024, "coMes": 10, "coPais": "IN", "coNcm": "17011400", "vlFob": 800}
+		]
+	}`
+
+	config := &types.ResponseConfig{
+		Mapping: map[string]string{
+			"total_records": "$.total",
+		},
+		Arrays: []types.ArrayMappingConfig{
+			{
+				Field: "rows",
+				Path:  "$.data[*]",
+				Mapping: map[string]string{
+					"co_ano":       "$.coAno",
+					"co_mes":       "$.coMes",
+					"co_ncm":       "$.coNcm",
+					"vl_fob":       "$.vlFob",
+					"country.code": "$.coPais",
+				},
+				Transforms: []types.TransformConfig{
+					{Field: "co_ncm", Operation: "trim"},
+				},
+			},
+		},
+	}
+
+	result, err := engine.Transform(jsonString, config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result["total_records"])
+
+	rows, ok := result["rows"].([]interface{})
+	assert.True(t, ok)
+	assert.Len(t, rows, 2)
+
+	first := rows[0].(map[string]interface{})
+	assert.Equal(t, int64(2024), first["co_ano"])
+	assert.Equal(t, "17011400", first["co_ncm"])
+	assert.Equal(t, 1500.5, first["vl_fob"])
+	assert.Equal(t, map[string]interface{}{"code": "CN"}, first["country"])
+
+	second := rows[1].(map[string]interface{})
+	assert.Equal(t, int64(10), second["co_mes"])
+}
+
+func TestEngine_Transform_ArrayMappingWithoutWildcard(t *testing.T) {
+	engine := NewEngine()
+
+	jsonData := map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"id": "1"},
+		},
+	}
+
+	config := &types.ResponseConfig{
+		Arrays: []types.ArrayMappingConfig{
+			{Field: "items", Path: "$.data", Mapping: map[string]string{"codigo": "$.id"}},
+			{Field: "empty", Path: "$.missing[*]", Mapping: map[string]string{"codigo": "$.id"}},
+		},
+	}
+
+	result, err := engine.Transform(jsonData, config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"codigo": "1"}}, result["items"])
+	assert.Equal(t, []interface{}{}, result["empty"])
+}
+
+func TestEngine_Transform_ArrayRequiredFields(t *testing.T) {
+	engine := NewEngine()
+
+	jsonData := map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"id": "1", "nome": "A"},
+			map[string]interface{}{"nome": "B"},
+			map[string]interface{}{"id": nil, "nome": "C"},
+		},
+	}
+
+	array := types.ArrayMappingConfig{
+		Field:    "items",
+		Path:     "$.data[*]",
+		Mapping:  map[string]string{"id": "$.id", "nome": "$.nome"},
+		Required: []string{"id"},
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		skip := array
+		skip.OnMissing = OnMissingSkip
+
+		result, err := engine.Transform(jsonData, &types.ResponseConfig{Arrays: []types.ArrayMappingConfig{skip}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{map[string]interface{}{"id": "1", "nome": "A"}}, result["items"])
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		_, err := engine.Transform(jsonData, &types.ResponseConfig{Arrays: []types.ArrayMappingConfig{array}})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "items[1]: missing required fields: id")
+	})
+}
+
+func TestSetField_Conflict(t *testing.T) {
+	data := map[string]interface{}{"country": "BR"}
+
+	err := SetField(data, "country.code", "BR")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "field conflict")
+}
+
+func TestEngine_Transform_Expressions(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterPlugin("to_upper", &ToUpperPlugin{})
+
+	jsonString := `{
+		"pais": "cn",
+		"data": [
+			{"vlFob": 1500, "kgLiquido": 500},
+			{"vlFob": 800, "kgLiquido": 0}
+		]
+	}`
+
+	config := &types.ResponseConfig{
+		Mapping: map[string]string{
+			"pais": "$.pais",
+		},
+		Arrays: []types.ArrayMappingConfig{
+			{
+				Field:   "rows",
+				Path:    "$.data[*]",
+				Mapping: map[string]string{"vl_fob": "$.vlFob", "kg_liquido": "$.kgLiquido"},
+				Expressions: []types.ExpressionConfig{
+					{Field: "price_per_kg", Expression: "vl_fob / kg_liquido"},
+					{Field: "flags.high_value", Expression: "vl_fob > 1000"},
+				},
+			},
+		},
+		Transforms: []types.TransformConfig{
+			{Field: "pais", Operation: "to_upper"},
+		},
+		Expressions: []types.ExpressionConfig{
+			// Roda após transforms: enxerga "CN"
+			{Field: "label", Expression: `"Destino: " + pais`},
+			{Field: "total_rows", Expression: "len(rows)"},
+		},
+	}
+
+	result, err := engine.Transform(jsonString, config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Destino: CN", result["label"])
+	assert.Equal(t, 2, result["total_rows"])
+
+	rows := result["rows"].([]interface{})
+	first := rows[0].(map[string]interface{})
+	assert.Equal(t, 3.0, first["price_per_kg"])
+	assert.Equal(t, map[string]interface{}{"high_value": true}, first["flags"])
+
+	// Divisão por zero vira nil (JSON null)
+	second := rows[1].(map[string]interface{})
+	assert.Nil(t, second["price_per_kg"])
+	assert.Contains(t, second, "price_per_kg")
+}
+
+func TestEngine_Transform_ExpressionRuntimeError(t *testing.T) {
+	engine := NewEngine()
+
+	config := &types.ResponseConfig{
+		Mapping: map[string]string{"nome": "$.nome"},
+		Expressions: []types.ExpressionConfig{
+			{Field: "invalido", Expression: "nome * 2"},
+		},
+	}
+
+	_, err := engine.Transform(map[string]interface{}{"nome": "abc"}, config)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to evaluate expression for field invalido")
+}
+
+func TestCompileExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    bool
+	}{
+		{"arithmetic", "vl_fob / kg_liquido", false},
+		{"conditional", "kg_liquido > 0 ? vl_fob / kg_liquido : nil", false},
+		{"interpolation", `co_pais + "-" + string(co_ano)`, false},
+		{"syntax error", "vl_fob / ", true},
+		{"disabled builtin", "now()", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileExpression(tt.expression)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
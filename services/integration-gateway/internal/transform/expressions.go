@@ -0,0 +1,91 @@
+package transform
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+)
+
+// maxExpressionNodes limita o tamanho da AST de uma expressão
+const maxExpressionNodes = 500
+
+// programCache cache de expressões compiladas (chave: expressão)
+var programCache sync.Map
+
+// CompileExpression compila uma expressão de campo calculado
+//
+// As expressões rodam em sandbox (expr-lang): apenas operadores e funções
+// built-in puras, sem acesso a I/O. Variáveis são os campos já mapeados;
+// campos ausentes avaliam como nil.
+func CompileExpression(expression string) (*vm.Program, error) {
+	if cached, ok := programCache.Load(expression); ok {
+		return cached.(*vm.Program), nil
+	}
+
+	program, err := expr.Compile(expression,
+		expr.AllowUndefinedVariables(),
+		expr.MaxNodes(maxExpressionNodes),
+		expr.DisableBuiltin("now"),
+		expr.DisableBuiltin("date"),
+		expr.DisableBuiltin("timezone"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", expression, err)
+	}
+
+	// Campos mapeados nunca são funções: qualquer chamada fora dos built-ins falharia em runtime
+	node := program.Node()
+	finder := &callFinder{}
+	ast.Walk(&node, finder)
+	if finder.name != "" {
+		return nil, fmt.Errorf("invalid expression %q: unknown function %s", expression, finder.name)
+	}
+
+	programCache.Store(expression, program)
+	return program, nil
+}
+
+// callFinder localiza chamadas a funções não built-in na AST
+type callFinder struct {
+	name string
+}
+
+func (f *callFinder) Visit(node *ast.Node) {
+	if call, ok := (*node).(*ast.CallNode); ok && f.name == "" {
+		f.name = call.Callee.String()
+	}
+}
+
+// applyExpressions calcula campos a partir dos valores de target, em ordem
+// (cada expressão enxerga os campos calculados pelas anteriores)
+func (e *Engine) applyExpressions(target map[string]interface{}, expressions []types.ExpressionConfig) error {
+	for _, expression := range expressions {
+		program, err := CompileExpression(expression.Expression)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", expression.Field, err)
+		}
+
+		value, err := expr.Run(program, target)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate expression for field %s: %w", expression.Field, err)
+		}
+
+		if err := SetField(target, expression.Field, normalizeNumber(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeNumber converte NaN/Inf (ex: divisão por zero) em nil, que é serializável em JSON
+func normalizeNumber(value interface{}) interface{} {
+	if f, ok := value.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return nil
+	}
+	return value
+}
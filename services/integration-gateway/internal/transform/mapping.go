@@ -0,0 +1,183 @@
+package transform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/ohler55/ojg/jp"
+)
+
+// Políticas para elementos de array sem campos obrigatórios
+const (
+	OnMissingFail = "fail"
+	OnMissingSkip = "skip"
+)
+
+// applyMapping aplica mapeamentos JSONPath de data em target
+// Campos não encontrados são ignorados (podem ser opcionais); a ordem é a dos nomes de campo,
+// para o resultado não depender da iteração do map (x e x.y são rejeitados na carga do connector)
+func (e *Engine) applyMapping(data interface{}, mapping map[string]string, target map[string]interface{}) error {
+	for _, field := range SortedFields(mapping) {
+		value, err := e.extractValue(data, mapping[field])
+		if err != nil {
+			continue
+		}
+		if err := SetField(target, field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SortedFields nomes de campo do mapping em ordem alfabética
+func SortedFields(mapping map[string]string) []string {
+	fields := make([]string, 0, len(mapping))
+	for field := range mapping {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// applyTransforms aplica transformações nos campos existentes de target
+func (e *Engine) applyTransforms(target map[string]interface{}, transforms []types.TransformConfig) error {
+	for i := range transforms {
+		transform := &transforms[i]
+		value, exists := GetField(target, transform.Field)
+		if !exists {
+			continue
+		}
+
+		transformed, err := e.applyTransform(value, transform)
+		if err != nil {
+			return fmt.Errorf("failed to transform field %s: %w", transform.Field, err)
+		}
+		if err := SetField(target, transform.Field, transformed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapArray itera os elementos de config.Path e constrói um objeto por elemento
+func (e *Engine) mapArray(data interface{}, config *types.ArrayMappingConfig) ([]interface{}, error) {
+	path, err := jp.ParseString(config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %s: %w", config.Path, err)
+	}
+
+	elements := path.Get(data)
+
+	// $.data (sem wildcard) retorna o próprio array como único resultado
+	if len(elements) == 1 && !endsWithWildcard(path) {
+		if arr, ok := elements[0].([]interface{}); ok {
+			elements = arr
+		}
+	}
+
+	items := make([]interface{}, 0, len(elements))
+	for i, element := range elements {
+		item := make(map[string]interface{})
+		if err := e.applyMapping(element, config.Mapping, item); err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", config.Field, i, err)
+		}
+
+		if missing := missingFields(item, config.Required); len(missing) > 0 {
+			if config.OnMissing == OnMissingSkip {
+				continue
+			}
+			return nil, fmt.Errorf("%s[%d]: missing required fields: %s",
+				config.Field, i, strings.Join(missing, ", "))
+		}
+
+		if err := e.applyTransforms(item, config.Transforms); err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", config.Field, i, err)
+		}
+
+		if err := e.applyExpressions(item, config.Expressions); err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", config.Field, i, err)
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// endsWithWildcard verifica se o último fragmento do JSONPath é [*]
+func endsWithWildcard(path jp.Expr) bool {
+	if len(path) == 0 {
+		return false
+	}
+	_, ok := path[len(path)-1].(jp.Wildcard)
+	return ok
+}
+
+// missingFields retorna os campos obrigatórios ausentes (ou nulos) em item
+func missingFields(item map[string]interface{}, required []string) []string {
+	var missing []string
+	for _, field := range required {
+		if value, exists := GetField(item, field); !exists || value == nil {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// GetField obtém valor de um campo, aceitando caminho aninhado (country.code)
+func GetField(data map[string]interface{}, field string) (interface{}, bool) {
+	parts := strings.Split(field, ".")
+	current := data
+	for i, part := range parts {
+		value, exists := current[part]
+		if !exists {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+		next, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	return nil, false
+}
+
+// SetField define valor de um campo, criando objetos intermediários para caminhos aninhados
+func SetField(data map[string]interface{}, field string, value interface{}) error {
+	parts := strings.Split(field, ".")
+	current := data
+	for _, part := range parts[:len(parts)-1] {
+		existing, exists := current[part]
+		if !exists {
+			next := make(map[string]interface{})
+			current[part] = next
+			current = next
+			continue
+		}
+		next, ok := existing.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("field conflict: %s is not an object in %s", part, field)
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+	return nil
+}
+
+// ValidFieldPath verifica se o nome de campo de saída é válido (segmentos não vazios)
+func ValidFieldPath(field string) bool {
+	if field == "" {
+		return false
+	}
+	for _, part := range strings.Split(field, ".") {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
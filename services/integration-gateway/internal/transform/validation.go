@@ -0,0 +1,118 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// Modos de validação de resposta
+const (
+	ValidationWarn   = "warn"   // registra métrica/log e segue com o payload
+	ValidationStrict = "strict" // falha a requisição (502)
+)
+
+// Tipos de violação
+const (
+	ViolationSchema        = "schema"
+	ViolationRequiredField = "required_field"
+)
+
+// Violation violação de contrato da resposta de um connector
+type Violation struct {
+	Type     string `json:"type"`
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+// schemaCache cache de schemas compilados (chave: schema serializado)
+var schemaCache sync.Map
+
+// CompileSchema compila o JSON Schema de resposta de um endpoint
+func CompileSchema(schema map[string]interface{}) (*jsonschema.Schema, error) {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response schema: %w", err)
+	}
+
+	key := string(raw)
+	if cached, ok := schemaCache.Load(key); ok {
+		return cached.(*jsonschema.Schema), nil
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid response schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("response.json", doc); err != nil {
+		return nil, fmt.Errorf("invalid response schema: %w", err)
+	}
+
+	compiled, err := compiler.Compile("response.json")
+	if err != nil {
+		return nil, fmt.Errorf("invalid response schema: %w", err)
+	}
+
+	schemaCache.Store(key, compiled)
+	return compiled, nil
+}
+
+// ValidateSchema valida o payload upstream contra o schema configurado
+func (e *Engine) ValidateSchema(data interface{}, config *types.ResponseConfig) []Violation {
+	if config.Schema == nil {
+		return nil
+	}
+
+	schema, err := CompileSchema(config.Schema)
+	if err != nil {
+		return []Violation{{Type: ViolationSchema, Location: "", Message: err.Error()}}
+	}
+
+	jsonData, err := e.ensureJSONData(data)
+	if err != nil {
+		return []Violation{{Type: ViolationSchema, Location: "", Message: err.Error()}}
+	}
+
+	err = schema.Validate(jsonData)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []Violation{{Type: ViolationSchema, Location: "", Message: err.Error()}}
+	}
+
+	var violations []Violation
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		violations = append(violations, Violation{
+			Type:     ViolationSchema,
+			Location: unit.InstanceLocation,
+			Message:  unit.Error.String(),
+		})
+	}
+	return violations
+}
+
+// CheckRequiredFields verifica campos obrigatórios (ausentes ou nulos) na saída mapeada
+func CheckRequiredFields(result map[string]interface{}, config *types.ResponseConfig) []Violation {
+	var violations []Violation
+	for _, field := range missingFields(result, config.RequiredFields) {
+		violations = append(violations, Violation{
+			Type:     ViolationRequiredField,
+			Location: field,
+			Message:  fmt.Sprintf("required field %s is missing in mapped response", field),
+		})
+	}
+	return violations
+}
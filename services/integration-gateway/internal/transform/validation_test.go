@@ -0,0 +1,78 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// comexstatSchema schema mínimo de uma resposta ComexStat (como decodificado do YAML)
+var comexstatSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []interface{}{"data", "total"},
+	"properties": map[string]interface{}{
+		"total": map[string]interface{}{"type": "integer"},
+		"data": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":     "object",
+				"required": []interface{}{"co_ncm", "vl_fob"},
+			},
+		},
+	},
+}
+
+func TestCompileSchema(t *testing.T) {
+	_, err := CompileSchema(comexstatSchema)
+	assert.NoError(t, err)
+
+	_, err = CompileSchema(map[string]interface{}{"type": "not-a-type"})
+	assert.Error(t, err)
+}
+
+func TestEngine_ValidateSchema(t *testing.T) {
+	engine := NewEngine()
+	config := &types.ResponseConfig{Schema: comexstatSchema}
+
+	t.Run("valid payload", func(t *testing.T) {
+		violations := engine.ValidateSchema([]byte(`{"total": 1, "data": [{"co_ncm": "17011400", "vl_fob": 10}]}`), config)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("renamed upstream field", func(t *testing.T) {
+		violations := engine.ValidateSchema([]byte(`{"total": 1, "data": [{"coNcm": "17011400", "vl_fob": 10}]}`), config)
+
+		require.Len(t, violations, 1)
+		assert.Equal(t, ViolationSchema, violations[0].Type)
+		assert.Equal(t, "/data/0", violations[0].Location)
+		assert.Contains(t, violations[0].Message, "co_ncm")
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		violations := engine.ValidateSchema([]byte(`not json`), config)
+		require.Len(t, violations, 1)
+		assert.Equal(t, ViolationSchema, violations[0].Type)
+	})
+
+	t.Run("no schema configured", func(t *testing.T) {
+		assert.Nil(t, engine.ValidateSchema([]byte(`{}`), &types.ResponseConfig{}))
+	})
+}
+
+func TestCheckRequiredFields(t *testing.T) {
+	config := &types.ResponseConfig{RequiredFields: []string{"total_records", "country.code", "page"}}
+
+	result := map[string]interface{}{
+		"total_records": 10,
+		"country":       map[string]interface{}{"code": "BR"},
+		"page":          nil,
+	}
+
+	violations := CheckRequiredFields(result, config)
+
+	require.Len(t, violations, 1)
+	assert.Equal(t, ViolationRequiredField, violations[0].Type)
+	assert.Equal(t, "page", violations[0].Location)
+}
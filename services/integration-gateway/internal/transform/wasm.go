@@ -0,0 +1,198 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/observability"
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// Limites padrão de execução de plugins WASM
+const (
+	DefaultWASMMaxMemoryMB = 16
+	DefaultWASMTimeout     = 100 * time.Millisecond
+
+	wasmPageSize = 64 * 1024
+)
+
+// WASMPlugin plugin de transformação executado em WebAssembly (wazero, pure Go)
+//
+// ABI esperada do módulo:
+//   - exporta "memory"
+//   - exporta alloc(size i32) -> ptr i32
+//   - exporta transform(ptr i32, len i32) -> i64, com (ptr << 32 | len) da saída
+//
+// Entrada e saída são JSON: recebe {"value": ..., "params": {...}} e
+// retorna {"value": ...} ou {"error": "mensagem"}.
+//
+// Cada chamada instancia o módulo do zero (memória isolada entre requests).
+type WASMPlugin struct {
+	name     string
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	timeout  time.Duration
+}
+
+// wasmOutput saída JSON do módulo
+type wasmOutput struct {
+	Value interface{} `json:"value"`
+	Error string      `json:"error,omitempty"`
+}
+
+// NewWASMPlugin compila um módulo WASM com limites de memória e tempo
+func NewWASMPlugin(ctx context.Context, name string, module []byte, maxMemoryMB int, timeout time.Duration) (*WASMPlugin, error) {
+	if maxMemoryMB <= 0 {
+		maxMemoryMB = DefaultWASMMaxMemoryMB
+	}
+	if timeout <= 0 {
+		timeout = DefaultWASMTimeout
+	}
+
+	config := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(maxMemoryMB * 1024 * 1024 / wasmPageSize)).
+		WithCloseOnContextDone(true)
+	runtime := wazero.NewRuntimeWithConfig(ctx, config)
+
+	// WASI sem filesystem, env ou rede (necessário para módulos TinyGo/Rust)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to instantiate WASI for plugin %s: %w", name, err)
+	}
+
+	compiled, err := runtime.CompileModule(ctx, module)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to compile WASM plugin %s: %w", name, err)
+	}
+
+	for _, export := range []string{"alloc", "transform"} {
+		if _, exists := compiled.ExportedFunctions()[export]; !exists {
+			runtime.Close(ctx)
+			return nil, fmt.Errorf("WASM plugin %s does not export %s", name, export)
+		}
+	}
+	if _, exists := compiled.ExportedMemories()["memory"]; !exists {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("WASM plugin %s does not export memory", name)
+	}
+
+	return &WASMPlugin{
+		name:     name,
+		runtime:  runtime,
+		compiled: compiled,
+		timeout:  timeout,
+	}, nil
+}
+
+// Transform executa o módulo WASM com o valor e params serializados em JSON
+func (p *WASMPlugin) Transform(value interface{}, params map[string]interface{}) (interface{}, error) {
+	start := time.Now()
+	defer func() {
+		observability.RecordTransformPlugin(p.name, time.Since(start).Seconds())
+	}()
+
+	input, err := json.Marshal(map[string]interface{}{
+		"value":  value,
+		"params": params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode input for plugin %s: %w", p.name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	module, err := p.runtime.InstantiateModule(ctx, p.compiled,
+		wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate plugin %s: %w", p.name, err)
+	}
+	defer module.Close(context.Background())
+
+	results, err := module.ExportedFunction("alloc").Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s alloc failed: %w", p.name, err)
+	}
+	ptr := uint32(results[0])
+
+	if !module.Memory().Write(ptr, input) {
+		return nil, fmt.Errorf("plugin %s: input out of memory bounds", p.name)
+	}
+
+	results, err = module.ExportedFunction("transform").Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("plugin %s exceeded timeout of %s", p.name, p.timeout)
+		}
+		return nil, fmt.Errorf("plugin %s transform failed: %w", p.name, err)
+	}
+
+	outPtr, outLen := uint32(results[0]>>32), uint32(results[0])
+	raw, ok := module.Memory().Read(outPtr, outLen)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s: output out of memory bounds", p.name)
+	}
+
+	var output wasmOutput
+	if err := json.Unmarshal(raw, &output); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid JSON: %w", p.name, err)
+	}
+	if output.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", p.name, output.Error)
+	}
+
+	return output.Value, nil
+}
+
+// Close libera o runtime do plugin
+func (p *WASMPlugin) Close(ctx context.Context) error {
+	return p.runtime.Close(ctx)
+}
+
+// RegisterWASMPlugins carrega e registra os plugins WASM declarados pelos conectores
+// Caminhos relativos de módulo são resolvidos a partir de configDir
+func (e *Engine) RegisterWASMPlugins(ctx context.Context, configDir string, connectors []*types.ConnectorConfig) error {
+	owners := make(map[string]string)
+	for _, connector := range connectors {
+		for _, config := range connector.Integration.WASMPlugins {
+			// Versões do mesmo connector podem declarar o mesmo plugin (vale o primeiro)
+			if owners[config.Name] == connector.ID {
+				continue
+			}
+			if _, exists := e.plugins[config.Name]; exists {
+				return fmt.Errorf("plugin %s (connector %s) is already registered", config.Name, connector.ID)
+			}
+			owners[config.Name] = connector.ID
+
+			modulePath := config.Module
+			if !filepath.IsAbs(modulePath) {
+				modulePath = filepath.Join(configDir, modulePath)
+			}
+
+			module, err := os.ReadFile(modulePath)
+			if err != nil {
+				return fmt.Errorf("failed to read WASM plugin %s: %w", config.Name, err)
+			}
+
+			timeout, err := time.ParseDuration(config.Timeout)
+			if config.Timeout != "" && err != nil {
+				return fmt.Errorf("invalid timeout for WASM plugin %s: %w", config.Name, err)
+			}
+
+			plugin, err := NewWASMPlugin(ctx, config.Name, module, config.MaxMemoryMB, timeout)
+			if err != nil {
+				return err
+			}
+
+			e.RegisterPlugin(config.Name, plugin)
+		}
+	}
+	return nil
+}
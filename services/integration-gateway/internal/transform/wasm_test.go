@@ -0,0 +1,150 @@
+package transform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bgc/integration-gateway/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Corpos de função (wasm binário) usados nos módulos de teste
+var (
+	// alloc(size) -> 1024
+	allocBody = []byte{0x00, 0x41, 0x80, 0x08, 0x0b}
+
+	// transform(ptr, len) -> ptr<<32 | len (ecoa a entrada: {"value": ..., "params": ...})
+	echoBody = []byte{0x00, 0x20, 0x00, 0xad, 0x42, 0x20, 0x86, 0x20, 0x01, 0xad, 0x84, 0x0b}
+
+	// transform(ptr, len) -> loop infinito
+	loopBody = []byte{0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x42, 0x00, 0x0b}
+)
+
+// buildTestModule monta um módulo WASM mínimo com memory, alloc e transform
+func buildTestModule(transformBody []byte, memoryPages byte) []byte {
+	section := func(id byte, content []byte) []byte {
+		return append([]byte{id, byte(len(content))}, content...)
+	}
+	name := func(s string) []byte {
+		return append([]byte{byte(len(s))}, s...)
+	}
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+	// types: (i32)->i32, (i32,i32)->i64
+	module = append(module, section(0x01, []byte{
+		0x02,
+		0x60, 0x01, 0x7f, 0x01, 0x7f,
+		0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e,
+	})...)
+
+	// functions: alloc=type0, transform=type1
+	module = append(module, section(0x03, []byte{0x02, 0x00, 0x01})...)
+
+	// memory
+	module = append(module, section(0x05, []byte{0x01, 0x00, memoryPages})...)
+
+	// exports
+	exports := []byte{0x03}
+	exports = append(append(exports, name("memory")...), 0x02, 0x00)
+	exports = append(append(exports, name("alloc")...), 0x00, 0x00)
+	exports = append(append(exports, name("transform")...), 0x00, 0x01)
+	module = append(module, section(0x07, exports)...)
+
+	// code
+	code := []byte{0x02, byte(len(allocBody))}
+	code = append(code, allocBody...)
+	code = append(code, byte(len(transformBody)))
+	code = append(code, transformBody...)
+	module = append(module, section(0x0a, code)...)
+
+	return module
+}
+
+func TestWASMPlugin_Transform(t *testing.T) {
+	ctx := context.Background()
+
+	plugin, err := NewWASMPlugin(ctx, "echo", buildTestModule(echoBody, 1), 1, time.Second)
+	require.NoError(t, err)
+	defer plugin.Close(ctx)
+
+	result, err := plugin.Transform("17011400", map[string]interface{}{"digits": 8})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "17011400", result)
+}
+
+func TestWASMPlugin_Timeout(t *testing.T) {
+	ctx := context.Background()
+
+	plugin, err := NewWASMPlugin(ctx, "loop", buildTestModule(loopBody, 1), 1, 50*time.Millisecond)
+	require.NoError(t, err)
+	defer plugin.Close(ctx)
+
+	start := time.Now()
+	_, err = plugin.Transform("x", nil)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeded timeout")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestWASMPlugin_MemoryLimit(t *testing.T) {
+	ctx := context.Background()
+
+	// Módulo exige 32 páginas (2MiB) com limite de 1MiB
+	plugin, err := NewWASMPlugin(ctx, "greedy", buildTestModule(echoBody, 32), 1, time.Second)
+	if err == nil {
+		defer plugin.Close(ctx)
+		_, err = plugin.Transform("x", nil)
+	}
+
+	assert.Error(t, err)
+}
+
+func TestWASMPlugin_MissingExports(t *testing.T) {
+	ctx := context.Background()
+
+	// Módulo vazio (apenas header)
+	_, err := NewWASMPlugin(ctx, "empty", []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}, 1, time.Second)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not export")
+}
+
+func TestEngine_RegisterWASMPlugins(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "plugins"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "plugins", "echo.wasm"), buildTestModule(echoBody, 1), 0644))
+
+	connectors := []*types.ConnectorConfig{
+		{
+			ID: "partner",
+			Integration: types.IntegrationConfig{
+				WASMPlugins: []types.WASMPluginConfig{
+					{Name: "partner_echo", Module: "plugins/echo.wasm", MaxMemoryMB: 1, Timeout: "200ms"},
+				},
+			},
+		},
+	}
+
+	engine := NewEngine()
+	err := engine.RegisterWASMPlugins(context.Background(), tmpDir, connectors)
+	require.NoError(t, err)
+
+	result, err := engine.Transform(map[string]interface{}{"codigo": "abc"}, &types.ResponseConfig{
+		Mapping:    map[string]string{"codigo": "$.codigo"},
+		Transforms: []types.TransformConfig{{Field: "codigo", Operation: "partner_echo"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", result["codigo"])
+
+	// Nome duplicado não sobrescreve plugin existente
+	err = engine.RegisterWASMPlugins(context.Background(), tmpDir, connectors)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already registered")
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"time"
 )
 
@@ -9,22 +10,74 @@ type ConnectorConfig struct {
 	ID            string                 `yaml:"id" json:"id"`
 	Name          string                 `yaml:"name" json:"name"`
 	Version       string                 `yaml:"version" json:"version"`
+	Rollout       *RolloutConfig         `yaml:"rollout,omitempty" json:"rollout,omitempty"` // versão canary (sem rollout = candidata a estável)
 	Provider      string                 `yaml:"provider" json:"provider"`
 	Integration   IntegrationConfig      `yaml:"integration" json:"integration"`
 	Environments  map[string]Environment `yaml:"environments" json:"environments"`
 	Compliance    ComplianceConfig       `yaml:"compliance" json:"compliance"`
 	Governance    GovernanceConfig       `yaml:"governance" json:"governance"`
 	Observability ObservabilityConfig    `yaml:"observability" json:"observability"`
+	Jobs          []JobConfig            `yaml:"jobs,omitempty" json:"jobs,omitempty"`
+}
+
+// RolloutConfig peso da versão no roteamento do tráfego sem versão fixada
+type RolloutConfig struct {
+	Weight int `yaml:"weight" json:"weight"` // % do tráfego (0 = apenas chamadas fixadas)
 }
 
 // IntegrationConfig configuração de integração
 type IntegrationConfig struct {
-	Type       string                    `yaml:"type" json:"type"`     // rest_api, soap, graphql, grpc
-	Protocol   string                    `yaml:"protocol" json:"protocol"` // http, https
-	Auth       AuthConfig                `yaml:"auth" json:"auth"`
-	Endpoints  map[string]EndpointConfig `yaml:"endpoints" json:"endpoints"`
-	Resilience ResilienceConfig          `yaml:"resilience" json:"resilience"`
-	Cache      CacheConfig               `yaml:"cache" json:"cache"`
+	Type        string                    `yaml:"type" json:"type"`         // rest_api, soap, graphql, grpc
+	Protocol    string                    `yaml:"protocol" json:"protocol"` // http, https
+	Auth        AuthConfig                `yaml:"auth" json:"auth"`
+	Endpoints   map[string]EndpointConfig `yaml:"endpoints" json:"endpoints"`
+	Resilience  ResilienceConfig          `yaml:"resilience" json:"resilience"`
+	Cache       CacheConfig               `yaml:"cache" json:"cache"`
+	WASMPlugins []WASMPluginConfig        `yaml:"wasm_plugins,omitempty" json:"wasm_plugins,omitempty"`
+}
+
+// WASMPluginConfig plugin de transformação distribuído como módulo WebAssembly
+type WASMPluginConfig struct {
+	Name        string `yaml:"name" json:"name"`
+	Module      string `yaml:"module" json:"module"` // caminho do .wasm (relativo ao diretório de configs)
+	MaxMemoryMB int    `yaml:"max_memory_mb,omitempty" json:"max_memory_mb,omitempty"`
+	Timeout     string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// JobConfig execução agendada de um endpoint com carga do resultado no Postgres
+type JobConfig struct {
+	Name        string            `yaml:"name" json:"name"`
+	Endpoint    string            `yaml:"endpoint" json:"endpoint"`
+	Schedule    string            `yaml:"schedule" json:"schedule"`                     // cron (5 campos), ex: "0 6 5 * *"
+	Timezone    string            `yaml:"timezone,omitempty" json:"timezone,omitempty"` // default: UTC
+	Environment string            `yaml:"environment,omitempty" json:"environment,omitempty"`
+	Params      map[string]string `yaml:"params,omitempty" json:"params,omitempty"` // templates de data, ex: "{{ year (addMonths -1 .Now) }}"
+	Pages       *JobPagesConfig   `yaml:"pages,omitempty" json:"pages,omitempty"`   // busca todas as páginas antes de gravar
+	Target      JobTargetConfig   `yaml:"target" json:"target"`
+	Retry       JobRetryConfig    `yaml:"retry,omitempty" json:"retry,omitempty"`
+}
+
+// JobTargetConfig tabela de destino do upsert
+type JobTargetConfig struct {
+	Table        string   `yaml:"table" json:"table"`                         // ex: stg.exportacao
+	Rows         string   `yaml:"rows" json:"rows"`                           // campo da saída mapeada com as linhas (array)
+	Columns      []string `yaml:"columns" json:"columns"`                     // colunas gravadas (chaves das linhas)
+	ConflictKeys []string `yaml:"conflict_keys" json:"conflict_keys"`         // chave única usada no ON CONFLICT
+	Sum          []string `yaml:"sum,omitempty" json:"sum,omitempty"`         // métricas somadas entre linhas da mesma chave
+	Replace      []string `yaml:"replace,omitempty" json:"replace,omitempty"` // colunas da chave cujo recorte (ex: ano+mês) é substituído
+}
+
+// JobPagesConfig paginação do endpoint do job (páginas 1..n na mesma execução)
+type JobPagesConfig struct {
+	Param    string `yaml:"param" json:"param"`                             // param da página, ex: page
+	Total    string `yaml:"total" json:"total"`                             // campo mapeado com o total de linhas (todas as páginas)
+	MaxPages int    `yaml:"max_pages,omitempty" json:"max_pages,omitempty"` // default: 100
+}
+
+// JobRetryConfig retries de uma execução agendada
+type JobRetryConfig struct {
+	MaxAttempts int    `yaml:"max_attempts,omitempty" json:"max_attempts,omitempty"` // default: 1
+	Backoff     string `yaml:"backoff,omitempty" json:"backoff,omitempty"`           // intervalo entre tentativas, default: 1m
 }
 
 // AuthConfig configuração de autenticação
@@ -60,6 +113,14 @@ type EndpointConfig struct {
 	Body        *BodyConfig            `yaml:"body,omitempty" json:"body,omitempty"`
 	Response    ResponseConfig         `yaml:"response" json:"response"`
 	Plugins     map[string]string      `yaml:"plugins,omitempty" json:"plugins,omitempty"`
+	Examples    []ExampleConfig        `yaml:"examples,omitempty" json:"examples,omitempty"`
+}
+
+// ExampleConfig exemplo de chamada usado nos contract tests (gateway test <connector>)
+type ExampleConfig struct {
+	Name   string                 `yaml:"name" json:"name"`
+	Params map[string]interface{} `yaml:"params" json:"params"`
+	Expect map[string]interface{} `yaml:"expect" json:"expect"` // subconjunto esperado da saída mapeada (aceita a.b)
 }
 
 // ParameterConfig configuração de parâmetro
@@ -72,6 +133,8 @@ type ParameterConfig struct {
 	MinLength int         `yaml:"min_length,omitempty" json:"min_length,omitempty"`
 	MaxLength int         `yaml:"max_length,omitempty" json:"max_length,omitempty"`
 	Default   interface{} `yaml:"default,omitempty" json:"default,omitempty"`
+	// CaseInsensitive o upstream ignora maiúsculas: o valor entra em minúsculas na chave de cache
+	CaseInsensitive bool `yaml:"case_insensitive,omitempty" json:"case_insensitive,omitempty"`
 }
 
 // BodyConfig configuração de body
@@ -84,8 +147,34 @@ type BodyConfig struct {
 type ResponseConfig struct {
 	SuccessStatus []int                  `yaml:"success_status" json:"success_status"`
 	ErrorStatus   []int                  `yaml:"error_status" json:"error_status"`
-	Mapping       map[string]string      `yaml:"mapping" json:"mapping"` // field -> JSONPath
+	Mapping       map[string]string      `yaml:"mapping" json:"mapping"` // field -> JSONPath (field aceita caminho aninhado: a.b)
+	Arrays        []ArrayMappingConfig   `yaml:"arrays,omitempty" json:"arrays,omitempty"`
 	Transforms    []TransformConfig      `yaml:"transforms,omitempty" json:"transforms,omitempty"`
+	Expressions   []ExpressionConfig     `yaml:"expressions,omitempty" json:"expressions,omitempty"`
+
+	// Contrato da resposta
+	Schema         map[string]interface{} `yaml:"schema,omitempty" json:"schema,omitempty"`                   // JSON Schema do payload upstream
+	RequiredFields []string               `yaml:"required_fields,omitempty" json:"required_fields,omitempty"` // campos obrigatórios na saída mapeada
+	Validation     string                 `yaml:"validation,omitempty" json:"validation,omitempty"`           // warn (default), strict
+}
+
+// ArrayMappingConfig mapeamento por elemento de array
+// Cada elemento encontrado em Path gera um objeto com Mapping/Transforms próprios
+type ArrayMappingConfig struct {
+	Field       string             `yaml:"field" json:"field"`
+	Path        string             `yaml:"path" json:"path"`       // JSONPath do array (ex: $.data[*])
+	Mapping     map[string]string  `yaml:"mapping" json:"mapping"` // field -> JSONPath relativo ao elemento
+	Transforms  []TransformConfig  `yaml:"transforms,omitempty" json:"transforms,omitempty"`
+	Expressions []ExpressionConfig `yaml:"expressions,omitempty" json:"expressions,omitempty"`
+	Required    []string           `yaml:"required,omitempty" json:"required,omitempty"`
+	OnMissing   string             `yaml:"on_missing,omitempty" json:"on_missing,omitempty"` // fail (default), skip
+}
+
+// ExpressionConfig campo calculado a partir de valores já mapeados
+// Ex: price_per_kg = vl_fob / kg_liquido
+type ExpressionConfig struct {
+	Field      string `yaml:"field" json:"field"`
+	Expression string `yaml:"expression" json:"expression"`
 }
 
 // TransformConfig configuração de transformação
@@ -126,9 +215,19 @@ type RateLimitConfig struct {
 
 // CacheConfig configuração de cache
 type CacheConfig struct {
-	Enabled    bool   `yaml:"enabled" json:"enabled"`
-	TTL        string `yaml:"ttl" json:"ttl"`
-	KeyPattern string `yaml:"key_pattern" json:"key_pattern"`
+	Enabled    bool                 `yaml:"enabled" json:"enabled"`
+	TTL        string               `yaml:"ttl" json:"ttl"`
+	KeyPattern string               `yaml:"key_pattern" json:"key_pattern"`                 // ex: "comexstat:{endpoint}:{ano}:{mes}"
+	Timezone   string               `yaml:"timezone,omitempty" json:"timezone,omitempty"`   // .Now das ttl_rules (default: UTC)
+	TTLRules   []CacheTTLRuleConfig `yaml:"ttl_rules,omitempty" json:"ttl_rules,omitempty"` // primeira regra que bate vence
+}
+
+// CacheTTLRuleConfig TTL diferenciado quando os params batem com when
+type CacheTTLRuleConfig struct {
+	Name      string            `yaml:"name,omitempty" json:"name,omitempty"`
+	Endpoints []string          `yaml:"endpoints,omitempty" json:"endpoints,omitempty"` // vazio = todos
+	When      map[string]string `yaml:"when" json:"when"`                               // valor ou template de data, ex: "{{ year .Now }}"
+	TTL       string            `yaml:"ttl" json:"ttl"`
 }
 
 // Environment configuração de ambiente
@@ -172,10 +271,15 @@ type AlertConfig struct {
 // ExecutionContext contexto de execução de uma requisição
 type ExecutionContext struct {
 	ConnectorID  string
+	Version      string // versão fixada pelo caller (vazio = roteamento canary)
 	EndpointName string
 	Environment  string
 	Params       map[string]interface{}
 	StartTime    time.Time
+	Context      context.Context // trace e cancelamento da request de origem (nil = context.Background())
+	RefreshCache bool            // ignora o cache na leitura e grava o resultado (pre-warm)
+	DryRun       bool            // monta a request sem chamar o upstream (resultado em Plan)
+	Explain      bool            // executa ignorando o cache na leitura e detalha as etapas (resultado em Explain)
 }
 
 // ExecutionResult resultado da execução
@@ -186,4 +290,7 @@ type ExecutionResult struct {
 	Error      error
 	CacheHit   bool
 	RetryCount int
+	Version    string       // versão do connector que atendeu a chamada
+	Plan       *RequestPlan // request montada (dry run)
+	Explain    *Explanation // etapas da execução (explain)
 }
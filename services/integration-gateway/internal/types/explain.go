@@ -0,0 +1,44 @@
+package types
+
+// MaskedValue valor exibido no lugar de credenciais (dry run)
+const MaskedValue = "***"
+
+// RequestPlan request montada pelo executor sem chamar o upstream (?dry_run=true)
+type RequestPlan struct {
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	Headers     map[string]string `json:"headers"` // valores de autenticação mascarados
+	Body        string            `json:"body,omitempty"`
+	Environment string            `json:"environment"`
+	Version     string            `json:"version"`
+	AuthType    string            `json:"auth_type"`
+	CacheKey    string            `json:"cache_key,omitempty"` // vazio = connector sem cache
+	CacheTTL    string            `json:"cache_ttl,omitempty"`
+	Timeout     string            `json:"timeout"`
+	Resilience  ResilienceConfig  `json:"resilience"`
+}
+
+// Explanation detalhes de uma execução real (?explain=true)
+type Explanation struct {
+	Steps          []StepTiming           `json:"steps"`
+	Attempts       []AttemptTrace         `json:"attempts,omitempty"`
+	Retries        int                    `json:"retries"`
+	CircuitBreaker string                 `json:"circuit_breaker,omitempty"` // closed, half-open, open (vazio = sem breaker)
+	CacheKey       string                 `json:"cache_key,omitempty"`
+	Raw            interface{}            `json:"raw,omitempty"`    // payload upstream (JSON ou texto)
+	Mapped         map[string]interface{} `json:"mapped,omitempty"` // saída após mapping e transforms
+}
+
+// StepTiming duração de uma etapa do executor
+type StepTiming struct {
+	Name     string `json:"name"`
+	Duration string `json:"duration"`
+}
+
+// AttemptTrace tentativa HTTP ao upstream
+type AttemptTrace struct {
+	Attempt    int    `json:"attempt"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Duration   string `json:"duration"`
+}
@@ -0,0 +1,13 @@
+package types
+
+import "strings"
+
+// VersionHeader header para fixar a versão do connector (alternativa a id@versão no path)
+const VersionHeader = "X-Connector-Version"
+
+// SplitConnectorRef separa uma referência id@versão (ex: comexstat@2.0.0)
+// O prefixo v é opcional na versão (comexstat@v2.0.0)
+func SplitConnectorRef(ref string) (id, version string) {
+	id, version, _ = strings.Cut(ref, "@")
+	return id, strings.TrimPrefix(version, "v")
+}